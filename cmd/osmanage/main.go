@@ -1,13 +1,19 @@
 package main
 
 import (
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"os"
+	"strings"
+	"time"
 
 	grpcServer "github.com/OpenSlides/openslides-cli/internal/grpc/server"
 	"github.com/OpenSlides/openslides-cli/internal/instance/config"
 	"github.com/OpenSlides/openslides-cli/internal/instance/create"
 	"github.com/OpenSlides/openslides-cli/internal/instance/remove"
+	"github.com/OpenSlides/openslides-cli/internal/instance/secrets"
 	"github.com/OpenSlides/openslides-cli/internal/instance/setup"
 	k8sActions "github.com/OpenSlides/openslides-cli/internal/k8s/actions"
 	"github.com/OpenSlides/openslides-cli/internal/logger"
@@ -18,32 +24,75 @@ import (
 	"github.com/OpenSlides/openslides-cli/internal/manage/actions/migrations"
 	"github.com/OpenSlides/openslides-cli/internal/manage/actions/set"
 	"github.com/OpenSlides/openslides-cli/internal/manage/actions/setpassword"
+	"github.com/OpenSlides/openslides-cli/internal/manage/client"
+	"github.com/OpenSlides/openslides-cli/internal/utils"
 
 	"github.com/spf13/cobra"
 )
 
 const RootHelp = `osmanage is an admin tool to perform management actions on OpenSlides instances.`
 
+// Values of --error-format
+const (
+	ErrorFormatText = "text"
+	ErrorFormatJSON = "json"
+)
+
+// errorFormatFlag selects how RunClient prints the final error
+const errorFormatFlag = "error-format"
+
 func main() {
 	code := RunClient()
 	os.Exit(code)
 }
 
 func RunClient() int {
-	err := RootCmd().Execute()
+	cmd, err := RootCmd().ExecuteC()
+	if closeErr := logger.Close(); closeErr != nil {
+		fmt.Fprintf(os.Stderr, "Error closing log file: %v\n", closeErr)
+	}
 
 	if err == nil {
 		return 0
 	}
 
-	code := 1
-	fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+	var exitErr *utils.ExitError
+	if errors.As(err, &exitErr) {
+		return exitErr.Code
+	}
+
+	format, _ := cmd.Flags().GetString(errorFormatFlag)
+	printError(os.Stderr, format, cmd.CommandPath(), err)
 
-	return code
+	return 1
+}
+
+// printError prints the error of command to w, as JSON object with
+// --error-format json and as plain text otherwise
+func printError(w io.Writer, format, command string, err error) {
+	if format == ErrorFormatJSON {
+		encodeErr := json.NewEncoder(w).Encode(struct {
+			Error   string `json:"error"`
+			Command string `json:"command"`
+		}{err.Error(), command})
+		if encodeErr == nil {
+			return
+		}
+	}
+	fmt.Fprintf(w, "Error: %v\n", err)
 }
 
 func RootCmd() *cobra.Command {
 	var logLevel string
+	var logFile string
+	var logFileOnly bool
+	var noColor bool
+	var noEmoji bool
+	var noProgress bool
+	var logSecrets bool
+	var timeout time.Duration
+	var configFile string
+	var errorFormat string
 
 	rootCmd := &cobra.Command{
 		Use:               "osmanage",
@@ -55,14 +104,66 @@ func RootCmd() *cobra.Command {
 	}
 
 	rootCmd.PersistentFlags().StringVar(&logLevel, "log-level", "warn", "Log level (debug, info, warn, error)")
+	rootCmd.PersistentFlags().StringVar(&logFile, "log-file", "", "Also write logs to this file (appended)")
+	rootCmd.PersistentFlags().BoolVar(&logFileOnly, "log-file-only", false, "Write logs only to --log-file, not to stderr")
+	rootCmd.PersistentFlags().BoolVar(&noColor, "no-color", false, "Disable colored log and status output")
+	rootCmd.PersistentFlags().BoolVar(&noEmoji, "no-emoji", false, "Use ASCII status icons and progress bars")
+	rootCmd.PersistentFlags().BoolVar(&noProgress, "no-progress", false, "Print plain progress lines instead of progress bars (default when stdout is not a terminal)")
+	rootCmd.PersistentFlags().BoolVar(&logSecrets, "log-secrets", false, "Do not redact the Authorization header and cookies in debug logs of backend requests")
+	rootCmd.PersistentFlags().StringVar(&configFile, utils.ConfigFileFlag, "", "YAML file with default flag values, keyed by flag name (default: "+utils.DefaultConfigFile()+" if it exists)")
+	rootCmd.PersistentFlags().StringVar(&errorFormat, errorFormatFlag, ErrorFormatText, "Format of the final error message on stderr ("+ErrorFormatText+", "+ErrorFormatJSON+")")
+	rootCmd.PersistentFlags().DurationVar(&timeout, utils.TimeoutFlag, 0, "Timeout for network and Kubernetes operations (default: per command, e.g. 3m for k8s health checks, 5m for k8s stop, none for backend requests)")
 
 	rootCmd.PersistentPreRunE = func(cmd *cobra.Command, args []string) error {
-		log, err := logger.New(logLevel)
+		optional := configFile == ""
+		if optional {
+			configFile = utils.DefaultConfigFile()
+		}
+		var configApplied []string
+		if configFile != "" {
+			applied, err := utils.ApplyConfigFile(cmd, configFile, optional)
+			if err != nil {
+				return err
+			}
+			configApplied = applied
+		}
+
+		if errorFormat != ErrorFormatText && errorFormat != ErrorFormatJSON {
+			return fmt.Errorf("invalid --error-format %q (available: %s, %s)", errorFormat, ErrorFormatText, ErrorFormatJSON)
+		}
+		if logFileOnly && logFile == "" {
+			return fmt.Errorf("--log-file-only requires --log-file")
+		}
+		// k8s commands define their own --timeout, which shadows the global one
+		if t, err := cmd.Flags().GetDuration(utils.TimeoutFlag); err == nil && utils.FlagGiven(cmd, utils.TimeoutFlag) && t <= 0 {
+			return fmt.Errorf("--timeout must be positive")
+		}
+
+		var log *logger.Logger
+		var err error
+		if logFile != "" {
+			log, err = logger.NewWithFile(logLevel, logFile, logFileOnly)
+		} else {
+			log, err = logger.New(logLevel)
+		}
 		if err != nil {
-			return fmt.Errorf("invalid log level: %w", err)
+			return fmt.Errorf("initializing logger: %w", err)
+		}
+		if noColor {
+			log.DisableColor()
 		}
+		k8sActions.SetASCII(noEmoji)
+		k8sActions.SetColor(!noColor)
+		k8sActions.SetProgress(!noProgress)
+		client.SetLogSecrets(logSecrets)
 		logger.SetGlobal(log)
 		logger.Debug("Logger initialized at level: %s", logLevel)
+		if logFile != "" {
+			logger.Debug("Writing logs to file: %s", logFile)
+		}
+		if len(configApplied) > 0 {
+			logger.Debug("Using %s from config file %s", strings.Join(configApplied, ", "), configFile)
+		}
 		return nil
 	}
 
@@ -75,6 +176,7 @@ func RootCmd() *cobra.Command {
 
 	k8sCmd.AddCommand(
 		k8sActions.StartCmd(),
+		k8sActions.DiffCmd(),
 		k8sActions.StopCmd(),
 		k8sActions.HealthCmd(),
 		k8sActions.ClusterStatusCmd(),
@@ -91,6 +193,7 @@ func RootCmd() *cobra.Command {
 		config.Cmd(),
 		create.Cmd(),
 		remove.Cmd(),
+		secrets.Cmd(),
 		createuser.Cmd(),
 		initialdata.Cmd(),
 		setpassword.Cmd(),
@@ -1,6 +1,11 @@
 package main
 
 import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"strings"
 	"testing"
 )
 
@@ -57,3 +62,33 @@ func TestRunClient(t *testing.T) {
 	// but we can at least verify the function exists and compiles
 	_ = RunClient
 }
+
+func TestPrintError(t *testing.T) {
+	var buf bytes.Buffer
+	printError(&buf, ErrorFormatText, "osmanage get", errors.New("connection refused"))
+	if buf.String() != "Error: connection refused\n" {
+		t.Errorf("Unexpected text error: %q", buf.String())
+	}
+
+	buf.Reset()
+	printError(&buf, ErrorFormatJSON, "osmanage get", errors.New(`query failed: "user" not found`))
+	var got map[string]string
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatalf("Expected JSON error, got %q: %v", buf.String(), err)
+	}
+	if got["error"] != `query failed: "user" not found` || got["command"] != "osmanage get" {
+		t.Errorf("Unexpected JSON error: %v", got)
+	}
+}
+
+func TestRootCmd_InvalidErrorFormat(t *testing.T) {
+	cmd := RootCmd()
+	cmd.SetArgs([]string{"--error-format", "xml", "k8s", "health", t.TempDir()})
+	cmd.SetOut(io.Discard)
+	cmd.SetErr(io.Discard)
+
+	err := cmd.Execute()
+	if err == nil || !strings.Contains(err.Error(), "invalid --error-format") {
+		t.Errorf("Expected invalid --error-format error, got %v", err)
+	}
+}
@@ -0,0 +1,92 @@
+// Package contrib embeds the default deployment templates and configs, used by
+// setup and config when no --template is given. They are grouped in profiles,
+// one per kind of deployment.
+package contrib
+
+import (
+	"embed"
+	"fmt"
+	"io/fs"
+)
+
+const (
+	// ProfileDocker renders a single Docker Compose file
+	ProfileDocker = "docker"
+	// ProfileK8s renders a namespace manifest and a stack/ directory of
+	// Kubernetes manifests
+	ProfileK8s = "k8s"
+	// DefaultProfile is the profile used if none is given
+	DefaultProfile = ProfileK8s
+)
+
+// DockerComposeTemplateName is the file name of DockerComposeTemplate
+const DockerComposeTemplateName = "docker-compose.yml.tmpl"
+
+// DockerComposeTemplate is the default Docker Compose template
+//
+//go:embed docker-compose.yml.tmpl
+var DockerComposeTemplate []byte
+
+// ExampleConfigName is the file name of ExampleConfig
+const ExampleConfigName = "example-config.yml"
+
+// ExampleConfig is the default config for DockerComposeTemplate
+//
+//go:embed example-config.yml
+var ExampleConfig []byte
+
+// K8sExampleConfigName is the file name of K8sExampleConfig
+const K8sExampleConfigName = "k8s-example-config.yml"
+
+// K8sExampleConfig is the default config for the templates in k8s/
+//
+//go:embed k8s-example-config.yml
+var K8sExampleConfig []byte
+
+//go:embed k8s
+var k8sTemplates embed.FS
+
+// Profile is a built-in template set with its example config. Either Template
+// or Templates is set.
+type Profile struct {
+	Name string
+
+	// TemplateName and Template are a single template, rendered to the
+	// filename given in the config
+	TemplateName string
+	Template     []byte
+
+	// Templates is a template directory, rendered to the same layout in the
+	// instance directory
+	Templates fs.FS
+
+	ConfigName string
+	Config     []byte
+}
+
+// LookupProfile returns the profile with the given name
+func LookupProfile(name string) (Profile, error) {
+	switch name {
+	case ProfileDocker:
+		return Profile{
+			Name:         ProfileDocker,
+			TemplateName: DockerComposeTemplateName,
+			Template:     DockerComposeTemplate,
+			ConfigName:   ExampleConfigName,
+			Config:       ExampleConfig,
+		}, nil
+	case ProfileK8s:
+		templates, err := fs.Sub(k8sTemplates, "k8s")
+		if err != nil {
+			return Profile{}, fmt.Errorf("opening embedded k8s templates: %w", err)
+		}
+		return Profile{
+			Name:       ProfileK8s,
+			Templates:  templates,
+			ConfigName: K8sExampleConfigName,
+			Config:     K8sExampleConfig,
+		}, nil
+	default:
+		return Profile{}, fmt.Errorf("unknown profile %q (available: %s, %s)", name, ProfileDocker, ProfileK8s)
+	}
+}
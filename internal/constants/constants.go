@@ -46,6 +46,14 @@ const (
 	// TlsCertSecretYAML is the manifest file for the kubernetes secret enabling HTTPS
 	TlsCertSecretYAML string = "tls-letsencrypt-secret.yaml"
 
+	// SavedSecretSuffix is appended to the secret name for manifests saved by
+	// k8s stop, like TlsCertSecretYAML. k8s start applies all such files.
+	SavedSecretSuffix string = "-secret.yaml"
+
+	// SaveSecretLabel marks secrets that k8s stop saves besides the TLS secret,
+	// with value "true"
+	SaveSecretLabel string = "osmanage/save-secret"
+
 	// DefaultTemplatingOutputFilename is the filename used, if none is set in config file(s)
 	DefaultTemplatingOutputFilename string = "os-deployment.yaml"
 
@@ -57,6 +65,9 @@ const (
 
 	// CertKeyName is filename for the HTTPS key file
 	CertKeyName string = "cert_key"
+
+	// InventoryFile in the instance root directory maps generated files to their SHA-256 digest
+	InventoryFile string = ".osmanage-manifest.json"
 )
 
 // File permissions
@@ -67,6 +78,10 @@ const (
 	// SecretFilePerm is the permission for secret files (owner read/write only)
 	SecretFilePerm fs.FileMode = 0644
 
+	// SavedSecretFilePerm is the permission for secret manifests saved by k8s stop
+	// and for secrets backups (owner read/write only)
+	SavedSecretFilePerm fs.FileMode = 0600
+
 	// InstanceDirPerm is the permission for project root directory (owner + others read)
 	InstanceDirPerm fs.FileMode = 0755
 
@@ -75,6 +90,9 @@ const (
 
 	// StackFilePerm is the permission for manifest files (owner write, others read)
 	StackFilePerm fs.FileMode = 0644
+
+	// OutputFilePerm is the permission for files written with --output-file (owner write, others read)
+	OutputFilePerm fs.FileMode = 0644
 )
 
 // Secret generation defaults
@@ -98,7 +116,24 @@ const (
 	// PasswordCharset defines allowed characters for randomly generated passwords.
 	// Includes lowercase, uppercase, digits, and safe special characters.
 	// Used for generating postgres_password and superadmin passwords.
-	PasswordCharset string = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789!@#$%^&*()-_=+[]"
+	PasswordCharset string = PasswordCharsetAlnum + "!@#$%^&*()-_=+[]"
+
+	// PasswordCharsetAlnum contains only letters and digits, for passwords that
+	// end up in connection strings or other places without escaping.
+	PasswordCharsetAlnum string = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"
+
+	// PasswordCharsetSafe adds the special characters that need no quoting in
+	// shells and URLs. The other symbols of PasswordCharset are left out:
+	//   ! $        history and variable expansion in shells
+	//   # &        shell comment and background operator, URL fragment and query separator
+	//   * ( ) [ ]  shell globbing and subshells, [ ] also enclose IPv6 hosts in URLs
+	//   ^          history substitution in shells, not allowed unescaped in URLs
+	//   @ %        end of the userinfo and start of escapes in URLs
+	//   =          key/value separator in query and libpq connection strings
+	//   +          decoded as space in form-encoded query strings
+	// "~", the only other unreserved URL character, expands to the home
+	// directory at the start of a shell word.
+	PasswordCharsetSafe string = PasswordCharsetAlnum + "-_."
 )
 
 // Default timeouts for Kubernetes operations
@@ -106,8 +141,24 @@ const (
 	DefaultInstanceTimeout   time.Duration = 3 * time.Minute // Wait for all instance pods to become ready
 	DefaultDeploymentTimeout time.Duration = 3 * time.Minute // Wait for deployment rollout to complete
 	DefaultNamespaceTimeout  time.Duration = 5 * time.Minute // Wait for namespace deletion (includes finalizers)
+	ForceFinalizeTimeout     time.Duration = 1 * time.Minute // Wait for namespace deletion after k8s stop --force-finalize
+	HealthProbeTimeout       time.Duration = 5 * time.Second // Single HTTP probe of a pod with --probe-path
 )
 
+// HealthProbePortName is the container port name probed by k8s health --probe-path
+const HealthProbePortName string = "http"
+
+// ManagedByLabel marks resources applied by k8s start --prune, with value
+// ManagedByValue. Only resources carrying it are pruned.
+const (
+	ManagedByLabel string = "app.kubernetes.io/managed-by"
+	ManagedByValue string = "osmanage"
+)
+
+// DefaultInstanceLabel is the label key set to the namespace on applied
+// resources when --instance-label is given without a value
+const DefaultInstanceLabel string = "app.kubernetes.io/instance"
+
 // constants for wait functions in health_check.go
 const (
 	// progress bar settings
@@ -125,6 +176,15 @@ const (
 	TickerDuration time.Duration = 2 * time.Second // checks health conditions every tick
 	IconReady      string        = "✓"             // for pod/deployment status printouts
 	IconNotReady   string        = "✗"
+
+	// pods with more container restarts are flagged in health printouts
+	RestartWarnThreshold int32 = 3
+
+	// ASCII fallbacks used with --no-emoji
+	IconReadyASCII     string = "[OK]"
+	IconNotReadyASCII  string = "[FAIL]"
+	SaucerASCII        string = "#"
+	SaucerPaddingASCII string = "-"
 )
 
 // OpenSlides K8s resource names and templates
@@ -147,6 +207,9 @@ const (
 	// BackendHTTPScheme is the HTTP scheme used for backend connections
 	BackendHTTPScheme string = "http://"
 
+	// BackendHTTPSScheme is the scheme used for TLS backend connections, e.g. through an ingress
+	BackendHTTPSScheme string = "https://"
+
 	// BackendHandleRequestPath is the API endpoint for sending actions
 	BackendHandleRequestPath string = "/internal/handle_request"
 
@@ -155,6 +218,15 @@ const (
 
 	// BackendContentType is the Content-Type header for backend requests
 	BackendContentType string = "application/json"
+
+	// InitialDataFetchTimeout bounds fetching initial data from an http(s) URL
+	InitialDataFetchTimeout time.Duration = 30 * time.Second
+
+	// BackendWaitInterval is the delay between connection attempts of --wait-for-backend
+	BackendWaitInterval time.Duration = 2 * time.Second
+
+	// WaitForBackendUsage is the help text of the --wait-for-backend flag of manage commands
+	WaitForBackendUsage = "wait up to this duration for the backendManage address to accept connections before sending the request, e.g. 2m (default: no waiting)"
 )
 
 // Environment variable keys (used by get command)
@@ -200,12 +272,15 @@ const (
 
 	// DefaultOrganizationFields are the default fields fetched for organization queries
 	DefaultOrganizationFields string = "id,name"
+
+	// DefaultStreamChunkSize is the number of models fetched at once by get --stream
+	DefaultStreamChunkSize int = 1000
 )
 
 // Connect flags defaults
 const (
-	// DefaultBackendManageAddress is the default address for reaching backendManage
-	DefaultBackendManageAddress = "localhost:9002"
+	// BackendManageAddressUsage is the help text of the --address flag of manage commands
+	BackendManageAddressUsage = "address of the OpenSlides backendManage service, host:port or https://host[:port] (default: $" + EnvOsmanageBackendAddress + ")"
 
 	// DefaultPasswordFile is the default file read when authenticating to backendManage
 	// TODO : const + "/" + const
@@ -217,6 +292,15 @@ const (
 	// DefaultMigrationProgressInterval is the default interval for checking migration progress
 	DefaultMigrationProgressInterval time.Duration = 1 * time.Second
 
+	// MigrationStatusRequired indicates migrations are pending and not yet run
+	MigrationStatusRequired string = "migration_required"
+
+	// FinalizationStatusRequired indicates migrations ran but are not yet applied to the live tables
+	FinalizationStatusRequired string = "finalization_required"
+
+	// ExitCodeMigrationsRequired is the exit code of the stats subcommand if migrations are required
+	ExitCodeMigrationsRequired int = 3
+
 	// MigrationStatusRunning indicates a migration is currently in progress
 	MigrationStatusRunning string = "migration_running"
 
@@ -3,6 +3,7 @@ package server
 import (
 	"context"
 
+	"github.com/OpenSlides/openslides-cli/contrib"
 	instanceconfig "github.com/OpenSlides/openslides-cli/internal/instance/config"
 	pb "github.com/OpenSlides/openslides-cli/proto/osmanage"
 )
@@ -13,8 +14,12 @@ func (s *OsmanageServiceServer) ConfigInstance(ctx context.Context, req *pb.Inst
 		req.Force,
 		req.Clean,
 		req.StackTemplatePath,
+		contrib.DefaultProfile,
 		nil,
 		req.Configs,
+		nil,
+		instanceconfig.LoadOptions{},
+		false,
 	)
 	if err != nil {
 		return &pb.InstanceConfigResponse{Success: false, Error: err.Error()}, nil
@@ -1,12 +1,12 @@
 package server
 
 import (
-	"strings"
 	"time"
 
 	"github.com/OpenSlides/openslides-cli/internal/constants"
 	"github.com/OpenSlides/openslides-cli/internal/k8s/actions"
 	"github.com/OpenSlides/openslides-cli/internal/k8s/client"
+	"github.com/OpenSlides/openslides-cli/internal/utils"
 	pb "github.com/OpenSlides/openslides-cli/proto/osmanage"
 )
 
@@ -14,7 +14,13 @@ func (s *OsmanageServiceServer) GetInstanceHealth(
 	req *pb.GetInstanceHealthRequest,
 	stream pb.OsmanageService_GetInstanceHealthServer,
 ) error {
-	namespace := strings.ReplaceAll(req.InstanceUrl, ".", "")
+	namespace := utils.ExtractNamespace(req.InstanceUrl)
+	if err := utils.ValidateNamespace(namespace); err != nil {
+		return stream.Send(&pb.GetInstanceHealthResponse{
+			Complete: true,
+			Error:    err.Error(),
+		})
+	}
 
 	k8sClient, err := client.New(req.Kubeconfig)
 	if err != nil {
@@ -42,7 +48,7 @@ func (s *OsmanageServiceServer) GetInstanceHealth(
 			return stream.Send(healthStatusToHealthResponse(status, false))
 		}
 
-		err := actions.WaitForInstanceHealthy(ctx, k8sClient, namespace, timeout, streamCallback)
+		err := actions.WaitForInstanceHealthy(ctx, k8sClient, namespace, timeout, actions.HealthThreshold{}, "", streamCallback)
 
 		if err != nil {
 			return stream.Send(&pb.GetInstanceHealthResponse{
@@ -56,7 +62,7 @@ func (s *OsmanageServiceServer) GetInstanceHealth(
 		})
 	}
 
-	status, err := actions.GetHealthStatus(ctx, k8sClient, namespace)
+	status, err := actions.GetHealthStatus(ctx, k8sClient, namespace, actions.HealthThreshold{}, "")
 	if err != nil {
 		return stream.Send(&pb.GetInstanceHealthResponse{
 			Complete: true,
@@ -17,6 +17,9 @@ func (s *OsmanageServiceServer) GetInstanceStatus(ctx context.Context, req *pb.G
 	}
 
 	namespace := utils.ExtractNamespace(req.InstanceUrl)
+	if err := utils.ValidateNamespace(namespace); err != nil {
+		return nil, err
+	}
 	status, err := actions.GetInstanceStatus(ctx, k8sClient, namespace)
 	if err != nil {
 		return nil, fmt.Errorf("getting instance status: %w", err)
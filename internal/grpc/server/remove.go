@@ -8,7 +8,7 @@ import (
 )
 
 func (s *OsmanageServiceServer) RemoveInstance(ctx context.Context, req *pb.RemoveInstanceRequest) (*pb.RemoveInstanceResponse, error) {
-	if err := remove.RemoveInstance(req.InstanceDir, req.Force); err != nil {
+	if err := remove.RemoveInstance(req.InstanceDir, req.Force, ""); err != nil {
 		return &pb.RemoveInstanceResponse{Success: false, Error: err.Error()}, nil
 	}
 	return &pb.RemoveInstanceResponse{Success: true}, nil
@@ -15,7 +15,7 @@ func (s *OsmanageServiceServer) SendManageAction(ctx context.Context, req *pb.Se
 	}
 
 	cl := manageclient.New(req.AddressBackendmanage, password)
-	resp, err := cl.SendAction(req.Action, req.Payload)
+	resp, err := cl.SendActionContext(ctx, req.Action, req.Payload)
 	if err != nil {
 		return &pb.SendManageActionResponse{Success: false, Error: err.Error()}, nil
 	}
@@ -3,6 +3,9 @@ package server
 import (
 	"context"
 
+	"github.com/OpenSlides/openslides-cli/contrib"
+	"github.com/OpenSlides/openslides-cli/internal/constants"
+	instanceconfig "github.com/OpenSlides/openslides-cli/internal/instance/config"
 	"github.com/OpenSlides/openslides-cli/internal/instance/setup"
 	pb "github.com/OpenSlides/openslides-cli/proto/osmanage"
 )
@@ -10,11 +13,16 @@ import (
 func (s *OsmanageServiceServer) SetupInstance(ctx context.Context, req *pb.InstanceConfigRequest) (*pb.InstanceConfigResponse, error) {
 	err := setup.Run(
 		req.InstanceDir,
-		req.Force,
+		setup.ForceAll(req.Force),
+		constants.PasswordCharset,
 		req.Clean,
 		req.StackTemplatePath,
+		contrib.DefaultProfile,
 		nil,
 		req.Configs,
+		nil,
+		instanceconfig.LoadOptions{},
+		false,
 	)
 	if err != nil {
 		return &pb.InstanceConfigResponse{Success: false, Error: err.Error()}, nil
@@ -37,7 +37,12 @@ func (s *OsmanageServiceServer) StartInstance(
 		return stream.Send(healthStatusToStartResponse(status, false))
 	}
 
-	err = actions.StartInstance(ctx, k8sClient, req.InstanceDir, req.SkipReadyCheck, timeout, req.Labels, streamCallback)
+	opts := actions.StartOptions{
+		SkipReadyCheck: req.SkipReadyCheck,
+		Timeout:        timeout,
+		Labels:         req.Labels,
+	}
+	err = actions.StartInstance(ctx, k8sClient, req.InstanceDir, opts, streamCallback)
 	if err != nil {
 		return stream.Send(&pb.StartInstanceResponse{
 			Complete: true,
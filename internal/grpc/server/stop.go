@@ -27,7 +27,7 @@ func (s *OsmanageServiceServer) StopInstance(
 		timeout = constants.DefaultNamespaceTimeout
 	}
 
-	err = actions.StopInstance(ctx, k8sClient, req.InstanceDir, timeout,
+	err = actions.StopInstance(ctx, k8sClient, req.InstanceDir, timeout, nil, false, false,
 		func(elapsedSeconds int) error {
 			return stream.Send(&pb.StopInstanceResponse{
 				Complete:       false,
@@ -2,16 +2,22 @@ package config
 
 import (
 	"bytes"
-	_ "embed"
 	"encoding/base64"
 	"errors"
 	"fmt"
 	"io/fs"
+	"math"
+	"net"
 	"os"
 	"path/filepath"
+	"reflect"
+	"slices"
+	"sort"
+	"strconv"
 	"strings"
 	"text/template"
 
+	"github.com/OpenSlides/openslides-cli/contrib"
 	"github.com/OpenSlides/openslides-cli/internal/constants"
 	"github.com/OpenSlides/openslides-cli/internal/logger"
 	"github.com/OpenSlides/openslides-cli/internal/utils"
@@ -19,6 +25,7 @@ import (
 	"dario.cat/mergo"
 	"github.com/ghodss/yaml"
 	"github.com/spf13/cobra"
+	"k8s.io/apimachinery/pkg/util/validation"
 )
 
 const (
@@ -26,19 +33,55 @@ const (
 	ConfigHelpExtra = `(Re)creates deployment configuration files from templates.
 
 Generates deployment files (Docker Compose or Kubernetes manifests) using
-templates and YAML configuration files. Multiple config files are deep-merged
-in order, with later file's fields overriding earlier ones.
+templates and YAML configuration files. Without --template, the built-in
+templates of --profile are used with their example config as base, so
+--config files only need to contain changes:
+  • k8s    - namespace.yaml and stack/ with a manifest per resource (default)
+  • docker - a single Docker Compose file named by the filename config key
+
+Multiple config files are deep-merged
+in order: maps are merged key by key, while scalars and lists of later files
+replace earlier ones. With --merge-append-lists, lists of later files are
+appended to earlier ones instead.
+
+With --expand-env, ${VAR} and $VAR in string values of the config files are
+replaced by environment variables. Unset variables are an error unless a
+default is given with ${VAR:-default}; write $$ for a literal $.
+
+With --strict-templates, templates referencing a config key that is not set
+fail with an error naming the key instead of rendering "<no value>". Templates
+relying on {{ or .key "default" }} for optional keys need {{ or (index . "key") "default" }}
+in this mode.
 
 Template functions available:
   • marshalContent - Marshal YAML content with indentation
   • envMapToK8S     - Convert environment map to Kubernetes format
   • readSecret     - Read and base64-encode secrets from secrets/ directory
+  • namespace      - Kubernetes namespace derived from the instance directory name
+  • default        - Fall back to a value if the piped one is empty, e.g. {{ .url | default "localhost" }}
+  • quote          - Wrap a value in double quotes, escaping as needed
+  • upper, lower   - Change the case of a string
+  • trim           - Remove leading and trailing whitespace
+  • b64enc         - Base64-encode a string
+  • dict           - Build a map from key-value pairs, e.g. {{ index . "services" | default dict }}
+
+Functions can be chained: {{ .url | default "localhost" | quote }}
 
 Examples:
   osmanage config ./my.instance.dir.org
+  osmanage config ./my.instance.dir.org --config ./overrides.yaml
+  osmanage config ./my.instance.dir.org --profile docker
   osmanage config ./my.instance.dir.org --template ./custom.tmpl --config ./config.yaml
   osmanage config ./my.instance.dir.org -t ./k8s-templates -c base.yaml -c overrides.yaml
-  osmanage config ./my.instance.dir.org --force`
+  osmanage config ./my.instance.dir.org -t ./k8s-templates -c config.yaml --var defaults.tag=4.3.0
+  osmanage config ./my.instance.dir.org -t ./k8s-templates -c config.yaml --expand-env
+  osmanage config ./my.instance.dir.org -t ./k8s-templates -c base.yaml -c extra-services.yaml --merge-append-lists
+  osmanage config ./my.instance.dir.org --force
+  osmanage config ./my.instance.dir.org -t ./k8s-templates -c config.yaml --strict-templates
+
+Use "osmanage config verify <instance-dir>" to check the generated files for changes,
+"osmanage config render" to print a single rendered template and
+"osmanage config explain <config-file>..." to see which config file set each value.`
 )
 
 // Cmd returns the subcommand.
@@ -54,7 +97,13 @@ func Cmd() *cobra.Command {
 	clean := cmd.Flags().Bool("clean", false, "Wipe stack folder contents before generating new files")
 	customTemplate := cmd.Flags().StringP("template", "t", "", "custom template file or directory")
 	configFiles := cmd.Flags().StringArrayP("config", "c", nil, "custom YAML config file (can be used multiple times)")
-	cmd.MarkFlagsRequiredTogether("template", "config")
+	vars := cmd.Flags().StringArray("var", nil, "override a config value with a dotted key, e.g. defaults.tag=4.3.0 (can be used multiple times)")
+	expandEnv := cmd.Flags().Bool("expand-env", false, ExpandEnvUsage)
+	appendLists := cmd.Flags().Bool("merge-append-lists", false, AppendListsUsage)
+	strictTemplates := cmd.Flags().Bool("strict-templates", false, StrictTemplatesUsage)
+	profile := cmd.Flags().String("profile", contrib.DefaultProfile, ProfileUsage)
+
+	cmd.AddCommand(VerifyCmd(), RenderCmd(), ExplainCmd())
 
 	cmd.RunE = func(cmd *cobra.Command, args []string) error {
 		logger.Info("=== CONFIG ===")
@@ -62,8 +111,14 @@ func Cmd() *cobra.Command {
 		baseDir := args[0]
 		logger.Debug("Base directory: %s", baseDir)
 		logger.Debug("Config files: %v", *configFiles)
+		logger.Debug("Config vars: %v", *vars)
 
-		if err := Run(baseDir, *force, *clean, *customTemplate, *configFiles, nil); err != nil {
+		if cmd.Flags().Changed("profile") && *customTemplate != "" {
+			return fmt.Errorf("--profile cannot be used with --template")
+		}
+
+		opts := LoadOptions{ExpandEnv: *expandEnv, AppendLists: *appendLists}
+		if err := Run(baseDir, *force, *clean, *customTemplate, *profile, *configFiles, nil, *vars, opts, *strictTemplates); err != nil {
 			return err
 		}
 
@@ -76,18 +131,30 @@ func Cmd() *cobra.Command {
 }
 
 // Run merges configFiles and optional instanceConfig (merged last, wins on conflict)
-// into a config map, then generates deployment files from the template into baseDir.
-func Run(baseDir string, force, clean bool, customTemplate string, configFiles []string, configs [][]byte) error {
+// into a config map as set by opts, applies vars on top, then generates
+// deployment files from the template into baseDir. Without customTemplate, the
+// templates and example config of the built-in profile are used. With
+// strictTemplates, templates fail on config keys that are not set.
+func Run(baseDir string, force, clean bool, customTemplate, profile string, configFiles []string, configs [][]byte, vars []string, opts LoadOptions, strictTemplates bool) error {
+	if customTemplate == "" {
+		if _, err := contrib.LookupProfile(profile); err != nil {
+			return err
+		}
+		opts.Profile = profile
+	}
 	if clean {
 		if err := os.RemoveAll(filepath.Join(baseDir, "stack")); err != nil {
 			return fmt.Errorf("cleaning stack folder: %w", err)
 		}
 	}
-	cfg, err := NewConfig(configFiles, configs)
+	cfg, err := LoadConfig(configFiles, configs, opts)
 	if err != nil {
 		return fmt.Errorf("parsing configuration: %w", err)
 	}
-	if err := CreateDirAndFiles(baseDir, force, customTemplate, cfg); err != nil {
+	if err := ApplyVars(cfg, vars); err != nil {
+		return fmt.Errorf("applying config vars: %w", err)
+	}
+	if err := CreateDirAndFiles(baseDir, force, customTemplate, profile, cfg, strictTemplates); err != nil {
 		return fmt.Errorf("creating deployment files: %w", err)
 	}
 	return nil
@@ -99,20 +166,56 @@ func Run(baseDir string, force, clean bool, customTemplate string, configFiles [
 // - configFiles: path-based configs for direct CLI use, files are read from disk
 // - configs: pre-read byte slices for gRPC use, files are read on the client side
 func NewConfig(configFiles []string, configs [][]byte) (map[string]any, error) {
+	return LoadConfig(configFiles, configs, LoadOptions{})
+}
+
+// LoadOptions controls how LoadConfig reads and merges configs. The zero value
+// takes values literally and lets lists of later configs replace earlier ones.
+type LoadOptions struct {
+	// ExpandEnv expands environment variables in the string values of each
+	// config before it is validated and merged, see ExpandEnv
+	ExpandEnv bool
+	// AppendLists appends lists of later configs to those of earlier ones
+	// instead of replacing them. Maps are always merged key by key and
+	// scalars always replaced.
+	AppendLists bool
+	// Profile, if set, names the built-in profile whose example config is
+	// merged first, as base for its templates, see contrib.LookupProfile
+	Profile string
+}
+
+// LoadConfig is like NewConfig with the given options
+func LoadConfig(configFiles []string, configs [][]byte, opts LoadOptions) (map[string]any, error) {
+	return loadConfig(configFiles, configs, opts, nil)
+}
+
+// loadConfig implements LoadConfig and records in prov, if not nil, which
+// config set each value.
+func loadConfig(configFiles []string, configs [][]byte, opts LoadOptions, prov Provenance) (map[string]any, error) {
 	config := make(map[string]any)
 
+	if opts.Profile != "" {
+		profile, err := contrib.LookupProfile(opts.Profile)
+		if err != nil {
+			return nil, err
+		}
+		if err := mergeYAML(&config, profile.Config, "built-in "+profile.ConfigName, opts, prov); err != nil {
+			return nil, err
+		}
+	}
+
 	for _, filename := range configFiles {
 		data, err := os.ReadFile(filename)
 		if err != nil {
 			return nil, fmt.Errorf("reading config file %q: %w", filename, err)
 		}
-		if err := mergeYAML(&config, data, filename); err != nil {
+		if err := mergeYAML(&config, data, filename, opts, prov); err != nil {
 			return nil, err
 		}
 	}
 
 	for i, data := range configs {
-		if err := mergeYAML(&config, data, fmt.Sprintf("config[%d]", i)); err != nil {
+		if err := mergeYAML(&config, data, fmt.Sprintf("config[%d]", i), opts, prov); err != nil {
 			return nil, err
 		}
 	}
@@ -122,22 +225,192 @@ func NewConfig(configFiles []string, configs [][]byte) (map[string]any, error) {
 
 // mergeYAML unmarshals YAML data into a map and deep-merges it into config,
 // with later values overriding existing keys. label is used for error messages.
-func mergeYAML(config *map[string]any, data []byte, label string) error {
+// Environment variables and lists are handled according to opts, the values set
+// are recorded under label in prov.
+func mergeYAML(config *map[string]any, data []byte, label string, opts LoadOptions, prov Provenance) error {
 	var parsed map[string]any
 	if err := yaml.Unmarshal(data, &parsed); err != nil {
 		return fmt.Errorf("unmarshaling YAML from %q: %w", label, err)
 	}
-	if err := mergo.Merge(config, parsed, mergo.WithOverride); err != nil {
+	if opts.ExpandEnv {
+		if err := ExpandEnv(parsed); err != nil {
+			return fmt.Errorf("expanding environment variables in %q: %w", label, err)
+		}
+	}
+	if err := validateAddress(parsed); err != nil {
+		return fmt.Errorf("invalid config in %q: %w", label, err)
+	}
+	mergeOpts := []func(*mergo.Config){mergo.WithOverride}
+	if opts.AppendLists {
+		mergeOpts = append(mergeOpts, mergo.WithAppendSlice)
+	}
+	if err := mergo.Merge(config, parsed, mergeOpts...); err != nil {
 		return fmt.Errorf("merging config from %q: %w", label, err)
 	}
+	prov.record(label, parsed, *config)
+	return nil
+}
+
+// ApplyVars deep-merges dotted key=value overrides into config as the highest
+// precedence layer. A var like "defaults.tag=4.3.0" becomes {"defaults": {"tag": "4.3.0"}}.
+// Values are parsed as YAML scalars, so they get the same types as in config files.
+func ApplyVars(config map[string]any, vars []string) error {
+	return applyVars(config, vars, nil)
+}
+
+// applyVars implements ApplyVars and records the values set by each var in prov.
+func applyVars(config map[string]any, vars []string, prov Provenance) error {
+	for _, v := range vars {
+		parsed, err := parseVar(v)
+		if err != nil {
+			return err
+		}
+		if err := validateAddress(parsed); err != nil {
+			return fmt.Errorf("invalid var %q: %w", v, err)
+		}
+		if err := mergo.Merge(&config, parsed, mergo.WithOverride); err != nil {
+			return fmt.Errorf("merging var %q: %w", v, err)
+		}
+		prov.record("--var "+v, parsed, config)
+	}
 	return nil
 }
 
+// ExpandEnvUsage is the usage of the --expand-env flag
+const ExpandEnvUsage = "replace ${VAR}, $VAR and ${VAR:-default} in config values with environment variables"
+
+// StrictTemplatesUsage is the usage of the --strict-templates flag
+const StrictTemplatesUsage = "fail on template references to config keys that are not set instead of rendering <no value>"
+
+// ProfileUsage is the usage of the --profile flag
+const ProfileUsage = "built-in template set, not combinable with --template: " + contrib.ProfileDocker + " (one Docker Compose file) or " + contrib.ProfileK8s + " (namespace.yaml and stack/ of Kubernetes manifests)"
+
+// AppendListsUsage is the usage of the --merge-append-lists flag
+const AppendListsUsage = "append lists of later config files to those of earlier ones instead of replacing them"
+
+// ExpandEnv replaces ${VAR} and $VAR in all string values of config, including
+// nested maps and lists, with environment variables using os.Expand.
+// ${VAR:-default} falls back to default if VAR is unset or empty, $$ is a
+// literal $. Variables that are unset and have no default are an error.
+func ExpandEnv(config map[string]any) error {
+	var missing []string
+	for key, value := range config {
+		config[key] = expandEnvValue(value, key, &missing)
+	}
+	if len(missing) > 0 {
+		sort.Strings(missing)
+		return fmt.Errorf("environment variables not set: %s (use ${VAR:-default} for optional ones)", strings.Join(missing, ", "))
+	}
+	return nil
+}
+
+// expandEnvValue expands the strings in value, found under the dotted key
+// path, and records variables that are not set in missing.
+func expandEnvValue(value any, path string, missing *[]string) any {
+	switch v := value.(type) {
+	case string:
+		return os.Expand(v, func(name string) string {
+			if name == "$" {
+				return "$"
+			}
+			name, def, hasDefault := strings.Cut(name, ":-")
+			if env, ok := os.LookupEnv(name); ok && (env != "" || !hasDefault) {
+				return env
+			}
+			if hasDefault {
+				return def
+			}
+			*missing = append(*missing, fmt.Sprintf("%s (in %s)", name, path))
+			return ""
+		})
+	case map[string]any:
+		for key, item := range v {
+			v[key] = expandEnvValue(item, path+"."+key, missing)
+		}
+		return v
+	case []any:
+		for i, item := range v {
+			v[i] = expandEnvValue(item, fmt.Sprintf("%s[%d]", path, i), missing)
+		}
+		return v
+	default:
+		return value
+	}
+}
+
+// validateAddress checks that host, if present, is an IP address or hostname
+// and port, if present, is an integer TCP port.
+func validateAddress(config map[string]any) error {
+	if host, ok := config["host"]; ok {
+		hostStr, isString := host.(string)
+		if !isString || (net.ParseIP(hostStr) == nil && len(validation.IsDNS1123Subdomain(strings.ToLower(hostStr))) > 0) {
+			return fmt.Errorf("host %v is not an IP address or hostname", host)
+		}
+	}
+
+	if port, ok := config["port"]; ok {
+		var portNum float64
+		switch v := port.(type) {
+		case float64:
+			portNum = v
+		case string:
+			n, err := strconv.Atoi(v)
+			if err != nil {
+				return fmt.Errorf("port %q is not a number", v)
+			}
+			portNum = float64(n)
+		default:
+			return fmt.Errorf("port %v is not a number", port)
+		}
+		if portNum != math.Trunc(portNum) || portNum < 1 || portNum > 65535 {
+			return fmt.Errorf("port %v is not a TCP port (1-65535)", port)
+		}
+	}
+	return nil
+}
+
+// parseVar converts a single dotted key=value override into a nested map.
+func parseVar(v string) (map[string]any, error) {
+	key, rawValue, found := strings.Cut(v, "=")
+	if !found {
+		return nil, fmt.Errorf("invalid var %q: expected key=value", v)
+	}
+
+	keys := strings.Split(key, ".")
+	if slices.Contains(keys, "") {
+		return nil, fmt.Errorf("invalid var %q: empty key segment", v)
+	}
+
+	var value any
+	if err := yaml.Unmarshal([]byte(rawValue), &value); err != nil {
+		return nil, fmt.Errorf("parsing value of var %q: %w", v, err)
+	}
+	if value == nil {
+		value = rawValue
+	}
+
+	result := map[string]any{keys[len(keys)-1]: value}
+	for i := len(keys) - 2; i >= 0; i-- {
+		result = map[string]any{keys[i]: result}
+	}
+	return result, nil
+}
+
 // CreateDirAndFiles creates the base directory and (re-)creates the deployment
-// files according to the given template. Use a truthy value for force to
-// override existing files.
-func CreateDirAndFiles(baseDir string, force bool, customTemplate string, cfg map[string]any) error {
-	logger.Debug("Creating deployment files - custom: %s", customTemplate)
+// files according to the given template, or the templates of the built-in
+// profile if customTemplate is empty. Use a truthy value for force to
+// override existing files. With strict, templates referencing missing config
+// keys fail. Finally the inventory of generated files is updated.
+func CreateDirAndFiles(baseDir string, force bool, customTemplate, profile string, cfg map[string]any, strict bool) error {
+	logger.Debug("Creating deployment files - custom: %s, profile: %s", customTemplate, profile)
+	if customTemplate == "" {
+		out, err := renderProfile(baseDir, profile, cfg, strict)
+		if err != nil {
+			return err
+		}
+		return writeTemplateOutput(baseDir, force, out)
+	}
+
 	fileInfo, err := os.Stat(customTemplate)
 	if err != nil {
 		if errors.Is(err, os.ErrNotExist) {
@@ -146,44 +419,94 @@ func CreateDirAndFiles(baseDir string, force bool, customTemplate string, cfg ma
 		return fmt.Errorf("checking file info of %q: %w", customTemplate, err)
 	}
 
+	// Render every template before writing anything, so a broken template
+	// does not leave a half-generated instance behind.
+	var out *templateOutput
 	if fileInfo.IsDir() {
-		return createFromTemplateDir(baseDir, force, customTemplate, cfg)
+		out, err = renderTemplateDir(baseDir, customTemplate, cfg, strict)
+	} else {
+		out, err = renderTemplateFile(baseDir, customTemplate, cfg, strict)
+	}
+	if err != nil {
+		return err
 	}
+	return writeTemplateOutput(baseDir, force, out)
+}
 
-	return createFromTemplateFile(baseDir, force, customTemplate, cfg)
+// writeTemplateOutput writes out to baseDir and updates the inventory
+func writeTemplateOutput(baseDir string, force bool, out *templateOutput) error {
+	generated, err := out.write(baseDir, force)
+	if err != nil {
+		return err
+	}
+
+	if err := updateInventory(baseDir, generated); err != nil {
+		return fmt.Errorf("updating inventory: %w", err)
+	}
+	return nil
+}
+
+// templateOutput holds the directories and rendered files of all templates,
+// to be created in order once every template rendered successfully
+type templateOutput struct {
+	dirs  []string
+	files []renderedFile
+}
+
+// renderedFile is the content of a deployment file rendered from a template
+type renderedFile struct {
+	path    string
+	content []byte
+}
+
+// renderProfile renders the templates of the built-in profile: a single file
+// named by the filename of cfg, or a directory layout like a template directory
+func renderProfile(baseDir string, name string, cfg map[string]any, strict bool) (*templateOutput, error) {
+	profile, err := contrib.LookupProfile(name)
+	if err != nil {
+		return nil, err
+	}
+	logger.Debug("Using built-in profile: %s", profile.Name)
+	if profile.Templates != nil {
+		return renderFS(baseDir, profile.Templates, cfg, strict)
+	}
+	return renderTemplateData(baseDir, profile.TemplateName, profile.Template, cfg, strict)
 }
 
-func createFromTemplateFile(baseDir string, force bool, tplFile string, cfg map[string]any) error {
+func renderTemplateFile(baseDir string, tplFile string, cfg map[string]any, strict bool) (*templateOutput, error) {
 	logger.Debug("Using custom template file: %s", tplFile)
 
 	data, err := os.ReadFile(tplFile)
 	if err != nil {
-		return fmt.Errorf("reading template file: %w", err)
+		return nil, fmt.Errorf("reading template file: %w", err)
 	}
+	return renderTemplateData(baseDir, tplFile, data, cfg, strict)
+}
 
-	if err := os.MkdirAll(baseDir, constants.InstanceDirPerm); err != nil {
-		return fmt.Errorf("creating instance directory: %w", err)
+// renderTemplateData renders the template data read from tplFile
+func renderTemplateData(baseDir string, tplFile string, data []byte, cfg map[string]any, strict bool) (*templateOutput, error) {
+	content, err := renderTemplate(data, cfg, baseDir, strict)
+	if err != nil {
+		return nil, fmt.Errorf("template %q: %w", tplFile, err)
 	}
 
 	// Extract filename from config if present, otherwise use a default
 	filename := filepath.Join(baseDir, getFilename(cfg, tplFile))
-	return createDeploymentFile(filename, force, data, cfg, baseDir)
+	return &templateOutput{files: []renderedFile{{path: filename, content: content}}}, nil
 }
 
-func createFromTemplateDir(baseDir string, force bool, tplDir string, cfg map[string]any) error {
+func renderTemplateDir(baseDir string, tplDir string, cfg map[string]any, strict bool) (*templateOutput, error) {
 	logger.Debug("Using custom template directory: %s", tplDir)
-
-	tplFS := os.DirFS(tplDir)
-
-	if err := os.MkdirAll(baseDir, constants.InstanceDirPerm); err != nil {
-		return fmt.Errorf("creating instance directory: %w", err)
-	}
-
-	return createFromFS(baseDir, force, tplFS, cfg)
+	return renderFS(baseDir, os.DirFS(tplDir), cfg, strict)
 }
 
-func createFromFS(baseDir string, force bool, tplFS fs.FS, cfg map[string]any) error {
-	return fs.WalkDir(tplFS, ".", func(path string, d fs.DirEntry, err error) error {
+// renderFS renders every template in tplFS to the same relative path in
+// baseDir. All templates are rendered even if one fails, so that every broken
+// template is reported at once.
+func renderFS(baseDir string, tplFS fs.FS, cfg map[string]any, strict bool) (*templateOutput, error) {
+	out := &templateOutput{}
+	var errs []error
+	err := fs.WalkDir(tplFS, ".", func(path string, d fs.DirEntry, err error) error {
 		if err != nil {
 			return err
 		}
@@ -191,10 +514,8 @@ func createFromFS(baseDir string, force bool, tplFS fs.FS, cfg map[string]any) e
 		targetPath := filepath.Join(baseDir, path)
 
 		if d.IsDir() {
-			// Use appropriate permissions based on directory name
-			perm := getDirPermissions(filepath.Base(targetPath))
-			logger.Debug("Creating directory: %s (perms: %04o)", targetPath, perm)
-			return os.MkdirAll(targetPath, perm)
+			out.dirs = append(out.dirs, targetPath)
+			return nil
 		}
 
 		logger.Debug("Processing template: %s", path)
@@ -203,8 +524,47 @@ func createFromFS(baseDir string, force bool, tplFS fs.FS, cfg map[string]any) e
 			return fmt.Errorf("reading template %q: %w", path, err)
 		}
 
-		return createDeploymentFile(targetPath, force, data, cfg, baseDir)
+		content, err := renderTemplate(data, cfg, baseDir, strict)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("template %q: %w", path, err))
+			return nil
+		}
+		out.files = append(out.files, renderedFile{path: targetPath, content: content})
+		return nil
 	})
+	if err != nil {
+		return nil, err
+	}
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+	return out, nil
+}
+
+// write creates the instance directory, the directories and the files of out
+// and returns for every file, relative to baseDir, whether it was written
+// (true) or kept because it exists (false).
+func (out *templateOutput) write(baseDir string, force bool) (map[string]bool, error) {
+	if err := os.MkdirAll(baseDir, constants.InstanceDirPerm); err != nil {
+		return nil, fmt.Errorf("creating instance directory: %w", err)
+	}
+
+	for _, dir := range out.dirs {
+		// Use appropriate permissions based on directory name
+		perm := getDirPermissions(filepath.Base(dir))
+		logger.Debug("Creating directory: %s (perms: %04o)", dir, perm)
+		if err := os.MkdirAll(dir, perm); err != nil {
+			return nil, err
+		}
+	}
+
+	generated := make(map[string]bool)
+	for _, file := range out.files {
+		if err := writeDeploymentFile(file, force, baseDir, generated); err != nil {
+			return nil, err
+		}
+	}
+	return generated, nil
 }
 
 // getDirPermissions returns appropriate permissions based on directory name
@@ -219,21 +579,47 @@ func getDirPermissions(dirName string) fs.FileMode {
 	}
 }
 
-func createDeploymentFile(filename string, force bool, tplData []byte, cfg map[string]any, baseDir string) error {
+// writeDeploymentFile writes the rendered file and records in generated
+// whether it was written (true) or kept because it exists (false).
+func writeDeploymentFile(file renderedFile, force bool, baseDir string, generated map[string]bool) error {
+	existed, err := utils.FileExists(file.path)
+	if err != nil {
+		return err
+	}
+
+	dir := filepath.Dir(file.path)
+	name := filepath.Base(file.path)
+	if err := utils.CreateFile(dir, force, name, file.content, constants.StackFilePerm); err != nil {
+		return err
+	}
+
+	rel, err := filepath.Rel(baseDir, file.path)
+	if err != nil {
+		return fmt.Errorf("getting relative path of %q: %w", file.path, err)
+	}
+	generated[filepath.ToSlash(rel)] = force || !existed
+	return nil
+}
+
+// renderTemplate executes the template tplData against cfg. readSecret reads
+// secrets of the instance in baseDir. With strict, referencing a key missing in
+// cfg is an error instead of rendering "<no value>".
+func renderTemplate(tplData []byte, cfg map[string]any, baseDir string, strict bool) ([]byte, error) {
 	tf := &TemplateFunctions{baseDir: baseDir}
-	tmpl, err := template.New("deployment").Funcs(tf.GetFuncMap()).Parse(string(tplData))
+	tmpl := template.New("deployment").Funcs(tf.GetFuncMap())
+	if strict {
+		tmpl = tmpl.Option("missingkey=error")
+	}
+	tmpl, err := tmpl.Parse(string(tplData))
 	if err != nil {
-		return fmt.Errorf("parsing template: %w", err)
+		return nil, fmt.Errorf("parsing template: %w", err)
 	}
 
 	var buf bytes.Buffer
 	if err := tmpl.Execute(&buf, cfg); err != nil {
-		return fmt.Errorf("executing template: %w", err)
+		return nil, fmt.Errorf("executing template: %w", err)
 	}
-
-	dir := filepath.Dir(filename)
-	name := filepath.Base(filename)
-	return utils.CreateFile(dir, force, name, buf.Bytes(), constants.StackFilePerm)
+	return buf.Bytes(), nil
 }
 
 // getFilename extracts the filename from config, or returns a default
@@ -258,11 +644,22 @@ func (tf *TemplateFunctions) GetFuncMap() template.FuncMap {
 		"marshalContent": marshalContent,
 		"envMapToK8S":    envMapToK8S,
 		"readSecret":     tf.ReadSecret,
+		"namespace":      tf.Namespace,
+		"default":        defaultValue,
+		"quote":          quote,
+		"upper":          strings.ToUpper,
+		"lower":          strings.ToLower,
+		"trim":           strings.TrimSpace,
+		"b64enc":         b64enc,
+		"dict":           dict,
 	}
 }
 
 // ReadSecret reads a secret file from the secrets directory and returns it base64 encoded
 func (tf *TemplateFunctions) ReadSecret(name string) (string, error) {
+	if tf.baseDir == "" {
+		return "", fmt.Errorf("cannot read secret %q: no instance directory given to read secrets from", name)
+	}
 	secretPath := filepath.Join(tf.baseDir, constants.SecretsDirName, name)
 	data, err := os.ReadFile(secretPath)
 	if err != nil {
@@ -274,6 +671,74 @@ func (tf *TemplateFunctions) ReadSecret(name string) (string, error) {
 	return base64.StdEncoding.EncodeToString(data), nil
 }
 
+// Namespace returns the Kubernetes namespace derived from the name of the
+// instance directory, see utils.ExtractNamespace
+func (tf *TemplateFunctions) Namespace() (string, error) {
+	if tf.baseDir == "" {
+		return "", fmt.Errorf("cannot derive namespace: no instance directory given")
+	}
+	absDir, err := filepath.Abs(tf.baseDir)
+	if err != nil {
+		return "", fmt.Errorf("resolving instance directory: %w", err)
+	}
+	namespace := utils.ExtractNamespace(absDir)
+	if err := utils.ValidateNamespace(namespace); err != nil {
+		return "", fmt.Errorf("%w: derived from directory %s, set namespace in the config", err, filepath.Base(absDir))
+	}
+	return namespace, nil
+}
+
+// defaultValue returns value, or def if value is empty: missing, nil, false,
+// zero, or an empty string, list or map. The argument order allows piping
+// value into default.
+func defaultValue(def any, value any) any {
+	if value == nil {
+		return def
+	}
+	v := reflect.ValueOf(value)
+	switch v.Kind() {
+	case reflect.String, reflect.Slice, reflect.Map, reflect.Array:
+		if v.Len() == 0 {
+			return def
+		}
+	default:
+		if v.IsZero() {
+			return def
+		}
+	}
+	return value
+}
+
+// quote returns value formatted with %v as a double-quoted Go string literal,
+// which is also a valid YAML double-quoted scalar. nil becomes "".
+func quote(value any) string {
+	if value == nil {
+		return strconv.Quote("")
+	}
+	return strconv.Quote(fmt.Sprint(value))
+}
+
+func b64enc(s string) string {
+	return base64.StdEncoding.EncodeToString([]byte(s))
+}
+
+// dict returns a map of alternating keys and values, e.g. as fallback for a
+// missing map in {{ index . "services" | default dict }}
+func dict(pairs ...any) (map[string]any, error) {
+	if len(pairs)%2 != 0 {
+		return nil, fmt.Errorf("dict needs pairs of keys and values, got %d arguments", len(pairs))
+	}
+	m := make(map[string]any, len(pairs)/2)
+	for i := 0; i < len(pairs); i += 2 {
+		key, ok := pairs[i].(string)
+		if !ok {
+			return nil, fmt.Errorf("dict key %v is not a string", pairs[i])
+		}
+		m[key] = pairs[i+1]
+	}
+	return m, nil
+}
+
 func marshalContent(ws int, v any) (string, error) {
 	data, err := yaml.Marshal(v)
 	if err != nil {
@@ -296,11 +761,18 @@ func marshalContent(ws int, v any) (string, error) {
 }
 
 func envMapToK8S(v map[string]any) []map[string]string {
-	// Handle map[string]any (from YAML unmarshaling)
+	// Handle map[string]any (from YAML unmarshaling). Sort the keys, so
+	// regenerated files only change if the environment does.
+	keys := make([]string, 0, len(v))
+	for key := range v {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
 	var list []map[string]string
-	for key, value := range v {
+	for _, key := range keys {
 		// Convert value to string
-		strValue := fmt.Sprintf("%v", value)
+		strValue := fmt.Sprintf("%v", v[key])
 		list = append(list, map[string]string{
 			"name":  key,
 			"value": strValue,
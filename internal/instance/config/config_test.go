@@ -3,9 +3,12 @@ package config
 import (
 	"os"
 	"path/filepath"
+	"reflect"
+	"slices"
 	"strings"
 	"testing"
 
+	"github.com/OpenSlides/openslides-cli/contrib"
 	"github.com/OpenSlides/openslides-cli/internal/constants"
 )
 
@@ -361,6 +364,243 @@ services:
 	})
 }
 
+func TestLoadConfig_AppendLists(t *testing.T) {
+	tmpdir := t.TempDir()
+
+	base := filepath.Join(tmpdir, "base.yml")
+	if err := os.WriteFile(base, []byte(`
+services:
+  - backend
+  - client
+defaults:
+  args: [--verbose]
+  tag: 4.2.0
+`), constants.StackFilePerm); err != nil {
+		t.Fatalf("failed to write base: %v", err)
+	}
+
+	extra := filepath.Join(tmpdir, "extra.yml")
+	if err := os.WriteFile(extra, []byte(`
+services:
+  - search
+defaults:
+  args: [--debug]
+  tag: 4.3.0
+`), constants.StackFilePerm); err != nil {
+		t.Fatalf("failed to write extra: %v", err)
+	}
+
+	t.Run("lists are replaced by default", func(t *testing.T) {
+		cfg, err := LoadConfig([]string{base, extra}, nil, LoadOptions{})
+		if err != nil {
+			t.Fatalf("LoadConfig() error = %v", err)
+		}
+		if want := []any{"search"}; !reflect.DeepEqual(cfg["services"], want) {
+			t.Errorf("Expected services %v, got %v", want, cfg["services"])
+		}
+	})
+
+	t.Run("lists are appended", func(t *testing.T) {
+		cfg, err := LoadConfig([]string{base, extra}, nil, LoadOptions{AppendLists: true})
+		if err != nil {
+			t.Fatalf("LoadConfig() error = %v", err)
+		}
+		if want := []any{"backend", "client", "search"}; !reflect.DeepEqual(cfg["services"], want) {
+			t.Errorf("Expected services %v, got %v", want, cfg["services"])
+		}
+
+		defaults := cfg["defaults"].(map[string]any)
+		if want := []any{"--verbose", "--debug"}; !reflect.DeepEqual(defaults["args"], want) {
+			t.Errorf("Expected nested list %v, got %v", want, defaults["args"])
+		}
+		if defaults["tag"] != "4.3.0" {
+			t.Errorf("Expected scalar tag to be replaced, got %v", defaults["tag"])
+		}
+	})
+
+}
+
+func TestApplyVars(t *testing.T) {
+	t.Run("nested vars override file values", func(t *testing.T) {
+		tmpdir := t.TempDir()
+
+		configFile := filepath.Join(tmpdir, "config.yml")
+		content := `---
+host: 127.0.0.1
+defaults:
+  containerRegistry: example.com/registry
+  tag: 4.2.0
+`
+		if err := os.WriteFile(configFile, []byte(content), constants.StackFilePerm); err != nil {
+			t.Fatalf("failed to write config: %v", err)
+		}
+
+		cfg, err := NewConfig([]string{configFile}, nil)
+		if err != nil {
+			t.Fatalf("NewConfig() error = %v", err)
+		}
+
+		vars := []string{"defaults.tag=4.3.0", "services.backendManage.tag=dev", "enableLocalHTTPS=true"}
+		if err := ApplyVars(cfg, vars); err != nil {
+			t.Fatalf("ApplyVars() error = %v", err)
+		}
+
+		defaults := cfg["defaults"].(map[string]any)
+		if defaults["tag"] != "4.3.0" {
+			t.Errorf("Expected tag 4.3.0 from var, got %v", defaults["tag"])
+		}
+		if defaults["containerRegistry"] != "example.com/registry" {
+			t.Errorf("Expected containerRegistry from file to be kept, got %v", defaults["containerRegistry"])
+		}
+		if cfg["host"] != "127.0.0.1" {
+			t.Errorf("Expected host from file to be kept, got %v", cfg["host"])
+		}
+
+		backendManage := cfg["services"].(map[string]any)["backendManage"].(map[string]any)
+		if backendManage["tag"] != "dev" {
+			t.Errorf("Expected services.backendManage.tag dev, got %v", backendManage["tag"])
+		}
+
+		if cfg["enableLocalHTTPS"] != true {
+			t.Errorf("Expected enableLocalHTTPS to be parsed as bool true, got %v (%T)", cfg["enableLocalHTTPS"], cfg["enableLocalHTTPS"])
+		}
+	})
+
+	t.Run("later vars win", func(t *testing.T) {
+		cfg := map[string]any{}
+		if err := ApplyVars(cfg, []string{"port=8000", "port=9000"}); err != nil {
+			t.Fatalf("ApplyVars() error = %v", err)
+		}
+		if cfg["port"] != float64(9000) {
+			t.Errorf("Expected port 9000, got %v", cfg["port"])
+		}
+	})
+
+	t.Run("empty value", func(t *testing.T) {
+		cfg := map[string]any{"url": "example.com"}
+		if err := ApplyVars(cfg, []string{"url="}); err != nil {
+			t.Fatalf("ApplyVars() error = %v", err)
+		}
+		if cfg["url"] != "" {
+			t.Errorf("Expected empty url, got %v", cfg["url"])
+		}
+	})
+
+	t.Run("invalid vars", func(t *testing.T) {
+		for _, v := range []string{"no-equals-sign", "defaults..tag=1", ".tag=1", "=value"} {
+			if err := ApplyVars(map[string]any{}, []string{v}); err == nil {
+				t.Errorf("Expected error for var %q", v)
+			}
+		}
+	})
+}
+
+func TestExpandEnv(t *testing.T) {
+	t.Setenv("OSM_TEST_PASSWORD", "s3cret")
+	t.Setenv("OSM_TEST_EMPTY", "")
+
+	t.Run("expands nested strings", func(t *testing.T) {
+		cfg := map[string]any{
+			"database": map[string]any{"password": "${OSM_TEST_PASSWORD}", "port": float64(5432)},
+			"hosts":    []any{"$OSM_TEST_PASSWORD.example.com"},
+			"price":    "$$5",
+			"tag":      "${OSM_TEST_UNSET_TAG:-latest}",
+			"empty":    "${OSM_TEST_EMPTY:-fallback}",
+			"plain":    "${OSM_TEST_EMPTY}",
+		}
+		if err := ExpandEnv(cfg); err != nil {
+			t.Fatalf("ExpandEnv() error = %v", err)
+		}
+
+		want := map[string]any{
+			"database": map[string]any{"password": "s3cret", "port": float64(5432)},
+			"hosts":    []any{"s3cret.example.com"},
+			"price":    "$5",
+			"tag":      "latest",
+			"empty":    "fallback",
+			"plain":    "",
+		}
+		if !reflect.DeepEqual(cfg, want) {
+			t.Errorf("ExpandEnv() = %v, want %v", cfg, want)
+		}
+	})
+
+	t.Run("unset variables fail", func(t *testing.T) {
+		cfg := map[string]any{"database": map[string]any{"password": "${OSM_TEST_UNSET}"}}
+		err := ExpandEnv(cfg)
+		if err == nil || !strings.Contains(err.Error(), "OSM_TEST_UNSET (in database.password)") {
+			t.Errorf("Expected error naming the unset variable, got %v", err)
+		}
+	})
+
+	t.Run("LoadConfig expands before validating", func(t *testing.T) {
+		t.Setenv("OSM_TEST_HOST", "db.example.com")
+		configFile := filepath.Join(t.TempDir(), "config.yml")
+		if err := os.WriteFile(configFile, []byte("host: ${OSM_TEST_HOST}\nport: ${OSM_TEST_PORT:-8000}\n"), constants.StackFilePerm); err != nil {
+			t.Fatalf("failed to write config: %v", err)
+		}
+
+		if _, err := LoadConfig([]string{configFile}, nil, LoadOptions{}); err == nil {
+			t.Error("Expected unexpanded host to be rejected")
+		}
+
+		cfg, err := LoadConfig([]string{configFile}, nil, LoadOptions{ExpandEnv: true})
+		if err != nil {
+			t.Fatalf("LoadConfig() error = %v", err)
+		}
+		if cfg["host"] != "db.example.com" || cfg["port"] != "8000" {
+			t.Errorf("Expected expanded host and port, got %v", cfg)
+		}
+	})
+}
+
+func TestValidateAddress(t *testing.T) {
+	valid := []map[string]any{
+		{},
+		{"host": "127.0.0.1", "port": float64(8000)},
+		{"host": "::1", "port": "9000"},
+		{"host": "openslides.example.com", "port": float64(65535)},
+		{"host": "localhost"},
+	}
+	for _, cfg := range valid {
+		if err := validateAddress(cfg); err != nil {
+			t.Errorf("validateAddress(%v) error = %v", cfg, err)
+		}
+	}
+
+	invalid := []map[string]any{
+		{"port": "9000abc"},
+		{"port": float64(0)},
+		{"port": float64(65536)},
+		{"port": 80.5},
+		{"port": true},
+		{"host": "not a host"},
+		{"host": ""},
+		{"host": float64(127)},
+	}
+	for _, cfg := range invalid {
+		if err := validateAddress(cfg); err == nil {
+			t.Errorf("Expected error for %v", cfg)
+		}
+	}
+}
+
+func TestNewConfig_InvalidAddress(t *testing.T) {
+	configFile := filepath.Join(t.TempDir(), "bad.yml")
+	if err := os.WriteFile(configFile, []byte("port: \"9000abc\"\n"), constants.StackFilePerm); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+
+	_, err := NewConfig([]string{configFile}, nil)
+	if err == nil || !strings.Contains(err.Error(), configFile) {
+		t.Errorf("Expected error referencing %s, got %v", configFile, err)
+	}
+
+	if err := ApplyVars(map[string]any{}, []string{"port=70000"}); err == nil {
+		t.Error("Expected error for invalid port var")
+	}
+}
+
 func TestGetFilename(t *testing.T) {
 	t.Run("with filename in config", func(t *testing.T) {
 		cfg := map[string]any{
@@ -444,6 +684,118 @@ func TestTemplateFunctions(t *testing.T) {
 	})
 }
 
+func TestRenderTemplate_Strict(t *testing.T) {
+	cfg := map[string]any{"defaults": map[string]any{"tag": "4.2.0"}}
+
+	t.Run("missing key renders no value by default", func(t *testing.T) {
+		content, err := renderTemplate([]byte("tag: {{ .defaults.tga }}"), cfg, "", false)
+		if err != nil {
+			t.Fatalf("renderTemplate() error = %v", err)
+		}
+		if string(content) != "tag: <no value>" {
+			t.Errorf("Expected <no value>, got %q", content)
+		}
+	})
+
+	t.Run("missing key fails in strict mode", func(t *testing.T) {
+		_, err := renderTemplate([]byte("tag: {{ .defaults.tga }}"), cfg, "", true)
+		if err == nil || !strings.Contains(err.Error(), `"tga"`) {
+			t.Errorf("Expected error naming the missing key, got %v", err)
+		}
+	})
+
+	t.Run("index fallback works in strict mode", func(t *testing.T) {
+		content, err := renderTemplate([]byte(`port: {{ or (index . "port") 8000 }}`), cfg, "", true)
+		if err != nil {
+			t.Fatalf("renderTemplate() error = %v", err)
+		}
+		if string(content) != "port: 8000" {
+			t.Errorf("Expected fallback value, got %q", content)
+		}
+	})
+}
+
+func TestTemplateStringFunctions(t *testing.T) {
+	cfg := map[string]any{
+		"url":   "",
+		"name":  "  OpenSlides  ",
+		"port":  float64(8000),
+		"list":  []any{},
+		"token": "secret123",
+	}
+
+	tests := []struct {
+		tpl  string
+		want string
+	}{
+		{`{{ .url | default "localhost" | quote }}`, `"localhost"`},
+		{`{{ .missing | default "localhost" }}`, `localhost`},
+		{`{{ .port | default 9000 }}`, `8000`},
+		{`{{ .list | default "none" }}`, `none`},
+		{`{{ .name | trim | upper }}`, `OPENSLIDES`},
+		{`{{ .name | trim | lower }}`, `openslides`},
+		{`{{ .port | quote }}`, `"8000"`},
+		{`{{ "say \"hi\"" | quote }}`, `"say \"hi\""`},
+		{`{{ .missing | quote }}`, `""`},
+		{`{{ .token | b64enc }}`, `c2VjcmV0MTIz`},
+	}
+	for _, tt := range tests {
+		content, err := renderTemplate([]byte(tt.tpl), cfg, "", false)
+		if err != nil {
+			t.Errorf("renderTemplate(%s) error = %v", tt.tpl, err)
+			continue
+		}
+		if string(content) != tt.want {
+			t.Errorf("renderTemplate(%s) = %s, want %s", tt.tpl, content, tt.want)
+		}
+	}
+}
+
+func TestDict(t *testing.T) {
+	got, err := dict("a", 1, "b", "two")
+	if err != nil {
+		t.Fatalf("dict() error = %v", err)
+	}
+	if !reflect.DeepEqual(got, map[string]any{"a": 1, "b": "two"}) {
+		t.Errorf("dict() = %v", got)
+	}
+	if empty, err := dict(); err != nil || len(empty) != 0 {
+		t.Errorf("dict() = %v, %v, want empty map", empty, err)
+	}
+	if _, err := dict("a"); err == nil {
+		t.Error("Expected error for odd number of arguments")
+	}
+	if _, err := dict(1, "a"); err == nil {
+		t.Error("Expected error for non-string key")
+	}
+}
+
+func TestCreateDirAndFiles_BuiltinProfilesStrict(t *testing.T) {
+	for _, profile := range []string{contrib.ProfileDocker, contrib.ProfileK8s} {
+		t.Run(profile, func(t *testing.T) {
+			cfg, err := LoadConfig(nil, nil, LoadOptions{Profile: profile})
+			if err != nil {
+				t.Fatalf("LoadConfig() error = %v", err)
+			}
+
+			outDir := filepath.Join(t.TempDir(), "my.instance.org")
+			secretsDir := filepath.Join(outDir, constants.SecretsDirName)
+			if err := os.MkdirAll(secretsDir, constants.SecretsDirPerm); err != nil {
+				t.Fatal(err)
+			}
+			for _, name := range []string{constants.AuthTokenKey, constants.AuthCookieKey, constants.InternalAuthPassword, constants.PgPasswordFile, constants.AdminSecretsFile} {
+				if err := os.WriteFile(filepath.Join(secretsDir, name), []byte("secret"), constants.SecretFilePerm); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			if err := CreateDirAndFiles(outDir, false, "", profile, cfg, true); err != nil {
+				t.Fatalf("CreateDirAndFiles() with strict templates error = %v", err)
+			}
+		})
+	}
+}
+
 func TestMarshalContent(t *testing.T) {
 	t.Run("marshal map", func(t *testing.T) {
 		data := map[string]string{
@@ -519,6 +871,17 @@ func TestEnvMapToK8S(t *testing.T) {
 		}
 	})
 
+	t.Run("sorts by name", func(t *testing.T) {
+		result := envMapToK8S(map[string]any{"C": 1, "A": 2, "B": 3})
+		var names []string
+		for _, item := range result {
+			names = append(names, item["name"])
+		}
+		if !slices.Equal(names, []string{"A", "B", "C"}) {
+			t.Errorf("Expected names in order A, B, C, got %v", names)
+		}
+	})
+
 	t.Run("handles empty map", func(t *testing.T) {
 		result := envMapToK8S(map[string]any{})
 		if len(result) != 0 {
@@ -534,7 +897,7 @@ func TestCreateDirAndFiles(t *testing.T) {
 		cfg := map[string]any{
 			"filename": constants.DefaultTemplatingOutputFilename,
 		}
-		err := CreateDirAndFiles(tmpdir, false, "nonexistent-template", cfg)
+		err := CreateDirAndFiles(tmpdir, false, "nonexistent-template", "", cfg, false)
 		if err == nil {
 			t.Error("Expected error for nonexistent template")
 		}
@@ -552,7 +915,7 @@ func TestCreateDirAndFiles(t *testing.T) {
 			"url":      "example.com",
 		}
 
-		err := CreateDirAndFiles(outDir, true, tplFile, cfg)
+		err := CreateDirAndFiles(outDir, true, tplFile, "", cfg, false)
 		if err != nil {
 			t.Errorf("CreateDirAndFiles() error = %v", err)
 		}
@@ -583,7 +946,7 @@ func TestCreateDirAndFiles(t *testing.T) {
 			"filename": constants.DefaultTemplatingOutputFilename,
 		}
 
-		err := CreateDirAndFiles(outDir, true, tplDir, cfg)
+		err := CreateDirAndFiles(outDir, true, tplDir, "", cfg, false)
 		if err != nil {
 			t.Errorf("CreateDirAndFiles() error = %v", err)
 		}
@@ -597,6 +960,37 @@ func TestCreateDirAndFiles(t *testing.T) {
 		}
 	})
 
+	t.Run("broken template writes nothing", func(t *testing.T) {
+		tplDir := filepath.Join(tmpdir, "broken-templates")
+		if err := os.MkdirAll(filepath.Join(tplDir, "stack"), constants.StackDirPerm); err != nil {
+			t.Fatalf("failed to create template dir: %v", err)
+		}
+		templates := map[string]string{
+			"a-good.yml":        "host: {{ .host }}",
+			"stack/b-parse.yml": "{{ if .host }}unclosed",
+			"stack/c-exec.yml":  "{{ .host.missing }}",
+		}
+		for name, content := range templates {
+			if err := os.WriteFile(filepath.Join(tplDir, name), []byte(content), constants.StackFilePerm); err != nil {
+				t.Fatalf("failed to write %s: %v", name, err)
+			}
+		}
+
+		outDir := filepath.Join(tmpdir, "output-broken")
+		err := CreateDirAndFiles(outDir, true, tplDir, "", map[string]any{"host": "example.com"}, false)
+		if err == nil {
+			t.Fatal("Expected error for broken templates")
+		}
+		for _, name := range []string{"b-parse.yml", "c-exec.yml"} {
+			if !strings.Contains(err.Error(), name) {
+				t.Errorf("Expected error to name %s, got %v", name, err)
+			}
+		}
+		if _, err := os.Stat(outDir); !os.IsNotExist(err) {
+			t.Errorf("Expected no output directory to be created, got %v", err)
+		}
+	})
+
 	t.Run("template with nested config access", func(t *testing.T) {
 		tplFile := filepath.Join(tmpdir, "nested-template.yml")
 		if err := os.WriteFile(tplFile, []byte("foo: {{ .services.client.foo }}\ntag: {{ .services.client.tag }}"), constants.StackFilePerm); err != nil {
@@ -614,7 +1008,7 @@ func TestCreateDirAndFiles(t *testing.T) {
 			},
 		}
 
-		err := CreateDirAndFiles(outDir, true, tplFile, cfg)
+		err := CreateDirAndFiles(outDir, true, tplFile, "", cfg, false)
 		if err != nil {
 			t.Errorf("CreateDirAndFiles() error = %v", err)
 		}
@@ -653,7 +1047,7 @@ registry: {{ or .services.auth.containerRegistry .defaults.containerRegistry }}`
 			},
 		}
 
-		err := CreateDirAndFiles(outDir, true, tplFile, cfg)
+		err := CreateDirAndFiles(outDir, true, tplFile, "", cfg, false)
 		if err != nil {
 			t.Errorf("CreateDirAndFiles() error = %v", err)
 		}
@@ -700,7 +1094,7 @@ projector:
 			},
 		}
 
-		err := CreateDirAndFiles(outDir, true, tplFile, cfg)
+		err := CreateDirAndFiles(outDir, true, tplFile, "", cfg, false)
 		if err != nil {
 			t.Errorf("CreateDirAndFiles() error = %v", err)
 		}
@@ -0,0 +1,157 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/OpenSlides/openslides-cli/internal/logger"
+	"github.com/spf13/cobra"
+)
+
+const (
+	ExplainHelp      = "Shows which config file set each value"
+	ExplainHelpExtra = `Merges the given config files like "osmanage config" and prints every
+resulting value with the config file that last set it. Lists appended with
+--merge-append-lists name every file that contributed. Values set by --var
+are attributed to the var.
+
+Examples:
+  osmanage config explain base.yaml prod.yaml overrides.yaml
+  osmanage config explain base.yaml prod.yaml --var defaults.tag=4.3.0
+  osmanage config explain base.yaml extra-services.yaml --merge-append-lists`
+)
+
+// Provenance maps the dotted key paths of leaf values in a merged config to
+// the configs that set them. Lists are leaves, maps are descended into.
+type Provenance map[string][]string
+
+// ExplainedValue is a leaf value of a merged config and the configs that set it
+type ExplainedValue struct {
+	Path    string
+	Value   any
+	Sources []string
+}
+
+// ExplainCmd returns the explain subcommand of config.
+func ExplainCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "explain <config-file>...",
+		Short: ExplainHelp,
+		Long:  ExplainHelp + "\n\n" + ExplainHelpExtra,
+		Args:  cobra.MinimumNArgs(1),
+	}
+
+	vars := cmd.Flags().StringArray("var", nil, "override a config value with a dotted key, e.g. defaults.tag=4.3.0 (can be used multiple times)")
+	expandEnv := cmd.Flags().Bool("expand-env", false, ExpandEnvUsage)
+	appendLists := cmd.Flags().Bool("merge-append-lists", false, AppendListsUsage)
+
+	cmd.RunE = func(cmd *cobra.Command, args []string) error {
+		logger.Debug("Explaining config files %v with vars %v", args, *vars)
+
+		opts := LoadOptions{ExpandEnv: *expandEnv, AppendLists: *appendLists}
+		values, err := Explain(args, *vars, opts)
+		if err != nil {
+			return err
+		}
+		return printExplained(cmd.OutOrStdout(), values)
+	}
+
+	return cmd
+}
+
+// Explain merges configFiles as set by opts, applies vars on top and returns
+// every leaf value of the result with the configs that set it, sorted by path.
+func Explain(configFiles []string, vars []string, opts LoadOptions) ([]ExplainedValue, error) {
+	prov := Provenance{}
+	cfg, err := loadConfig(configFiles, nil, opts, prov)
+	if err != nil {
+		return nil, fmt.Errorf("parsing configuration: %w", err)
+	}
+	if err := applyVars(cfg, vars, prov); err != nil {
+		return nil, fmt.Errorf("applying config vars: %w", err)
+	}
+
+	var values []ExplainedValue
+	walkLeaves(cfg, nil, func(path []string, value any) {
+		key := strings.Join(path, ".")
+		values = append(values, ExplainedValue{Path: key, Value: value, Sources: prov[key]})
+	})
+	sort.Slice(values, func(i, j int) bool { return values[i].Path < values[j].Path })
+	return values, nil
+}
+
+// record attributes the leaves of parsed to label, if they made it into merged.
+// Values mergo did not take over keep their previous source.
+// Lists that grew by appending get label added to their sources.
+func (p Provenance) record(label string, parsed, merged map[string]any) {
+	if p == nil {
+		return
+	}
+	walkLeaves(parsed, nil, func(path []string, value any) {
+		key := strings.Join(path, ".")
+		current, ok := lookupPath(merged, path)
+		if !ok {
+			return
+		}
+		if reflect.DeepEqual(current, value) {
+			p[key] = []string{label}
+			return
+		}
+		if list, isList := value.([]any); isList && len(list) > 0 {
+			if _, appended := current.([]any); appended {
+				p[key] = append(p[key], label)
+			}
+		}
+	})
+}
+
+// walkLeaves calls fn with the key path of every value in m that is not a
+// non-empty map
+func walkLeaves(m map[string]any, prefix []string, fn func(path []string, value any)) {
+	for key, value := range m {
+		path := append(append([]string{}, prefix...), key)
+		if nested, ok := value.(map[string]any); ok && len(nested) > 0 {
+			walkLeaves(nested, path, fn)
+			continue
+		}
+		fn(path, value)
+	}
+}
+
+// lookupPath returns the value at path in m
+func lookupPath(m map[string]any, path []string) (any, bool) {
+	var current any = m
+	for _, key := range path {
+		nested, ok := current.(map[string]any)
+		if !ok {
+			return nil, false
+		}
+		if current, ok = nested[key]; !ok {
+			return nil, false
+		}
+	}
+	return current, true
+}
+
+// printExplained prints one aligned line per value: path, JSON encoded value
+// and sources
+func printExplained(w io.Writer, values []ExplainedValue) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	for _, v := range values {
+		encoded, err := json.Marshal(v.Value)
+		if err != nil {
+			return fmt.Errorf("encoding value of %s: %w", v.Path, err)
+		}
+		sources := strings.Join(v.Sources, ", ")
+		if sources == "" {
+			sources = "-"
+		}
+		fmt.Fprintf(tw, "%s\t%s\t(%s)\n", v.Path, encoded, sources)
+	}
+	return tw.Flush()
+}
@@ -0,0 +1,122 @@
+package config
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/OpenSlides/openslides-cli/internal/constants"
+)
+
+func writeExplainConfig(t *testing.T, dir, name, content string) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, []byte(content), constants.StackFilePerm); err != nil {
+		t.Fatalf("failed to write %s: %v", name, err)
+	}
+	return path
+}
+
+func explainedSources(values []ExplainedValue) map[string][]string {
+	sources := make(map[string][]string, len(values))
+	for _, v := range values {
+		sources[v.Path] = v.Sources
+	}
+	return sources
+}
+
+func TestExplain(t *testing.T) {
+	dir := t.TempDir()
+	base := writeExplainConfig(t, dir, "base.yml", `
+defaults:
+  containerRegistry: example.com/registry
+  tag: 4.2.0
+services:
+  - backend
+enableLocalHTTPS: true
+`)
+	prod := writeExplainConfig(t, dir, "prod.yml", `
+defaults:
+  tag: 4.2.1
+services:
+  - search
+`)
+
+	t.Run("last file wins", func(t *testing.T) {
+		values, err := Explain([]string{base, prod}, []string{"port=8001"}, LoadOptions{})
+		if err != nil {
+			t.Fatalf("Explain() error = %v", err)
+		}
+
+		want := map[string][]string{
+			"defaults.containerRegistry": {base},
+			"defaults.tag":               {prod},
+			"enableLocalHTTPS":           {base},
+			"port":                       {"--var port=8001"},
+			"services":                   {prod},
+		}
+		if got := explainedSources(values); !reflect.DeepEqual(got, want) {
+			t.Errorf("Explain() sources = %v, want %v", got, want)
+		}
+
+		var paths []string
+		for _, v := range values {
+			paths = append(paths, v.Path)
+		}
+		if want := []string{"defaults.containerRegistry", "defaults.tag", "enableLocalHTTPS", "port", "services"}; !reflect.DeepEqual(paths, want) {
+			t.Errorf("Expected sorted paths %v, got %v", want, paths)
+		}
+	})
+
+	t.Run("appended lists name every file", func(t *testing.T) {
+		values, err := Explain([]string{base, prod}, nil, LoadOptions{AppendLists: true})
+		if err != nil {
+			t.Fatalf("Explain() error = %v", err)
+		}
+		if got := explainedSources(values)["services"]; !reflect.DeepEqual(got, []string{base, prod}) {
+			t.Errorf("Expected services from both files, got %v", got)
+		}
+	})
+
+	t.Run("emptied values are attributed", func(t *testing.T) {
+		empty := writeExplainConfig(t, dir, "empty.yml", "defaults:\n  tag: \"\"\n")
+		values, err := Explain([]string{base, empty}, nil, LoadOptions{})
+		if err != nil {
+			t.Fatalf("Explain() error = %v", err)
+		}
+		for _, v := range values {
+			if v.Path == "defaults.tag" && (v.Value != "" || !reflect.DeepEqual(v.Sources, []string{empty})) {
+				t.Errorf("Expected empty defaults.tag from %s, got %q from %v", empty, v.Value, v.Sources)
+			}
+		}
+	})
+}
+
+func TestExplainCmd(t *testing.T) {
+	dir := t.TempDir()
+	base := writeExplainConfig(t, dir, "base.yml", "defaults:\n  tag: 4.2.0\nport: 8000\n")
+	override := writeExplainConfig(t, dir, "override.yml", "defaults:\n  tag: 4.3.0\n")
+
+	cmd := Cmd()
+	var buf bytes.Buffer
+	cmd.SetOut(&buf)
+	cmd.SetErr(&bytes.Buffer{})
+	cmd.SetArgs([]string{"explain", base, override})
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("Expected 2 lines, got %q", buf.String())
+	}
+	if !strings.HasPrefix(lines[0], "defaults.tag") || !strings.Contains(lines[0], `"4.3.0"`) || !strings.HasSuffix(lines[0], "("+override+")") {
+		t.Errorf("Unexpected line for defaults.tag: %q", lines[0])
+	}
+	if !strings.HasPrefix(lines[1], "port") || !strings.Contains(lines[1], "8000") || !strings.HasSuffix(lines[1], "("+base+")") {
+		t.Errorf("Unexpected line for port: %q", lines[1])
+	}
+}
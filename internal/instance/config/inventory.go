@@ -0,0 +1,168 @@
+package config
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/OpenSlides/openslides-cli/internal/constants"
+	"github.com/OpenSlides/openslides-cli/internal/logger"
+	"github.com/spf13/cobra"
+)
+
+const (
+	VerifyHelp      = "Checks generated deployment files for changes"
+	VerifyHelpExtra = `Compares the deployment files of an instance with the SHA-256 digests
+recorded in ` + constants.InventoryFile + ` when they were generated by setup or config.
+Reports files that were changed or removed since and fails if there are any.
+
+Examples:
+  osmanage config verify ./my.instance.dir.org`
+)
+
+// Inventory maps the paths of generated files, relative to the instance
+// directory, to the hex encoded SHA-256 digest of their content
+type Inventory map[string]string
+
+// Drift is a generated file that no longer matches the inventory
+type Drift struct {
+	Path    string
+	Missing bool
+}
+
+// VerifyCmd returns the verify subcommand of config.
+func VerifyCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "verify <instance-dir>",
+		Short: VerifyHelp,
+		Long:  VerifyHelp + "\n\n" + VerifyHelpExtra,
+		Args:  cobra.ExactArgs(1),
+	}
+
+	cmd.RunE = func(cmd *cobra.Command, args []string) error {
+		logger.Info("=== CONFIG VERIFY ===")
+
+		baseDir := args[0]
+		inventory, err := ReadInventory(baseDir)
+		if err != nil {
+			return err
+		}
+
+		drifts, err := VerifyInventory(baseDir, inventory)
+		if err != nil {
+			return err
+		}
+
+		for _, drift := range drifts {
+			status := "changed"
+			if drift.Missing {
+				status = "missing"
+			}
+			fmt.Printf("%s: %s\n", status, drift.Path)
+		}
+		if len(drifts) > 0 {
+			return fmt.Errorf("%d of %d generated files changed or missing", len(drifts), len(inventory))
+		}
+
+		fmt.Printf("All %d generated files match the inventory\n", len(inventory))
+		return nil
+	}
+
+	return cmd
+}
+
+// ReadInventory reads the inventory file of the instance in baseDir.
+func ReadInventory(baseDir string) (Inventory, error) {
+	path := filepath.Join(baseDir, constants.InventoryFile)
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading inventory: %w", err)
+	}
+
+	var inventory Inventory
+	if err := json.Unmarshal(data, &inventory); err != nil {
+		return nil, fmt.Errorf("parsing inventory %q: %w", path, err)
+	}
+	return inventory, nil
+}
+
+// VerifyInventory recomputes the digests of all files in inventory and returns
+// the files that changed or are missing, sorted by path.
+func VerifyInventory(baseDir string, inventory Inventory) ([]Drift, error) {
+	paths := make([]string, 0, len(inventory))
+	for path := range inventory {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	var drifts []Drift
+	for _, path := range paths {
+		digest, err := hashFile(filepath.Join(baseDir, filepath.FromSlash(path)))
+		if errors.Is(err, os.ErrNotExist) {
+			drifts = append(drifts, Drift{Path: path, Missing: true})
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		if digest != inventory[path] {
+			drifts = append(drifts, Drift{Path: path})
+		}
+	}
+	return drifts, nil
+}
+
+// updateInventory writes the inventory of the generated files, given as map
+// from relative path to whether the file was written in this run. Kept files
+// retain their previous digest, so edits made since are still reported.
+func updateInventory(baseDir string, generated map[string]bool) error {
+	previous, err := ReadInventory(baseDir)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		logger.Warn("Ignoring existing inventory: %v", err)
+	}
+
+	inventory := make(Inventory, len(generated))
+	for path, written := range generated {
+		if digest, ok := previous[path]; ok && !written {
+			inventory[path] = digest
+			continue
+		}
+		digest, err := hashFile(filepath.Join(baseDir, filepath.FromSlash(path)))
+		if err != nil {
+			return err
+		}
+		inventory[path] = digest
+	}
+
+	data, err := json.MarshalIndent(inventory, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encoding inventory: %w", err)
+	}
+	path := filepath.Join(baseDir, constants.InventoryFile)
+	if err := os.WriteFile(path, append(data, '\n'), constants.StackFilePerm); err != nil {
+		return fmt.Errorf("writing inventory %q: %w", path, err)
+	}
+	logger.Debug("Wrote inventory of %d files to %s", len(inventory), path)
+	return nil
+}
+
+// hashFile returns the hex encoded SHA-256 digest of the file at path
+func hashFile(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", fmt.Errorf("opening %q: %w", path, err)
+	}
+	defer func() { _ = f.Close() }()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", fmt.Errorf("hashing %q: %w", path, err)
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
@@ -0,0 +1,103 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/OpenSlides/openslides-cli/internal/constants"
+)
+
+func TestInventory(t *testing.T) {
+	tmpdir := t.TempDir()
+
+	tplDir := filepath.Join(tmpdir, "templates")
+	if err := os.MkdirAll(filepath.Join(tplDir, constants.StackDirName), 0755); err != nil {
+		t.Fatal(err)
+	}
+	templates := map[string]string{
+		constants.NamespaceYAML:                               "name: {{ .name }}\n",
+		filepath.Join(constants.StackDirName, "backend.yaml"): "image: backend\n",
+	}
+	for name, content := range templates {
+		if err := os.WriteFile(filepath.Join(tplDir, name), []byte(content), constants.StackFilePerm); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	outDir := filepath.Join(tmpdir, "instance")
+	cfg := map[string]any{"name": "test"}
+	if err := CreateDirAndFiles(outDir, false, tplDir, "", cfg, false); err != nil {
+		t.Fatalf("CreateDirAndFiles() error = %v", err)
+	}
+
+	inventory, err := ReadInventory(outDir)
+	if err != nil {
+		t.Fatalf("ReadInventory() error = %v", err)
+	}
+	if len(inventory) != 2 || inventory["stack/backend.yaml"] == "" || inventory[constants.NamespaceYAML] == "" {
+		t.Fatalf("Unexpected inventory: %v", inventory)
+	}
+
+	drifts, err := VerifyInventory(outDir, inventory)
+	if err != nil {
+		t.Fatalf("VerifyInventory() error = %v", err)
+	}
+	if len(drifts) != 0 {
+		t.Errorf("Expected no drift after generating, got %v", drifts)
+	}
+
+	t.Run("reports changed and missing files", func(t *testing.T) {
+		if err := os.WriteFile(filepath.Join(outDir, constants.NamespaceYAML), []byte("name: edited\n"), constants.StackFilePerm); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Remove(filepath.Join(outDir, constants.StackDirName, "backend.yaml")); err != nil {
+			t.Fatal(err)
+		}
+
+		drifts, err := VerifyInventory(outDir, inventory)
+		if err != nil {
+			t.Fatalf("VerifyInventory() error = %v", err)
+		}
+		want := []Drift{{Path: constants.NamespaceYAML}, {Path: "stack/backend.yaml", Missing: true}}
+		if len(drifts) != len(want) || drifts[0] != want[0] || drifts[1] != want[1] {
+			t.Errorf("Expected %v, got %v", want, drifts)
+		}
+	})
+
+	t.Run("regenerating without force keeps edits visible", func(t *testing.T) {
+		if err := CreateDirAndFiles(outDir, false, tplDir, "", cfg, false); err != nil {
+			t.Fatalf("CreateDirAndFiles() error = %v", err)
+		}
+		inventory, err := ReadInventory(outDir)
+		if err != nil {
+			t.Fatalf("ReadInventory() error = %v", err)
+		}
+
+		drifts, err := VerifyInventory(outDir, inventory)
+		if err != nil {
+			t.Fatalf("VerifyInventory() error = %v", err)
+		}
+		if len(drifts) != 1 || drifts[0].Path != constants.NamespaceYAML {
+			t.Errorf("Expected edited namespace.yaml to be reported, got %v", drifts)
+		}
+	})
+
+	t.Run("regenerating with force", func(t *testing.T) {
+		if err := CreateDirAndFiles(outDir, true, tplDir, "", cfg, false); err != nil {
+			t.Fatalf("CreateDirAndFiles() error = %v", err)
+		}
+		inventory, err := ReadInventory(outDir)
+		if err != nil {
+			t.Fatalf("ReadInventory() error = %v", err)
+		}
+
+		drifts, err := VerifyInventory(outDir, inventory)
+		if err != nil {
+			t.Fatalf("VerifyInventory() error = %v", err)
+		}
+		if len(drifts) != 0 {
+			t.Errorf("Expected no drift after forced regeneration, got %v", drifts)
+		}
+	})
+}
@@ -0,0 +1,91 @@
+package config
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/OpenSlides/openslides-cli/internal/logger"
+	"github.com/spf13/cobra"
+)
+
+const (
+	RenderHelp      = "Renders a single template to stdout"
+	RenderHelpExtra = `Renders one template file against the merged configuration and writes the
+result to stdout. Nothing is written to disk and no instance layout is
+created, which makes this handy for developing templates.
+
+Config files, --expand-env, --merge-append-lists, --var overrides and
+--strict-templates work like for "osmanage config".
+Templates using readSecret need --base-dir, the instance directory whose
+secrets/ directory holds the secrets.
+
+Examples:
+  osmanage config render --template ./k8s-templates/stack/backend-deployment.yaml.tmpl --config ./config.yaml
+  osmanage config render -t ./custom.tmpl -c base.yaml -c overrides.yaml --var defaults.tag=4.3.0
+  osmanage config render -t ./secrets.yaml.tmpl -c config.yaml --base-dir ./my.instance.dir.org`
+)
+
+// RenderCmd returns the render subcommand of config.
+func RenderCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "render",
+		Short: RenderHelp,
+		Long:  RenderHelp + "\n\n" + RenderHelpExtra,
+		Args:  cobra.NoArgs,
+	}
+
+	tplFile := cmd.Flags().StringP("template", "t", "", "template file to render (required)")
+	configFiles := cmd.Flags().StringArrayP("config", "c", nil, "custom YAML config file (can be used multiple times)")
+	vars := cmd.Flags().StringArray("var", nil, "override a config value with a dotted key, e.g. defaults.tag=4.3.0 (can be used multiple times)")
+	expandEnv := cmd.Flags().Bool("expand-env", false, ExpandEnvUsage)
+	appendLists := cmd.Flags().Bool("merge-append-lists", false, AppendListsUsage)
+	strictTemplates := cmd.Flags().Bool("strict-templates", false, StrictTemplatesUsage)
+	baseDir := cmd.Flags().String("base-dir", "", "instance directory to read secrets from for readSecret")
+	_ = cmd.MarkFlagRequired("template")
+
+	cmd.RunE = func(cmd *cobra.Command, args []string) error {
+		logger.Debug("Rendering template %s with config files %v and vars %v", *tplFile, *configFiles, *vars)
+		opts := LoadOptions{ExpandEnv: *expandEnv, AppendLists: *appendLists}
+		return Render(cmd.OutOrStdout(), *tplFile, *configFiles, *vars, opts, *strictTemplates, *baseDir)
+	}
+
+	return cmd
+}
+
+// Render merges configFiles as set by opts, applies vars on top and writes the
+// template file tplFile rendered against the result to w. With strict, the
+// template fails on missing config keys. baseDir is the instance directory
+// readSecret reads from; without it, templates using readSecret fail.
+func Render(w io.Writer, tplFile string, configFiles []string, vars []string, opts LoadOptions, strict bool, baseDir string) error {
+	info, err := os.Stat(tplFile)
+	if err != nil {
+		return fmt.Errorf("checking template file: %w", err)
+	}
+	if info.IsDir() {
+		return fmt.Errorf("template %q is a directory, render takes a single template file", tplFile)
+	}
+
+	data, err := os.ReadFile(tplFile)
+	if err != nil {
+		return fmt.Errorf("reading template file: %w", err)
+	}
+
+	cfg, err := LoadConfig(configFiles, nil, opts)
+	if err != nil {
+		return fmt.Errorf("parsing configuration: %w", err)
+	}
+	if err := ApplyVars(cfg, vars); err != nil {
+		return fmt.Errorf("applying config vars: %w", err)
+	}
+
+	content, err := renderTemplate(data, cfg, baseDir, strict)
+	if err != nil {
+		return fmt.Errorf("rendering %s: %w", tplFile, err)
+	}
+
+	if _, err := w.Write(content); err != nil {
+		return fmt.Errorf("writing output: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,97 @@
+package config
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/OpenSlides/openslides-cli/internal/constants"
+)
+
+func writeRenderFile(t *testing.T, dir, name, content string) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("failed to write %s: %v", name, err)
+	}
+	return path
+}
+
+func TestRender(t *testing.T) {
+	dir := t.TempDir()
+	tpl := writeRenderFile(t, dir, "service.yaml.tmpl", "image: {{ .defaults.registry }}/backend:{{ .defaults.tag }}\n")
+	base := writeRenderFile(t, dir, "base.yaml", "defaults:\n  registry: ghcr.io/openslides\n  tag: 4.2.0\n")
+	override := writeRenderFile(t, dir, "override.yaml", "defaults:\n  tag: 4.2.1\n")
+
+	var buf bytes.Buffer
+	if err := Render(&buf, tpl, []string{base, override}, []string{"defaults.registry=myreg"}, LoadOptions{}, false, ""); err != nil {
+		t.Fatalf("Render() error = %v", err)
+	}
+	if got, want := buf.String(), "image: myreg/backend:4.2.1\n"; got != want {
+		t.Errorf("Render() = %q, want %q", got, want)
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 3 {
+		t.Errorf("Expected render not to write any files, found %d entries", len(entries))
+	}
+}
+
+func TestRender_ReadSecret(t *testing.T) {
+	dir := t.TempDir()
+	tpl := writeRenderFile(t, dir, "secret.yaml.tmpl", "password: {{ readSecret \"postgres_password\" }}\n")
+
+	t.Run("without base dir", func(t *testing.T) {
+		err := Render(&bytes.Buffer{}, tpl, nil, nil, LoadOptions{}, false, "")
+		if err == nil || !strings.Contains(err.Error(), "no instance directory given") {
+			t.Errorf("Expected missing base dir error, got %v", err)
+		}
+	})
+
+	t.Run("with base dir", func(t *testing.T) {
+		instanceDir := t.TempDir()
+		secretsDir := filepath.Join(instanceDir, constants.SecretsDirName)
+		if err := os.MkdirAll(secretsDir, constants.SecretsDirPerm); err != nil {
+			t.Fatal(err)
+		}
+		writeRenderFile(t, secretsDir, "postgres_password", "secret123")
+
+		var buf bytes.Buffer
+		if err := Render(&buf, tpl, nil, nil, LoadOptions{}, false, instanceDir); err != nil {
+			t.Fatalf("Render() error = %v", err)
+		}
+		if got, want := buf.String(), "password: c2VjcmV0MTIz\n"; got != want {
+			t.Errorf("Render() = %q, want %q", got, want)
+		}
+	})
+}
+
+func TestRender_TemplateDirectory(t *testing.T) {
+	err := Render(&bytes.Buffer{}, t.TempDir(), nil, nil, LoadOptions{}, false, "")
+	if err == nil || !strings.Contains(err.Error(), "is a directory") {
+		t.Errorf("Expected directory error, got %v", err)
+	}
+}
+
+func TestRenderCmd(t *testing.T) {
+	dir := t.TempDir()
+	tpl := writeRenderFile(t, dir, "hello.tmpl", "hello {{ .name }}\n")
+	cfg := writeRenderFile(t, dir, "config.yaml", "name: world\n")
+
+	cmd := Cmd()
+	var buf bytes.Buffer
+	cmd.SetOut(&buf)
+	cmd.SetErr(&bytes.Buffer{})
+	cmd.SetArgs([]string{"render", "-t", tpl, "-c", cfg})
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	if got := buf.String(); got != "hello world\n" {
+		t.Errorf("Expected rendered template on stdout, got %q", got)
+	}
+}
@@ -7,7 +7,9 @@ import (
 	"strings"
 
 	"github.com/OpenSlides/openslides-cli/internal/constants"
+	"github.com/OpenSlides/openslides-cli/internal/instance/secrets"
 	"github.com/OpenSlides/openslides-cli/internal/logger"
+	"github.com/OpenSlides/openslides-cli/internal/utils"
 	"github.com/spf13/cobra"
 )
 
@@ -41,6 +43,7 @@ func Cmd() *cobra.Command {
 	dbPassword := cmd.Flags().String("db-password", "", "PostgreSQL database password (required)")
 	superadminPassword := cmd.Flags().String("superadmin-password", "", "Superadmin password (required)")
 	voteKey := cmd.Flags().String("vote-key", "", "Vote Key (required)")
+	force := cmd.Flags().BoolP("force", "f", false, "Skip confirmation prompt when overwriting existing secrets")
 
 	_ = cmd.MarkFlagRequired("db-password")
 	_ = cmd.MarkFlagRequired("superadmin-password")
@@ -51,6 +54,18 @@ func Cmd() *cobra.Command {
 		instanceDir := args[0]
 		logger.Debug("Instance directory: %s", instanceDir)
 
+		if existing := existingSecrets(instanceDir); len(existing) > 0 && !*force && utils.IsInteractive(os.Stdin) {
+			logger.Warn("This will overwrite existing secrets: %s", strings.Join(existing, ", "))
+			confirmed, err := utils.Confirm(os.Stdout, os.Stdin, "Are you sure you want to continue?", "")
+			if err != nil {
+				return fmt.Errorf("confirming overwrite: %w", err)
+			}
+			if !confirmed {
+				logger.Info("Create cancelled")
+				return nil
+			}
+		}
+
 		if err := CreateInstance(instanceDir, *dbPassword, *superadminPassword, *voteKey); err != nil {
 			return fmt.Errorf("creating instance: %w", err)
 		}
@@ -109,27 +124,24 @@ func CreateInstance(instanceDir, dbPassword, superadminPassword, voteKey string)
 	return nil
 }
 
-// secureSecretsDirectory sets restrictive permissions on the secrets directory and all files within
-func secureSecretsDirectory(secretsDir string) error {
-	if err := os.Chmod(secretsDir, constants.SecretsDirPerm); err != nil {
-		return fmt.Errorf("setting directory permissions: %w", err)
-	}
-
-	entries, err := os.ReadDir(secretsDir)
-	if err != nil {
-		return fmt.Errorf("reading secrets directory: %w", err)
-	}
-
-	for _, entry := range entries {
-		if entry.IsDir() {
-			continue
-		}
-
-		filePath := filepath.Join(secretsDir, entry.Name())
-		if err := os.Chmod(filePath, constants.SecretFilePerm); err != nil {
-			return fmt.Errorf("setting permissions for %s: %w", entry.Name(), err)
+// existingSecrets returns the names of secret files written by CreateInstance
+// that already exist and are not empty
+func existingSecrets(instanceDir string) []string {
+	var existing []string
+	for _, name := range []string{constants.PgPasswordFile, constants.AdminSecretsFile, constants.VoteKeyFile} {
+		info, err := os.Stat(filepath.Join(instanceDir, constants.SecretsDirName, name))
+		if err == nil && info.Size() > 0 {
+			existing = append(existing, name)
 		}
 	}
+	return existing
+}
 
-	return nil
+// secureSecretsDirectory sets restrictive permissions on the secrets directory and all files within
+func secureSecretsDirectory(secretsDir string) error {
+	changes, err := secrets.Secure(secretsDir)
+	for _, c := range changes {
+		logger.Debug("Changed permissions of %s from %04o to %04o", c.Path, c.Old, c.New)
+	}
+	return err
 }
@@ -2,9 +2,13 @@ package remove
 
 import (
 	"fmt"
+	"io"
 	"os"
+	"path/filepath"
 
+	"github.com/OpenSlides/openslides-cli/internal/instance/secrets"
 	"github.com/OpenSlides/openslides-cli/internal/logger"
+	"github.com/OpenSlides/openslides-cli/internal/utils"
 	"github.com/spf13/cobra"
 )
 
@@ -15,8 +19,13 @@ const (
 WARNING: This operation is irreversible! All configuration files, secrets,
 and instance data in the directory will be permanently deleted.
 
+On an interactive terminal you are asked to type the instance name (the name
+of the directory) to confirm. Without a terminal --force is required.
+
 Examples:
-  osmanage remove ./my.instance.dir.org --force`
+  osmanage remove ./my.instance.dir.org
+  osmanage remove ./my.instance.dir.org --force
+  osmanage remove ./my.instance.dir.org --backup ./my.instance.dir.org-secrets.tar.gz`
 )
 
 func Cmd() *cobra.Command {
@@ -28,13 +37,14 @@ func Cmd() *cobra.Command {
 	}
 
 	force := cmd.Flags().BoolP("force", "f", false, "Skip confirmation prompt")
+	backup := cmd.Flags().String("backup", "", "Write the secrets directory as tar.gz to this path before removal")
 
 	cmd.RunE = func(cmd *cobra.Command, args []string) error {
 		logger.Info("=== K8S REMOVE INSTANCE ===")
 		instanceDir := args[0]
 		logger.Debug("Instance directory: %s", instanceDir)
 
-		if err := RemoveInstance(instanceDir, *force); err != nil {
+		if err := RemoveInstance(instanceDir, *force, *backup); err != nil {
 			return fmt.Errorf("removing instance: %w", err)
 		}
 
@@ -45,9 +55,17 @@ func Cmd() *cobra.Command {
 	return cmd
 }
 
-// RemoveInstance removes the entire instance directory
-// When force is false, prompts for confirmation before deletion.
-func RemoveInstance(instanceDir string, force bool) error {
+// RemoveInstance removes the entire instance directory.
+// When force is false, asks on an interactive stdin to type the instance name
+// to confirm and fails if stdin is not interactive.
+// If backupPath is set, the secrets are archived there first, see secrets.Backup.
+func RemoveInstance(instanceDir string, force bool, backupPath string) error {
+	return removeInstance(instanceDir, force, backupPath, utils.IsInteractive(os.Stdin), os.Stdout, os.Stdin)
+}
+
+// removeInstance is RemoveInstance with the confirmation prompt written to w
+// and the answer read from r if interactive is true.
+func removeInstance(instanceDir string, force bool, backupPath string, interactive bool, w io.Writer, r io.Reader) error {
 	info, err := os.Stat(instanceDir)
 	if err != nil {
 		if os.IsNotExist(err) {
@@ -61,19 +79,35 @@ func RemoveInstance(instanceDir string, force bool) error {
 	}
 
 	if !force {
+		if !interactive {
+			return fmt.Errorf("refusing to remove %s without --force in a non-interactive session", instanceDir)
+		}
+
+		absDir, err := filepath.Abs(instanceDir)
+		if err != nil {
+			return fmt.Errorf("resolving instance name: %w", err)
+		}
+		name := filepath.Base(absDir)
+
 		logger.Warn("This will permanently delete: %s", instanceDir)
 		logger.Warn("All configuration files, secrets, and data will be lost!")
 
-		fmt.Print("Are you sure you want to continue? [y/N]: ")
-		var response string
-		_, _ = fmt.Scanln(&response)
-
-		if response != "y" && response != "Y" && response != "yes" && response != "YES" {
+		confirmed, err := utils.Confirm(w, r, "Type the instance name to confirm deletion:", name)
+		if err != nil {
+			return fmt.Errorf("confirming removal: %w", err)
+		}
+		if !confirmed {
 			logger.Info("Removal cancelled")
 			return nil
 		}
 	}
 
+	if backupPath != "" {
+		if err := secrets.Backup(instanceDir, backupPath); err != nil {
+			return fmt.Errorf("backing up secrets: %w", err)
+		}
+	}
+
 	logger.Info("Removing instance directory: %s", instanceDir)
 
 	if err := os.RemoveAll(instanceDir); err != nil {
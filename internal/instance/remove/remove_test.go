@@ -1,6 +1,7 @@
 package remove
 
 import (
+	"io"
 	"os"
 	"path/filepath"
 	"strings"
@@ -36,7 +37,7 @@ func TestRemoveInstance_DirectoryExists(t *testing.T) {
 		}
 	})
 
-	err = RemoveInstance(instanceDir, true)
+	err = RemoveInstance(instanceDir, true, "")
 	if err != nil {
 		t.Fatalf("removeInstance failed: %v", err)
 	}
@@ -59,7 +60,7 @@ func TestRemoveInstance_DirectoryNotExist(t *testing.T) {
 
 	nonExistentDir := filepath.Join(tmpDir, "does-not-exist")
 
-	err = RemoveInstance(nonExistentDir, true)
+	err = RemoveInstance(nonExistentDir, true, "")
 	if err == nil {
 		t.Error("Expected error when removing non-existent directory, got nil")
 	}
@@ -86,7 +87,7 @@ func TestRemoveInstance_NotADirectory(t *testing.T) {
 		t.Fatalf("Failed to create test file: %v", err)
 	}
 
-	err = RemoveInstance(testFile, true)
+	err = RemoveInstance(testFile, true, "")
 	if err == nil {
 		t.Error("Expected error when removing a file instead of directory, got nil")
 	}
@@ -140,7 +141,7 @@ func TestRemoveInstance_RemovesNestedStructure(t *testing.T) {
 		}
 	})
 
-	err = RemoveInstance(instanceDir, true)
+	err = RemoveInstance(instanceDir, true, "")
 	if err != nil {
 		t.Fatalf("removeInstance failed: %v", err)
 	}
@@ -171,7 +172,7 @@ func TestRemoveInstance_WithForceFlag(t *testing.T) {
 		}
 	})
 
-	err = RemoveInstance(instanceDir, true)
+	err = RemoveInstance(instanceDir, true, "")
 	if err != nil {
 		t.Fatalf("removeInstance with force=true failed: %v", err)
 	}
@@ -198,7 +199,7 @@ func TestRemoveInstance_EmptyDirectory(t *testing.T) {
 		}
 	})
 
-	err = RemoveInstance(instanceDir, true)
+	err = RemoveInstance(instanceDir, true, "")
 	if err != nil {
 		t.Fatalf("removeInstance failed on empty directory: %v", err)
 	}
@@ -235,7 +236,7 @@ func TestRemoveInstance_WithSymlinks(t *testing.T) {
 		}
 	})
 
-	err = RemoveInstance(instanceDir, true)
+	err = RemoveInstance(instanceDir, true, "")
 	if err != nil {
 		t.Fatalf("removeInstance failed: %v", err)
 	}
@@ -248,3 +249,89 @@ func TestRemoveInstance_WithSymlinks(t *testing.T) {
 		t.Error("Symlink target should not be deleted")
 	}
 }
+
+func TestRemoveInstance_Confirmation(t *testing.T) {
+	newInstance := func(t *testing.T) string {
+		instanceDir := filepath.Join(t.TempDir(), "my.instance.dir.org")
+		if err := os.MkdirAll(filepath.Join(instanceDir, constants.SecretsDirName), constants.SecretsDirPerm); err != nil {
+			t.Fatalf("Failed to create instance dir: %v", err)
+		}
+		return instanceDir
+	}
+
+	t.Run("exact name confirms", func(t *testing.T) {
+		instanceDir := newInstance(t)
+		var out strings.Builder
+		if err := removeInstance(instanceDir, false, "", true, &out, strings.NewReader("my.instance.dir.org\n")); err != nil {
+			t.Fatalf("removeInstance() error = %v", err)
+		}
+		if !strings.Contains(out.String(), "Type the instance name to confirm deletion:") {
+			t.Errorf("Expected prompt, got %q", out.String())
+		}
+		if _, err := os.Stat(instanceDir); !os.IsNotExist(err) {
+			t.Error("Instance directory still exists after confirmed removal")
+		}
+	})
+
+	t.Run("other answer cancels", func(t *testing.T) {
+		for _, answer := range []string{"y\n", "my.instance.dir\n", ""} {
+			instanceDir := newInstance(t)
+			if err := removeInstance(instanceDir, false, "", true, io.Discard, strings.NewReader(answer)); err != nil {
+				t.Fatalf("removeInstance() error = %v", err)
+			}
+			if _, err := os.Stat(instanceDir); err != nil {
+				t.Errorf("Instance directory removed with answer %q", answer)
+			}
+		}
+	})
+
+	t.Run("non-interactive requires force", func(t *testing.T) {
+		instanceDir := newInstance(t)
+		err := removeInstance(instanceDir, false, "", false, io.Discard, strings.NewReader("my.instance.dir.org\n"))
+		if err == nil || !strings.Contains(err.Error(), "--force") {
+			t.Errorf("Expected error requiring --force, got %v", err)
+		}
+		if _, err := os.Stat(instanceDir); err != nil {
+			t.Error("Instance directory removed without force in non-interactive session")
+		}
+	})
+}
+
+func TestRemoveInstance_Backup(t *testing.T) {
+	tmpDir := t.TempDir()
+	instanceDir := filepath.Join(tmpDir, "my.instance.dir.org")
+	secretsDir := filepath.Join(instanceDir, constants.SecretsDirName)
+	if err := os.MkdirAll(secretsDir, constants.SecretsDirPerm); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(secretsDir, constants.AuthTokenKey), []byte("secret"), constants.SecretFilePerm); err != nil {
+		t.Fatal(err)
+	}
+
+	backupPath := filepath.Join(tmpDir, "secrets.tar.gz")
+	if err := RemoveInstance(instanceDir, true, backupPath); err != nil {
+		t.Fatalf("RemoveInstance() error = %v", err)
+	}
+	if _, err := os.Stat(instanceDir); !os.IsNotExist(err) {
+		t.Error("Instance directory still exists after removal")
+	}
+	if info, err := os.Stat(backupPath); err != nil || info.Size() == 0 {
+		t.Errorf("Expected backup at %s, got %v", backupPath, err)
+	}
+}
+
+func TestRemoveInstance_BackupFailureKeepsInstance(t *testing.T) {
+	tmpDir := t.TempDir()
+	instanceDir := filepath.Join(tmpDir, "my.instance.dir.org")
+	if err := os.MkdirAll(filepath.Join(instanceDir, constants.SecretsDirName), constants.SecretsDirPerm); err != nil {
+		t.Fatal(err)
+	}
+
+	backupPath := filepath.Join(instanceDir, "backup.tar.gz")
+	if err := RemoveInstance(instanceDir, true, backupPath); err == nil {
+		t.Error("Expected error for backup inside the instance directory")
+	}
+	if _, err := os.Stat(instanceDir); err != nil {
+		t.Error("Instance directory removed although backup failed")
+	}
+}
@@ -0,0 +1,119 @@
+package secrets
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/OpenSlides/openslides-cli/internal/constants"
+	"github.com/OpenSlides/openslides-cli/internal/logger"
+)
+
+// Backup writes the secrets directory of instanceDir, including a saved TLS
+// certificate secret, as tar.gz to backupPath. Entries are stored relative to
+// instanceDir with their original permissions. The backup file is readable by
+// the owner only; an existing file at backupPath is not overwritten.
+func Backup(instanceDir, backupPath string) (err error) {
+	absDir, err := filepath.Abs(instanceDir)
+	if err != nil {
+		return fmt.Errorf("resolving instance directory: %w", err)
+	}
+	absBackup, err := filepath.Abs(backupPath)
+	if err != nil {
+		return fmt.Errorf("resolving backup path: %w", err)
+	}
+	if strings.HasPrefix(absBackup, absDir+string(filepath.Separator)) {
+		return fmt.Errorf("backup %s must be outside of the instance directory", backupPath)
+	}
+
+	secretsDir := filepath.Join(instanceDir, constants.SecretsDirName)
+	if info, err := os.Stat(secretsDir); err != nil || !info.IsDir() {
+		return fmt.Errorf("no %s directory to back up in %s", constants.SecretsDirName, instanceDir)
+	}
+
+	f, err := os.OpenFile(backupPath, os.O_WRONLY|os.O_CREATE|os.O_EXCL, constants.SavedSecretFilePerm)
+	if err != nil {
+		return fmt.Errorf("creating backup file: %w", err)
+	}
+	defer func() {
+		if closeErr := f.Close(); closeErr != nil && err == nil {
+			err = fmt.Errorf("closing backup file: %w", closeErr)
+		}
+		if err != nil {
+			_ = os.Remove(backupPath)
+		}
+	}()
+
+	gz := gzip.NewWriter(f)
+	tw := tar.NewWriter(gz)
+
+	err = filepath.WalkDir(secretsDir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		return addToArchive(tw, instanceDir, path, d)
+	})
+	if err != nil {
+		return fmt.Errorf("archiving secrets: %w", err)
+	}
+
+	if err := tw.Close(); err != nil {
+		return fmt.Errorf("closing tar archive: %w", err)
+	}
+	if err := gz.Close(); err != nil {
+		return fmt.Errorf("closing gzip stream: %w", err)
+	}
+
+	logger.Info("Backed up secrets to %s", backupPath)
+	return nil
+}
+
+// addToArchive writes the directory or regular file at path to tw, named
+// relative to baseDir. Other file types are skipped.
+func addToArchive(tw *tar.Writer, baseDir, path string, d fs.DirEntry) error {
+	info, err := d.Info()
+	if err != nil {
+		return err
+	}
+	if !info.IsDir() && !info.Mode().IsRegular() {
+		logger.Warn("Skipping %s in backup: not a regular file", path)
+		return nil
+	}
+
+	rel, err := filepath.Rel(baseDir, path)
+	if err != nil {
+		return err
+	}
+
+	header, err := tar.FileInfoHeader(info, "")
+	if err != nil {
+		return err
+	}
+	header.Name = filepath.ToSlash(rel)
+	if info.IsDir() {
+		header.Name += "/"
+	}
+
+	if err := tw.WriteHeader(header); err != nil {
+		return err
+	}
+	if info.IsDir() {
+		return nil
+	}
+
+	src, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = src.Close() }()
+
+	if _, err := io.Copy(tw, src); err != nil {
+		return fmt.Errorf("writing %s: %w", rel, err)
+	}
+	return nil
+}
@@ -0,0 +1,142 @@
+package secrets
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/OpenSlides/openslides-cli/internal/constants"
+	"github.com/OpenSlides/openslides-cli/internal/instance/setup"
+	"github.com/OpenSlides/openslides-cli/internal/logger"
+	"github.com/spf13/cobra"
+)
+
+const (
+	RotateHelp      = "Regenerate secrets of an instance"
+	RotateHelpExtra = `Regenerates secrets of an instance after writing the old ones to an archive
+(see 'secrets backup'). Without --only the key secrets auth_token_key,
+auth_cookie_key and internal_auth_password are rotated, passwords like
+postgres_password and superadmin only when named.
+
+Restart the instance afterwards to use the new secrets.
+
+Examples:
+  osmanage secrets rotate ./my.instance.dir.org
+  osmanage secrets rotate ./my.instance.dir.org --only auth_token_key,auth_cookie_key
+  osmanage secrets rotate ./my.instance.dir.org --only vote_key --backup ./old-secrets.tar.gz
+  osmanage secrets rotate ./my.instance.dir.org --only postgres_password --password-charset alnum`
+)
+
+// Rotation status of a secret
+const (
+	RotationChanged   = "changed"
+	RotationCreated   = "created"
+	RotationUnchanged = "unchanged"
+)
+
+// Rotation is the result of regenerating a single secret
+type Rotation struct {
+	Name   string
+	Status string
+}
+
+func RotateCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "rotate <instance-dir>",
+		Short: RotateHelp,
+		Long:  RotateHelp + "\n\n" + RotateHelpExtra,
+		Args:  cobra.ExactArgs(1),
+	}
+
+	only := cmd.Flags().StringSlice("only", nil, "secrets to rotate (default: auth_token_key, auth_cookie_key, internal_auth_password)")
+	backup := cmd.Flags().String("backup", "", "archive for the old secrets (default: <instance-dir>-secrets-<timestamp>.tar.gz)")
+	passwordCharset := cmd.Flags().String("password-charset", "full", setup.PasswordCharsetUsage)
+
+	cmd.RunE = func(cmd *cobra.Command, args []string) error {
+		logger.Info("=== SECRETS ROTATE ===")
+		instanceDir := args[0]
+		logger.Debug("Instance directory: %s", instanceDir)
+
+		charset, err := setup.PasswordCharset(*passwordCharset)
+		if err != nil {
+			return err
+		}
+
+		backupPath := *backup
+		if backupPath == "" {
+			if backupPath, err = defaultBackupPath(instanceDir, time.Now()); err != nil {
+				return err
+			}
+		}
+
+		rotations, err := Rotate(instanceDir, *only, charset, backupPath)
+		if err != nil {
+			return fmt.Errorf("rotating secrets: %w", err)
+		}
+
+		fmt.Printf("Old secrets written to: %s\n", backupPath)
+		for _, r := range rotations {
+			fmt.Printf("%s: %s\n", r.Status, r.Name)
+		}
+		return nil
+	}
+
+	return cmd
+}
+
+// Rotate writes the secrets of instanceDir to the archive backupPath and then
+// regenerates the secrets named in names, see setup.SelectSecrets for the
+// default. New passwords are drawn from charset. Nothing is regenerated if the
+// backup fails.
+func Rotate(instanceDir string, names []string, charset, backupPath string) ([]Rotation, error) {
+	specs, err := setup.SelectSecrets(names, charset)
+	if err != nil {
+		return nil, err
+	}
+
+	secretsDir := filepath.Join(instanceDir, constants.SecretsDirName)
+	old := make(map[string][]byte, len(specs))
+	for _, spec := range specs {
+		if data, err := os.ReadFile(filepath.Join(secretsDir, spec.Name)); err == nil {
+			old[spec.Name] = data
+		}
+	}
+
+	if err := Backup(instanceDir, backupPath); err != nil {
+		return nil, fmt.Errorf("backing up secrets: %w", err)
+	}
+
+	if err := setup.RegenerateSecrets(secretsDir, specs); err != nil {
+		return nil, err
+	}
+
+	rotations := make([]Rotation, 0, len(specs))
+	for _, spec := range specs {
+		data, err := os.ReadFile(filepath.Join(secretsDir, spec.Name))
+		if err != nil {
+			return nil, fmt.Errorf("reading rotated secret %q: %w", spec.Name, err)
+		}
+
+		status := RotationChanged
+		if previous, ok := old[spec.Name]; !ok {
+			status = RotationCreated
+		} else if bytes.Equal(previous, data) {
+			status = RotationUnchanged
+		}
+		logger.Info("Rotated secret %s: %s", spec.Name, status)
+		rotations = append(rotations, Rotation{Name: spec.Name, Status: status})
+	}
+	return rotations, nil
+}
+
+// defaultBackupPath returns the archive path next to instanceDir named after
+// it and the time of the rotation
+func defaultBackupPath(instanceDir string, now time.Time) (string, error) {
+	absDir, err := filepath.Abs(instanceDir)
+	if err != nil {
+		return "", fmt.Errorf("resolving instance directory: %w", err)
+	}
+	return fmt.Sprintf("%s-secrets-%s.tar.gz", absDir, now.Format("20060102-150405")), nil
+}
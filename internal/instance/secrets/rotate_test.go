@@ -0,0 +1,109 @@
+package secrets
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/OpenSlides/openslides-cli/internal/constants"
+)
+
+func TestRotate(t *testing.T) {
+	tmpDir := t.TempDir()
+	instanceDir := filepath.Join(tmpDir, "my.instance.dir.org")
+	writeSecrets(t, instanceDir, map[string]string{
+		constants.AuthTokenKey:         "old token",
+		constants.AuthCookieKey:        "old cookie",
+		constants.PgPasswordFile:       "old postgres",
+		constants.AdminSecretsFile:     "old admin",
+		constants.InternalAuthPassword: "old internal",
+	})
+	secretsDir := filepath.Join(instanceDir, constants.SecretsDirName)
+
+	t.Run("default secrets", func(t *testing.T) {
+		backupPath := filepath.Join(tmpDir, "default.tar.gz")
+		rotations, err := Rotate(instanceDir, nil, constants.PasswordCharset, backupPath)
+		if err != nil {
+			t.Fatalf("Rotate() error = %v", err)
+		}
+
+		want := []Rotation{
+			{Name: constants.AuthTokenKey, Status: RotationChanged},
+			{Name: constants.AuthCookieKey, Status: RotationChanged},
+			{Name: constants.InternalAuthPassword, Status: RotationChanged},
+		}
+		if len(rotations) != len(want) {
+			t.Fatalf("Expected %v, got %v", want, rotations)
+		}
+		for i := range want {
+			if rotations[i] != want[i] {
+				t.Errorf("Rotation %d: expected %+v, got %+v", i, want[i], rotations[i])
+			}
+		}
+
+		for name, old := range map[string]string{constants.PgPasswordFile: "old postgres", constants.AdminSecretsFile: "old admin"} {
+			if data, _ := os.ReadFile(filepath.Join(secretsDir, name)); string(data) != old {
+				t.Errorf("Expected %s to be kept, got %q", name, data)
+			}
+		}
+
+		_, contents := readArchive(t, backupPath)
+		if contents["secrets/"+constants.AuthTokenKey] != "old token" {
+			t.Errorf("Expected old token in backup, got %q", contents["secrets/"+constants.AuthTokenKey])
+		}
+	})
+
+	t.Run("only named secrets", func(t *testing.T) {
+		rotations, err := Rotate(instanceDir, []string{constants.PgPasswordFile, constants.VoteKeyFile}, constants.PasswordCharsetAlnum, filepath.Join(tmpDir, "only.tar.gz"))
+		if err != nil {
+			t.Fatalf("Rotate() error = %v", err)
+		}
+		want := []Rotation{
+			{Name: constants.PgPasswordFile, Status: RotationChanged},
+			{Name: constants.VoteKeyFile, Status: RotationCreated},
+		}
+		if len(rotations) != 2 || rotations[0] != want[0] || rotations[1] != want[1] {
+			t.Errorf("Expected %v, got %v", want, rotations)
+		}
+		if data, _ := os.ReadFile(filepath.Join(secretsDir, constants.PgPasswordFile)); strings.Trim(string(data), constants.PasswordCharsetAlnum) != "" {
+			t.Errorf("Expected alphanumeric postgres password, got %q", data)
+		}
+		if data, _ := os.ReadFile(filepath.Join(secretsDir, constants.AdminSecretsFile)); string(data) != "old admin" {
+			t.Errorf("Expected superadmin to be kept, got %q", data)
+		}
+	})
+
+	t.Run("unknown secret", func(t *testing.T) {
+		backupPath := filepath.Join(tmpDir, "unknown.tar.gz")
+		if _, err := Rotate(instanceDir, []string{"unknown"}, constants.PasswordCharset, backupPath); err == nil {
+			t.Error("Expected error for unknown secret")
+		}
+		if _, err := os.Stat(backupPath); !os.IsNotExist(err) {
+			t.Error("Expected no backup for unknown secret")
+		}
+	})
+
+	t.Run("failed backup keeps secrets", func(t *testing.T) {
+		before, _ := os.ReadFile(filepath.Join(secretsDir, constants.AuthTokenKey))
+		if _, err := Rotate(instanceDir, nil, constants.PasswordCharset, filepath.Join(tmpDir, "default.tar.gz")); err == nil {
+			t.Error("Expected error for existing backup")
+		}
+		if after, _ := os.ReadFile(filepath.Join(secretsDir, constants.AuthTokenKey)); string(after) != string(before) {
+			t.Error("Secret rotated although backup failed")
+		}
+	})
+}
+
+func TestDefaultBackupPath(t *testing.T) {
+	dir := t.TempDir()
+	now := time.Date(2026, 3, 1, 14, 5, 9, 0, time.UTC)
+	got, err := defaultBackupPath(filepath.Join(dir, "my.instance.dir.org"), now)
+	if err != nil {
+		t.Fatalf("defaultBackupPath() error = %v", err)
+	}
+	if want := filepath.Join(dir, "my.instance.dir.org-secrets-20260301-140509.tar.gz"); got != want {
+		t.Errorf("defaultBackupPath() = %s, want %s", got, want)
+	}
+}
@@ -0,0 +1,197 @@
+package secrets
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/OpenSlides/openslides-cli/internal/constants"
+	"github.com/OpenSlides/openslides-cli/internal/logger"
+	"github.com/OpenSlides/openslides-cli/internal/utils"
+	"github.com/spf13/cobra"
+)
+
+const (
+	SecretsHelp = "Back up, restore, rotate and secure the secrets of an instance"
+
+	BackupHelp      = "Write the secrets of an instance to an archive"
+	BackupHelpExtra = `Writes the secrets directory of an instance as tar.gz archive, e.g. to move
+the instance to another machine. The archive keeps the file permissions, store
+it as securely as the secrets themselves. An existing archive is not overwritten.
+
+Examples:
+  osmanage secrets backup ./my.instance.dir.org ./my.instance.dir.org-secrets.tar.gz`
+
+	RestoreHelp      = "Restore the secrets of an instance from an archive"
+	RestoreHelpExtra = `Extracts the secrets directory from an archive written by 'secrets backup'
+or 'remove --backup' into an instance directory. The secrets directory gets 700,
+every secret 644 and Kubernetes secret manifests saved by 'k8s stop'
+(*-secret.yaml) 600 permissions, regardless of the permissions in the archive.
+
+Existing secrets are kept unless --force is given.
+
+Examples:
+  osmanage secrets restore ./my.instance.dir.org-secrets.tar.gz ./my.instance.dir.org
+  osmanage secrets restore ./my.instance.dir.org-secrets.tar.gz ./my.instance.dir.org --force`
+)
+
+// Cmd returns the secrets command group.
+func Cmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "secrets",
+		Short: SecretsHelp,
+		Long:  SecretsHelp,
+	}
+
+	cmd.AddCommand(BackupCmd(), RestoreCmd(), RotateCmd(), SecureCmd())
+	return cmd
+}
+
+func BackupCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "backup <instance-dir> <archive>",
+		Short: BackupHelp,
+		Long:  BackupHelp + "\n\n" + BackupHelpExtra,
+		Args:  cobra.ExactArgs(2),
+	}
+
+	cmd.RunE = func(cmd *cobra.Command, args []string) error {
+		logger.Info("=== SECRETS BACKUP ===")
+		instanceDir, archive := args[0], args[1]
+		logger.Debug("Instance directory: %s", instanceDir)
+
+		if err := Backup(instanceDir, archive); err != nil {
+			return fmt.Errorf("backing up secrets: %w", err)
+		}
+
+		fmt.Printf("Secrets written to: %s\n", archive)
+		return nil
+	}
+
+	return cmd
+}
+
+func RestoreCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "restore <archive> <instance-dir>",
+		Short: RestoreHelp,
+		Long:  RestoreHelp + "\n\n" + RestoreHelpExtra,
+		Args:  cobra.ExactArgs(2),
+	}
+
+	force := cmd.Flags().BoolP("force", "f", false, "overwrite existing secrets")
+
+	cmd.RunE = func(cmd *cobra.Command, args []string) error {
+		logger.Info("=== SECRETS RESTORE ===")
+		archive, instanceDir := args[0], args[1]
+		logger.Debug("Instance directory: %s", instanceDir)
+
+		if err := Restore(archive, instanceDir, *force); err != nil {
+			return fmt.Errorf("restoring secrets: %w", err)
+		}
+
+		fmt.Printf("Secrets restored in: %s\n", instanceDir)
+		return nil
+	}
+
+	return cmd
+}
+
+// Restore extracts the secrets directory from the tar.gz archive into
+// instanceDir. Directories are created with SecretsDirPerm, files are written
+// with SecretFilePerm. Existing files are kept unless force is set, like
+// setup does when generating secrets. Entries outside the secrets directory
+// are skipped.
+func Restore(archive, instanceDir string, force bool) error {
+	f, err := os.Open(archive)
+	if err != nil {
+		return fmt.Errorf("opening archive: %w", err)
+	}
+	defer func() { _ = f.Close() }()
+
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		return fmt.Errorf("reading gzip stream: %w", err)
+	}
+	tr := tar.NewReader(gz)
+
+	secretsDir := filepath.Join(instanceDir, constants.SecretsDirName)
+	if err := os.MkdirAll(secretsDir, constants.SecretsDirPerm); err != nil {
+		return fmt.Errorf("creating secrets directory: %w", err)
+	}
+	if err := os.Chmod(secretsDir, constants.SecretsDirPerm); err != nil {
+		return fmt.Errorf("securing secrets directory: %w", err)
+	}
+
+	restored := 0
+	for {
+		header, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("reading archive: %w", err)
+		}
+
+		if path.Clean(header.Name) == constants.SecretsDirName {
+			continue
+		}
+		rel, ok := secretPath(header.Name)
+		if !ok {
+			logger.Warn("Skipping %s: not in the %s directory", header.Name, constants.SecretsDirName)
+			continue
+		}
+		target := filepath.Join(secretsDir, filepath.FromSlash(rel))
+
+		switch header.Typeflag {
+		case tar.TypeDir:
+			if err := os.MkdirAll(target, constants.SecretsDirPerm); err != nil {
+				return fmt.Errorf("creating directory %s: %w", rel, err)
+			}
+		case tar.TypeReg:
+			data, err := io.ReadAll(tr)
+			if err != nil {
+				return fmt.Errorf("reading %s: %w", header.Name, err)
+			}
+			dir := filepath.Dir(target)
+			if err := os.MkdirAll(dir, constants.SecretsDirPerm); err != nil {
+				return fmt.Errorf("creating directory for %s: %w", rel, err)
+			}
+			if exists, _ := utils.FileExists(target); exists && !force {
+				logger.Info("Keeping existing secret: %s", rel)
+				continue
+			}
+			if err := utils.CreateFile(dir, force, filepath.Base(target), data, secretFilePerm(target)); err != nil {
+				return fmt.Errorf("restoring %s: %w", rel, err)
+			}
+			// WriteFile keeps the permissions of an overwritten file
+			if err := os.Chmod(target, secretFilePerm(target)); err != nil {
+				return fmt.Errorf("securing %s: %w", rel, err)
+			}
+			restored++
+		default:
+			logger.Warn("Skipping %s: not a regular file", header.Name)
+		}
+	}
+
+	logger.Info("Restored %d secrets", restored)
+	return nil
+}
+
+// secretPath returns the path of an archive entry relative to the secrets
+// directory. ok is false for entries outside of it, including the directory
+// itself and paths escaping it.
+func secretPath(name string) (rel string, ok bool) {
+	cleaned := path.Clean(strings.TrimSuffix(name, "/"))
+	rel, found := strings.CutPrefix(cleaned, constants.SecretsDirName+"/")
+	if !found || rel == "" || rel == ".." || strings.HasPrefix(rel, "../") {
+		return "", false
+	}
+	return rel, true
+}
@@ -0,0 +1,238 @@
+package secrets
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/OpenSlides/openslides-cli/internal/constants"
+)
+
+// readArchive returns the mode and content of every entry of a tar.gz file
+func readArchive(t *testing.T, path string) (map[string]fs.FileMode, map[string]string) {
+	t.Helper()
+
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("opening archive: %v", err)
+	}
+	defer func() { _ = f.Close() }()
+
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		t.Fatalf("reading gzip: %v", err)
+	}
+	tr := tar.NewReader(gz)
+
+	modes := map[string]fs.FileMode{}
+	contents := map[string]string{}
+	for {
+		header, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			t.Fatalf("reading tar: %v", err)
+		}
+		modes[header.Name] = header.FileInfo().Mode().Perm()
+		data, err := io.ReadAll(tr)
+		if err != nil {
+			t.Fatalf("reading %s: %v", header.Name, err)
+		}
+		contents[header.Name] = string(data)
+	}
+	return modes, contents
+}
+
+// writeSecrets creates an instance directory with the given secrets
+func writeSecrets(t *testing.T, instanceDir string, secrets map[string]string) {
+	t.Helper()
+	secretsDir := filepath.Join(instanceDir, constants.SecretsDirName)
+	if err := os.MkdirAll(secretsDir, constants.SecretsDirPerm); err != nil {
+		t.Fatal(err)
+	}
+	// MkdirAll is subject to the umask, so set the permissions explicitly
+	if err := os.Chmod(secretsDir, constants.SecretsDirPerm); err != nil {
+		t.Fatal(err)
+	}
+	for name, content := range secrets {
+		if err := os.WriteFile(filepath.Join(secretsDir, name), []byte(content), constants.SecretFilePerm); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestBackup(t *testing.T) {
+	tmpDir := t.TempDir()
+	instanceDir := filepath.Join(tmpDir, "my.instance.dir.org")
+	writeSecrets(t, instanceDir, map[string]string{
+		constants.AuthTokenKey:      "token",
+		constants.TlsCertSecretYAML: "tls",
+	})
+	if err := os.WriteFile(filepath.Join(instanceDir, "docker-compose.yml"), []byte("services: {}"), constants.StackFilePerm); err != nil {
+		t.Fatal(err)
+	}
+
+	backupPath := filepath.Join(tmpDir, "secrets.tar.gz")
+	if err := Backup(instanceDir, backupPath); err != nil {
+		t.Fatalf("Backup() error = %v", err)
+	}
+
+	modes, contents := readArchive(t, backupPath)
+	wantModes := map[string]fs.FileMode{
+		"secrets/":                               constants.SecretsDirPerm,
+		"secrets/" + constants.AuthTokenKey:      constants.SecretFilePerm,
+		"secrets/" + constants.TlsCertSecretYAML: constants.SecretFilePerm,
+	}
+	if len(modes) != len(wantModes) {
+		t.Errorf("Expected entries %v, got %v", wantModes, modes)
+	}
+	for name, mode := range wantModes {
+		if modes[name] != mode {
+			t.Errorf("%s: mode = %v, want %v", name, modes[name], mode)
+		}
+	}
+	if contents["secrets/"+constants.AuthTokenKey] != "token" {
+		t.Errorf("Unexpected content of %s: %q", constants.AuthTokenKey, contents["secrets/"+constants.AuthTokenKey])
+	}
+
+	info, err := os.Stat(backupPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode().Perm() != constants.SavedSecretFilePerm {
+		t.Errorf("Backup file mode = %v, want %v", info.Mode().Perm(), constants.SavedSecretFilePerm)
+	}
+}
+
+func TestBackup_Errors(t *testing.T) {
+	tmpDir := t.TempDir()
+	instanceDir := filepath.Join(tmpDir, "my.instance.dir.org")
+	writeSecrets(t, instanceDir, nil)
+
+	existing := filepath.Join(tmpDir, "existing.tar.gz")
+	if err := os.WriteFile(existing, []byte("previous backup"), constants.SecretFilePerm); err != nil {
+		t.Fatal(err)
+	}
+
+	for name, backupPath := range map[string]string{
+		"existing file":     existing,
+		"inside instance":   filepath.Join(instanceDir, "backup.tar.gz"),
+		"missing directory": filepath.Join(tmpDir, "missing", "backup.tar.gz"),
+	} {
+		if err := Backup(instanceDir, backupPath); err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+	}
+	if err := Backup(filepath.Join(tmpDir, "no-instance"), filepath.Join(tmpDir, "new.tar.gz")); err == nil {
+		t.Error("Expected error for instance without secrets directory")
+	}
+
+	data, err := os.ReadFile(existing)
+	if err != nil || string(data) != "previous backup" {
+		t.Errorf("Existing backup was modified: %q, %v", data, err)
+	}
+}
+
+func TestRestore(t *testing.T) {
+	tmpDir := t.TempDir()
+	source := filepath.Join(tmpDir, "source")
+	savedSecret := constants.TlsCertSecret + constants.SavedSecretSuffix
+	writeSecrets(t, source, map[string]string{
+		constants.AuthTokenKey:   "token",
+		constants.PgPasswordFile: "postgres",
+		savedSecret:              "kind: Secret",
+	})
+	archive := filepath.Join(tmpDir, "secrets.tar.gz")
+	if err := Backup(source, archive); err != nil {
+		t.Fatalf("Backup() error = %v", err)
+	}
+
+	t.Run("into new instance", func(t *testing.T) {
+		target := filepath.Join(tmpDir, "target")
+		if err := Restore(archive, target, false); err != nil {
+			t.Fatalf("Restore() error = %v", err)
+		}
+
+		secretsDir := filepath.Join(target, constants.SecretsDirName)
+		info, err := os.Stat(secretsDir)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if info.Mode().Perm() != constants.SecretsDirPerm {
+			t.Errorf("Secrets directory mode = %v, want %v", info.Mode().Perm(), constants.SecretsDirPerm)
+		}
+		for name, want := range map[string]struct {
+			content string
+			perm    fs.FileMode
+		}{
+			constants.AuthTokenKey:   {"token", constants.SecretFilePerm},
+			constants.PgPasswordFile: {"postgres", constants.SecretFilePerm},
+			savedSecret:              {"kind: Secret", constants.SavedSecretFilePerm},
+		} {
+			path := filepath.Join(secretsDir, name)
+			data, err := os.ReadFile(path)
+			if err != nil || string(data) != want.content {
+				t.Errorf("%s: got %q, %v", name, data, err)
+			}
+			info, err := os.Stat(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if info.Mode().Perm() != want.perm {
+				t.Errorf("%s: mode = %v, want %v", name, info.Mode().Perm(), want.perm)
+			}
+		}
+	})
+
+	t.Run("keeps existing secrets without force", func(t *testing.T) {
+		target := filepath.Join(tmpDir, "existing")
+		writeSecrets(t, target, map[string]string{constants.AuthTokenKey: "local"})
+
+		if err := Restore(archive, target, false); err != nil {
+			t.Fatalf("Restore() error = %v", err)
+		}
+		tokenPath := filepath.Join(target, constants.SecretsDirName, constants.AuthTokenKey)
+		if data, _ := os.ReadFile(tokenPath); string(data) != "local" {
+			t.Errorf("Expected existing secret to be kept, got %q", data)
+		}
+		if data, _ := os.ReadFile(filepath.Join(target, constants.SecretsDirName, constants.PgPasswordFile)); string(data) != "postgres" {
+			t.Errorf("Expected missing secret to be restored, got %q", data)
+		}
+
+		if err := Restore(archive, target, true); err != nil {
+			t.Fatalf("Restore() error = %v", err)
+		}
+		if data, _ := os.ReadFile(tokenPath); string(data) != "token" {
+			t.Errorf("Expected secret to be overwritten with force, got %q", data)
+		}
+	})
+}
+
+func TestSecretPath(t *testing.T) {
+	tests := []struct {
+		name   string
+		wantOk bool
+		want   string
+	}{
+		{"secrets/auth_token_key", true, "auth_token_key"},
+		{"secrets/nested/key", true, "nested/key"},
+		{"secrets/", false, ""},
+		{"secrets/../etc/passwd", false, ""},
+		{"secrets/a/../../x", false, ""},
+		{"/secrets/key", false, ""},
+		{"docker-compose.yml", false, ""},
+	}
+
+	for _, tt := range tests {
+		got, ok := secretPath(tt.name)
+		if ok != tt.wantOk || got != tt.want {
+			t.Errorf("secretPath(%q) = %q, %v, want %q, %v", tt.name, got, ok, tt.want, tt.wantOk)
+		}
+	}
+}
@@ -0,0 +1,121 @@
+package secrets
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/OpenSlides/openslides-cli/internal/constants"
+	"github.com/OpenSlides/openslides-cli/internal/logger"
+	"github.com/spf13/cobra"
+)
+
+const (
+	SecureHelp      = "Reset the permissions of the secrets of an instance"
+	SecureHelpExtra = `Sets the permissions of the secrets directory of an instance to 700 and of
+every secret to 644, e.g. after copying the instance directory with cp -r.
+Kubernetes secret manifests saved by 'k8s stop' (*-secret.yaml) get 600.
+Subdirectories and their contents are left untouched. Every changed file is
+reported.
+
+Examples:
+  osmanage secrets secure ./my.instance.dir.org`
+)
+
+// PermissionChange is a file whose permissions were reset by Secure
+type PermissionChange struct {
+	Path string
+	Old  fs.FileMode
+	New  fs.FileMode
+}
+
+func SecureCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "secure <instance-dir>",
+		Short: SecureHelp,
+		Long:  SecureHelp + "\n\n" + SecureHelpExtra,
+		Args:  cobra.ExactArgs(1),
+	}
+
+	cmd.RunE = func(cmd *cobra.Command, args []string) error {
+		logger.Info("=== SECRETS SECURE ===")
+		instanceDir := args[0]
+		logger.Debug("Instance directory: %s", instanceDir)
+
+		secretsDir := filepath.Join(instanceDir, constants.SecretsDirName)
+		if info, err := os.Stat(secretsDir); err != nil || !info.IsDir() {
+			return fmt.Errorf("no %s directory in %s", constants.SecretsDirName, instanceDir)
+		}
+
+		changes, err := Secure(secretsDir)
+		for _, c := range changes {
+			fmt.Printf("%s: %04o -> %04o\n", c.Path, c.Old, c.New)
+		}
+		if err != nil {
+			return fmt.Errorf("securing secrets: %w", err)
+		}
+
+		if len(changes) == 0 {
+			fmt.Println("All permissions are already correct.")
+		}
+		return nil
+	}
+
+	return cmd
+}
+
+// secretFilePerm returns the permission of a file in the secrets directory.
+// Saved Kubernetes secret manifests are only read by osmanage, the other
+// secrets are mounted into the containers.
+func secretFilePerm(name string) fs.FileMode {
+	if strings.HasSuffix(name, constants.SavedSecretSuffix) {
+		return constants.SavedSecretFilePerm
+	}
+	return constants.SecretFilePerm
+}
+
+// Secure sets SecretsDirPerm on secretsDir and the permission of secretFilePerm
+// on every file in it, skipping subdirectories. It returns the changed paths,
+// including those changed before an error.
+func Secure(secretsDir string) ([]PermissionChange, error) {
+	var changes []PermissionChange
+
+	chmod := func(path string, perm fs.FileMode) error {
+		info, err := os.Stat(path)
+		if err != nil {
+			return err
+		}
+		if info.Mode().Perm() == perm {
+			return nil
+		}
+		if err := os.Chmod(path, perm); err != nil {
+			return err
+		}
+		changes = append(changes, PermissionChange{Path: path, Old: info.Mode().Perm(), New: perm})
+		return nil
+	}
+
+	if err := chmod(secretsDir, constants.SecretsDirPerm); err != nil {
+		return changes, fmt.Errorf("setting directory permissions: %w", err)
+	}
+
+	entries, err := os.ReadDir(secretsDir)
+	if err != nil {
+		return changes, fmt.Errorf("reading secrets directory: %w", err)
+	}
+
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+
+		filePath := filepath.Join(secretsDir, entry.Name())
+		if err := chmod(filePath, secretFilePerm(entry.Name())); err != nil {
+			return changes, fmt.Errorf("setting permissions for %s: %w", entry.Name(), err)
+		}
+	}
+
+	return changes, nil
+}
@@ -0,0 +1,73 @@
+package secrets
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/OpenSlides/openslides-cli/internal/constants"
+)
+
+func TestSecure(t *testing.T) {
+	instanceDir := t.TempDir()
+	writeSecrets(t, instanceDir, map[string]string{
+		constants.AuthTokenKey: "token",
+		constants.VoteKeyFile:  "vote",
+		"smtp-secret.yaml":     "kind: Secret",
+	})
+	secretsDir := filepath.Join(instanceDir, constants.SecretsDirName)
+
+	// Drifted permissions, e.g. after cp -r
+	for path, perm := range map[string]os.FileMode{
+		secretsDir: 0755,
+		filepath.Join(secretsDir, constants.AuthTokenKey): 0666,
+		filepath.Join(secretsDir, "smtp-secret.yaml"):     0644,
+	} {
+		if err := os.Chmod(path, perm); err != nil {
+			t.Fatal(err)
+		}
+	}
+	subDir := filepath.Join(secretsDir, "old")
+	if err := os.Mkdir(subDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chmod(subDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	changes, err := Secure(secretsDir)
+	if err != nil {
+		t.Fatalf("Secure() error = %v", err)
+	}
+
+	changed := map[string]PermissionChange{}
+	for _, c := range changes {
+		changed[filepath.Base(c.Path)] = c
+	}
+	if len(changes) != 3 {
+		t.Errorf("Expected 3 changes, got %+v", changes)
+	}
+	if c := changed[constants.SecretsDirName]; c.Old != 0755 || c.New != constants.SecretsDirPerm {
+		t.Errorf("Unexpected change of secrets dir: %+v", c)
+	}
+	if c := changed[constants.AuthTokenKey]; c.Old != 0666 || c.New != constants.SecretFilePerm {
+		t.Errorf("Unexpected change of %s: %+v", constants.AuthTokenKey, c)
+	}
+	if c := changed["smtp-secret.yaml"]; c.Old != 0644 || c.New != constants.SavedSecretFilePerm {
+		t.Errorf("Unexpected change of saved secret manifest: %+v", c)
+	}
+
+	info, err := os.Stat(subDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode().Perm() != 0755 {
+		t.Errorf("Expected subdirectory to be skipped, got %v", info.Mode().Perm())
+	}
+
+	// A second run changes nothing
+	changes, err = Secure(secretsDir)
+	if err != nil || len(changes) != 0 {
+		t.Errorf("Expected no changes on second run, got %+v, %v", changes, err)
+	}
+}
@@ -9,13 +9,18 @@ import (
 	"crypto/x509/pkix"
 	"encoding/base64"
 	"encoding/pem"
+	"errors"
 	"fmt"
 	"io"
+	"io/fs"
 	"math/big"
 	"os"
 	"path/filepath"
+	"slices"
+	"strings"
 	"time"
 
+	"github.com/OpenSlides/openslides-cli/contrib"
 	"github.com/OpenSlides/openslides-cli/internal/constants"
 	"github.com/OpenSlides/openslides-cli/internal/instance/config"
 	"github.com/OpenSlides/openslides-cli/internal/logger"
@@ -34,25 +39,108 @@ This command:
 3. Creates SSL certificates (if enableLocalHTTPS: true)
 4. Generates deployment files from templates
 
+Without --template, the built-in templates of --profile are used with their
+example config as base, so no other files are needed and --config files only
+need to contain changes. The k8s profile (default) creates namespace.yaml and
+a stack/ directory of Kubernetes manifests, the docker profile a single Docker
+Compose file.
+
 Examples:
   osmanage setup ./my.instance.dir.org
   osmanage setup ./my.instance.dir.org --force
+  osmanage setup ./my.instance.dir.org --profile docker
+  osmanage setup ./my.instance.dir.org --force-certs --force-files
+  osmanage setup ./my.instance.dir.org --password-charset alnum
   osmanage setup ./my.instance.dir.org --template ./custom --config ./config.yaml
-  osmanage setup ./my.instance.dir.org --config ./base.yaml --config ./override.yaml`
+  osmanage setup ./my.instance.dir.org --config ./base.yaml --config ./override.yaml
+  osmanage setup ./my.instance.dir.org --template ./custom --config ./config.yaml --var defaults.tag=4.3.0
+  osmanage setup ./my.instance.dir.org --template ./custom --config ./config.yaml --expand-env
+  osmanage setup ./my.instance.dir.org --template ./custom --config ./config.yaml --strict-templates`
 )
 
+// Force selects the setup phases that overwrite existing files
+type Force struct {
+	Secrets bool
+	Certs   bool
+	Files   bool
+}
+
+// ForceAll returns a Force with every phase set to force
+func ForceAll(force bool) Force {
+	return Force{Secrets: force, Certs: force, Files: force}
+}
+
 type SecretSpec struct {
 	Name      string
 	Generator func() ([]byte, error)
 }
 
-var defaultSecrets = []SecretSpec{
-	{constants.AuthTokenKey, randomSecret},
-	{constants.AuthCookieKey, randomSecret},
-	{constants.InternalAuthPassword, randomSecret},
-	{constants.PgPasswordFile, func() ([]byte, error) { return randomString(constants.DefaultPostgresPasswordLength) }},
-	{constants.VoteKeyFile, func() ([]byte, error) { return randomString(constants.DefaultVoteKeyLength) }},
-	{constants.AdminSecretsFile, func() ([]byte, error) { return randomString(constants.DefaultSuperadminPasswordLength) }},
+var defaultSecrets = secretSpecs(constants.PasswordCharset)
+
+// secretSpecs returns the default secrets with passwords drawn from charset
+func secretSpecs(charset string) []SecretSpec {
+	return []SecretSpec{
+		{constants.AuthTokenKey, randomSecret},
+		{constants.AuthCookieKey, randomSecret},
+		{constants.InternalAuthPassword, randomSecret},
+		{constants.PgPasswordFile, func() ([]byte, error) { return randomString(constants.DefaultPostgresPasswordLength, charset) }},
+		{constants.VoteKeyFile, func() ([]byte, error) { return randomString(constants.DefaultVoteKeyLength, charset) }},
+		{constants.AdminSecretsFile, func() ([]byte, error) { return randomString(constants.DefaultSuperadminPasswordLength, charset) }},
+	}
+}
+
+// passwordCharsets maps the names accepted by --password-charset to the
+// characters generated passwords are drawn from
+var passwordCharsets = map[string]string{
+	"alnum": constants.PasswordCharsetAlnum,
+	"safe":  constants.PasswordCharsetSafe,
+	"full":  constants.PasswordCharset,
+}
+
+// PasswordCharsetUsage is the usage of the --password-charset flag
+const PasswordCharsetUsage = "characters of generated passwords: alnum (letters and digits), safe (no shell or URL special characters) or full"
+
+// PasswordCharset returns the charset registered under name, see
+// passwordCharsets
+func PasswordCharset(name string) (string, error) {
+	charset, ok := passwordCharsets[name]
+	if !ok {
+		return "", fmt.Errorf("unknown password charset %q (available: alnum, safe, full)", name)
+	}
+	return charset, nil
+}
+
+// rotatedSecrets are the key secrets regenerated by default on rotation.
+// Passwords are only rotated when named explicitly.
+var rotatedSecrets = []string{constants.AuthTokenKey, constants.AuthCookieKey, constants.InternalAuthPassword}
+
+// SelectSecrets returns the specs of the default secrets with the given names,
+// or of the secrets rotated by default if names is empty. Passwords are drawn
+// from charset.
+func SelectSecrets(names []string, charset string) ([]SecretSpec, error) {
+	if len(names) == 0 {
+		names = rotatedSecrets
+	}
+
+	specs := secretSpecs(charset)
+	var selected []SecretSpec
+	for _, name := range names {
+		idx := slices.IndexFunc(specs, func(spec SecretSpec) bool { return spec.Name == name })
+		if idx < 0 {
+			available := make([]string, len(specs))
+			for i, spec := range specs {
+				available[i] = spec.Name
+			}
+			return nil, fmt.Errorf("unknown secret %q (available: %s)", name, strings.Join(available, ", "))
+		}
+		selected = append(selected, specs[idx])
+	}
+	return selected, nil
+}
+
+// RegenerateSecrets overwrites the given secrets in dir with new values
+func RegenerateSecrets(dir string, secrets []SecretSpec) error {
+	return createSecrets(dir, true, secrets)
 }
 
 func Cmd() *cobra.Command {
@@ -63,20 +151,43 @@ func Cmd() *cobra.Command {
 		Args:  cobra.ExactArgs(1),
 	}
 
-	force := cmd.Flags().BoolP("force", "f", false, "overwrite existing files")
+	force := cmd.Flags().BoolP("force", "f", false, "overwrite existing files (same as --force-secrets --force-certs --force-files)")
+	forceSecrets := cmd.Flags().Bool("force-secrets", false, "overwrite existing secrets")
+	forceCerts := cmd.Flags().Bool("force-certs", false, "overwrite existing SSL certificates")
+	forceFiles := cmd.Flags().Bool("force-files", false, "overwrite existing deployment files")
 	clean := cmd.Flags().Bool("clean", false, "Wipe stack folder contents before generating new files")
 	customTemplate := cmd.Flags().StringP("template", "t", "", "custom template file or directory")
 	configFiles := cmd.Flags().StringArrayP("config", "c", nil, "custom YAML config file (can be used multiple times)")
-	cmd.MarkFlagsRequiredTogether("template", "config")
+	vars := cmd.Flags().StringArray("var", nil, "override a config value with a dotted key, e.g. defaults.tag=4.3.0 (can be used multiple times)")
+	expandEnv := cmd.Flags().Bool("expand-env", false, config.ExpandEnvUsage)
+	appendLists := cmd.Flags().Bool("merge-append-lists", false, config.AppendListsUsage)
+	strictTemplates := cmd.Flags().Bool("strict-templates", false, config.StrictTemplatesUsage)
+	profile := cmd.Flags().String("profile", contrib.DefaultProfile, config.ProfileUsage)
+	passwordCharset := cmd.Flags().String("password-charset", "full", PasswordCharsetUsage)
 
 	cmd.RunE = func(cmd *cobra.Command, args []string) error {
 		logger.Info("=== SETUP ===")
 
 		baseDir := args[0]
 		logger.Debug("Base directory: %s", baseDir)
-		logger.Debug("Force: %v, Custom: %s", *force, *customTemplate)
+		forcePhases := Force{
+			Secrets: *force || *forceSecrets,
+			Certs:   *force || *forceCerts,
+			Files:   *force || *forceFiles,
+		}
+		logger.Debug("Force: %+v, Custom: %s", forcePhases, *customTemplate)
+
+		if cmd.Flags().Changed("profile") && *customTemplate != "" {
+			return fmt.Errorf("--profile cannot be used with --template")
+		}
+
+		charset, err := PasswordCharset(*passwordCharset)
+		if err != nil {
+			return err
+		}
 
-		if err := Run(baseDir, *force, *clean, *customTemplate, *configFiles, nil); err != nil {
+		opts := config.LoadOptions{ExpandEnv: *expandEnv, AppendLists: *appendLists}
+		if err := Run(baseDir, forcePhases, charset, *clean, *customTemplate, *profile, *configFiles, nil, *vars, opts, *strictTemplates); err != nil {
 			return err
 		}
 
@@ -91,46 +202,79 @@ func Cmd() *cobra.Command {
 // Run creates secrets, optional SSL certificates, and deployment files for a new
 // instance. Exactly one of configFiles (CLI) or configs (gRPC) should be provided.
 // configs are pre-read byte slices sent over gRPC, configFiles are read from disk.
-// In both cases the last entry wins on conflict before generating deployment files
-// from the template into baseDir.
-func Run(baseDir string, force, clean bool, customTemplate string, configFiles []string, configs [][]byte) error {
+// In both cases the configs are merged in order as set by opts, see config.LoadOptions.
+// vars are applied on top before generating deployment files from the template into baseDir,
+// or from the built-in profile without customTemplate, with strictTemplates failing on
+// config keys that are not set.
+// force selects which phases overwrite existing files, generated passwords are
+// drawn from charset. If a step fails, the instance or secrets directory is
+// removed again if this run created it.
+func Run(baseDir string, force Force, charset string, clean bool, customTemplate, profile string, configFiles []string, configs [][]byte, vars []string, opts config.LoadOptions, strictTemplates bool) (err error) {
+	if customTemplate == "" {
+		if _, err := contrib.LookupProfile(profile); err != nil {
+			return err
+		}
+		opts.Profile = profile
+	}
 	if clean {
 		if err := os.RemoveAll(filepath.Join(baseDir, "stack")); err != nil {
 			return fmt.Errorf("cleaning stack folder: %w", err)
 		}
 	}
 
-	cfg, err := config.NewConfig(configFiles, configs)
+	cfg, err := config.LoadConfig(configFiles, configs, opts)
 	if err != nil {
 		return fmt.Errorf("parsing configuration: %w", err)
 	}
+	if err := config.ApplyVars(cfg, vars); err != nil {
+		return fmt.Errorf("applying config vars: %w", err)
+	}
 
 	secretsDir := filepath.Join(baseDir, constants.SecretsDirName)
+	if created := firstMissingDir(baseDir, secretsDir); created != "" {
+		defer func() {
+			if err != nil {
+				logger.Debug("Removing %s after failed setup", created)
+				_ = os.RemoveAll(created)
+			}
+		}()
+	}
 	logger.Debug("Creating secrets directory: %s", secretsDir)
 	if err := os.MkdirAll(secretsDir, constants.SecretsDirPerm); err != nil {
 		return fmt.Errorf("creating secrets directory: %w", err)
 	}
 
 	logger.Info("Creating secrets...")
-	if err := createSecrets(secretsDir, force, defaultSecrets); err != nil {
+	if err := createSecrets(secretsDir, force.Secrets, secretSpecs(charset)); err != nil {
 		return fmt.Errorf("creating secrets: %w", err)
 	}
 
 	if enableLocalHTTPS, ok := cfg["enableLocalHTTPS"].(bool); ok && enableLocalHTTPS {
 		logger.Info("Creating SSL certificates...")
-		if err := createCerts(secretsDir, force); err != nil {
+		if err := createCerts(secretsDir, force.Certs); err != nil {
 			return fmt.Errorf("creating certificates: %w", err)
 		}
 	}
 
 	logger.Info("Creating deployment files...")
-	if err := config.CreateDirAndFiles(baseDir, force, customTemplate, cfg); err != nil {
+	if err := config.CreateDirAndFiles(baseDir, force.Files, customTemplate, profile, cfg, strictTemplates); err != nil {
 		return fmt.Errorf("creating deployment files: %w", err)
 	}
 
 	return nil
 }
 
+// firstMissingDir returns the first of dirs that does not exist, or an empty
+// string if all exist
+func firstMissingDir(dirs ...string) string {
+	for _, dir := range dirs {
+		if _, err := os.Stat(dir); errors.Is(err, fs.ErrNotExist) {
+			return dir
+		}
+	}
+	return ""
+}
+
 func createSecrets(dir string, force bool, secrets []SecretSpec) error {
 	for _, spec := range secrets {
 		logger.Debug("Generating secret: %s", spec.Name)
@@ -162,23 +306,37 @@ func randomSecret() ([]byte, error) {
 	return buf.Bytes(), nil
 }
 
-func randomString(length int) ([]byte, error) {
+// randomString returns length characters drawn uniformly from charset. Random
+// bytes at or above the largest multiple of len(charset) are discarded and
+// redrawn, so the modulo does not favor the first characters of charset.
+func randomString(length int, charset string) ([]byte, error) {
 	if length <= 0 {
 		return nil, fmt.Errorf("length must be positive, got %d", length)
 	}
+	if charset == "" || len(charset) > 256 {
+		return nil, fmt.Errorf("charset must contain 1 to 256 characters, got %d", len(charset))
+	}
 
-	result := make([]byte, length)
+	result := make([]byte, 0, length)
 
-	charset := constants.PasswordCharset
 	maxIndex := len(charset)
+	limit := 256 - 256%maxIndex
 
 	randomBytes := make([]byte, length)
-	if _, err := rand.Read(randomBytes); err != nil {
-		return nil, fmt.Errorf("generating random bytes: %w", err)
-	}
+	for len(result) < length {
+		if _, err := rand.Read(randomBytes); err != nil {
+			return nil, fmt.Errorf("generating random bytes: %w", err)
+		}
 
-	for i := range length {
-		result[i] = charset[int(randomBytes[i])%maxIndex]
+		for _, b := range randomBytes {
+			if int(b) >= limit {
+				continue
+			}
+			result = append(result, charset[int(b)%maxIndex])
+			if len(result) == length {
+				break
+			}
+		}
 	}
 
 	return result, nil
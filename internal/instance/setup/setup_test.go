@@ -1,12 +1,20 @@
 package setup
 
 import (
+	"encoding/base64"
+	"fmt"
+	"io"
 	"os"
 	"path/filepath"
 	"strings"
 	"testing"
 
+	"github.com/OpenSlides/openslides-cli/contrib"
 	"github.com/OpenSlides/openslides-cli/internal/constants"
+	"github.com/OpenSlides/openslides-cli/internal/instance/config"
+	"github.com/OpenSlides/openslides-cli/internal/utils"
+
+	"github.com/ghodss/yaml"
 )
 
 func TestRandomSecret(t *testing.T) {
@@ -43,7 +51,7 @@ func TestRandomString(t *testing.T) {
 	t.Run("generates correct length", func(t *testing.T) {
 		lengths := []int{16, 32, 64, 128}
 		for _, length := range lengths {
-			str, err := randomString(length)
+			str, err := randomString(length, constants.PasswordCharset)
 			if err != nil {
 				t.Errorf("randomString(%d) error = %v", length, err)
 			}
@@ -54,11 +62,11 @@ func TestRandomString(t *testing.T) {
 	})
 
 	t.Run("generates unique strings", func(t *testing.T) {
-		str1, err := randomString(32)
+		str1, err := randomString(32, constants.PasswordCharset)
 		if err != nil {
 			t.Errorf("randomString() error = %v", err)
 		}
-		str2, err := randomString(32)
+		str2, err := randomString(32, constants.PasswordCharset)
 		if err != nil {
 			t.Errorf("randomString() error = %v", err)
 		}
@@ -69,24 +77,54 @@ func TestRandomString(t *testing.T) {
 	})
 
 	t.Run("contains only allowed characters", func(t *testing.T) {
-		allowedChars := constants.PasswordCharset
+		for _, allowedChars := range []string{constants.PasswordCharset, constants.PasswordCharsetSafe, constants.PasswordCharsetAlnum} {
+			str, err := randomString(100, allowedChars)
+			if err != nil {
+				t.Errorf("randomString() error = %v", err)
+			}
+
+			for i, ch := range str {
+				if !strings.ContainsRune(allowedChars, rune(ch)) {
+					t.Errorf("Character at position %d (%c) is not in allowed charset %q", i, ch, allowedChars)
+				}
+			}
+		}
+	})
 
-		str, err := randomString(100)
+	t.Run("uniform distribution", func(t *testing.T) {
+		// Without rejection sampling the first 256%len(charset) characters
+		// come up 4/3 as often as the rest.
+		charset := constants.PasswordCharset
+		samples := 20000 * len(charset)
+		str, err := randomString(samples, charset)
 		if err != nil {
-			t.Errorf("randomString() error = %v", err)
+			t.Fatalf("randomString() error = %v", err)
 		}
 
-		for i, ch := range str {
-			if !strings.ContainsRune(allowedChars, rune(ch)) {
-				t.Errorf("Character at position %d (%c) is not in allowed charset", i, ch)
+		counts := make(map[byte]int, len(charset))
+		for _, ch := range str {
+			counts[ch]++
+		}
+
+		expected := float64(samples) / float64(len(charset))
+		for i := range len(charset) {
+			ch := charset[i]
+			if deviation := float64(counts[ch])/expected - 1; deviation < -0.05 || deviation > 0.05 {
+				t.Errorf("Character %c occurred %d times, expected about %.0f", ch, counts[ch], expected)
 			}
 		}
 	})
 
+	t.Run("empty charset", func(t *testing.T) {
+		if _, err := randomString(16, ""); err == nil {
+			t.Error("Expected error for empty charset")
+		}
+	})
+
 	t.Run("invalid length", func(t *testing.T) {
 		invalidLengths := []int{0, -1, -10}
 		for _, length := range invalidLengths {
-			_, err := randomString(length)
+			_, err := randomString(length, constants.PasswordCharset)
 			if err == nil {
 				t.Errorf("Expected error for length %d", length)
 			}
@@ -96,7 +134,7 @@ func TestRandomString(t *testing.T) {
 	t.Run("suitable for postgres password", func(t *testing.T) {
 		// Test that generated strings work as postgres passwords
 		// Postgres passwords can contain most characters except null bytes
-		str, err := randomString(100)
+		str, err := randomString(100, constants.PasswordCharset)
 		if err != nil {
 			t.Errorf("randomString() error = %v", err)
 		}
@@ -503,7 +541,7 @@ func TestSecretSpec_Pattern(t *testing.T) {
 			{
 				Name: "short_password",
 				Generator: func() ([]byte, error) {
-					return randomString(12) // Custom length
+					return randomString(12, constants.PasswordCharset) // Custom length
 				},
 			},
 		}
@@ -532,3 +570,247 @@ func TestSecretSpec_Pattern(t *testing.T) {
 		}
 	})
 }
+
+func TestRun_ForcePhases(t *testing.T) {
+	tmpdir := t.TempDir()
+
+	configFile := filepath.Join(tmpdir, "config.yml")
+	if err := os.WriteFile(configFile, []byte("filename: out.yml\nenableLocalHTTPS: true\n"), constants.StackFilePerm); err != nil {
+		t.Fatal(err)
+	}
+	templateFile := filepath.Join(tmpdir, "template.yml")
+	if err := os.WriteFile(templateFile, []byte("generated: true\n"), constants.StackFilePerm); err != nil {
+		t.Fatal(err)
+	}
+
+	outDir := filepath.Join(tmpdir, "output")
+	secretPath := filepath.Join(outDir, constants.SecretsDirName, constants.AuthTokenKey)
+	certPath := filepath.Join(outDir, constants.SecretsDirName, constants.CertCertName)
+	filePath := filepath.Join(outDir, "out.yml")
+
+	if err := Run(outDir, Force{}, constants.PasswordCharset, false, templateFile, "", []string{configFile}, nil, nil, config.LoadOptions{}, false); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+
+	// Mark all generated files to see which ones get overwritten
+	for _, path := range []string{secretPath, certPath, filePath} {
+		if err := os.WriteFile(path, []byte("old"), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := Run(outDir, Force{Certs: true}, constants.PasswordCharset, false, templateFile, "", []string{configFile}, nil, nil, config.LoadOptions{}, false); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+
+	for path, wantOverwritten := range map[string]bool{secretPath: false, certPath: true, filePath: false} {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if overwritten := string(data) != "old"; overwritten != wantOverwritten {
+			t.Errorf("%s: overwritten = %v, want %v", filepath.Base(path), overwritten, wantOverwritten)
+		}
+	}
+}
+
+func TestRun_BuiltinTemplate(t *testing.T) {
+	tmpdir := t.TempDir()
+	configFile := filepath.Join(tmpdir, "config.yml")
+	if err := os.WriteFile(configFile, []byte("port: 8443\nenableLocalHTTPS: false\n"), constants.StackFilePerm); err != nil {
+		t.Fatal(err)
+	}
+
+	outDir := filepath.Join(tmpdir, "output")
+	if err := Run(outDir, Force{}, constants.PasswordCharset, false, "", contrib.ProfileDocker, []string{configFile}, nil, nil, config.LoadOptions{}, false); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+
+	data, err := os.ReadFile(filepath.Join(outDir, "docker-compose.yml"))
+	if err != nil {
+		t.Fatalf("Expected docker-compose.yml from the built-in template: %v", err)
+	}
+	compose := string(data)
+	if !strings.Contains(compose, "127.0.0.1:8443:8000") {
+		t.Error("Expected port from config file to override the example config")
+	}
+	if !strings.Contains(compose, "ghcr.io/openslides/openslides/openslides-backend:") {
+		t.Error("Expected image registry from the example config")
+	}
+	if strings.Contains(compose, "<no value>") {
+		t.Error("Expected all template values to be set")
+	}
+	if _, err := os.Stat(filepath.Join(outDir, constants.SecretsDirName, constants.CertCertName)); !os.IsNotExist(err) {
+		t.Error("Expected no certificate with enableLocalHTTPS disabled by the config file")
+	}
+}
+
+func TestRun_K8sProfile(t *testing.T) {
+	outDir := filepath.Join(t.TempDir(), "my.instance.org")
+	if err := Run(outDir, Force{}, constants.PasswordCharset, false, "", contrib.ProfileK8s, nil, nil, nil, config.LoadOptions{}, false); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+
+	if _, err := os.Stat(filepath.Join(outDir, "docker-compose.yml")); !os.IsNotExist(err) {
+		t.Error("Expected no docker-compose.yml with the k8s profile")
+	}
+
+	namespace, err := utils.ResolveNamespace(outDir)
+	if err != nil {
+		t.Fatalf("ResolveNamespace() error = %v", err)
+	}
+	if namespace != "myinstanceorg" {
+		t.Errorf("namespace = %q, want myinstanceorg", namespace)
+	}
+
+	deployment := filepath.Join(outDir, constants.StackDirName, fmt.Sprintf(constants.DeploymentFileTemplate, constants.BackendmanageDeploymentName))
+	if _, err := os.Stat(deployment); err != nil {
+		t.Errorf("Expected backendmanage deployment in stack/: %v", err)
+	}
+
+	manifests, err := filepath.Glob(filepath.Join(outDir, constants.StackDirName, "*.yaml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, path := range manifests {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if strings.Contains(string(data), "<no value>") {
+			t.Errorf("%s: expected all template values to be set", filepath.Base(path))
+		}
+		var manifest struct {
+			Kind     string `json:"kind"`
+			Metadata struct {
+				Namespace string `json:"namespace"`
+			} `json:"metadata"`
+		}
+		if err := yaml.Unmarshal(data, &manifest); err != nil {
+			t.Errorf("%s: invalid YAML: %v", filepath.Base(path), err)
+			continue
+		}
+		if manifest.Metadata.Namespace != namespace {
+			t.Errorf("%s: namespace = %q, want %q", filepath.Base(path), manifest.Metadata.Namespace, namespace)
+		}
+	}
+
+	secret, err := os.ReadFile(filepath.Join(outDir, constants.StackDirName, "secrets.yaml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	password, err := os.ReadFile(filepath.Join(outDir, constants.SecretsDirName, constants.PgPasswordFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(secret), base64.StdEncoding.EncodeToString(password)) {
+		t.Error("Expected the generated postgres password in the secret manifest")
+	}
+}
+
+func TestRun_UnknownProfile(t *testing.T) {
+	outDir := filepath.Join(t.TempDir(), "output")
+	err := Run(outDir, Force{}, constants.PasswordCharset, false, "", "swarm", nil, nil, nil, config.LoadOptions{}, false)
+	if err == nil || !strings.Contains(err.Error(), `unknown profile "swarm"`) {
+		t.Fatalf("Run() error = %v, want unknown profile", err)
+	}
+	if _, err := os.Stat(outDir); !os.IsNotExist(err) {
+		t.Error("Expected nothing to be created for an unknown profile")
+	}
+}
+
+func TestRun_RemovesCreatedDirOnFailure(t *testing.T) {
+	tmpdir := t.TempDir()
+	templateFile := filepath.Join(tmpdir, "template.yml")
+	if err := os.WriteFile(templateFile, []byte("{{ .missing }}\n"), constants.StackFilePerm); err != nil {
+		t.Fatal(err)
+	}
+
+	outDir := filepath.Join(tmpdir, "output")
+	if err := Run(outDir, Force{}, constants.PasswordCharset, false, templateFile, "", nil, nil, nil, config.LoadOptions{}, true); err == nil {
+		t.Fatal("Expected error for missing key with strict templates")
+	}
+	if _, err := os.Stat(outDir); !os.IsNotExist(err) {
+		t.Error("Expected the instance directory created by the failed run to be removed")
+	}
+
+	// An existing instance directory is kept, only the new secrets are removed
+	if err := os.MkdirAll(outDir, constants.InstanceDirPerm); err != nil {
+		t.Fatal(err)
+	}
+	if err := Run(outDir, Force{}, constants.PasswordCharset, false, templateFile, "", nil, nil, nil, config.LoadOptions{}, true); err == nil {
+		t.Fatal("Expected error for missing key with strict templates")
+	}
+	if _, err := os.Stat(outDir); err != nil {
+		t.Errorf("Expected existing instance directory to be kept: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(outDir, constants.SecretsDirName)); !os.IsNotExist(err) {
+		t.Error("Expected the secrets directory created by the failed run to be removed")
+	}
+}
+
+func TestCmd_ProfileWithTemplate(t *testing.T) {
+	cmd := Cmd()
+	cmd.SetArgs([]string{t.TempDir(), "--profile", "docker", "--template", "custom.tmpl"})
+	cmd.SetOut(io.Discard)
+	cmd.SetErr(io.Discard)
+	if err := cmd.Execute(); err == nil || !strings.Contains(err.Error(), "--profile cannot be used with --template") {
+		t.Errorf("Expected --profile/--template error, got %v", err)
+	}
+}
+
+func TestForceAll(t *testing.T) {
+	if got := ForceAll(true); got != (Force{Secrets: true, Certs: true, Files: true}) {
+		t.Errorf("ForceAll(true) = %+v", got)
+	}
+	if got := ForceAll(false); got != (Force{}) {
+		t.Errorf("ForceAll(false) = %+v", got)
+	}
+}
+
+func TestSelectSecrets(t *testing.T) {
+	specs, err := SelectSecrets(nil, constants.PasswordCharset)
+	if err != nil {
+		t.Fatalf("SelectSecrets() error = %v", err)
+	}
+	for _, spec := range specs {
+		if spec.Name == constants.PgPasswordFile || spec.Name == constants.AdminSecretsFile {
+			t.Errorf("Password %s rotated by default", spec.Name)
+		}
+	}
+	if len(specs) != len(rotatedSecrets) {
+		t.Errorf("Expected %d default secrets, got %d", len(rotatedSecrets), len(specs))
+	}
+
+	specs, err = SelectSecrets([]string{constants.AdminSecretsFile}, constants.PasswordCharset)
+	if err != nil {
+		t.Fatalf("SelectSecrets() error = %v", err)
+	}
+	if len(specs) != 1 || specs[0].Name != constants.AdminSecretsFile {
+		t.Errorf("Expected only %s, got %v", constants.AdminSecretsFile, specs)
+	}
+
+	if _, err := SelectSecrets([]string{"unknown"}, constants.PasswordCharset); err == nil {
+		t.Error("Expected error for unknown secret")
+	}
+}
+
+func TestPasswordCharset(t *testing.T) {
+	for name, want := range map[string]string{
+		"alnum": constants.PasswordCharsetAlnum,
+		"safe":  constants.PasswordCharsetSafe,
+		"full":  constants.PasswordCharset,
+	} {
+		got, err := PasswordCharset(name)
+		if err != nil {
+			t.Errorf("PasswordCharset(%q) error = %v", name, err)
+		}
+		if got != want {
+			t.Errorf("PasswordCharset(%q) = %q, want %q", name, got, want)
+		}
+	}
+
+	if _, err := PasswordCharset("ascii"); err == nil {
+		t.Error("Expected error for unknown charset")
+	}
+}
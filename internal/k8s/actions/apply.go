@@ -2,16 +2,20 @@ package actions
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
+	"slices"
 	"sort"
+	"strings"
 
 	"github.com/OpenSlides/openslides-cli/internal/constants"
 	"github.com/OpenSlides/openslides-cli/internal/k8s/client"
 	"github.com/OpenSlides/openslides-cli/internal/logger"
 	"github.com/OpenSlides/openslides-cli/internal/utils"
 
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
@@ -21,12 +25,34 @@ import (
 
 const (
 	// fieldManager identifies this client in Server-Side Apply operations
+	// unless ApplyOptions.FieldManager is set
 	fieldManager string = "osmanage"
-
-	// forceConflicts takes ownership of fields from other managers when conflicts occur
-	forceConflicts bool = true
 )
 
+// ApplyOptions controls the ownership of applied fields in Server-Side Apply.
+// The zero value applies as fieldManager and takes ownership of fields from
+// other managers when conflicts occur.
+type ApplyOptions struct {
+	FieldManager string // field manager name, fieldManager if empty
+	NoForce      bool   // fail on conflicts with other managers instead of taking their fields
+}
+
+// Manager returns the field manager name to apply with
+func (o ApplyOptions) Manager() string {
+	if o.FieldManager == "" {
+		return fieldManager
+	}
+	return o.FieldManager
+}
+
+// apiOptions returns the options for the apply request
+func (o ApplyOptions) apiOptions() metav1.ApplyOptions {
+	return metav1.ApplyOptions{
+		FieldManager: o.Manager(),
+		Force:        !o.NoForce,
+	}
+}
+
 // resourceKey uniquely identifies a Kubernetes resource by GVR and name
 type resourceKey struct {
 	gvr  schema.GroupVersionResource
@@ -35,7 +61,9 @@ type resourceKey struct {
 
 // applyManifest applies a single YAML manifest file using RESTMapper and returns
 // the applied resourceKey and namespace. Returns nil key if the manifest is skipped.
-func applyManifest(ctx context.Context, k8sClient *client.Client, manifestPath string, labels map[string]string) (*resourceKey, string, error) {
+// Container images of Deployments are replaced according to images (container name to image).
+// instanceLabels are added to the metadata of the applied object.
+func applyManifest(ctx context.Context, k8sClient *client.Client, manifestPath string, labels map[string]string, images map[string]string, instanceLabels map[string]string, opts ApplyOptions) (*resourceKey, string, error) {
 	logger.Debug("Applying manifest: %s", manifestPath)
 
 	data, err := os.ReadFile(manifestPath)
@@ -48,16 +76,34 @@ func applyManifest(ctx context.Context, k8sClient *client.Client, manifestPath s
 		return nil, "", fmt.Errorf("parsing YAML: %w", err)
 	}
 
+	return applyObject(ctx, k8sClient, &obj, manifestPath, labels, images, instanceLabels, opts)
+}
+
+// applyObject applies a single parsed manifest like applyManifest. source names
+// the origin of obj in log messages.
+func applyObject(ctx context.Context, k8sClient *client.Client, obj *unstructured.Unstructured, source string, labels map[string]string, images map[string]string, instanceLabels map[string]string, opts ApplyOptions) (*resourceKey, string, error) {
 	if obj.GetKind() == "" {
-		logger.Info("Skipping manifest with no kind: %s", manifestPath)
+		logger.Info("Skipping manifest with no kind: %s", source)
 		return nil, "", nil
 	}
 
-	if !matchesLabels(&obj, labels) {
+	if !matchesLabels(obj, labels) {
 		logger.Debug("Skipping %s/%s: does not match labels", obj.GetKind(), obj.GetName())
 		return nil, "", nil
 	}
 
+	if len(images) > 0 && obj.GetKind() == "Deployment" {
+		overridden, err := overrideImages(obj, images)
+		if err != nil {
+			return nil, "", fmt.Errorf("overriding images of %s: %w", obj.GetName(), err)
+		}
+		for _, name := range overridden {
+			logger.Info("Overriding image of %s/%s: %s", obj.GetName(), name, images[name])
+		}
+	}
+
+	setLabels(obj, instanceLabels)
+
 	namespace := obj.GetNamespace()
 	if namespace == "" && obj.GetKind() == "Namespace" {
 		namespace = obj.GetName()
@@ -89,33 +135,119 @@ func applyManifest(ctx context.Context, k8sClient *client.Client, manifestPath s
 		result, err = dynamicClient.Resource(mapping.Resource).Namespace(namespace).Apply(
 			ctx,
 			obj.GetName(),
-			&obj,
-			metav1.ApplyOptions{
-				FieldManager: fieldManager,
-				Force:        forceConflicts,
-			},
+			obj,
+			opts.apiOptions(),
 		)
 	} else {
 		// Cluster-scoped resource (Namespace, ClusterRole, etc.)
 		result, err = dynamicClient.Resource(mapping.Resource).Apply(
 			ctx,
 			obj.GetName(),
-			&obj,
-			metav1.ApplyOptions{
-				FieldManager: fieldManager,
-				Force:        forceConflicts,
-			},
+			obj,
+			opts.apiOptions(),
 		)
 	}
 
 	if err != nil {
-		return nil, namespace, fmt.Errorf("applying %s/%s: %w", obj.GetKind(), obj.GetName(), err)
+		return nil, namespace, applyError(obj, err)
 	}
 
 	logger.Info("Applied %s: %s", result.GetKind(), result.GetName())
 	return &resourceKey{gvr: mapping.Resource, name: obj.GetName()}, namespace, nil
 }
 
+// applyError wraps an error from applying obj. Conflicts with other field
+// managers, which only occur with ApplyOptions.NoForce, name the contested
+// fields and how to resolve them.
+func applyError(obj *unstructured.Unstructured, err error) error {
+	var status apierrors.APIStatus
+	if !apierrors.IsConflict(err) || !errors.As(err, &status) || status.Status().Details == nil {
+		return fmt.Errorf("applying %s/%s: %w", obj.GetKind(), obj.GetName(), err)
+	}
+
+	var fields []string
+	for _, cause := range status.Status().Details.Causes {
+		if cause.Type == metav1.CauseTypeFieldManagerConflict {
+			fields = append(fields, cause.Field)
+		}
+	}
+	if len(fields) == 0 {
+		return fmt.Errorf("applying %s/%s: %w", obj.GetKind(), obj.GetName(), err)
+	}
+
+	return fmt.Errorf("applying %s/%s: fields owned by another field manager: %s "+
+		"(remove them from the manifest, hand over their ownership or apply without --no-force to take them over): %w",
+		obj.GetKind(), obj.GetName(), strings.Join(fields, ", "), err)
+}
+
+// overrideImages sets the image of every container (and init container) of a
+// Deployment whose name is a key in images. Returns the names of changed containers.
+func overrideImages(obj *unstructured.Unstructured, images map[string]string) ([]string, error) {
+	var overridden []string
+	for _, field := range []string{"containers", "initContainers"} {
+		path := []string{"spec", "template", "spec", field}
+		containers, found, err := unstructured.NestedSlice(obj.Object, path...)
+		if err != nil {
+			return nil, fmt.Errorf("reading %s: %w", field, err)
+		}
+		if !found {
+			continue
+		}
+
+		changed := false
+		for _, c := range containers {
+			container, ok := c.(map[string]any)
+			if !ok {
+				continue
+			}
+			name, _ := container["name"].(string)
+			if image, ok := images[name]; ok {
+				container["image"] = image
+				overridden = append(overridden, name)
+				changed = true
+			}
+		}
+
+		if changed {
+			if err := unstructured.SetNestedSlice(obj.Object, containers, path...); err != nil {
+				return nil, fmt.Errorf("setting %s: %w", field, err)
+			}
+		}
+	}
+	return overridden, nil
+}
+
+// deploymentContainerNames returns the names of all containers of all
+// Deployment manifests in dirPath
+func deploymentContainerNames(dirPath string) (map[string]bool, error) {
+	files, err := os.ReadDir(dirPath)
+	if err != nil {
+		return nil, fmt.Errorf("reading directory: %w", err)
+	}
+
+	names := map[string]bool{}
+	for _, file := range files {
+		if file.IsDir() || !utils.IsYAMLFile(file.Name()) {
+			continue
+		}
+		obj, err := readManifest(filepath.Join(dirPath, file.Name()))
+		if err != nil || obj.GetKind() != "Deployment" {
+			continue
+		}
+		for _, field := range []string{"containers", "initContainers"} {
+			containers, _, _ := unstructured.NestedSlice(obj.Object, "spec", "template", "spec", field)
+			for _, c := range containers {
+				if container, ok := c.(map[string]any); ok {
+					if name, ok := container["name"].(string); ok {
+						names[name] = true
+					}
+				}
+			}
+		}
+	}
+	return names, nil
+}
+
 // matchesLabels checks if an unstructured object has all the given labels
 func matchesLabels(obj *unstructured.Unstructured, labels map[string]string) bool {
 	if len(labels) == 0 {
@@ -130,11 +262,48 @@ func matchesLabels(obj *unstructured.Unstructured, labels map[string]string) boo
 	return true
 }
 
-// applyDirectory applies all YAML files in a directory and returns the set of applied resources.
-func applyDirectory(ctx context.Context, k8sClient *client.Client, dirPath string, labels map[string]string) ([]resourceKey, error) {
+// setLabels adds labels to the metadata of obj, overwriting existing values of the same keys
+func setLabels(obj *unstructured.Unstructured, labels map[string]string) {
+	if len(labels) == 0 {
+		return
+	}
+	objLabels := obj.GetLabels()
+	if objLabels == nil {
+		objLabels = make(map[string]string, len(labels))
+	}
+	for k, v := range labels {
+		objLabels[k] = v
+	}
+	obj.SetLabels(objLabels)
+}
+
+// DeploymentFilter selects deployments by name. An empty Only list matches all
+// deployments, names in Exclude never match. Other kinds are not affected.
+type DeploymentFilter struct {
+	Only    []string
+	Exclude []string
+}
+
+// IsSet reports whether any filter is configured
+func (f DeploymentFilter) IsSet() bool {
+	return len(f.Only) > 0 || len(f.Exclude) > 0
+}
+
+// Matches reports whether the deployment with the given name is selected
+func (f DeploymentFilter) Matches(name string) bool {
+	if len(f.Only) > 0 && !slices.Contains(f.Only, name) {
+		return false
+	}
+	return !slices.Contains(f.Exclude, name)
+}
+
+// applyDirectory applies all YAML files in a directory and returns the set of applied resources
+// and the number of manifests that failed to apply.
+// Deployments not matching filter are skipped, see applyManifest for images and instanceLabels.
+func applyDirectory(ctx context.Context, k8sClient *client.Client, dirPath string, labels map[string]string, filter DeploymentFilter, images map[string]string, instanceLabels map[string]string, opts ApplyOptions) ([]resourceKey, int, error) {
 	files, err := os.ReadDir(dirPath)
 	if err != nil {
-		return nil, fmt.Errorf("reading directory: %w", err)
+		return nil, 0, fmt.Errorf("reading directory: %w", err)
 	}
 
 	var yamlFiles []os.DirEntry
@@ -157,6 +326,7 @@ func applyDirectory(ctx context.Context, k8sClient *client.Client, dirPath strin
 	})
 
 	var applied []resourceKey
+	failed := 0
 	for _, file := range yamlFiles {
 		manifestPath := filepath.Join(dirPath, file.Name())
 		info, err := file.Info()
@@ -168,9 +338,16 @@ func applyDirectory(ctx context.Context, k8sClient *client.Client, dirPath strin
 			logger.Info("File is empty, skipping: %s", file.Name())
 			continue
 		}
-		key, _, err := applyManifest(ctx, k8sClient, manifestPath, labels)
+		if filter.IsSet() {
+			if obj, err := readManifest(manifestPath); err == nil && obj.GetKind() == "Deployment" && !filter.Matches(obj.GetName()) {
+				logger.Debug("Skipping deployment %s: filtered out", obj.GetName())
+				continue
+			}
+		}
+		key, _, err := applyManifest(ctx, k8sClient, manifestPath, labels, images, instanceLabels, opts)
 		if err != nil {
 			logger.Error("Failed to apply %s: %v", file.Name(), err)
+			failed++
 			continue
 		}
 		if key != nil {
@@ -178,12 +355,52 @@ func applyDirectory(ctx context.Context, k8sClient *client.Client, dirPath strin
 		}
 	}
 
-	return applied, nil
+	return applied, failed, nil
+}
+
+// pruneSkipReason returns why pruning after applying must be skipped, or an
+// empty string if it is safe. Resources that failed to apply, e.g. on a field
+// manager conflict with ApplyOptions.NoForce, or were filtered out are missing
+// from the applied set, so pruning would delete their running version.
+func pruneSkipReason(failed int, filter DeploymentFilter) string {
+	switch {
+	case failed > 0:
+		return fmt.Sprintf("%d manifests failed to apply", failed)
+	case filter.IsSet():
+		return "deployment filter set"
+	default:
+		return ""
+	}
 }
 
 // pruneOrphans deletes namespaced resources in the given namespace that are owned
-// by osmanage but are no longer present in the applied set.
-func pruneOrphans(ctx context.Context, k8sClient *client.Client, namespace string, applied []resourceKey) error {
+// by the field manager but are no longer present in the applied set.
+func pruneOrphans(ctx context.Context, k8sClient *client.Client, namespace string, applied []resourceKey, manager string) error {
+	return pruneResources(ctx, k8sClient, namespace, applied, manager, metav1.ListOptions{})
+}
+
+// pruneManaged is like pruneOrphans but only considers resources labeled with
+// constants.ManagedByLabel, as set by k8s start --prune.
+func pruneManaged(ctx context.Context, k8sClient *client.Client, namespace string, applied []resourceKey, manager string) error {
+	selector := constants.ManagedByLabel + "=" + constants.ManagedByValue
+	return pruneResources(ctx, k8sClient, namespace, applied, manager, metav1.ListOptions{LabelSelector: selector})
+}
+
+// appliedBy reports whether manager is one of the field managers of obj.
+// Objects that only copied labels from an applied resource, like Endpoints of a
+// Service, are not.
+func appliedBy(obj *unstructured.Unstructured, manager string) bool {
+	for _, mf := range obj.GetManagedFields() {
+		if mf.Manager == manager {
+			return true
+		}
+	}
+	return false
+}
+
+// pruneResources deletes the namespaced resources matching listOpts that were
+// applied by manager but are not in the applied set.
+func pruneResources(ctx context.Context, k8sClient *client.Client, namespace string, applied []resourceKey, manager string, listOpts metav1.ListOptions) error {
 	desired := make(map[resourceKey]bool, len(applied))
 	for _, k := range applied {
 		desired[k] = true
@@ -212,27 +429,21 @@ func pruneOrphans(ctx context.Context, k8sClient *client.Client, namespace strin
 					Resource: resource.Name,
 				}
 
-				list, err := dynamicClient.Resource(gvr).Namespace(namespace).List(ctx, metav1.ListOptions{})
+				list, err := dynamicClient.Resource(gvr).Namespace(namespace).List(ctx, listOpts)
 				if err != nil {
 					logger.Debug("Skipping %s: %v", gvr.Resource, err)
 					continue
 				}
 
 				for _, item := range list.Items {
-					if desired[resourceKey{gvr: gvr, name: item.GetName()}] {
+					if desired[resourceKey{gvr: gvr, name: item.GetName()}] || !appliedBy(&item, manager) {
 						continue
 					}
-					for _, mf := range item.GetManagedFields() {
-						if mf.Manager != fieldManager {
-							continue
-						}
-						logger.Info("Pruning orphaned %s: %s", item.GetKind(), item.GetName())
-						if err := dynamicClient.Resource(gvr).Namespace(namespace).Delete(
-							ctx, item.GetName(), metav1.DeleteOptions{},
-						); err != nil {
-							logger.Warn("Failed to prune %s/%s: %v", item.GetKind(), item.GetName(), err)
-						}
-						break
+					logger.Info("Pruning orphaned %s: %s", item.GetKind(), item.GetName())
+					if err := dynamicClient.Resource(gvr).Namespace(namespace).Delete(
+						ctx, item.GetName(), metav1.DeleteOptions{},
+					); err != nil {
+						logger.Warn("Failed to prune %s/%s: %v", item.GetKind(), item.GetName(), err)
 					}
 				}
 			}
@@ -242,15 +453,25 @@ func pruneOrphans(ctx context.Context, k8sClient *client.Client, namespace strin
 	return nil
 }
 
-// getKindFromFile reads the Kind field from a YAML file
-func getKindFromFile(path string) string {
+// readManifest reads and parses a single YAML manifest file
+func readManifest(path string) (*unstructured.Unstructured, error) {
 	data, err := os.ReadFile(path)
 	if err != nil {
-		return ""
+		return nil, fmt.Errorf("reading manifest: %w", err)
 	}
 
 	var obj unstructured.Unstructured
 	if err := yaml.Unmarshal(data, &obj); err != nil {
+		return nil, fmt.Errorf("parsing YAML: %w", err)
+	}
+
+	return &obj, nil
+}
+
+// getKindFromFile reads the Kind field from a YAML file
+func getKindFromFile(path string) string {
+	obj, err := readManifest(path)
+	if err != nil {
 		return ""
 	}
 
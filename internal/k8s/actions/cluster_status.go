@@ -6,6 +6,7 @@ import (
 
 	"github.com/OpenSlides/openslides-cli/internal/k8s/client"
 	"github.com/OpenSlides/openslides-cli/internal/logger"
+	"github.com/OpenSlides/openslides-cli/internal/utils"
 	"github.com/spf13/cobra"
 
 	corev1 "k8s.io/api/core/v1"
@@ -51,7 +52,8 @@ func ClusterStatusCmd() *cobra.Command {
 			return fmt.Errorf("creating k8s client: %w", err)
 		}
 
-		ctx := context.Background()
+		ctx, cancel := utils.TimeoutContext(cmd, 0)
+		defer cancel()
 
 		status, err := CheckClusterStatus(ctx, k8sClient)
 		if err != nil {
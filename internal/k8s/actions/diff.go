@@ -0,0 +1,292 @@
+package actions
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/OpenSlides/openslides-cli/internal/constants"
+	"github.com/OpenSlides/openslides-cli/internal/k8s/client"
+	"github.com/OpenSlides/openslides-cli/internal/logger"
+	"github.com/OpenSlides/openslides-cli/internal/utils"
+	"github.com/spf13/cobra"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+)
+
+const (
+	DiffHelp      = "Show changes start would apply to an instance"
+	DiffHelpExtra = `Compares the manifests in the stack/ directory of an instance with the live
+objects on the cluster. Only fields set in the manifests are compared, fields
+defaulted or managed by the cluster are ignored. Values of Secrets are not shown.
+
+Examples:
+  osmanage k8s diff ./my.instance.dir.org
+  osmanage k8s diff ./my.instance.dir.org --kubeconfig ~/.kube/config
+  osmanage k8s diff ./my.instance.dir.org --labels osinstance/examplelabel=true`
+)
+
+// diffAction classifies what applying a manifest would do to the live object
+type diffAction string
+
+const (
+	diffCreate    diffAction = "would create"
+	diffUpdate    diffAction = "would update"
+	diffUnchanged diffAction = "unchanged"
+)
+
+// fieldChange is a single field whose live value differs from the manifest
+type fieldChange struct {
+	path    string
+	live    string
+	desired string
+}
+
+// resourceDiff is the result of comparing one manifest with its live object
+type resourceDiff struct {
+	kind    string
+	name    string
+	action  diffAction
+	changes []fieldChange
+}
+
+func DiffCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "diff <instance-dir>",
+		Short: DiffHelp,
+		Long:  DiffHelp + "\n\n" + DiffHelpExtra,
+		Args:  cobra.ExactArgs(1),
+	}
+
+	kubeconfig := cmd.Flags().String("kubeconfig", "", "Path to kubeconfig file")
+	labels := cmd.Flags().StringToString("labels", nil, "Label selector to filter resources, e.g. 'osinstance/migrate=true'")
+
+	cmd.RunE = func(cmd *cobra.Command, args []string) error {
+		logger.Info("=== K8S DIFF INSTANCE ===")
+		instanceDir := args[0]
+		logger.Debug("Instance directory: %s", instanceDir)
+
+		k8sClient, err := client.New(*kubeconfig)
+		if err != nil {
+			return fmt.Errorf("creating k8s client: %w", err)
+		}
+
+		ctx, cancel := utils.TimeoutContext(cmd, 0)
+		defer cancel()
+		diffs, err := DiffInstance(ctx, k8sClient, instanceDir, *labels)
+		if err != nil {
+			return err
+		}
+
+		printDiffs(os.Stdout, diffs)
+		return nil
+	}
+
+	return cmd
+}
+
+// DiffInstance compares all manifests in the stack directory of instanceDir
+// matching labels with the live objects on the cluster.
+func DiffInstance(ctx context.Context, k8sClient *client.Client, instanceDir string, labels map[string]string) ([]resourceDiff, error) {
+	stackDir := filepath.Join(instanceDir, constants.StackDirName)
+	files, err := os.ReadDir(stackDir)
+	if err != nil {
+		return nil, fmt.Errorf("reading directory: %w", err)
+	}
+
+	var objs []*unstructured.Unstructured
+	for _, file := range files {
+		if file.IsDir() || !utils.IsYAMLFile(file.Name()) {
+			continue
+		}
+		obj, err := readManifest(filepath.Join(stackDir, file.Name()))
+		if err != nil {
+			return nil, fmt.Errorf("reading %s: %w", file.Name(), err)
+		}
+		if obj.GetKind() == "" || !matchesLabels(obj, labels) {
+			continue
+		}
+		objs = append(objs, obj)
+	}
+
+	sort.SliceStable(objs, func(i, j int) bool {
+		return constants.GetKindPriority(objs[i].GetKind()) < constants.GetKindPriority(objs[j].GetKind())
+	})
+
+	mapper, err := k8sClient.RESTMapper()
+	if err != nil {
+		return nil, fmt.Errorf("getting REST mapper: %w", err)
+	}
+	dynamicClient, err := k8sClient.Dynamic()
+	if err != nil {
+		return nil, fmt.Errorf("getting dynamic client: %w", err)
+	}
+
+	diffs := make([]resourceDiff, 0, len(objs))
+	for _, obj := range objs {
+		gvk := obj.GroupVersionKind()
+		mapping, err := mapper.RESTMapping(gvk.GroupKind(), gvk.Version)
+		if err != nil {
+			return nil, fmt.Errorf("getting REST mapping for %s: %w", gvk.String(), err)
+		}
+
+		namespaced := mapping.Scope.Name() == meta.RESTScopeNameNamespace
+		diff, err := diffManifest(ctx, dynamicClient, mapping.Resource, namespaced, obj)
+		if err != nil {
+			return nil, err
+		}
+		diffs = append(diffs, diff)
+	}
+
+	return diffs, nil
+}
+
+// diffManifest fetches the live object of obj and compares the fields set in obj
+// with it. A missing live object is reported as diffCreate.
+func diffManifest(ctx context.Context, dynamicClient dynamic.Interface, gvr schema.GroupVersionResource, namespaced bool, obj *unstructured.Unstructured) (resourceDiff, error) {
+	diff := resourceDiff{kind: obj.GetKind(), name: obj.GetName()}
+
+	var resource dynamic.ResourceInterface = dynamicClient.Resource(gvr)
+	if namespaced {
+		if obj.GetNamespace() == "" {
+			return diff, fmt.Errorf("resource %s/%s is namespaced but has no namespace specified", obj.GetKind(), obj.GetName())
+		}
+		resource = dynamicClient.Resource(gvr).Namespace(obj.GetNamespace())
+	}
+
+	live, err := resource.Get(ctx, obj.GetName(), metav1.GetOptions{})
+	if err != nil {
+		if errors.IsNotFound(err) {
+			diff.action = diffCreate
+			return diff, nil
+		}
+		return diff, fmt.Errorf("getting %s/%s: %w", obj.GetKind(), obj.GetName(), err)
+	}
+
+	diff.changes = compareObjects(obj, live)
+	diff.action = diffUnchanged
+	if len(diff.changes) > 0 {
+		diff.action = diffUpdate
+	}
+	return diff, nil
+}
+
+// compareObjects returns the fields of desired whose values differ in live.
+// Of the metadata only labels and annotations are compared, status is ignored.
+func compareObjects(desired, live *unstructured.Unstructured) []fieldChange {
+	secret := desired.GetKind() == "Secret"
+
+	var changes []fieldChange
+	keys := sortedKeys(desired.Object)
+	for _, key := range keys {
+		switch key {
+		case "apiVersion", "kind", "status":
+			continue
+		case "metadata":
+			for _, field := range []string{"labels", "annotations"} {
+				value, found, _ := unstructured.NestedFieldNoCopy(desired.Object, "metadata", field)
+				if !found {
+					continue
+				}
+				liveValue, liveFound, _ := unstructured.NestedFieldNoCopy(live.Object, "metadata", field)
+				changes = compareValues("metadata."+field, value, liveValue, liveFound, false, changes)
+			}
+		default:
+			liveValue, liveFound := live.Object[key]
+			redact := secret && (key == "data" || key == "stringData")
+			changes = compareValues(key, desired.Object[key], liveValue, liveFound, redact, changes)
+		}
+	}
+	return changes
+}
+
+// compareValues recursively compares desired with live and appends differing
+// leaves to changes. Maps and lists are compared element by element so that
+// fields only present in live do not count as changes.
+func compareValues(path string, desired, live any, liveFound bool, redact bool, changes []fieldChange) []fieldChange {
+	switch d := desired.(type) {
+	case map[string]any:
+		l, ok := live.(map[string]any)
+		if !liveFound || !ok {
+			break
+		}
+		for _, key := range sortedKeys(d) {
+			liveValue, found := l[key]
+			changes = compareValues(path+"."+key, d[key], liveValue, found, redact, changes)
+		}
+		return changes
+	case []any:
+		l, ok := live.([]any)
+		if !liveFound || !ok || len(l) != len(d) {
+			break
+		}
+		for i := range d {
+			changes = compareValues(fmt.Sprintf("%s[%d]", path, i), d[i], l[i], true, redact, changes)
+		}
+		return changes
+	}
+
+	desiredString := formatDiffValue(desired, true)
+	liveString := formatDiffValue(live, liveFound)
+	if liveFound && desiredString == liveString {
+		return changes
+	}
+	if redact {
+		desiredString, liveString = "<redacted>", "<redacted>"
+		if !liveFound {
+			liveString = "<none>"
+		}
+	}
+	return append(changes, fieldChange{path: path, live: liveString, desired: desiredString})
+}
+
+// formatDiffValue returns value as compact JSON, or <none> if it is not set
+func formatDiffValue(value any, found bool) string {
+	if !found {
+		return "<none>"
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprintf("%v", value)
+	}
+	return string(data)
+}
+
+// sortedKeys returns the keys of m in lexical order
+func sortedKeys(m map[string]any) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// printDiffs writes one line per resource followed by its changed fields and a summary
+func printDiffs(w io.Writer, diffs []resourceDiff) {
+	counts := map[diffAction]int{}
+	for _, diff := range diffs {
+		counts[diff.action]++
+		fmt.Fprintf(w, "%s %s/%s\n", diff.action, diff.kind, diff.name)
+		for _, change := range diff.changes {
+			fmt.Fprintf(w, "  %s: %s -> %s\n", change.path, change.live, change.desired)
+		}
+	}
+
+	summary := []string{
+		fmt.Sprintf("%d to create", counts[diffCreate]),
+		fmt.Sprintf("%d to update", counts[diffUpdate]),
+		fmt.Sprintf("%d unchanged", counts[diffUnchanged]),
+	}
+	fmt.Fprintf(w, "\n%s\n", strings.Join(summary, ", "))
+}
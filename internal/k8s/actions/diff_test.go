@@ -0,0 +1,143 @@
+package actions
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+)
+
+var deploymentGVR = schema.GroupVersionResource{Group: "apps", Version: "v1", Resource: "deployments"}
+
+func testDeployment(name string, replicas int64, image string) *unstructured.Unstructured {
+	return &unstructured.Unstructured{Object: map[string]any{
+		"apiVersion": "apps/v1",
+		"kind":       "Deployment",
+		"metadata": map[string]any{
+			"name":      name,
+			"namespace": "myinstance",
+			"labels":    map[string]any{"app": name},
+		},
+		"spec": map[string]any{
+			"replicas": replicas,
+			"template": map[string]any{
+				"spec": map[string]any{
+					"containers": []any{
+						map[string]any{"name": name, "image": image},
+					},
+				},
+			},
+		},
+	}}
+}
+
+func TestDiffManifest(t *testing.T) {
+	live := testDeployment("backend", 1, "openslides/openslides-backend:4.2.0")
+	// Fields defaulted by the cluster must not count as changes
+	live.Object["status"] = map[string]any{"readyReplicas": int64(1)}
+	containers, _, _ := unstructured.NestedSlice(live.Object, "spec", "template", "spec", "containers")
+	containers[0].(map[string]any)["imagePullPolicy"] = "IfNotPresent"
+	_ = unstructured.SetNestedSlice(live.Object, containers, "spec", "template", "spec", "containers")
+	unchanged := testDeployment("search", 1, "openslides/openslides-search:4.2.0")
+
+	dynamicClient := dynamicfake.NewSimpleDynamicClient(runtime.NewScheme(), live, unchanged.DeepCopy())
+	ctx := context.Background()
+
+	t.Run("create", func(t *testing.T) {
+		diff, err := diffManifest(ctx, dynamicClient, deploymentGVR, true, testDeployment("client", 1, "openslides/openslides-client:4.2.0"))
+		if err != nil {
+			t.Fatalf("diffManifest() error = %v", err)
+		}
+		if diff.action != diffCreate {
+			t.Errorf("Expected %q, got %q", diffCreate, diff.action)
+		}
+	})
+
+	t.Run("unchanged", func(t *testing.T) {
+		diff, err := diffManifest(ctx, dynamicClient, deploymentGVR, true, testDeployment("search", 1, "openslides/openslides-search:4.2.0"))
+		if err != nil {
+			t.Fatalf("diffManifest() error = %v", err)
+		}
+		if diff.action != diffUnchanged || len(diff.changes) != 0 {
+			t.Errorf("Expected unchanged, got %q with %v", diff.action, diff.changes)
+		}
+	})
+
+	t.Run("update", func(t *testing.T) {
+		diff, err := diffManifest(ctx, dynamicClient, deploymentGVR, true, testDeployment("backend", 2, "openslides/openslides-backend:4.2.1"))
+		if err != nil {
+			t.Fatalf("diffManifest() error = %v", err)
+		}
+		if diff.action != diffUpdate {
+			t.Fatalf("Expected %q, got %q", diffUpdate, diff.action)
+		}
+
+		want := []fieldChange{
+			{path: "spec.replicas", live: "1", desired: "2"},
+			{path: "spec.template.spec.containers[0].image", live: `"openslides/openslides-backend:4.2.0"`, desired: `"openslides/openslides-backend:4.2.1"`},
+		}
+		if len(diff.changes) != len(want) {
+			t.Fatalf("Expected %d changes, got %v", len(want), diff.changes)
+		}
+		for i, change := range want {
+			if diff.changes[i] != change {
+				t.Errorf("Change %d: expected %+v, got %+v", i, change, diff.changes[i])
+			}
+		}
+	})
+
+	t.Run("missing namespace", func(t *testing.T) {
+		obj := testDeployment("backend", 1, "openslides/openslides-backend:4.2.0")
+		obj.SetNamespace("")
+		if _, err := diffManifest(ctx, dynamicClient, deploymentGVR, true, obj); err == nil {
+			t.Error("Expected error for namespaced resource without namespace")
+		}
+	})
+}
+
+func TestCompareObjects_RedactsSecrets(t *testing.T) {
+	secret := func(password string) *unstructured.Unstructured {
+		return &unstructured.Unstructured{Object: map[string]any{
+			"apiVersion": "v1",
+			"kind":       "Secret",
+			"metadata":   map[string]any{"name": "postgres", "namespace": "myinstance"},
+			"data":       map[string]any{"password": password},
+		}}
+	}
+
+	changes := compareObjects(secret("bmV3"), secret("b2xk"))
+	if len(changes) != 1 {
+		t.Fatalf("Expected 1 change, got %v", changes)
+	}
+	if changes[0].path != "data.password" {
+		t.Errorf("Expected path data.password, got %s", changes[0].path)
+	}
+	if strings.Contains(changes[0].live+changes[0].desired, "b2xk") || strings.Contains(changes[0].live+changes[0].desired, "bmV3") {
+		t.Errorf("Expected secret values to be redacted, got %+v", changes[0])
+	}
+}
+
+func TestPrintDiffs(t *testing.T) {
+	var buf bytes.Buffer
+	printDiffs(&buf, []resourceDiff{
+		{kind: "Deployment", name: "client", action: diffCreate},
+		{kind: "Deployment", name: "backend", action: diffUpdate, changes: []fieldChange{{path: "spec.replicas", live: "1", desired: "2"}}},
+		{kind: "Service", name: "backend", action: diffUnchanged},
+	})
+
+	want := `would create Deployment/client
+would update Deployment/backend
+  spec.replicas: 1 -> 2
+unchanged Service/backend
+
+1 to create, 1 to update, 1 unchanged
+`
+	if buf.String() != want {
+		t.Errorf("Unexpected output:\n%s\nwant:\n%s", buf.String(), want)
+	}
+}
@@ -0,0 +1,130 @@
+package actions
+
+import (
+	"io"
+	"os"
+
+	"github.com/OpenSlides/openslides-cli/internal/constants"
+	"golang.org/x/term"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// Display holds the characters used for status icons and progress bars
+type Display struct {
+	IconReady     string
+	IconNotReady  string
+	Saucer        string
+	SaucerPadding string
+}
+
+var (
+	defaultDisplay = Display{
+		IconReady:     constants.IconReady,
+		IconNotReady:  constants.IconNotReady,
+		Saucer:        constants.Saucer,
+		SaucerPadding: constants.SaucerPadding,
+	}
+	asciiDisplay = Display{
+		IconReady:     constants.IconReadyASCII,
+		IconNotReady:  constants.IconNotReadyASCII,
+		Saucer:        constants.SaucerASCII,
+		SaucerPadding: constants.SaucerPaddingASCII,
+	}
+
+	display = defaultDisplay
+
+	colorEnabled = true
+
+	progressEnabled = true
+)
+
+// ANSI color codes for status printouts
+const (
+	colorGreen  = "\033[32m"
+	colorYellow = "\033[33m"
+	colorRed    = "\033[31m"
+	colorReset  = "\033[0m"
+)
+
+// SetASCII switches status icons and progress bars to plain ASCII characters
+// for terminals and log viewers that cannot render unicode symbols.
+func SetASCII(ascii bool) {
+	if ascii {
+		display = asciiDisplay
+	} else {
+		display = defaultDisplay
+	}
+}
+
+// statusIcon returns the ready or not ready icon of the current display
+func statusIcon(ready bool) string {
+	if ready {
+		return display.IconReady
+	}
+	return display.IconNotReady
+}
+
+// SetColor enables or disables colored status printouts. Even when enabled,
+// colors are only written to terminals and not if the NO_COLOR env var is set.
+func SetColor(enabled bool) {
+	colorEnabled = enabled
+}
+
+// useColor reports whether status printouts to w should be colored
+func useColor(w io.Writer) bool {
+	if !colorEnabled {
+		return false
+	}
+	if _, ok := os.LookupEnv("NO_COLOR"); ok {
+		return false
+	}
+	f, ok := w.(*os.File)
+	return ok && term.IsTerminal(int(f.Fd()))
+}
+
+// SetProgress enables or disables progress bars. Even when enabled, bars are
+// only rendered on terminals, otherwise plain progress lines are printed.
+func SetProgress(enabled bool) {
+	progressEnabled = enabled
+}
+
+// useProgressBar reports whether progress written to w should be rendered as
+// animated bar rather than as plain lines
+func useProgressBar(w io.Writer) bool {
+	if !progressEnabled {
+		return false
+	}
+	f, ok := w.(*os.File)
+	return ok && term.IsTerminal(int(f.Fd()))
+}
+
+// colorize wraps s in the given color if enabled
+func colorize(s, color string, enabled bool) string {
+	if !enabled || color == "" {
+		return s
+	}
+	return color + s + colorReset
+}
+
+// readyColor returns the color of the ready or not ready icon
+func readyColor(ready bool) string {
+	if ready {
+		return colorGreen
+	}
+	return colorRed
+}
+
+// phaseColor returns the color of a pod phase: green for running, yellow for
+// pending and red for failures.
+func phaseColor(phase corev1.PodPhase) string {
+	switch phase {
+	case corev1.PodRunning, corev1.PodSucceeded:
+		return colorGreen
+	case corev1.PodPending:
+		return colorYellow
+	case corev1.PodFailed, corev1.PodUnknown:
+		return colorRed
+	default:
+		return ""
+	}
+}
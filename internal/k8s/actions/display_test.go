@@ -0,0 +1,186 @@
+package actions
+
+import (
+	"bytes"
+	"os"
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestSetASCII(t *testing.T) {
+	t.Cleanup(func() { SetASCII(false) })
+
+	SetASCII(true)
+	if statusIcon(true) != "[OK]" || statusIcon(false) != "[FAIL]" {
+		t.Errorf("Expected ASCII icons, got %q and %q", statusIcon(true), statusIcon(false))
+	}
+	if display.Saucer != "#" || display.SaucerPadding != "-" {
+		t.Errorf("Expected ASCII saucer, got %q and %q", display.Saucer, display.SaucerPadding)
+	}
+
+	SetASCII(false)
+	if statusIcon(true) != "✓" || statusIcon(false) != "✗" {
+		t.Errorf("Expected default icons, got %q and %q", statusIcon(true), statusIcon(false))
+	}
+}
+
+func TestPrintHealthStatus_NoColorWhenNotTerminal(t *testing.T) {
+	status := &HealthStatus{
+		Ready: 1,
+		Total: 3,
+		Pods: []corev1.Pod{
+			{
+				ObjectMeta: metav1.ObjectMeta{Name: "backend"},
+				Status: corev1.PodStatus{
+					Phase:      corev1.PodRunning,
+					Conditions: []corev1.PodCondition{{Type: corev1.PodReady, Status: corev1.ConditionTrue}},
+				},
+			},
+			{ObjectMeta: metav1.ObjectMeta{Name: "search"}, Status: corev1.PodStatus{Phase: corev1.PodPending}},
+			{ObjectMeta: metav1.ObjectMeta{Name: "media"}, Status: corev1.PodStatus{Phase: corev1.PodFailed}},
+		},
+	}
+
+	var buf bytes.Buffer
+	printHealthStatus(&buf, "test-ns", status)
+
+	output := buf.String()
+	if strings.Contains(output, "\033[") {
+		t.Errorf("Expected no color codes when not writing to a terminal, got %q", output)
+	}
+	for _, want := range []string{"backend", "Running", "Pending", "Failed"} {
+		if !strings.Contains(output, want) {
+			t.Errorf("Expected output to contain %q, got %q", want, output)
+		}
+	}
+}
+
+func TestColorize(t *testing.T) {
+	if got := colorize("Running", phaseColor(corev1.PodRunning), true); got != colorGreen+"Running"+colorReset {
+		t.Errorf("Expected green Running, got %q", got)
+	}
+	if got := colorize("Pending", phaseColor(corev1.PodPending), true); got != colorYellow+"Pending"+colorReset {
+		t.Errorf("Expected yellow Pending, got %q", got)
+	}
+	if got := colorize("Failed", phaseColor(corev1.PodFailed), true); got != colorRed+"Failed"+colorReset {
+		t.Errorf("Expected red Failed, got %q", got)
+	}
+	if got := colorize("Running", colorGreen, false); got != "Running" {
+		t.Errorf("Expected plain text when disabled, got %q", got)
+	}
+}
+
+func TestUseColor_Disabled(t *testing.T) {
+	t.Cleanup(func() { SetColor(true) })
+
+	SetColor(false)
+	if useColor(os.Stdout) {
+		t.Error("Expected no color after SetColor(false)")
+	}
+}
+
+func TestUseProgressBar(t *testing.T) {
+	t.Cleanup(func() { SetProgress(true) })
+
+	var buf bytes.Buffer
+	if useProgressBar(&buf) {
+		t.Error("Expected no progress bar for a non-terminal writer")
+	}
+
+	SetProgress(false)
+	if useProgressBar(os.Stdout) {
+		t.Error("Expected no progress bar after SetProgress(false)")
+	}
+}
+
+func TestPrintHealthStatus_ProbeFailure(t *testing.T) {
+	status := &HealthStatus{
+		Ready: 0,
+		Total: 1,
+		Pods: []corev1.Pod{
+			{
+				ObjectMeta: metav1.ObjectMeta{Name: "backend"},
+				Status: corev1.PodStatus{
+					Phase:      corev1.PodRunning,
+					Conditions: []corev1.PodCondition{{Type: corev1.PodReady, Status: corev1.ConditionTrue}},
+				},
+			},
+		},
+		ProbeFailures: map[string]string{"backend": "GET /health: status 503"},
+	}
+
+	var buf bytes.Buffer
+	printHealthStatus(&buf, "test-ns", status)
+
+	output := buf.String()
+	if !strings.Contains(output, statusIcon(false)+" backend") || !strings.Contains(output, "(probe failed: GET /health: status 503)") {
+		t.Errorf("Expected failed probe in output, got %q", output)
+	}
+}
+
+func TestPrintHealthStatus_RestartCounts(t *testing.T) {
+	pod := func(name string, restarts ...int32) corev1.Pod {
+		var statuses []corev1.ContainerStatus
+		for _, r := range restarts {
+			statuses = append(statuses, corev1.ContainerStatus{RestartCount: r})
+		}
+		return corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Status:     corev1.PodStatus{Phase: corev1.PodRunning, ContainerStatuses: statuses},
+		}
+	}
+	status := &HealthStatus{
+		Total: 2,
+		Pods:  []corev1.Pod{pod("backend", 1, 1), pod("search", 3, 2)},
+	}
+
+	var buf bytes.Buffer
+	printHealthStatus(&buf, "test-ns", status)
+
+	lines := strings.Split(buf.String(), "\n")
+	var backend, search string
+	for _, line := range lines {
+		switch {
+		case strings.Contains(line, "backend"):
+			backend = line
+		case strings.Contains(line, "search"):
+			search = line
+		}
+	}
+	if !strings.HasSuffix(backend, "restarts: 2") {
+		t.Errorf("Expected backend with 2 restarts, got %q", backend)
+	}
+	if !strings.HasSuffix(search, "restarts: 5 (flapping?)") {
+		t.Errorf("Expected search flagged with 5 restarts, got %q", search)
+	}
+}
+
+func TestPrintNamespaceStatus(t *testing.T) {
+	ns := &corev1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{Name: "stuck", Finalizers: []string{"example.org/cleanup"}},
+		Spec:       corev1.NamespaceSpec{Finalizers: []corev1.FinalizerName{corev1.FinalizerKubernetes}},
+		Status: corev1.NamespaceStatus{
+			Phase: corev1.NamespaceTerminating,
+			Conditions: []corev1.NamespaceCondition{
+				{Type: corev1.NamespaceDeletionDiscoveryFailure, Status: corev1.ConditionTrue, Message: "metrics.k8s.io/v1beta1: stale APIService"},
+				{Type: corev1.NamespaceContentRemaining, Status: corev1.ConditionFalse, Message: "All resources successfully removed"},
+			},
+		},
+	}
+
+	var buf bytes.Buffer
+	printNamespaceStatus(&buf, ns)
+
+	output := buf.String()
+	for _, want := range []string{"Phase: Terminating", "spec: kubernetes", "metadata: example.org/cleanup", "NamespaceDeletionDiscoveryFailure", "stale APIService"} {
+		if !strings.Contains(output, want) {
+			t.Errorf("Expected output to contain %q, got %q", want, output)
+		}
+	}
+	if strings.Contains(output, "All resources successfully removed") {
+		t.Errorf("Expected inactive conditions to be omitted, got %q", output)
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"strings"
 
 	"github.com/OpenSlides/openslides-cli/internal/k8s/client"
+	"github.com/OpenSlides/openslides-cli/internal/utils"
 	"github.com/spf13/cobra"
 
 	"k8s.io/apimachinery/pkg/api/errors"
@@ -44,7 +45,8 @@ func GetServiceAddressCmd() *cobra.Command {
 			return fmt.Errorf("creating k8s client: %w", err)
 		}
 
-		ctx := context.Background()
+		ctx, cancel := utils.TimeoutContext(cmd, 0)
+		defer cancel()
 		address, err := GetServiceAddress(ctx, k8sClient.Clientset(), namespace, serviceName)
 		if err != nil {
 			return err
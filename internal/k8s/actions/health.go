@@ -3,6 +3,10 @@ package actions
 import (
 	"context"
 	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
 
 	"github.com/spf13/cobra"
 
@@ -16,9 +20,21 @@ const (
 	HealthHelp      = "Check health status of an OpenSlides instance"
 	HealthHelpExtra = `Checks if all pods in the instance namespace are ready and running.
 
+With --probe-path, ready pods exposing a container port named "http" must also
+answer a GET request for this path with a 2xx status, sent through the
+Kubernetes API server proxy. Pods without such a port are checked for
+readiness only.
+
+With --watch, the status is printed again every --interval until Ctrl-C.
+Add --until-healthy to stop with exit code 0 once the instance is healthy.
+
 Examples:
   osmanage k8s health ./my.instance.dir.org 
-  osmanage k8s health ./my.instance.dir.org --wait --timeout 30s`
+  osmanage k8s health ./my.instance.dir.org --wait --timeout 30s
+  osmanage k8s health ./my.instance.dir.org --health-threshold 0.9
+  osmanage k8s health ./my.instance.dir.org --wait --health-threshold 11/12
+  osmanage k8s health ./my.instance.dir.org --probe-path /system/health
+  osmanage k8s health ./my.instance.dir.org --watch --interval 5s --until-healthy`
 )
 
 func HealthCmd() *cobra.Command {
@@ -32,14 +48,40 @@ func HealthCmd() *cobra.Command {
 	kubeconfig := cmd.Flags().String("kubeconfig", "", "Path to kubeconfig file")
 	wait := cmd.Flags().Bool("wait", false, "Wait for instance to become healthy")
 	timeout := cmd.Flags().Duration("timeout", constants.DefaultInstanceTimeout, "Timeout for instance health check")
+	healthThreshold := cmd.Flags().String("health-threshold", "", "Minimum share of ready pods to count as healthy, as fraction '0.9', percentage '90%' or ratio 'N/M' (default: all)")
+	watch := cmd.Flags().Bool("watch", false, "Print the health status every --interval until interrupted")
+	interval := cmd.Flags().Duration("interval", constants.TickerDuration, "Refresh interval for --watch")
+	untilHealthy := cmd.Flags().Bool("until-healthy", false, "With --watch, exit successfully once the instance is healthy")
+	probePath := cmd.Flags().String("probe-path", "", "HTTP path that ready pods with an 'http' port must answer with 2xx, e.g. /health")
 
 	cmd.RunE = func(cmd *cobra.Command, args []string) error {
 		logger.Info("=== K8S HEALTH CHECK ===")
 
 		instanceDir := args[0]
-		namespace := utils.ExtractNamespace(instanceDir)
+		namespace, err := utils.ResolveNamespace(instanceDir)
+		if err != nil {
+			return err
+		}
 		logger.Debug("Namespace: %s", namespace)
 
+		threshold, err := ParseHealthThreshold(*healthThreshold)
+		if err != nil {
+			return err
+		}
+
+		if *watch && *wait {
+			return fmt.Errorf("--watch cannot be combined with --wait")
+		}
+		if *untilHealthy && !*watch {
+			return fmt.Errorf("--until-healthy requires --watch")
+		}
+		if *interval <= 0 {
+			return fmt.Errorf("--interval must be positive")
+		}
+		if *probePath != "" && !strings.HasPrefix(*probePath, "/") {
+			return fmt.Errorf("--probe-path must start with /")
+		}
+
 		k8sClient, err := client.New(*kubeconfig)
 		if err != nil {
 			return fmt.Errorf("creating k8s client: %w", err)
@@ -47,16 +89,28 @@ func HealthCmd() *cobra.Command {
 
 		ctx := context.Background()
 
+		if *watch {
+			// Unbounded unless --timeout is given explicitly
+			ctx, stop := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+			defer stop()
+			if t := utils.Timeout(cmd, 0); t > 0 {
+				var cancel context.CancelFunc
+				ctx, cancel = context.WithTimeout(ctx, t)
+				defer cancel()
+			}
+			return WatchInstanceHealth(ctx, k8sClient, namespace, os.Stdout, *interval, threshold, *probePath, *untilHealthy)
+		}
+
 		if *wait {
-			return WaitForInstanceHealthy(ctx, k8sClient, namespace, *timeout, nil)
+			return WaitForInstanceHealthy(ctx, k8sClient, namespace, *timeout, threshold, *probePath, nil)
 		}
 
-		status, err := GetHealthStatus(ctx, k8sClient, namespace)
+		status, err := GetHealthStatus(ctx, k8sClient, namespace, threshold, *probePath)
 		if err != nil {
 			return fmt.Errorf("getting health status: %w", err)
 		}
 
-		printHealthStatus(namespace, status)
+		printHealthStatus(os.Stdout, namespace, status)
 
 		if !status.Healthy {
 			return fmt.Errorf("instance is not healthy: %d/%d pods ready", status.Ready, status.Total)
@@ -3,7 +3,10 @@ package actions
 import (
 	"context"
 	"fmt"
+	"io"
+	"math/big"
 	"os"
+	"strconv"
 	"strings"
 	"time"
 
@@ -11,6 +14,7 @@ import (
 	"github.com/OpenSlides/openslides-cli/internal/k8s/client"
 	"github.com/OpenSlides/openslides-cli/internal/logger"
 	"github.com/schollz/progressbar/v3"
+	"golang.org/x/term"
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
@@ -19,11 +23,79 @@ import (
 
 // HealthStatus represents the health status of an instance
 type HealthStatus struct {
-	Healthy    bool
-	Ready      int
-	Total      int
-	ActivePods int
-	Pods       []corev1.Pod
+	Healthy       bool
+	Ready         int
+	Total         int
+	ActivePods    int
+	Pods          []corev1.Pod
+	ProbeFailures map[string]string // pod name to reason, only with a probe path
+}
+
+// podHealthy reports whether pod is ready and did not fail the HTTP probe
+func (s *HealthStatus) podHealthy(pod *corev1.Pod) bool {
+	return IsPodReady(pod) && s.ProbeFailures[pod.Name] == ""
+}
+
+// HealthThreshold is the minimum share of ready pods for an instance to count
+// as healthy. The zero value requires all pods to be ready.
+type HealthThreshold struct {
+	Ready int // at least Ready of every Of pods, kept exact
+	Of    int
+}
+
+// maxThresholdDenominator bounds the denominator of a parsed fraction or
+// percentage, so Required cannot overflow
+const maxThresholdDenominator = 1_000_000
+
+// ParseHealthThreshold parses a fraction like "0.9", a percentage like "90%"
+// or a ratio like "4/5" (at least 4 of every 5 pods ready). Fractions and
+// percentages are converted to an exact ratio. An empty string requires all
+// pods to be ready.
+func ParseHealthThreshold(value string) (HealthThreshold, error) {
+	if value == "" {
+		return HealthThreshold{}, nil
+	}
+
+	if ready, total, ok := strings.Cut(value, "/"); ok {
+		n, err := strconv.Atoi(ready)
+		if err != nil {
+			return HealthThreshold{}, fmt.Errorf("invalid health threshold %q: %w", value, err)
+		}
+		m, err := strconv.Atoi(total)
+		if err != nil {
+			return HealthThreshold{}, fmt.Errorf("invalid health threshold %q: %w", value, err)
+		}
+		if n <= 0 || n > m {
+			return HealthThreshold{}, fmt.Errorf("invalid health threshold %q: expected 0 < N <= M", value)
+		}
+		return HealthThreshold{Ready: n, Of: m}, nil
+	}
+
+	number, percent := strings.CutSuffix(value, "%")
+	r, ok := new(big.Rat).SetString(number)
+	if !ok || strings.Trim(number, "0123456789.") != "" {
+		return HealthThreshold{}, fmt.Errorf("invalid health threshold %q: expected fraction, percentage or N/M", value)
+	}
+	if percent {
+		r.Quo(r, big.NewRat(100, 1))
+	}
+	if r.Sign() <= 0 || r.Cmp(big.NewRat(1, 1)) > 0 {
+		return HealthThreshold{}, fmt.Errorf("invalid health threshold %q: must be in (0, 1] or (0%%, 100%%]", value)
+	}
+	if r.Denom().Cmp(big.NewInt(maxThresholdDenominator)) > 0 {
+		return HealthThreshold{}, fmt.Errorf("invalid health threshold %q: too many decimal places", value)
+	}
+	return HealthThreshold{Ready: int(r.Num().Int64()), Of: int(r.Denom().Int64())}, nil
+}
+
+// Required returns the number of ready pods needed out of total.
+func (t HealthThreshold) Required(total int) int {
+	switch {
+	case t.Of > 0:
+		return (total*t.Ready + t.Of - 1) / t.Of
+	default:
+		return total
+	}
 }
 
 // DeploymentStatus represents the rollout status of a deployment
@@ -51,8 +123,66 @@ func IsPodReady(pod *corev1.Pod) bool {
 	return false
 }
 
-// GetHealthStatus returns instance pod health
-func GetHealthStatus(ctx context.Context, k8sClient *client.Client, namespace string) (*HealthStatus, error) {
+// restartCount returns the sum of the restart counts of all containers of pod
+func restartCount(pod *corev1.Pod) int32 {
+	var restarts int32
+	for _, cs := range pod.Status.ContainerStatuses {
+		restarts += cs.RestartCount
+	}
+	return restarts
+}
+
+// httpPort returns the container port named constants.HealthProbePortName, or 0
+// if the pod exposes none
+func httpPort(pod *corev1.Pod) int32 {
+	for _, container := range pod.Spec.Containers {
+		for _, port := range container.Ports {
+			if port.Name == constants.HealthProbePortName && (port.Protocol == "" || port.Protocol == corev1.ProtocolTCP) {
+				return port.ContainerPort
+			}
+		}
+	}
+	return 0
+}
+
+// probeReadyPods calls probe for every ready pod exposing an HTTP port and
+// returns the failure reasons by pod name. Pods without an HTTP port are only
+// checked for readiness.
+func probeReadyPods(pods []corev1.Pod, probe func(pod *corev1.Pod, port int32) error) map[string]string {
+	failures := map[string]string{}
+	for _, pod := range pods {
+		port := httpPort(&pod)
+		if port == 0 || !IsPodReady(&pod) {
+			continue
+		}
+		if err := probe(&pod, port); err != nil {
+			logger.Debug("Probe of pod %s failed: %v", pod.Name, err)
+			failures[pod.Name] = err.Error()
+		}
+	}
+	return failures
+}
+
+// probePod sends a GET request for path to port of pod through the API server
+// proxy, so it works both in-cluster and with a kubeconfig. Responses other
+// than 2xx are an error.
+func probePod(ctx context.Context, k8sClient *client.Client, pod *corev1.Pod, port int32, path string) error {
+	ctx, cancel := context.WithTimeout(ctx, constants.HealthProbeTimeout)
+	defer cancel()
+
+	_, err := k8sClient.Clientset().CoreV1().Pods(pod.Namespace).
+		ProxyGet("http", pod.Name, strconv.Itoa(int(port)), path, nil).
+		DoRaw(ctx)
+	if err != nil {
+		return fmt.Errorf("GET %s: %w", path, err)
+	}
+	return nil
+}
+
+// GetHealthStatus returns instance pod health. The instance is healthy if at
+// least the threshold of pods is ready. With a probePath, ready pods exposing
+// an HTTP port must also answer a GET request for it to count as ready.
+func GetHealthStatus(ctx context.Context, k8sClient *client.Client, namespace string, threshold HealthThreshold, probePath string) (*HealthStatus, error) {
 	pods, err := k8sClient.Clientset().CoreV1().Pods(namespace).List(ctx, metav1.ListOptions{})
 	if err != nil {
 		return nil, fmt.Errorf("listing pods: %w", err)
@@ -77,9 +207,16 @@ func GetHealthStatus(ctx context.Context, k8sClient *client.Client, namespace st
 		}
 	}
 
+	status := &HealthStatus{Pods: filteredPods}
+	if probePath != "" {
+		status.ProbeFailures = probeReadyPods(filteredPods, func(pod *corev1.Pod, port int32) error {
+			return probePod(ctx, k8sClient, pod, port, probePath)
+		})
+	}
+
 	ready := 0
 	for _, pod := range filteredPods {
-		if IsPodReady(&pod) {
+		if status.podHealthy(&pod) {
 			ready++
 		}
 	}
@@ -89,17 +226,15 @@ func GetHealthStatus(ctx context.Context, k8sClient *client.Client, namespace st
 		total = len(filteredPods)
 	}
 
-	return &HealthStatus{
-		Healthy:    ready == total,
-		Ready:      ready,
-		Total:      total,
-		ActivePods: len(filteredPods),
-		Pods:       filteredPods,
-	}, nil
+	status.Healthy = ready >= threshold.Required(total)
+	status.Ready = ready
+	status.Total = total
+	status.ActivePods = len(filteredPods)
+	return status, nil
 }
 
 // pollUntil runs fn on every interval tick until fn returns done=true, fn returns
-// an error, or the timeout is exceeded.
+// an error, the timeout is exceeded or ctx is cancelled.
 func pollUntil(ctx context.Context, interval, timeout time.Duration, fn func() (done bool, err error)) error {
 	ticker := time.NewTicker(interval)
 	defer ticker.Stop()
@@ -118,36 +253,115 @@ func pollUntil(ctx context.Context, interval, timeout time.Duration, fn func() (
 				return nil
 			}
 		case <-timeoutCtx.Done():
+			if err := ctx.Err(); err != nil {
+				return fmt.Errorf("cancelled: %w", err)
+			}
 			return fmt.Errorf("timeout: %w", timeoutCtx.Err())
 		}
 	}
 }
 
-// printHealthStatus prints pod-level health details to stdout.
-func printHealthStatus(namespace string, status *HealthStatus) {
+// printHealthStatus prints pod-level health details to w. Icons and phases
+// are colored if w is a terminal.
+func printHealthStatus(w io.Writer, namespace string, status *HealthStatus) {
 	if status.Total == 0 {
-		fmt.Printf("No pods found in namespace %s\n", namespace)
+		fmt.Fprintf(w, "No pods found in namespace %s\n", namespace)
 		return
 	}
 
-	fmt.Printf("\nNamespace: %s\n", namespace)
-	fmt.Printf("Ready: %d/%d pods (active: %d)\n\n", status.Ready, status.Total, status.ActivePods)
-	fmt.Println("Pod Status:")
+	color := useColor(w)
+
+	fmt.Fprintf(w, "\nNamespace: %s\n", namespace)
+	fmt.Fprintf(w, "Ready: %d/%d pods (active: %d)\n\n", status.Ready, status.Total, status.ActivePods)
+	fmt.Fprintln(w, "Pod Status:")
 	for _, pod := range status.Pods {
-		icon := constants.IconNotReady
-		if IsPodReady(&pod) {
-			icon = constants.IconReady
+		ready := status.podHealthy(&pod)
+		fmt.Fprintf(w, "  %s %-50s %-10s",
+			colorize(statusIcon(ready), readyColor(ready), color),
+			pod.Name,
+			colorize(string(pod.Status.Phase), phaseColor(pod.Status.Phase), color))
+		restarts := restartCount(&pod)
+		if restarts > constants.RestartWarnThreshold {
+			fmt.Fprintf(w, " restarts: %s", colorize(fmt.Sprintf("%d (flapping?)", restarts), colorYellow, color))
+		} else {
+			fmt.Fprintf(w, " restarts: %d", restarts)
+		}
+		if reason := status.ProbeFailures[pod.Name]; reason != "" {
+			fmt.Fprintf(w, " (probe failed: %s)", reason)
 		}
-		fmt.Printf("  %s %-50s %s\n", icon, pod.Name, pod.Status.Phase)
+		fmt.Fprintln(w)
 	}
-	fmt.Println()
+	fmt.Fprintln(w)
+}
+
+// clearScreen moves the cursor home and clears w if it is a terminal
+func clearScreen(w io.Writer) {
+	if f, ok := w.(*os.File); ok && term.IsTerminal(int(f.Fd())) {
+		fmt.Fprint(w, "\033[H\033[2J")
+	}
+}
+
+// WatchInstanceHealth prints the health status of namespace to w every
+// interval, clearing the screen between renders, until ctx is cancelled. With
+// untilHealthy it returns nil as soon as the instance is healthy.
+func WatchInstanceHealth(
+	ctx context.Context,
+	k8sClient *client.Client,
+	namespace string,
+	w io.Writer,
+	interval time.Duration,
+	threshold HealthThreshold,
+	probePath string,
+	untilHealthy bool,
+) error {
+	return watchHealth(ctx, interval, untilHealthy, func() (*HealthStatus, error) {
+		return GetHealthStatus(ctx, k8sClient, namespace, threshold, probePath)
+	}, func(status *HealthStatus, err error) {
+		clearScreen(w)
+		fmt.Fprintf(w, "Every %v: k8s health %s (%s)\n", interval, namespace, time.Now().Format(time.TimeOnly))
+		if err != nil {
+			fmt.Fprintf(w, "\nError getting health status: %v\n", err)
+			return
+		}
+		printHealthStatus(w, namespace, status)
+	})
 }
 
-// getNotReadyNames returns the names of pods that are not ready.
-func getNotReadyNames(pods []corev1.Pod) []string {
+// watchHealth calls render with the result of getStatus immediately and then
+// every interval. It returns nil once the status is healthy if untilHealthy is
+// set, and an error when ctx is cancelled.
+func watchHealth(
+	ctx context.Context,
+	interval time.Duration,
+	untilHealthy bool,
+	getStatus func() (*HealthStatus, error),
+	render func(*HealthStatus, error),
+) error {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		status, err := getStatus()
+		render(status, err)
+		if untilHealthy && err == nil && status.Healthy {
+			logger.Info("Instance is healthy: %d/%d pods ready", status.Ready, status.Total)
+			return nil
+		}
+
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return fmt.Errorf("watch stopped: %w", ctx.Err())
+		}
+	}
+}
+
+// getNotReadyNames returns the names of pods that are not ready or failed the
+// HTTP probe.
+func getNotReadyNames(status *HealthStatus) []string {
 	var names []string
-	for _, pod := range pods {
-		if !IsPodReady(&pod) {
+	for _, pod := range status.Pods {
+		if !status.podHealthy(&pod) {
 			names = append(names, pod.Name)
 		}
 	}
@@ -158,17 +372,23 @@ func getNotReadyNames(pods []corev1.Pod) []string {
 //
 // When callback is non-nil (gRPC mode), it is called on every tick with the
 // current status and no progress bar is rendered. When callback is nil (CLI
-// mode), a progress bar is written to stdout.
+// mode), a progress bar is written to stdout. The instance counts as healthy
+// once the threshold of pods is ready. A non-empty probePath is passed on to
+// GetHealthStatus.
 func WaitForInstanceHealthy(
 	ctx context.Context,
 	k8sClient *client.Client,
 	namespace string,
 	timeout time.Duration,
+	threshold HealthThreshold,
+	probePath string,
 	callback func(*HealthStatus) error,
 ) error {
+	showBar := callback == nil && useProgressBar(os.Stdout)
+
 	var bar *progressbar.ProgressBar
-	if callback == nil {
-		initial, err := GetHealthStatus(ctx, k8sClient, namespace)
+	if showBar {
+		initial, err := GetHealthStatus(ctx, k8sClient, namespace, threshold, probePath)
 		if err != nil {
 			return fmt.Errorf("getting initial health status: %w", err)
 		}
@@ -180,7 +400,7 @@ func WaitForInstanceHealthy(
 	var lastStatus *HealthStatus
 
 	err := pollUntil(ctx, constants.TickerDuration, timeout, func() (bool, error) {
-		status, err := GetHealthStatus(ctx, k8sClient, namespace)
+		status, err := GetHealthStatus(ctx, k8sClient, namespace, threshold, probePath)
 		if err != nil {
 			logger.Debug("Error checking health: %v", err)
 			return false, nil
@@ -191,15 +411,17 @@ func WaitForInstanceHealthy(
 			if err := callback(status); err != nil {
 				return false, err
 			}
+		} else if !showBar {
+			fmt.Printf("Pods ready: %d/%d\n", status.Ready, status.Total)
 		} else {
 			if bar == nil && status.Total > 0 {
 				bar = createProgressBar(status.Total, "Pods ready", constants.AddDetailLineBuffer)
 			}
 			if bar != nil && !bar.IsFinished() {
-				notReady := getNotReadyNames(status.Pods)
+				notReady := getNotReadyNames(status)
 				detail := ""
 				if len(notReady) > 0 {
-					detail = fmt.Sprintf("%s Pending: %s", constants.IconNotReady, strings.Join(notReady, ", "))
+					detail = fmt.Sprintf("%s Pending: %s", display.IconNotReady, strings.Join(notReady, ", "))
 				}
 				if err := bar.AddDetail(detail); err != nil {
 					return false, fmt.Errorf("updating progress bar detail: %w", err)
@@ -228,7 +450,7 @@ func WaitForInstanceHealthy(
 		}
 		logger.Warn("Timeout reached. Current status:")
 		if lastStatus != nil {
-			printHealthStatus(namespace, lastStatus)
+			printHealthStatus(os.Stdout, namespace, lastStatus)
 		}
 		return fmt.Errorf("timeout waiting for instance to become healthy")
 	}
@@ -251,8 +473,10 @@ func waitForDeploymentReady(
 	}
 	desired := int(*deployment.Spec.Replicas)
 
+	showBar := callback == nil && useProgressBar(os.Stdout)
+
 	var bar *progressbar.ProgressBar
-	if callback == nil && desired > 0 {
+	if showBar && desired > 0 {
 		bar = createProgressBar(desired, fmt.Sprintf("Waiting for %s rollout", deploymentName), 0)
 	}
 
@@ -288,6 +512,8 @@ func waitForDeploymentReady(
 			if err := callback(status); err != nil {
 				return false, err
 			}
+		} else if !showBar {
+			fmt.Printf("%s rollout: %d/%d ready\n", deploymentName, ready, desired)
 		} else {
 			if bar != nil && !bar.IsFinished() {
 				if err := bar.Set(ready); err != nil {
@@ -325,7 +551,8 @@ func waitForDeploymentReady(
 	return nil
 }
 
-// waitForNamespaceDeletion waits for a namespace to be completely deleted.
+// waitForNamespaceDeletion waits for a namespace to be completely deleted, at
+// most timeout or until ctx is cancelled.
 func waitForNamespaceDeletion(
 	ctx context.Context,
 	k8sClient *client.Client,
@@ -335,8 +562,10 @@ func waitForNamespaceDeletion(
 ) error {
 	clientset := k8sClient.Clientset()
 
+	showBar := callback == nil && useProgressBar(os.Stdout)
+
 	var bar *progressbar.ProgressBar
-	if callback == nil {
+	if showBar {
 		bar = createProgressBar(-1, fmt.Sprintf("Stopping %s", namespace), 0)
 	}
 
@@ -353,6 +582,8 @@ func waitForNamespaceDeletion(
 			if err := callback(elapsed); err != nil {
 				return false, err
 			}
+		} else if !showBar {
+			fmt.Printf("Stopping %s: %ds\n", namespace, elapsed)
 		}
 
 		_, err := clientset.CoreV1().Namespaces().Get(ctx, namespace, metav1.GetOptions{})
@@ -377,7 +608,16 @@ func waitForNamespaceDeletion(
 		if bar != nil {
 			_ = bar.Finish()
 		}
-		return fmt.Errorf("timeout waiting for namespace %s to be deleted", namespace)
+		if ctx.Err() != nil {
+			return fmt.Errorf("stopped waiting for namespace %s to be deleted, the deletion continues in the cluster: %w", namespace, ctx.Err())
+		}
+		logger.Warn("Timeout reached. Namespace status:")
+		if ns, err := clientset.CoreV1().Namespaces().Get(ctx, namespace, metav1.GetOptions{}); err == nil {
+			printNamespaceStatus(os.Stdout, ns)
+		} else {
+			logger.Debug("Getting namespace %s: %v", namespace, err)
+		}
+		return fmt.Errorf("timeout waiting for namespace %s to be deleted after %v (stuck finalizers? increase --timeout)", namespace, timeout)
 	}
 	return nil
 }
@@ -394,6 +634,40 @@ func namespaceIsActive(ctx context.Context, k8sClient *client.Client, namespace
 	return ns.Status.Phase == corev1.NamespaceActive, nil
 }
 
+// printNamespaceStatus prints the phase, remaining finalizers and active
+// conditions of a namespace to w, to explain why its deletion is stuck.
+func printNamespaceStatus(w io.Writer, ns *corev1.Namespace) {
+	fmt.Fprintf(w, "\nNamespace: %s\n", ns.Name)
+	fmt.Fprintf(w, "Phase: %s\n", ns.Status.Phase)
+	if ns.DeletionTimestamp != nil {
+		fmt.Fprintf(w, "Deletion requested: %s\n", ns.DeletionTimestamp.Format(time.RFC3339))
+	}
+
+	if len(ns.Spec.Finalizers) > 0 || len(ns.Finalizers) > 0 {
+		fmt.Fprintln(w, "\nRemaining finalizers:")
+		for _, f := range ns.Spec.Finalizers {
+			fmt.Fprintf(w, "  spec: %s\n", f)
+		}
+		for _, f := range ns.Finalizers {
+			fmt.Fprintf(w, "  metadata: %s\n", f)
+		}
+	}
+
+	var active []corev1.NamespaceCondition
+	for _, c := range ns.Status.Conditions {
+		if c.Status == corev1.ConditionTrue {
+			active = append(active, c)
+		}
+	}
+	if len(active) > 0 {
+		fmt.Fprintln(w, "\nConditions:")
+		for _, c := range active {
+			fmt.Fprintf(w, "  %s %-40s %s\n", statusIcon(false), c.Type, c.Message)
+		}
+	}
+	fmt.Fprintln(w)
+}
+
 // printDeploymentStatus prints deployment rollout details to stdout.
 func printDeploymentStatus(namespace, name string, deployment *appsv1.Deployment) {
 	fmt.Printf("\nDeployment: %s (namespace: %s)\n", name, namespace)
@@ -410,10 +684,7 @@ func printDeploymentStatus(namespace, name string, deployment *appsv1.Deployment
 	if len(deployment.Status.Conditions) > 0 {
 		fmt.Println("\nConditions:")
 		for _, condition := range deployment.Status.Conditions {
-			icon := constants.IconReady
-			if condition.Status != corev1.ConditionTrue {
-				icon = constants.IconNotReady
-			}
+			icon := statusIcon(condition.Status == corev1.ConditionTrue)
 			fmt.Printf("  %s %-20s %s\n", icon, condition.Type, condition.Message)
 		}
 	}
@@ -427,8 +698,8 @@ func createProgressBar(max int, description string, maxDetailRow int) *progressb
 		progressbar.OptionSetWriter(os.Stdout),
 		progressbar.OptionSetMaxDetailRow(maxDetailRow),
 		progressbar.OptionSetTheme(progressbar.Theme{
-			Saucer:        constants.Saucer,
-			SaucerPadding: constants.SaucerPadding,
+			Saucer:        display.Saucer,
+			SaucerPadding: display.SaucerPadding,
 			BarStart:      constants.BarStart,
 			BarEnd:        constants.BarEnd,
 		}),
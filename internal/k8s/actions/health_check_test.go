@@ -1,9 +1,13 @@
 package actions
 
 import (
+	"context"
+	"errors"
 	"testing"
+	"time"
 
 	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
 func TestIsPodReady_Ready(t *testing.T) {
@@ -54,3 +58,231 @@ func TestIsPodReady_MultipleConditions(t *testing.T) {
 		t.Error("Expected pod to be ready even with multiple conditions")
 	}
 }
+
+func TestParseHealthThreshold(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    HealthThreshold
+		wantErr bool
+	}{
+		{"", HealthThreshold{}, false},
+		{"0.9", HealthThreshold{Ready: 9, Of: 10}, false},
+		{"1", HealthThreshold{Ready: 1, Of: 1}, false},
+		{"0.56", HealthThreshold{Ready: 14, Of: 25}, false},
+		{"90%", HealthThreshold{Ready: 9, Of: 10}, false},
+		{"7%", HealthThreshold{Ready: 7, Of: 100}, false},
+		{"100%", HealthThreshold{Ready: 1, Of: 1}, false},
+		{"4/5", HealthThreshold{Ready: 4, Of: 5}, false},
+		{"0%", HealthThreshold{}, true},
+		{"101%", HealthThreshold{}, true},
+		{"1e-1", HealthThreshold{}, true},
+		{"0.0000001", HealthThreshold{}, true},
+		{"0", HealthThreshold{}, true},
+		{"1.5", HealthThreshold{}, true},
+		{"6/5", HealthThreshold{}, true},
+		{"0/5", HealthThreshold{}, true},
+		{"a/5", HealthThreshold{}, true},
+		{"most", HealthThreshold{}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			got, err := ParseHealthThreshold(tt.value)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseHealthThreshold(%q) error = %v, wantErr %v", tt.value, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("ParseHealthThreshold(%q) = %+v, want %+v", tt.value, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHealthThresholdRequired_Fraction(t *testing.T) {
+	tests := []struct {
+		value       string
+		total, want int
+	}{
+		{"0.9", 10, 9},
+		{"0.9", 12, 11},
+		{"0.9", 1, 1},
+		{"0.9", 0, 0},
+		{"0.56", 25, 14},
+		{"0.07", 100, 7},
+		{"56%", 25, 14},
+		{"7%", 100, 7},
+	}
+	for _, tt := range tests {
+		threshold, err := ParseHealthThreshold(tt.value)
+		if err != nil {
+			t.Fatalf("ParseHealthThreshold(%q) error = %v", tt.value, err)
+		}
+		if got := threshold.Required(tt.total); got != tt.want {
+			t.Errorf("%s: Required(%d) = %d, want %d", tt.value, tt.total, got, tt.want)
+		}
+	}
+}
+
+func TestHealthThresholdRequired_Ratio(t *testing.T) {
+	threshold := HealthThreshold{Ready: 11, Of: 12}
+
+	tests := []struct {
+		total, want int
+	}{
+		{12, 11},
+		{24, 22},
+		{6, 6},
+		{1, 1},
+		{0, 0},
+	}
+	for _, tt := range tests {
+		if got := threshold.Required(tt.total); got != tt.want {
+			t.Errorf("Required(%d) = %d, want %d", tt.total, got, tt.want)
+		}
+	}
+}
+
+func TestHealthThresholdRequired_Default(t *testing.T) {
+	if got := (HealthThreshold{}).Required(12); got != 12 {
+		t.Errorf("Expected all pods required, got %d", got)
+	}
+}
+
+func probeTestPod(name string, ready bool, ports ...corev1.ContainerPort) corev1.Pod {
+	status := corev1.ConditionFalse
+	if ready {
+		status = corev1.ConditionTrue
+	}
+	return corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Spec:       corev1.PodSpec{Containers: []corev1.Container{{Name: name, Ports: ports}}},
+		Status: corev1.PodStatus{
+			Conditions: []corev1.PodCondition{{Type: corev1.PodReady, Status: status}},
+		},
+	}
+}
+
+func TestHTTPPort(t *testing.T) {
+	tests := []struct {
+		name  string
+		ports []corev1.ContainerPort
+		want  int32
+	}{
+		{"named http", []corev1.ContainerPort{{Name: "metrics", ContainerPort: 9090}, {Name: "http", ContainerPort: 9002}}, 9002},
+		{"unnamed", []corev1.ContainerPort{{ContainerPort: 9002}}, 0},
+		{"udp", []corev1.ContainerPort{{Name: "http", ContainerPort: 9002, Protocol: corev1.ProtocolUDP}}, 0},
+		{"no ports", nil, 0},
+	}
+
+	for _, tt := range tests {
+		pod := probeTestPod("backend", true, tt.ports...)
+		if got := httpPort(&pod); got != tt.want {
+			t.Errorf("%s: httpPort() = %d, want %d", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestProbeReadyPods(t *testing.T) {
+	httpPort := corev1.ContainerPort{Name: "http", ContainerPort: 9002}
+	pods := []corev1.Pod{
+		probeTestPod("backend", true, httpPort),
+		probeTestPod("search", true, httpPort),
+		probeTestPod("media", false, httpPort),
+		probeTestPod("redis", true),
+	}
+
+	var probed []string
+	failures := probeReadyPods(pods, func(pod *corev1.Pod, port int32) error {
+		probed = append(probed, pod.Name)
+		if port != 9002 {
+			t.Errorf("Expected port 9002, got %d", port)
+		}
+		if pod.Name == "search" {
+			return errors.New("status 503")
+		}
+		return nil
+	})
+
+	if len(probed) != 2 || probed[0] != "backend" || probed[1] != "search" {
+		t.Errorf("Expected only ready pods with http port to be probed, got %v", probed)
+	}
+	if len(failures) != 1 || failures["search"] != "status 503" {
+		t.Errorf("Expected failure for search, got %v", failures)
+	}
+
+	status := &HealthStatus{Pods: pods, ProbeFailures: failures}
+	if !status.podHealthy(&pods[0]) || status.podHealthy(&pods[1]) || status.podHealthy(&pods[2]) || !status.podHealthy(&pods[3]) {
+		t.Errorf("Unexpected pod health with probe failures %v", failures)
+	}
+	if names := getNotReadyNames(status); len(names) != 2 || names[0] != "search" || names[1] != "media" {
+		t.Errorf("Expected search and media not ready, got %v", names)
+	}
+}
+
+func TestWatchHealth_UntilHealthy(t *testing.T) {
+	calls := 0
+	renders := 0
+	err := watchHealth(context.Background(), time.Millisecond, true, func() (*HealthStatus, error) {
+		calls++
+		switch calls {
+		case 1:
+			return nil, errors.New("connection refused")
+		case 2:
+			return &HealthStatus{Ready: 1, Total: 2}, nil
+		default:
+			return &HealthStatus{Healthy: true, Ready: 2, Total: 2}, nil
+		}
+	}, func(status *HealthStatus, err error) {
+		renders++
+	})
+
+	if err != nil {
+		t.Fatalf("watchHealth() error = %v", err)
+	}
+	if calls != 3 || renders != 3 {
+		t.Errorf("Expected 3 status checks and renders, got %d and %d", calls, renders)
+	}
+}
+
+func TestWatchHealth_RunsUntilCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	calls := 0
+	err := watchHealth(ctx, time.Millisecond, false, func() (*HealthStatus, error) {
+		calls++
+		if calls == 3 {
+			cancel()
+		}
+		return &HealthStatus{Healthy: true}, nil
+	}, func(*HealthStatus, error) {})
+
+	if err == nil || !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected cancellation error, got %v", err)
+	}
+	if calls != 3 {
+		t.Errorf("Expected watch to continue while healthy until cancelled, got %d checks", calls)
+	}
+}
+
+func TestPollUntil_Timeout(t *testing.T) {
+	err := pollUntil(context.Background(), time.Millisecond, 10*time.Millisecond, func() (bool, error) {
+		return false, nil
+	})
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected deadline exceeded, got %v", err)
+	}
+}
+
+func TestPollUntil_Cancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	calls := 0
+	err := pollUntil(ctx, time.Millisecond, time.Minute, func() (bool, error) {
+		calls++
+		if calls == 2 {
+			cancel()
+		}
+		return false, nil
+	})
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected cancellation, got %v", err)
+	}
+}
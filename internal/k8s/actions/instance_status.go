@@ -37,14 +37,20 @@ func GetInstanceStatusCmd() *cobra.Command {
 	cmd.RunE = func(cmd *cobra.Command, args []string) error {
 		logger.Info("=== K8S INSTANCE STATUS ===")
 		instanceDir := args[0]
-		namespace := utils.ExtractNamespace(instanceDir)
+		namespace, err := utils.ResolveNamespace(instanceDir)
+		if err != nil {
+			return err
+		}
 
 		k8sClient, err := client.New(*kubeconfig)
 		if err != nil {
 			return fmt.Errorf("creating k8s client: %w", err)
 		}
 
-		status, err := GetInstanceStatus(context.Background(), k8sClient, namespace)
+		ctx, cancel := utils.TimeoutContext(cmd, 0)
+		defer cancel()
+
+		status, err := GetInstanceStatus(ctx, k8sClient, namespace)
 		if err != nil {
 			return fmt.Errorf("getting instance status: %w", err)
 		}
@@ -23,7 +23,8 @@ Note: You must edit the deployment file to change the replica count before runni
 Examples:
   osmanage k8s scale ./my.instance.dir.org --service backendmanage
   osmanage k8s scale ./my.instance.dir.org --service autoupdate --skip-ready-check
-  osmanage k8s scale ./my.instance.dir.org --service search --kubeconfig ~/.kube/config --timeout 30s`
+  osmanage k8s scale ./my.instance.dir.org --service search --kubeconfig ~/.kube/config --timeout 30s
+  osmanage k8s scale ./my.instance.dir.org --service backend --field-manager osmanage-staging --no-force`
 )
 
 func ScaleCmd() *cobra.Command {
@@ -38,6 +39,8 @@ func ScaleCmd() *cobra.Command {
 	kubeconfig := cmd.Flags().String("kubeconfig", "", "Path to kubeconfig file")
 	skipReadyCheck := cmd.Flags().Bool("skip-ready-check", false, "Skip waiting for deployment to become ready")
 	timeout := cmd.Flags().Duration("timeout", constants.DefaultDeploymentTimeout, "Timeout for deployment rollout check")
+	manager := cmd.Flags().String("field-manager", fieldManager, "Field manager name for server-side apply")
+	noForce := cmd.Flags().Bool("no-force", false, "Fail on fields owned by other field managers instead of taking them over")
 
 	_ = cmd.MarkFlagRequired("service")
 
@@ -45,6 +48,9 @@ func ScaleCmd() *cobra.Command {
 		if strings.TrimSpace(*service) == "" {
 			return fmt.Errorf("--service cannot be empty")
 		}
+		if strings.TrimSpace(*manager) == "" {
+			return fmt.Errorf("--field-manager cannot be empty")
+		}
 
 		logger.Info("=== K8S SCALE SERVICE ===")
 		instanceDir := args[0]
@@ -55,7 +61,8 @@ func ScaleCmd() *cobra.Command {
 			return fmt.Errorf("creating k8s client: %w", err)
 		}
 
-		if err := ScaleService(context.Background(), k8sClient, *service, instanceDir, *skipReadyCheck, *timeout, nil); err != nil {
+		applyOpts := ApplyOptions{FieldManager: *manager, NoForce: *noForce}
+		if err := ScaleService(context.Background(), k8sClient, *service, instanceDir, *skipReadyCheck, *timeout, applyOpts, nil); err != nil {
 			return err
 		}
 
@@ -66,9 +73,12 @@ func ScaleCmd() *cobra.Command {
 	return cmd
 }
 
-// ScaleService applies the deployment manifest for a service and optionally waits for rollout.
-func ScaleService(ctx context.Context, k8sClient *client.Client, service, instanceDir string, skipReadyCheck bool, timeout time.Duration, callback func(*DeploymentStatus) error) error {
-	namespace := utils.ExtractNamespace(instanceDir)
+// ScaleService applies the deployment manifest for a service with applyOpts and optionally waits for rollout.
+func ScaleService(ctx context.Context, k8sClient *client.Client, service, instanceDir string, skipReadyCheck bool, timeout time.Duration, applyOpts ApplyOptions, callback func(*DeploymentStatus) error) error {
+	namespace, err := utils.ResolveNamespace(instanceDir)
+	if err != nil {
+		return err
+	}
 	logger.Info("Service: %s", service)
 	logger.Info("Namespace: %s", namespace)
 
@@ -76,7 +86,7 @@ func ScaleService(ctx context.Context, k8sClient *client.Client, service, instan
 	deploymentPath := filepath.Join(instanceDir, constants.StackDirName, deploymentFile)
 
 	logger.Info("Applying deployment manifest: %s", deploymentPath)
-	if _, _, err := applyManifest(ctx, k8sClient, deploymentPath, nil); err != nil {
+	if _, _, err := applyManifest(ctx, k8sClient, deploymentPath, nil, nil, nil, applyOpts); err != nil {
 		return fmt.Errorf("applying deployment: %w", err)
 	}
 
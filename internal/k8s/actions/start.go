@@ -4,6 +4,9 @@ import (
 	"context"
 	"fmt"
 	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
 	"time"
 
 	"github.com/OpenSlides/openslides-cli/internal/constants"
@@ -11,17 +14,35 @@ import (
 	"github.com/OpenSlides/openslides-cli/internal/logger"
 	"github.com/OpenSlides/openslides-cli/internal/utils"
 	"github.com/spf13/cobra"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
 )
 
 const (
 	StartHelp      = "Start an OpenSlides instance"
 	StartHelpExtra = `Applies Kubernetes manifests to start an OpenSlides instance.
 
+With --prune, every applied resource is labeled app.kubernetes.io/managed-by=osmanage
+and resources in the namespace that carry this label but are no longer part of
+the instance directory are deleted after applying.
+
+Resources are applied server-side as field manager "osmanage", taking over
+fields owned by other managers. Use --field-manager and --no-force to coexist
+with GitOps tools: conflicting fields then fail the apply and are reported.
+
 Examples:
   osmanage k8s start ./my.instance.dir.org
   osmanage k8s start ./my.instance.dir.org --skip-ready-check
   osmanage k8s start ./my.instance.dir.org --kubeconfig ~/.kube/config --timeout 30s
-  osmanage k8s start ./my.instance.dir.org --labels osinstance/examplelabel=true,osinstance/examplelabel2=10`
+  osmanage k8s start ./my.instance.dir.org --timeout 15m --wait-timeout search=5m
+  osmanage k8s start ./my.instance.dir.org --labels osinstance/examplelabel=true,osinstance/examplelabel2=10
+  osmanage k8s start ./my.instance.dir.org --set-image backend=myreg/openslides-backend:dev
+  osmanage k8s start ./my.instance.dir.org --health-threshold 0.9
+  osmanage k8s start ./my.instance.dir.org --instance-label
+  osmanage k8s start ./my.instance.dir.org --instance-label=example.org/instance
+  osmanage k8s start ./my.instance.dir.org --prune
+  osmanage k8s start ./my.instance.dir.org --field-manager osmanage-staging --no-force`
 )
 
 func StartCmd() *cobra.Command {
@@ -34,20 +55,63 @@ func StartCmd() *cobra.Command {
 
 	kubeconfig := cmd.Flags().String("kubeconfig", "", "Path to kubeconfig file")
 	skipReadyCheck := cmd.Flags().Bool("skip-ready-check", false, "Skip waiting for instance to become ready")
-	timeout := cmd.Flags().Duration("timeout", constants.DefaultInstanceTimeout, "Timeout for instance health check")
+	timeout := cmd.Flags().Duration("timeout", constants.DefaultInstanceTimeout, "Timeout for the deployment rollout and instance health check together")
 	labels := cmd.Flags().StringToString("labels", nil, "Label selector to filter resources, e.g. 'osinstance/migrate=true'")
+	waitTimeouts := cmd.Flags().StringToString("wait-timeout", nil, "Per-deployment rollout timeout within --timeout, e.g. 'backendmanage=10m'")
+	healthThreshold := cmd.Flags().String("health-threshold", "", "Minimum share of ready pods to count as healthy, as fraction '0.9', percentage '90%' or ratio 'N/M' (default: all)")
+	setImages := cmd.Flags().StringToString("set-image", nil, "Override container images at apply time, e.g. 'backend=myreg/openslides-backend:dev'")
+	instanceLabel := cmd.Flags().String("instance-label", "", "Label key set to the namespace on all applied resources (without value: "+constants.DefaultInstanceLabel+")")
+	cmd.Flags().Lookup("instance-label").NoOptDefVal = constants.DefaultInstanceLabel
+	prune := cmd.Flags().Bool("prune", false, "Label applied resources as managed by osmanage and delete labeled resources missing from the instance directory")
+	manager := cmd.Flags().String("field-manager", fieldManager, "Field manager name for server-side apply")
+	noForce := cmd.Flags().Bool("no-force", false, "Fail on fields owned by other field managers instead of taking them over")
 
 	cmd.RunE = func(cmd *cobra.Command, args []string) error {
 		logger.Info("=== K8S START INSTANCE ===")
 		instanceDir := args[0]
 		logger.Debug("Instance directory: %s", instanceDir)
 
+		deploymentTimeouts, err := parseDeploymentTimeouts(*waitTimeouts)
+		if err != nil {
+			return err
+		}
+		if err := validateImageOverrides(*setImages); err != nil {
+			return err
+		}
+		threshold, err := ParseHealthThreshold(*healthThreshold)
+		if err != nil {
+			return err
+		}
+		if *prune && len(*labels) > 0 {
+			return fmt.Errorf("--prune cannot be combined with --labels, resources filtered out would be deleted")
+		}
+		if *instanceLabel != "" {
+			if errs := validation.IsQualifiedName(*instanceLabel); len(errs) > 0 {
+				return fmt.Errorf("invalid --instance-label %q: %s", *instanceLabel, strings.Join(errs, "; "))
+			}
+		}
+
+		if strings.TrimSpace(*manager) == "" {
+			return fmt.Errorf("--field-manager cannot be empty")
+		}
+
 		k8sClient, err := client.New(*kubeconfig)
 		if err != nil {
 			return fmt.Errorf("creating k8s client: %w", err)
 		}
 
-		if err := StartInstance(context.Background(), k8sClient, instanceDir, *skipReadyCheck, *timeout, *labels, nil); err != nil {
+		opts := StartOptions{
+			SkipReadyCheck:     *skipReadyCheck,
+			Timeout:            *timeout,
+			DeploymentTimeouts: deploymentTimeouts,
+			Labels:             *labels,
+			ImageOverrides:     *setImages,
+			InstanceLabel:      *instanceLabel,
+			Prune:              *prune,
+			Apply:              ApplyOptions{FieldManager: *manager, NoForce: *noForce},
+			Threshold:          threshold,
+		}
+		if err := StartInstance(context.Background(), k8sClient, instanceDir, opts, nil); err != nil {
 			return err
 		}
 
@@ -58,43 +122,202 @@ func StartCmd() *cobra.Command {
 	return cmd
 }
 
+// StartOptions configures StartInstance
+type StartOptions struct {
+	SkipReadyCheck     bool                     // return after applying without waiting for the instance
+	Timeout            time.Duration            // maximum time to wait for the rollout and for the instance to become healthy
+	DeploymentTimeouts map[string]time.Duration // per-deployment rollout timeouts within Timeout
+	Labels             map[string]string        // label selector, only matching resources are applied
+	ImageOverrides     map[string]string        // container name to image replacing the one in the manifests
+	InstanceLabel      string                   // label key set to the namespace on all applied resources, none if empty
+	Prune              bool                     // delete managed resources missing from the instance directory
+	Apply              ApplyOptions             // field manager and conflict handling of server-side apply
+	Threshold          HealthThreshold          // share of ready pods needed for the instance to count as healthy
+}
+
 // StartInstance applies namespace, optional TLS secret, and stack manifests,
-// then optionally waits for all pods to become healthy.
-func StartInstance(ctx context.Context, k8sClient *client.Client, instanceDir string, skipReadyCheck bool, timeout time.Duration, labels map[string]string, callback func(*HealthStatus) error) error {
+// then optionally waits for all pods to become healthy. In CLI mode (callback
+// is nil) or if opts.DeploymentTimeouts is non-empty, every deployment is first
+// awaited individually with its own rollout progress bar.
+// With opts.Prune, applied resources are labeled with constants.ManagedByLabel and labeled
+// resources in the namespace that were not applied are deleted afterwards.
+func StartInstance(ctx context.Context, k8sClient *client.Client, instanceDir string, opts StartOptions, callback func(*HealthStatus) error) error {
 	namespacePath := filepath.Join(instanceDir, constants.NamespaceYAML)
-	_, namespace, err := applyManifest(ctx, k8sClient, namespacePath, nil)
+
+	namespace, err := utils.ResolveNamespace(instanceDir)
+	if err != nil {
+		return err
+	}
+
+	instanceLabels := map[string]string{}
+	if opts.InstanceLabel != "" {
+		instanceLabels[opts.InstanceLabel] = namespace
+		logger.Debug("Labeling resources with %s=%s", opts.InstanceLabel, namespace)
+	}
+	if opts.Prune {
+		instanceLabels[constants.ManagedByLabel] = constants.ManagedByValue
+	}
+
+	_, namespace, err = applyManifest(ctx, k8sClient, namespacePath, nil, nil, instanceLabels, opts.Apply)
 	if err != nil {
 		return fmt.Errorf("applying namespace: %w", err)
 	}
 	logger.Info("Applied namespace: %s", namespace)
 
-	tlsSecretPath := filepath.Join(instanceDir, constants.SecretsDirName, constants.TlsCertSecretYAML)
-	tlsExists, err := utils.FileExists(tlsSecretPath)
+	var applied []resourceKey
+	secretPaths, err := savedSecretPaths(instanceDir)
 	if err != nil {
-		return fmt.Errorf("checking tls secret path %s: %w", tlsSecretPath, err)
+		return err
 	}
-	if tlsExists {
-		logger.Info("Found and applying %s", tlsSecretPath)
-		if _, _, err := applyManifest(ctx, k8sClient, tlsSecretPath, nil); err != nil {
-			return fmt.Errorf("applying TLS secret: %w", err)
+	for _, secretPath := range secretPaths {
+		logger.Info("Found and applying %s", secretPath)
+		key, _, err := applyManifest(ctx, k8sClient, secretPath, nil, nil, instanceLabels, opts.Apply)
+		if err != nil {
+			return fmt.Errorf("applying secret %s: %w", filepath.Base(secretPath), err)
+		}
+		if key != nil {
+			applied = append(applied, *key)
 		}
 	}
 
 	stackDir := filepath.Join(instanceDir, constants.StackDirName)
+	if len(opts.ImageOverrides) > 0 {
+		warnUnknownContainers(stackDir, opts.ImageOverrides)
+	}
+
 	logger.Info("Applying stack manifests from: %s", stackDir)
-	if _, err := applyDirectory(ctx, k8sClient, stackDir, labels); err != nil {
+	stackApplied, failed, err := applyDirectory(ctx, k8sClient, stackDir, opts.Labels, DeploymentFilter{}, opts.ImageOverrides, instanceLabels, opts.Apply)
+	if err != nil {
 		return fmt.Errorf("applying stack: %w", err)
 	}
+	applied = append(applied, stackApplied...)
 
-	if skipReadyCheck {
+	if opts.Prune {
+		if reason := pruneSkipReason(failed, DeploymentFilter{}); reason != "" {
+			logger.Warn("%s, skipping pruning", reason)
+		} else if err := pruneManaged(ctx, k8sClient, namespace, applied, opts.Apply.Manager()); err != nil {
+			logger.Warn("Failed to prune removed resources: %v", err)
+		}
+	}
+
+	if opts.SkipReadyCheck {
 		logger.Info("Skipping ready check")
 		return nil
 	}
 
+	// The rollout and the health check share one deadline
+	deadline := time.Now().Add(opts.Timeout)
+	if callback == nil || len(opts.DeploymentTimeouts) > 0 {
+		logger.Info("Waiting for deployments to roll out...")
+		if err := waitForDeployments(ctx, k8sClient, namespace, opts.Timeout, opts.DeploymentTimeouts); err != nil {
+			return fmt.Errorf("waiting for deployments: %w", err)
+		}
+	}
+
+	remaining := time.Until(deadline)
+	if remaining <= 0 {
+		return fmt.Errorf("waiting for ready: timeout after %v", opts.Timeout)
+	}
 	logger.Info("Waiting for instance to become ready...")
-	if err := WaitForInstanceHealthy(ctx, k8sClient, namespace, timeout, callback); err != nil {
+	if err := WaitForInstanceHealthy(ctx, k8sClient, namespace, remaining, opts.Threshold, "", callback); err != nil {
 		return fmt.Errorf("waiting for ready: %w", err)
 	}
 
 	return nil
 }
+
+// savedSecretPaths returns the secret manifests in the secrets directory of
+// the instance, like the TLS secret and those saved by k8s stop, sorted by name
+func savedSecretPaths(instanceDir string) ([]string, error) {
+	pattern := filepath.Join(instanceDir, constants.SecretsDirName, "*"+constants.SavedSecretSuffix)
+	paths, err := filepath.Glob(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("finding secret manifests: %w", err)
+	}
+	return paths, nil
+}
+
+// parseDeploymentTimeouts converts the service=duration pairs of --wait-timeout
+// into a map of deployment name to timeout.
+func parseDeploymentTimeouts(raw map[string]string) (map[string]time.Duration, error) {
+	timeouts := make(map[string]time.Duration, len(raw))
+	for name, value := range raw {
+		d, err := time.ParseDuration(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid --wait-timeout for %s: %w", name, err)
+		}
+		if d <= 0 {
+			return nil, fmt.Errorf("invalid --wait-timeout for %s: must be positive, got %v", name, d)
+		}
+		timeouts[name] = d
+	}
+	return timeouts, nil
+}
+
+// validateImageOverrides checks that every --set-image entry has a container name and an image
+func validateImageOverrides(images map[string]string) error {
+	for name, image := range images {
+		if strings.TrimSpace(name) == "" || strings.TrimSpace(image) == "" {
+			return fmt.Errorf("invalid --set-image %s=%s: expected container=image", name, image)
+		}
+	}
+	return nil
+}
+
+// warnUnknownContainers warns about image overrides that match no container of
+// the deployments in stackDir
+func warnUnknownContainers(stackDir string, images map[string]string) {
+	names, err := deploymentContainerNames(stackDir)
+	if err != nil {
+		logger.Debug("Could not check image overrides: %v", err)
+		return
+	}
+	for name := range images {
+		if !names[name] {
+			logger.Warn("--set-image %s matches no container", name)
+		}
+	}
+}
+
+// waitForDeployments waits for the rollout of every deployment in the namespace,
+// all together at most defaultTimeout. Deployments listed in timeouts wait at
+// most their own timeout within that deadline.
+func waitForDeployments(ctx context.Context, k8sClient *client.Client, namespace string, defaultTimeout time.Duration, timeouts map[string]time.Duration) error {
+	deployments, err := k8sClient.Clientset().AppsV1().Deployments(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return fmt.Errorf("listing deployments: %w", err)
+	}
+
+	names := make([]string, 0, len(deployments.Items))
+	for _, d := range deployments.Items {
+		names = append(names, d.Name)
+	}
+	sort.Strings(names)
+
+	for name := range timeouts {
+		if !slices.Contains(names, name) {
+			logger.Warn("--wait-timeout given for unknown deployment %s in namespace %s", name, namespace)
+		}
+	}
+
+	deadline := time.Now().Add(defaultTimeout)
+	for _, name := range names {
+		timeout := deploymentTimeout(time.Until(deadline), timeouts[name])
+		if timeout <= 0 {
+			return fmt.Errorf("timeout after %v waiting for deployment rollouts, %s not ready", defaultTimeout, name)
+		}
+		if err := waitForDeploymentReady(ctx, k8sClient, namespace, name, timeout, nil); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// deploymentTimeout returns how long to wait for a single deployment: the time
+// remaining until the shared deadline, capped by the deployment's own timeout if set.
+func deploymentTimeout(remaining, own time.Duration) time.Duration {
+	if own > 0 && own < remaining {
+		return own
+	}
+	return remaining
+}
@@ -0,0 +1,251 @@
+package actions
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/OpenSlides/openslides-cli/internal/constants"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestParseDeploymentTimeouts_Valid(t *testing.T) {
+	timeouts, err := parseDeploymentTimeouts(map[string]string{
+		"backendmanage": "10m",
+		"search":        "90s",
+	})
+	if err != nil {
+		t.Fatalf("parseDeploymentTimeouts() error = %v", err)
+	}
+
+	if timeouts["backendmanage"] != 10*time.Minute {
+		t.Errorf("Expected backendmanage timeout 10m, got %v", timeouts["backendmanage"])
+	}
+	if timeouts["search"] != 90*time.Second {
+		t.Errorf("Expected search timeout 90s, got %v", timeouts["search"])
+	}
+}
+
+func TestParseDeploymentTimeouts_Empty(t *testing.T) {
+	timeouts, err := parseDeploymentTimeouts(nil)
+	if err != nil {
+		t.Fatalf("parseDeploymentTimeouts() error = %v", err)
+	}
+	if len(timeouts) != 0 {
+		t.Errorf("Expected no timeouts, got %v", timeouts)
+	}
+}
+
+func TestParseDeploymentTimeouts_Invalid(t *testing.T) {
+	for _, value := range []string{"ten minutes", "", "0s", "-1m"} {
+		if _, err := parseDeploymentTimeouts(map[string]string{"backendmanage": value}); err == nil {
+			t.Errorf("Expected error for timeout %q", value)
+		}
+	}
+}
+
+func TestDeploymentTimeout(t *testing.T) {
+	tests := []struct {
+		name      string
+		remaining time.Duration
+		own       time.Duration
+		want      time.Duration
+	}{
+		{"no own timeout", 3 * time.Minute, 0, 3 * time.Minute},
+		{"own timeout within deadline", 3 * time.Minute, time.Minute, time.Minute},
+		{"own timeout capped by deadline", 2 * time.Minute, 10 * time.Minute, 2 * time.Minute},
+		{"deadline passed", -time.Second, time.Minute, -time.Second},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := deploymentTimeout(tt.remaining, tt.own); got != tt.want {
+				t.Errorf("deploymentTimeout(%v, %v) = %v, want %v", tt.remaining, tt.own, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestOverrideImages(t *testing.T) {
+	obj := &unstructured.Unstructured{Object: map[string]any{
+		"kind": "Deployment",
+		"spec": map[string]any{
+			"template": map[string]any{
+				"spec": map[string]any{
+					"containers": []any{
+						map[string]any{"name": "backend", "image": "openslides/openslides-backend:latest"},
+						map[string]any{"name": "sidecar", "image": "busybox:latest"},
+					},
+				},
+			},
+		},
+	}}
+
+	overridden, err := overrideImages(obj, map[string]string{"backend": "myreg/openslides-backend:dev"})
+	if err != nil {
+		t.Fatalf("overrideImages() error = %v", err)
+	}
+	if len(overridden) != 1 || overridden[0] != "backend" {
+		t.Errorf("Expected [backend] overridden, got %v", overridden)
+	}
+
+	containers, _, _ := unstructured.NestedSlice(obj.Object, "spec", "template", "spec", "containers")
+	images := map[string]string{}
+	for _, c := range containers {
+		container := c.(map[string]any)
+		images[container["name"].(string)] = container["image"].(string)
+	}
+	if images["backend"] != "myreg/openslides-backend:dev" {
+		t.Errorf("Expected backend image to be overridden, got %q", images["backend"])
+	}
+	if images["sidecar"] != "busybox:latest" {
+		t.Errorf("Expected sidecar image unchanged, got %q", images["sidecar"])
+	}
+}
+
+func TestValidateImageOverrides(t *testing.T) {
+	if err := validateImageOverrides(map[string]string{"backend": "myreg/openslides-backend:dev"}); err != nil {
+		t.Errorf("validateImageOverrides() error = %v", err)
+	}
+	if err := validateImageOverrides(map[string]string{"backend": ""}); err == nil {
+		t.Error("Expected error for empty image")
+	}
+}
+
+func TestSetLabels(t *testing.T) {
+	dir := t.TempDir()
+	writeDeploymentManifest(t, dir, "backend", "registry/backend:4.2.0")
+	if err := os.WriteFile(filepath.Join(dir, "service.yaml"), []byte("apiVersion: v1\nkind: Service\nmetadata:\n  name: backend\n  labels:\n    app: backend\n"), 0644); err != nil {
+		t.Fatalf("writing manifest: %v", err)
+	}
+
+	instanceLabels := map[string]string{constants.DefaultInstanceLabel: "myinstance"}
+	for _, file := range []string{"backend.yaml", "service.yaml"} {
+		obj, err := readManifest(filepath.Join(dir, file))
+		if err != nil {
+			t.Fatalf("readManifest(%s) error = %v", file, err)
+		}
+
+		setLabels(obj, instanceLabels)
+
+		labels := obj.GetLabels()
+		if labels[constants.DefaultInstanceLabel] != "myinstance" {
+			t.Errorf("%s: expected instance label, got %v", file, labels)
+		}
+		if file == "service.yaml" && labels["app"] != "backend" {
+			t.Errorf("%s: expected existing labels to be kept, got %v", file, labels)
+		}
+	}
+}
+
+func TestSetLabels_Empty(t *testing.T) {
+	obj := &unstructured.Unstructured{Object: map[string]any{"kind": "Service", "metadata": map[string]any{"name": "backend"}}}
+
+	setLabels(obj, nil)
+	if _, found, _ := unstructured.NestedMap(obj.Object, "metadata", "labels"); found {
+		t.Error("Expected no labels to be added")
+	}
+}
+
+func TestAppliedBy(t *testing.T) {
+	obj := &unstructured.Unstructured{}
+	obj.SetManagedFields([]metav1.ManagedFieldsEntry{{Manager: "kube-controller-manager"}})
+	if appliedBy(obj, fieldManager) {
+		t.Error("Expected object managed by the controller manager only not to count as applied by osmanage")
+	}
+
+	obj.SetManagedFields(append(obj.GetManagedFields(), metav1.ManagedFieldsEntry{Manager: fieldManager, Operation: metav1.ManagedFieldsOperationApply}))
+	if !appliedBy(obj, fieldManager) {
+		t.Error("Expected object with osmanage field manager to count as applied by osmanage")
+	}
+	if appliedBy(obj, "gitops") {
+		t.Error("Expected object not to count as applied by a different field manager")
+	}
+}
+
+func TestStartCmd_PruneWithLabels(t *testing.T) {
+	cmd := StartCmd()
+	cmd.SetArgs([]string{t.TempDir(), "--prune", "--labels", "osinstance/migrate=true"})
+	cmd.SetOut(io.Discard)
+	cmd.SetErr(io.Discard)
+	err := cmd.Execute()
+	if err == nil || !strings.Contains(err.Error(), "--prune cannot be combined with --labels") {
+		t.Errorf("Expected --prune/--labels error, got %v", err)
+	}
+}
+
+func TestStartCmd_EmptyFieldManager(t *testing.T) {
+	cmd := StartCmd()
+	cmd.SetArgs([]string{t.TempDir(), "--field-manager", " "})
+	cmd.SetOut(io.Discard)
+	cmd.SetErr(io.Discard)
+	err := cmd.Execute()
+	if err == nil || !strings.Contains(err.Error(), "--field-manager cannot be empty") {
+		t.Errorf("Expected empty field manager error, got %v", err)
+	}
+}
+
+func TestApplyOptions(t *testing.T) {
+	opts := ApplyOptions{}.apiOptions()
+	if opts.FieldManager != fieldManager || !opts.Force {
+		t.Errorf("Expected zero value to force apply as %s, got %+v", fieldManager, opts)
+	}
+
+	opts = ApplyOptions{FieldManager: "gitops", NoForce: true}.apiOptions()
+	if opts.FieldManager != "gitops" || opts.Force {
+		t.Errorf("Expected non-forced apply as gitops, got %+v", opts)
+	}
+}
+
+func TestApplyError_Conflict(t *testing.T) {
+	obj := &unstructured.Unstructured{}
+	obj.SetKind("Deployment")
+	obj.SetName("backend")
+
+	conflict := apierrors.NewApplyConflict([]metav1.StatusCause{
+		{Type: metav1.CauseTypeFieldManagerConflict, Message: `conflict with "argocd"`, Field: ".spec.replicas"},
+		{Type: metav1.CauseTypeFieldManagerConflict, Message: `conflict with "argocd"`, Field: ".spec.template.spec.containers[name=\"backend\"].image"},
+	}, "Apply failed with 2 conflicts")
+
+	err := applyError(obj, conflict)
+	if !apierrors.IsConflict(err) {
+		t.Errorf("Expected wrapped conflict error, got %v", err)
+	}
+	msg := err.Error()
+	for _, want := range []string{"Deployment/backend", ".spec.replicas", "--no-force"} {
+		if !strings.Contains(msg, want) {
+			t.Errorf("Expected %q in error, got %s", want, msg)
+		}
+	}
+
+	err = applyError(obj, apierrors.NewBadRequest("invalid"))
+	if strings.Contains(err.Error(), "field manager") {
+		t.Errorf("Expected plain error for non-conflicts, got %s", err)
+	}
+}
+
+func TestSavedSecretPaths(t *testing.T) {
+	dir := t.TempDir()
+	secretsDir := filepath.Join(dir, constants.SecretsDirName)
+	if err := os.MkdirAll(secretsDir, constants.SecretsDirPerm); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"smtp-secret.yaml", constants.TlsCertSecretYAML, "auth_token_key", "notes.yaml"} {
+		if err := os.WriteFile(filepath.Join(secretsDir, name), []byte("x"), constants.SecretFilePerm); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	paths, err := savedSecretPaths(dir)
+	if err != nil {
+		t.Fatalf("savedSecretPaths() error = %v", err)
+	}
+	want := []string{filepath.Join(secretsDir, "smtp-secret.yaml"), filepath.Join(secretsDir, constants.TlsCertSecretYAML)}
+	if len(paths) != 2 || paths[0] != want[0] || paths[1] != want[1] {
+		t.Errorf("savedSecretPaths() = %v, want %v", paths, want)
+	}
+}
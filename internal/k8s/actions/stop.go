@@ -4,7 +4,11 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"os/signal"
 	"path/filepath"
+	"slices"
+	"strings"
+	"syscall"
 	"time"
 
 	"github.com/OpenSlides/openslides-cli/internal/constants"
@@ -13,17 +17,39 @@ import (
 	"github.com/OpenSlides/openslides-cli/internal/utils"
 	"github.com/spf13/cobra"
 
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
 	"sigs.k8s.io/yaml"
 )
 
 const (
 	StopHelp      = "Stop an OpenSlides instance"
 	StopHelpExtra = `Stops an OpenSlides instance by deleting its Kubernetes namespace.
-If a TLS certificate secret exists, it will be saved before deletion.
+If a TLS certificate secret exists, it will be saved before deletion, as well
+as secrets labeled osmanage/save-secret=true and those given with --save-secret.
+They are written to the secrets directory as <name>-secret.yaml and applied
+again by k8s start. If saving fails or a secret given with --save-secret does
+not exist, the namespace is not deleted unless --force is given.
+
+On an interactive terminal you are asked to type the namespace name to confirm,
+use --force to skip the confirmation.
+
+The command waits until the namespace is gone, at most --timeout
+(default 5m). Namespaces with slow finalizers may need a longer timeout.
+Ctrl-C stops waiting, the deletion itself continues in the cluster.
+On timeout, the remaining finalizers and conditions of the namespace are shown.
+
+As a last resort, --force-finalize removes the spec finalizers of a namespace
+that is still terminating after the timeout. This skips the cleanup Kubernetes
+would have done and can leave orphaned resources behind.
 
 Examples:
-  osmanage k8s stop ./my.instance.dir.org --kubeconfig ~/.kube/config`
+  osmanage k8s stop ./my.instance.dir.org --kubeconfig ~/.kube/config
+  osmanage k8s stop ./my.instance.dir.org --force
+  osmanage k8s stop ./my.instance.dir.org --save-secret oidc-client,smtp-credentials
+  osmanage k8s stop ./my.instance.dir.org --timeout 15m
+  osmanage k8s stop ./my.instance.dir.org --force-finalize`
 )
 
 func StopCmd() *cobra.Command {
@@ -35,7 +61,10 @@ func StopCmd() *cobra.Command {
 	}
 
 	kubeconfig := cmd.Flags().String("kubeconfig", "", "Path to kubeconfig file")
-	timeout := cmd.Flags().Duration("timeout", constants.DefaultNamespaceTimeout, "timeout for namespace deletion")
+	timeout := cmd.Flags().Duration("timeout", constants.DefaultNamespaceTimeout, "Timeout for namespace deletion")
+	force := cmd.Flags().BoolP("force", "f", false, "Skip confirmation prompt and delete the namespace even if secrets could not be saved")
+	saveSecretNames := cmd.Flags().StringSlice("save-secret", nil, "Additional secrets to save to the secrets directory before deletion")
+	forceFinalize := cmd.Flags().Bool("force-finalize", false, "Remove the namespace finalizers if it is still terminating after the timeout (last resort)")
 
 	cmd.RunE = func(cmd *cobra.Command, args []string) error {
 		logger.Info("=== K8S STOP INSTANCE ===")
@@ -43,12 +72,31 @@ func StopCmd() *cobra.Command {
 
 		logger.Debug("Instance directory: %s", instanceDir)
 
+		if !*force && utils.IsInteractive(os.Stdin) {
+			namespace, err := utils.ResolveNamespace(instanceDir)
+			if err != nil {
+				return err
+			}
+			logger.Warn("This will delete namespace %s and all its resources", namespace)
+			confirmed, err := utils.Confirm(os.Stdout, os.Stdin, "Type the namespace name to confirm:", namespace)
+			if err != nil {
+				return fmt.Errorf("confirming stop: %w", err)
+			}
+			if !confirmed {
+				logger.Info("Stop cancelled")
+				return nil
+			}
+		}
+
 		k8sClient, err := client.New(*kubeconfig)
 		if err != nil {
 			return fmt.Errorf("creating k8s client: %w", err)
 		}
 
-		if err := StopInstance(context.Background(), k8sClient, instanceDir, *timeout, nil); err != nil {
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+
+		if err := StopInstance(ctx, k8sClient, instanceDir, *timeout, *saveSecretNames, *force, *forceFinalize, nil); err != nil {
 			return err
 		}
 
@@ -59,36 +107,94 @@ func StopCmd() *cobra.Command {
 	return cmd
 }
 
-// StopInstance saves the TLS secret if present, then deletes the namespace
-// and waits for it to be fully removed.
-func StopInstance(ctx context.Context, k8sClient *client.Client, instanceDir string, timeout time.Duration, callback func(elapsedSeconds int) error) error {
-	namespace := utils.ExtractNamespace(instanceDir)
+// StopInstance saves the TLS secret if present, labeled secrets and the secrets
+// in saveSecretNames, then deletes the namespace and waits for it to be fully
+// removed. If the secrets cannot all be saved, the namespace is kept unless
+// force is set. With forceFinalize, the finalizers of a namespace still
+// terminating after timeout are removed.
+func StopInstance(ctx context.Context, k8sClient *client.Client, instanceDir string, timeout time.Duration, saveSecretNames []string, force, forceFinalize bool, callback func(elapsedSeconds int) error) error {
+	namespace, err := utils.ResolveNamespace(instanceDir)
+	if err != nil {
+		return err
+	}
 
-	if err := saveTLSSecret(ctx, k8sClient, namespace, instanceDir); err != nil {
-		logger.Warn("Failed to save TLS secret: %v", err)
+	if err := saveSecrets(ctx, k8sClient.Clientset(), namespace, instanceDir, saveSecretNames); err != nil {
+		if !force {
+			return fmt.Errorf("saving secrets, namespace %s not deleted (use --force to delete it anyway): %w", namespace, err)
+		}
+		logger.Warn("Failed to save secrets, deleting namespace %s anyway: %v", namespace, err)
 	}
 
 	logger.Info("Stopping instance: %s", namespace)
-	if err := deleteNamespace(ctx, k8sClient, namespace, timeout, callback); err != nil {
+	if err := deleteNamespace(ctx, k8sClient, namespace, timeout, forceFinalize, callback); err != nil {
 		return fmt.Errorf("deleting namespace: %w", err)
 	}
 
 	return nil
 }
 
-// saveTLSSecret saves the TLS certificate secret to a YAML file if it exists
-func saveTLSSecret(ctx context.Context, k8sClient *client.Client, namespace, instanceDir string) error {
-	clientset := k8sClient.Clientset()
+// selectSecretsToSave returns the secrets to save before deletion: the TLS
+// secret, secrets labeled constants.SaveSecretLabel=true and those in names.
+// Names not found are reported as missing.
+func selectSecretsToSave(secrets []corev1.Secret, names []string) (selected []corev1.Secret, missing []string) {
+	found := make(map[string]bool, len(secrets))
+	for _, secret := range secrets {
+		found[secret.Name] = true
+		if secret.Name == constants.TlsCertSecret ||
+			secret.Labels[constants.SaveSecretLabel] == "true" ||
+			slices.Contains(names, secret.Name) {
+			selected = append(selected, secret)
+		}
+	}
+	for _, name := range names {
+		if !found[name] {
+			missing = append(missing, name)
+		}
+	}
+	return selected, missing
+}
 
-	secret, err := clientset.CoreV1().Secrets(namespace).Get(ctx, constants.TlsCertSecret, metav1.GetOptions{})
-	if err != nil {
-		logger.Debug("TLS secret %s not found in namespace %s", constants.TlsCertSecret, namespace)
-		return nil
+// savedSecret returns a manifest of secret without server-set metadata, so it
+// can be applied again to a new namespace
+func savedSecret(secret *corev1.Secret) *corev1.Secret {
+	return &corev1.Secret{
+		TypeMeta: metav1.TypeMeta{APIVersion: "v1", Kind: "Secret"},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        secret.Name,
+			Namespace:   secret.Namespace,
+			Labels:      secret.Labels,
+			Annotations: secret.Annotations,
+		},
+		Type:      secret.Type,
+		Immutable: secret.Immutable,
+		Data:      secret.Data,
 	}
+}
 
-	secretYAML, err := yaml.Marshal(secret)
+// saveSecrets saves the secrets selected by selectSecretsToSave to the secrets
+// directory of the instance as <name>-secret.yaml, readable by the owner only.
+// Named secrets that do not exist are reported as error after the others are saved.
+func saveSecrets(ctx context.Context, clientset kubernetes.Interface, namespace, instanceDir string, names []string) error {
+	list, err := clientset.CoreV1().Secrets(namespace).List(ctx, metav1.ListOptions{})
 	if err != nil {
-		return fmt.Errorf("marshaling secret to YAML: %w", err)
+		return fmt.Errorf("listing secrets: %w", err)
+	}
+
+	secrets, missing := selectSecretsToSave(list.Items, names)
+	if err := writeSavedSecrets(instanceDir, secrets); err != nil {
+		return err
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("secrets not found in namespace %s: %s", namespace, strings.Join(missing, ", "))
+	}
+	return nil
+}
+
+// writeSavedSecrets writes secrets to the secrets directory of the instance
+func writeSavedSecrets(instanceDir string, secrets []corev1.Secret) error {
+	if len(secrets) == 0 {
+		logger.Debug("No secrets to save")
+		return nil
 	}
 
 	secretsDir := filepath.Join(instanceDir, constants.SecretsDirName)
@@ -96,17 +202,27 @@ func saveTLSSecret(ctx context.Context, k8sClient *client.Client, namespace, ins
 		return fmt.Errorf("creating secrets directory: %w", err)
 	}
 
-	secretPath := filepath.Join(secretsDir, constants.TlsCertSecretYAML)
-	if err := os.WriteFile(secretPath, secretYAML, constants.SecretFilePerm); err != nil {
-		return fmt.Errorf("writing secret file: %w", err)
-	}
+	for _, secret := range secrets {
+		secretYAML, err := yaml.Marshal(savedSecret(&secret))
+		if err != nil {
+			return fmt.Errorf("marshaling secret %s to YAML: %w", secret.Name, err)
+		}
 
-	logger.Info("Saved TLS secret to: %s", secretPath)
+		secretPath := filepath.Join(secretsDir, secret.Name+constants.SavedSecretSuffix)
+		if err := os.WriteFile(secretPath, secretYAML, constants.SavedSecretFilePerm); err != nil {
+			return fmt.Errorf("writing secret file: %w", err)
+		}
+		// WriteFile keeps the mode of existing files
+		if err := os.Chmod(secretPath, constants.SavedSecretFilePerm); err != nil {
+			return fmt.Errorf("setting permissions of %s: %w", secretPath, err)
+		}
+		logger.Info("Saved secret %s to: %s", secret.Name, secretPath)
+	}
 	return nil
 }
 
 // deleteNamespace deletes a Kubernetes namespace
-func deleteNamespace(ctx context.Context, k8sClient *client.Client, namespace string, timeout time.Duration, callback func(elapsedSeconds int) error) error {
+func deleteNamespace(ctx context.Context, k8sClient *client.Client, namespace string, timeout time.Duration, forceFinalize bool, callback func(elapsedSeconds int) error) error {
 	clientset := k8sClient.Clientset()
 
 	logger.Debug("Deleting namespace: %s", namespace)
@@ -122,5 +238,38 @@ func deleteNamespace(ctx context.Context, k8sClient *client.Client, namespace st
 	logger.Info("Namespace %s deletion initiated", namespace)
 
 	logger.Debug("Waiting for namespace to be fully deleted...")
-	return waitForNamespaceDeletion(ctx, k8sClient, namespace, timeout, callback)
+	err = waitForNamespaceDeletion(ctx, k8sClient, namespace, timeout, callback)
+	if err == nil || !forceFinalize || ctx.Err() != nil {
+		return err
+	}
+
+	logger.Error("%v", err)
+	if err := finalizeNamespace(ctx, k8sClient, namespace); err != nil {
+		return err
+	}
+	return waitForNamespaceDeletion(ctx, k8sClient, namespace, constants.ForceFinalizeTimeout, callback)
+}
+
+// finalizeNamespace removes the spec finalizers of a terminating namespace via
+// the finalize subresource, so Kubernetes deletes it without further cleanup.
+// Finalizers in the namespace metadata are left untouched.
+func finalizeNamespace(ctx context.Context, k8sClient *client.Client, namespace string) error {
+	namespaces := k8sClient.Clientset().CoreV1().Namespaces()
+
+	ns, err := namespaces.Get(ctx, namespace, metav1.GetOptions{})
+	if err != nil {
+		return fmt.Errorf("getting namespace %s: %w", namespace, err)
+	}
+
+	logger.Warn("!!! Forcing finalization of namespace %s !!!", namespace)
+	logger.Warn("Removing finalizers %v skips the cleanup Kubernetes would do and may leave orphaned resources", ns.Spec.Finalizers)
+	if len(ns.Finalizers) > 0 {
+		logger.Warn("Metadata finalizers %v are not removed and may still block the deletion", ns.Finalizers)
+	}
+
+	ns.Spec.Finalizers = nil
+	if _, err := namespaces.Finalize(ctx, ns, metav1.UpdateOptions{}); err != nil {
+		return fmt.Errorf("finalizing namespace %s: %w", namespace, err)
+	}
+	return nil
 }
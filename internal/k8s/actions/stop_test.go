@@ -0,0 +1,103 @@
+package actions
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/OpenSlides/openslides-cli/internal/constants"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestSelectSecretsToSave(t *testing.T) {
+	secret := func(name string, labels map[string]string) corev1.Secret {
+		return corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: name, Labels: labels}}
+	}
+	secrets := []corev1.Secret{
+		secret(constants.TlsCertSecret, nil),
+		secret("oidc", map[string]string{constants.SaveSecretLabel: "true"}),
+		secret("smtp", nil),
+		secret("other", map[string]string{constants.SaveSecretLabel: "false"}),
+	}
+
+	selected, missing := selectSecretsToSave(secrets, []string{"smtp", "gone"})
+
+	var names []string
+	for _, s := range selected {
+		names = append(names, s.Name)
+	}
+	if len(names) != 3 || names[0] != constants.TlsCertSecret || names[1] != "oidc" || names[2] != "smtp" {
+		t.Errorf("Expected TLS, labeled and named secrets, got %v", names)
+	}
+	if len(missing) != 1 || missing[0] != "gone" {
+		t.Errorf("Expected gone to be missing, got %v", missing)
+	}
+}
+
+func TestSaveSecrets(t *testing.T) {
+	clientset := fake.NewClientset(
+		&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: constants.TlsCertSecret, Namespace: "myinstance"}},
+		&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "smtp", Namespace: "myinstance"}},
+	)
+
+	t.Run("all found", func(t *testing.T) {
+		instanceDir := t.TempDir()
+		if err := saveSecrets(context.Background(), clientset, "myinstance", instanceDir, []string{"smtp"}); err != nil {
+			t.Fatalf("saveSecrets() error = %v", err)
+		}
+		for _, name := range []string{constants.TlsCertSecret, "smtp"} {
+			info, err := os.Stat(filepath.Join(instanceDir, constants.SecretsDirName, name+constants.SavedSecretSuffix))
+			if err != nil {
+				t.Fatalf("Expected %s to be saved: %v", name, err)
+			}
+			if info.Mode().Perm() != constants.SavedSecretFilePerm {
+				t.Errorf("%s: mode = %v, want %v", name, info.Mode().Perm(), constants.SavedSecretFilePerm)
+			}
+		}
+	})
+
+	t.Run("named secret missing", func(t *testing.T) {
+		instanceDir := t.TempDir()
+		err := saveSecrets(context.Background(), clientset, "myinstance", instanceDir, []string{"smtp", "gone"})
+		if err == nil || !strings.Contains(err.Error(), "gone") {
+			t.Fatalf("Expected error naming the missing secret, got %v", err)
+		}
+		if _, err := os.Stat(filepath.Join(instanceDir, constants.SecretsDirName, "smtp"+constants.SavedSecretSuffix)); err != nil {
+			t.Errorf("Expected found secrets to be saved anyway: %v", err)
+		}
+	})
+}
+
+func TestSavedSecret(t *testing.T) {
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            "smtp",
+			Namespace:       "myinstance",
+			Labels:          map[string]string{"app": "mail"},
+			UID:             "1234",
+			ResourceVersion: "42",
+			ManagedFields:   []metav1.ManagedFieldsEntry{{Manager: fieldManager}},
+		},
+		Type: corev1.SecretTypeOpaque,
+		Data: map[string][]byte{"password": []byte("secret")},
+	}
+
+	saved := savedSecret(secret)
+
+	if saved.Kind != "Secret" || saved.APIVersion != "v1" {
+		t.Errorf("Expected v1 Secret type meta, got %s/%s", saved.APIVersion, saved.Kind)
+	}
+	if saved.Name != "smtp" || saved.Namespace != "myinstance" || saved.Labels["app"] != "mail" {
+		t.Errorf("Expected name, namespace and labels to be kept, got %+v", saved.ObjectMeta)
+	}
+	if saved.UID != "" || saved.ResourceVersion != "" || len(saved.ManagedFields) != 0 {
+		t.Errorf("Expected server-set metadata to be dropped, got %+v", saved.ObjectMeta)
+	}
+	if string(saved.Data["password"]) != "secret" || saved.Type != corev1.SecretTypeOpaque {
+		t.Errorf("Expected data and type to be kept, got %v %v", saved.Type, saved.Data)
+	}
+}
@@ -9,6 +9,7 @@ import (
 	"github.com/OpenSlides/openslides-cli/internal/constants"
 	"github.com/OpenSlides/openslides-cli/internal/k8s/client"
 	"github.com/OpenSlides/openslides-cli/internal/logger"
+	"github.com/OpenSlides/openslides-cli/internal/utils"
 	"github.com/spf13/cobra"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
@@ -75,7 +76,10 @@ func UpdateBackendmanage(
 	timeout time.Duration,
 	callback func(*DeploymentStatus) error,
 ) error {
-	namespace := strings.ReplaceAll(instanceUrl, ".", "")
+	namespace := utils.ExtractNamespace(instanceUrl)
+	if err := utils.ValidateNamespace(namespace); err != nil {
+		return fmt.Errorf("%w: derived from instance URL %s", err, instanceUrl)
+	}
 	image := fmt.Sprintf(constants.BackendmanageImageTemplate, containerRegistry, tag)
 
 	logger.Info("Updating deployment to image: %s", image)
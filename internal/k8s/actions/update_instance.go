@@ -3,7 +3,11 @@ package actions
 import (
 	"context"
 	"fmt"
+	"io"
+	"os"
 	"path/filepath"
+	"sort"
+	"strings"
 	"time"
 
 	"github.com/OpenSlides/openslides-cli/internal/constants"
@@ -11,16 +15,30 @@ import (
 	"github.com/OpenSlides/openslides-cli/internal/logger"
 	"github.com/OpenSlides/openslides-cli/internal/utils"
 	"github.com/spf13/cobra"
+	appsv1 "k8s.io/api/apps/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes"
 )
 
 const (
 	UpdateInstanceHelp      = "Updates an OpenSlides instance."
 	UpdateInstanceHelpExtra = `Updates the instance by applying new manifest files from the instance directory.
 
+Resources are applied server-side as field manager "osmanage", taking over
+fields owned by other managers. With --field-manager and --no-force, fields
+owned by other managers fail the apply instead. Only resources applied by the
+field manager are pruned.
+
 Examples:
   osmanage k8s update-instance ./my.instance.dir.org
   osmanage k8s update-instance ./my.instance.dir.org --skip-ready-check
-  osmanage k8s update-instance ./my.instance.dir.org --kubeconfig ~/.kube/config`
+  osmanage k8s update-instance ./my.instance.dir.org --kubeconfig ~/.kube/config
+  osmanage k8s update-instance ./my.instance.dir.org --dry-run
+  osmanage k8s update-instance ./my.instance.dir.org --only backend,client --dry-run
+  osmanage k8s update-instance ./my.instance.dir.org --exclude media
+  osmanage k8s update-instance ./my.instance.dir.org --field-manager osmanage-staging --no-force`
 )
 
 func UpdateInstanceCmd() *cobra.Command {
@@ -34,6 +52,11 @@ func UpdateInstanceCmd() *cobra.Command {
 	kubeconfig := cmd.Flags().String("kubeconfig", "", "Path to kubeconfig file")
 	skipReadyCheck := cmd.Flags().Bool("skip-ready-check", false, "Skip waiting for instance to become ready")
 	timeout := cmd.Flags().Duration("timeout", constants.DefaultInstanceTimeout, "Timeout for instance health check")
+	dryRun := cmd.Flags().Bool("dry-run", false, "List targeted deployments with current and proposed images without applying")
+	only := cmd.Flags().StringSlice("only", nil, "Only update these deployments (comma-separated names)")
+	exclude := cmd.Flags().StringSlice("exclude", nil, "Do not update these deployments (comma-separated names)")
+	manager := cmd.Flags().String("field-manager", fieldManager, "Field manager name for server-side apply")
+	noForce := cmd.Flags().Bool("no-force", false, "Fail on fields owned by other field managers instead of taking them over")
 
 	cmd.RunE = func(cmd *cobra.Command, args []string) error {
 		logger.Info("=== K8S UPDATE INSTANCE ===")
@@ -41,12 +64,33 @@ func UpdateInstanceCmd() *cobra.Command {
 
 		logger.Debug("Instance directory: %s", instanceDir)
 
+		if strings.TrimSpace(*manager) == "" {
+			return fmt.Errorf("--field-manager cannot be empty")
+		}
+
 		k8sClient, err := client.New(*kubeconfig)
 		if err != nil {
 			return fmt.Errorf("creating k8s client: %w", err)
 		}
 
-		if err := UpdateInstance(context.Background(), k8sClient, instanceDir, *skipReadyCheck, *timeout, nil, nil); err != nil {
+		filter := DeploymentFilter{Only: *only, Exclude: *exclude}
+
+		if *dryRun {
+			namespace, err := utils.ResolveNamespace(instanceDir)
+			if err != nil {
+				return err
+			}
+			stackDir := filepath.Join(instanceDir, constants.StackDirName)
+			changes, err := PreviewImageChanges(context.Background(), k8sClient.Clientset(), namespace, stackDir, filter)
+			if err != nil {
+				return err
+			}
+			printImageChanges(os.Stdout, changes)
+			return nil
+		}
+
+		applyOpts := ApplyOptions{FieldManager: *manager, NoForce: *noForce}
+		if err := UpdateInstance(context.Background(), k8sClient, instanceDir, *skipReadyCheck, *timeout, filter, applyOpts, nil, nil); err != nil {
 			return err
 		}
 
@@ -59,16 +103,22 @@ func UpdateInstanceCmd() *cobra.Command {
 
 // UpdateInstance applies new stack manifests and optionally waits for instance
 // to become healthy. Returns early with inactive=true if the namespace is not running.
+// applyOpts sets the field manager and conflict handling of server-side apply.
 func UpdateInstance(
 	ctx context.Context,
 	k8sClient *client.Client,
 	instanceDir string,
 	skipReadyCheck bool,
 	timeout time.Duration,
+	filter DeploymentFilter,
+	applyOpts ApplyOptions,
 	callback func(*HealthStatus) error,
 	inactiveCallback func() error,
 ) error {
-	namespace := utils.ExtractNamespace(instanceDir)
+	namespace, err := utils.ResolveNamespace(instanceDir)
+	if err != nil {
+		return err
+	}
 	logger.Info("Namespace: %s", namespace)
 
 	isActive, err := namespaceIsActive(ctx, k8sClient, namespace)
@@ -91,12 +141,14 @@ func UpdateInstance(
 	logger.Info("Updating OpenSlides services.")
 
 	stackDir := filepath.Join(instanceDir, constants.StackDirName)
-	applied, err := applyDirectory(ctx, k8sClient, stackDir, nil)
+	applied, failed, err := applyDirectory(ctx, k8sClient, stackDir, nil, filter, nil, nil, applyOpts)
 	if err != nil {
 		return fmt.Errorf("applying stack: %w", err)
 	}
 
-	if err := pruneOrphans(ctx, k8sClient, namespace, applied); err != nil {
+	if reason := pruneSkipReason(failed, filter); reason != "" {
+		logger.Warn("%s, skipping pruning of orphaned resources", reason)
+	} else if err := pruneOrphans(ctx, k8sClient, namespace, applied, applyOpts.Manager()); err != nil {
 		logger.Warn("Failed to prune orphaned resources: %v", err)
 	}
 
@@ -106,9 +158,104 @@ func UpdateInstance(
 	}
 
 	logger.Info("Waiting for instance to become ready...")
-	if err := WaitForInstanceHealthy(ctx, k8sClient, namespace, timeout, callback); err != nil {
+	if err := WaitForInstanceHealthy(ctx, k8sClient, namespace, timeout, HealthThreshold{}, "", callback); err != nil {
 		return fmt.Errorf("waiting for instance health: %w", err)
 	}
 
 	return nil
 }
+
+// ImageChange describes the image of a single container before and after an update
+type ImageChange struct {
+	Deployment    string
+	Container     string
+	CurrentImage  string
+	ProposedImage string
+}
+
+// PreviewImageChanges compares the container images of all deployment manifests
+// in stackDir matching filter with the deployments currently running in namespace.
+// Nothing is modified. Containers of deployments that do not exist yet have an
+// empty CurrentImage.
+func PreviewImageChanges(
+	ctx context.Context,
+	clientset kubernetes.Interface,
+	namespace, stackDir string,
+	filter DeploymentFilter,
+) ([]ImageChange, error) {
+	files, err := os.ReadDir(stackDir)
+	if err != nil {
+		return nil, fmt.Errorf("reading directory: %w", err)
+	}
+
+	var changes []ImageChange
+	for _, file := range files {
+		if file.IsDir() || !utils.IsYAMLFile(file.Name()) {
+			continue
+		}
+
+		obj, err := readManifest(filepath.Join(stackDir, file.Name()))
+		if err != nil {
+			logger.Warn("Skipping %s: %v", file.Name(), err)
+			continue
+		}
+		if obj.GetKind() != "Deployment" || !filter.Matches(obj.GetName()) {
+			continue
+		}
+
+		var proposed appsv1.Deployment
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(obj.Object, &proposed); err != nil {
+			return nil, fmt.Errorf("converting deployment %s: %w", obj.GetName(), err)
+		}
+
+		currentImages := map[string]string{}
+		current, err := clientset.AppsV1().Deployments(namespace).Get(ctx, proposed.Name, metav1.GetOptions{})
+		switch {
+		case apierrors.IsNotFound(err):
+			logger.Debug("Deployment %s does not exist yet", proposed.Name)
+		case err != nil:
+			return nil, fmt.Errorf("getting deployment %s: %w", proposed.Name, err)
+		default:
+			for _, c := range current.Spec.Template.Spec.Containers {
+				currentImages[c.Name] = c.Image
+			}
+		}
+
+		for _, c := range proposed.Spec.Template.Spec.Containers {
+			changes = append(changes, ImageChange{
+				Deployment:    proposed.Name,
+				Container:     c.Name,
+				CurrentImage:  currentImages[c.Name],
+				ProposedImage: c.Image,
+			})
+		}
+	}
+
+	sort.SliceStable(changes, func(i, j int) bool {
+		return changes[i].Deployment < changes[j].Deployment
+	})
+	return changes, nil
+}
+
+// printImageChanges writes the dry-run preview of image changes
+func printImageChanges(w io.Writer, changes []ImageChange) {
+	if len(changes) == 0 {
+		fmt.Fprintln(w, "No deployments targeted")
+		return
+	}
+
+	fmt.Fprintf(w, "Deployments that would be updated (%d containers):\n\n", len(changes))
+	for _, c := range changes {
+		current := c.CurrentImage
+		if current == "" {
+			current = "<none>"
+		}
+		marker := " "
+		if current != c.ProposedImage {
+			marker = "*"
+		}
+		fmt.Fprintf(w, "%s %s/%s\n", marker, c.Deployment, c.Container)
+		fmt.Fprintf(w, "    current:  %s\n", current)
+		fmt.Fprintf(w, "    proposed: %s\n", c.ProposedImage)
+	}
+}
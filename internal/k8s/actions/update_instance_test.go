@@ -0,0 +1,166 @@
+package actions
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+const deploymentManifestTemplate = `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: NAME
+  namespace: myinstance
+spec:
+  template:
+    spec:
+      containers:
+        - name: NAME
+          image: IMAGE
+`
+
+func writeDeploymentManifest(t *testing.T, dir, name, image string) {
+	t.Helper()
+	content := strings.ReplaceAll(deploymentManifestTemplate, "NAME", name)
+	content = strings.ReplaceAll(content, "IMAGE", image)
+	if err := os.WriteFile(filepath.Join(dir, name+".yaml"), []byte(content), 0644); err != nil {
+		t.Fatalf("writing manifest: %v", err)
+	}
+}
+
+func runningDeployment(name, image string) *appsv1.Deployment {
+	return &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "myinstance"},
+		Spec: appsv1.DeploymentSpec{
+			Template: corev1.PodTemplateSpec{
+				Spec: corev1.PodSpec{
+					Containers: []corev1.Container{{Name: name, Image: image}},
+				},
+			},
+		},
+	}
+}
+
+func setupPreview(t *testing.T) (string, *fake.Clientset) {
+	t.Helper()
+	stackDir := t.TempDir()
+	writeDeploymentManifest(t, stackDir, "backend", "registry/backend:4.2.0")
+	writeDeploymentManifest(t, stackDir, "client", "registry/client:4.2.0")
+	writeDeploymentManifest(t, stackDir, "media", "registry/media:4.2.0")
+	if err := os.WriteFile(filepath.Join(stackDir, "service.yaml"), []byte("apiVersion: v1\nkind: Service\nmetadata:\n  name: backend\n"), 0644); err != nil {
+		t.Fatalf("writing manifest: %v", err)
+	}
+
+	clientset := fake.NewClientset(
+		runningDeployment("backend", "registry/backend:4.1.0"),
+		runningDeployment("client", "registry/client:4.2.0"),
+	)
+	return stackDir, clientset
+}
+
+func TestPreviewImageChanges_AllDeployments(t *testing.T) {
+	stackDir, clientset := setupPreview(t)
+
+	changes, err := PreviewImageChanges(context.Background(), clientset, "myinstance", stackDir, DeploymentFilter{})
+	if err != nil {
+		t.Fatalf("PreviewImageChanges() error = %v", err)
+	}
+
+	expected := []ImageChange{
+		{Deployment: "backend", Container: "backend", CurrentImage: "registry/backend:4.1.0", ProposedImage: "registry/backend:4.2.0"},
+		{Deployment: "client", Container: "client", CurrentImage: "registry/client:4.2.0", ProposedImage: "registry/client:4.2.0"},
+		{Deployment: "media", Container: "media", CurrentImage: "", ProposedImage: "registry/media:4.2.0"},
+	}
+	if !reflect.DeepEqual(changes, expected) {
+		t.Errorf("PreviewImageChanges() = %+v, want %+v", changes, expected)
+	}
+
+	// A dry run must not modify the cluster
+	for _, action := range clientset.Actions() {
+		if action.GetVerb() != "get" {
+			t.Errorf("Unexpected %s action on %s", action.GetVerb(), action.GetResource().Resource)
+		}
+	}
+}
+
+func TestPreviewImageChanges_Filters(t *testing.T) {
+	stackDir, clientset := setupPreview(t)
+
+	tests := []struct {
+		name     string
+		filter   DeploymentFilter
+		expected []string
+	}{
+		{"only", DeploymentFilter{Only: []string{"backend", "media"}}, []string{"backend", "media"}},
+		{"exclude", DeploymentFilter{Exclude: []string{"client"}}, []string{"backend", "media"}},
+		{"only and exclude", DeploymentFilter{Only: []string{"backend", "client"}, Exclude: []string{"backend"}}, []string{"client"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			changes, err := PreviewImageChanges(context.Background(), clientset, "myinstance", stackDir, tt.filter)
+			if err != nil {
+				t.Fatalf("PreviewImageChanges() error = %v", err)
+			}
+			var names []string
+			for _, c := range changes {
+				names = append(names, c.Deployment)
+			}
+			if !reflect.DeepEqual(names, tt.expected) {
+				t.Errorf("Targeted deployments = %v, want %v", names, tt.expected)
+			}
+		})
+	}
+}
+
+func TestPrintImageChanges(t *testing.T) {
+	var buf bytes.Buffer
+	printImageChanges(&buf, []ImageChange{
+		{Deployment: "backend", Container: "backend", CurrentImage: "registry/backend:4.1.0", ProposedImage: "registry/backend:4.2.0"},
+		{Deployment: "media", Container: "media", ProposedImage: "registry/media:4.2.0"},
+	})
+
+	output := buf.String()
+	for _, want := range []string{
+		"* backend/backend",
+		"current:  registry/backend:4.1.0",
+		"proposed: registry/backend:4.2.0",
+		"* media/media",
+		"current:  <none>",
+	} {
+		if !strings.Contains(output, want) {
+			t.Errorf("Expected output to contain %q, got:\n%s", want, output)
+		}
+	}
+}
+
+func TestPruneSkipReason(t *testing.T) {
+	tests := []struct {
+		name   string
+		failed int
+		filter DeploymentFilter
+		want   string
+	}{
+		{"all applied", 0, DeploymentFilter{}, ""},
+		{"failed apply", 2, DeploymentFilter{}, "2 manifests failed to apply"},
+		{"filter set", 0, DeploymentFilter{Only: []string{"proxy"}}, "deployment filter set"},
+		{"failed apply with filter", 1, DeploymentFilter{Exclude: []string{"proxy"}}, "1 manifests failed to apply"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := pruneSkipReason(tt.failed, tt.filter); got != tt.want {
+				t.Errorf("pruneSkipReason() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -2,6 +2,7 @@ package client
 
 import (
 	"fmt"
+	"os"
 	"path/filepath"
 	"sync"
 
@@ -32,38 +33,13 @@ type Client struct {
 }
 
 // New creates a Kubernetes client from the given kubeconfig path.
-// If kubeconfigPath is empty, attempts to use in-cluster config first,
-// then falls back to the default kubeconfig location ($HOME/.kube/config).
+// If kubeconfigPath is empty, the files in $KUBECONFIG are used if set
+// (colon-separated and merged like kubectl does), otherwise in-cluster config
+// is tried first, then the default kubeconfig location ($HOME/.kube/config).
 func New(kubeconfigPath string) (*Client, error) {
-	var config *rest.Config
-	var err error
-	var source string
-
-	if kubeconfigPath != "" {
-		// Use provided kubeconfig path
-		config, err = clientcmd.BuildConfigFromFlags("", kubeconfigPath)
-		if err != nil {
-			return nil, fmt.Errorf("failed to load kubeconfig from %s: %w", kubeconfigPath, err)
-		}
-		source = fmt.Sprintf("kubeconfig: %s", kubeconfigPath)
-	} else {
-		// Try in-cluster config first
-		config, err = rest.InClusterConfig()
-		if err == nil {
-			source = "in-cluster service account"
-		} else {
-			// Fall back to default kubeconfig location
-			kubeconfigPath = getDefaultKubeconfigPath()
-			if kubeconfigPath == "" {
-				return nil, fmt.Errorf("failed to get in-cluster config and could not determine home directory")
-			}
-
-			config, err = clientcmd.BuildConfigFromFlags("", kubeconfigPath)
-			if err != nil {
-				return nil, fmt.Errorf("failed to create k8s config: not running in-cluster and no valid kubeconfig found at %s: %w", kubeconfigPath, err)
-			}
-			source = fmt.Sprintf("kubeconfig: %s", kubeconfigPath)
-		}
+	config, source, err := loadConfig(kubeconfigPath, os.Getenv(clientcmd.RecommendedConfigPathEnvVar))
+	if err != nil {
+		return nil, err
 	}
 
 	clientset, err := kubernetes.NewForConfig(config)
@@ -79,6 +55,45 @@ func New(kubeconfigPath string) (*Client, error) {
 	}, nil
 }
 
+// loadConfig returns the REST config and a description of its source for New.
+// kubeconfigEnv is the value of $KUBECONFIG.
+func loadConfig(kubeconfigPath, kubeconfigEnv string) (*rest.Config, string, error) {
+	if kubeconfigPath != "" {
+		// Use provided kubeconfig path
+		config, err := clientcmd.BuildConfigFromFlags("", kubeconfigPath)
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to load kubeconfig from %s: %w", kubeconfigPath, err)
+		}
+		return config, fmt.Sprintf("kubeconfig: %s", kubeconfigPath), nil
+	}
+
+	if paths := filepath.SplitList(kubeconfigEnv); len(paths) > 0 {
+		rules := &clientcmd.ClientConfigLoadingRules{Precedence: paths}
+		config, err := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(rules, &clientcmd.ConfigOverrides{}).ClientConfig()
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to load kubeconfig from $%s (%s): %w", clientcmd.RecommendedConfigPathEnvVar, kubeconfigEnv, err)
+		}
+		return config, fmt.Sprintf("$%s: %s", clientcmd.RecommendedConfigPathEnvVar, kubeconfigEnv), nil
+	}
+
+	// Try in-cluster config first
+	if config, err := rest.InClusterConfig(); err == nil {
+		return config, "in-cluster service account", nil
+	}
+
+	// Fall back to default kubeconfig location
+	kubeconfigPath = getDefaultKubeconfigPath()
+	if kubeconfigPath == "" {
+		return nil, "", fmt.Errorf("failed to get in-cluster config and could not determine home directory")
+	}
+
+	config, err := clientcmd.BuildConfigFromFlags("", kubeconfigPath)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to create k8s config: not running in-cluster and no valid kubeconfig found at %s: %w", kubeconfigPath, err)
+	}
+	return config, fmt.Sprintf("kubeconfig: %s", kubeconfigPath), nil
+}
+
 // getDefaultKubeconfigPath returns the standard kubeconfig location
 func getDefaultKubeconfigPath() string {
 	home := homedir.HomeDir()
@@ -0,0 +1,82 @@
+package client
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writeKubeconfig(t *testing.T, dir, name, context, server string) string {
+	t.Helper()
+	content := `apiVersion: v1
+kind: Config
+clusters:
+- name: ` + name + `
+  cluster:
+    server: ` + server + `
+users:
+- name: ` + name + `
+  user:
+    token: secret
+contexts:
+- name: ` + name + `
+  context:
+    cluster: ` + name + `
+    user: ` + name + `
+`
+	if context != "" {
+		content += "current-context: " + context + "\n"
+	}
+	path := filepath.Join(dir, name+".yaml")
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatalf("failed to write kubeconfig: %v", err)
+	}
+	return path
+}
+
+func TestLoadConfig(t *testing.T) {
+	dir := t.TempDir()
+	prod := writeKubeconfig(t, dir, "prod", "prod", "https://prod.example.com")
+	// staging has no current-context, so it only contributes its cluster
+	staging := writeKubeconfig(t, dir, "staging", "", "https://staging.example.com")
+
+	t.Run("explicit path wins over KUBECONFIG", func(t *testing.T) {
+		config, source, err := loadConfig(prod, staging)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if config.Host != "https://prod.example.com" {
+			t.Errorf("expected prod host, got %s", config.Host)
+		}
+		if !strings.Contains(source, prod) {
+			t.Errorf("expected source to name %s, got %s", prod, source)
+		}
+	})
+
+	t.Run("KUBECONFIG with multiple paths is merged", func(t *testing.T) {
+		env := staging + string(os.PathListSeparator) + prod
+		config, source, err := loadConfig("", env)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if config.Host != "https://prod.example.com" {
+			t.Errorf("expected current-context from second file, got %s", config.Host)
+		}
+		if !strings.Contains(source, "KUBECONFIG") {
+			t.Errorf("expected source to mention KUBECONFIG, got %s", source)
+		}
+	})
+
+	t.Run("KUBECONFIG without usable context fails", func(t *testing.T) {
+		if _, _, err := loadConfig("", staging); err == nil {
+			t.Error("expected error without current-context")
+		}
+	})
+
+	t.Run("missing explicit path fails", func(t *testing.T) {
+		if _, _, err := loadConfig(filepath.Join(dir, "missing.yaml"), ""); err == nil {
+			t.Error("expected error for missing kubeconfig")
+		}
+	})
+}
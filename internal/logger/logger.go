@@ -2,10 +2,14 @@ package logger
 
 import (
 	"fmt"
+	"io"
 	"log"
 	"os"
+	"regexp"
 	"strings"
 	"sync"
+
+	"golang.org/x/term"
 )
 
 type Level int
@@ -20,6 +24,8 @@ const (
 type Logger struct {
 	level  Level
 	logger *log.Logger
+	file   *os.File
+	color  bool
 }
 
 type LogEntry struct {
@@ -55,9 +61,77 @@ func New(levelStr string) (*Logger, error) {
 	return &Logger{
 		level:  level,
 		logger: log.New(os.Stderr, "", flags),
+		color:  colorSupported(os.Stderr),
 	}, nil
 }
 
+// colorSupported reports whether ANSI colors should be used for f. Colors are
+// disabled if f is not a terminal or the NO_COLOR env var is set (see no-color.org).
+func colorSupported(f *os.File) bool {
+	if _, ok := os.LookupEnv("NO_COLOR"); ok {
+		return false
+	}
+	return term.IsTerminal(int(f.Fd()))
+}
+
+// DisableColor turns off colored level output
+func (l *Logger) DisableColor() {
+	l.color = false
+}
+
+// NewWithFile creates a logger that additionally writes to the file at path.
+// The file is opened in append mode and created with 0644 if missing. If
+// fileOnly is true, logs are written to the file only and not to stderr.
+// Call Close to release the file.
+func NewWithFile(levelStr, path string, fileOnly bool) (*Logger, error) {
+	l, err := New(levelStr)
+	if err != nil {
+		return nil, err
+	}
+
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("opening log file: %w", err)
+	}
+
+	// Keep escape sequences out of the log file, but not out of stderr
+	var w io.Writer = io.MultiWriter(os.Stderr, noColorWriter{f})
+	if fileOnly {
+		w = f
+		l.color = false
+	}
+
+	l.logger.SetOutput(w)
+	l.file = f
+	return l, nil
+}
+
+// colorSequence matches the ANSI color codes written by log
+var colorSequence = regexp.MustCompile(`\x1b\[[0-9;]*m`)
+
+// noColorWriter removes ANSI color codes before writing to w
+type noColorWriter struct {
+	w io.Writer
+}
+
+func (n noColorWriter) Write(p []byte) (int, error) {
+	if _, err := n.w.Write(colorSequence.ReplaceAll(p, nil)); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// Close closes the log file, if any. Logging afterwards goes to stderr only.
+func (l *Logger) Close() error {
+	if l.file == nil {
+		return nil
+	}
+	err := l.file.Close()
+	l.file = nil
+	l.logger.SetOutput(os.Stderr)
+	return err
+}
+
 func ParseLevel(levelStr string) (Level, error) {
 	switch strings.ToLower(levelStr) {
 	case "debug":
@@ -88,6 +162,24 @@ func levelToString(level Level) string {
 	}
 }
 
+// levelColor returns the ANSI color code for level
+func levelColor(level Level) string {
+	switch level {
+	case LevelDebug:
+		return "\033[90m" // gray
+	case LevelInfo:
+		return "\033[36m" // cyan
+	case LevelWarn:
+		return "\033[33m" // yellow
+	case LevelError:
+		return "\033[31m" // red
+	default:
+		return ""
+	}
+}
+
+const colorReset = "\033[0m"
+
 func SetGlobal(l *Logger) {
 	global = l
 }
@@ -132,7 +224,11 @@ func (l *Logger) log(level Level, format string, v ...any) {
 	})
 
 	if l.level <= level {
-		l.logger.Printf("[%s] %s", strings.ToUpper(levelToString(level)), msg)
+		prefix := "[" + strings.ToUpper(levelToString(level)) + "]"
+		if l.color {
+			prefix = levelColor(level) + prefix + colorReset
+		}
+		l.logger.Printf("%s %s", prefix, msg)
 	}
 }
 
@@ -165,3 +261,11 @@ func Error(format string, v ...any) {
 		global.Error(format, v...)
 	}
 }
+
+// Close closes the log file of the global logger, if any
+func Close() error {
+	if global != nil {
+		return global.Close()
+	}
+	return nil
+}
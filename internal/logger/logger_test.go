@@ -2,7 +2,10 @@ package logger
 
 import (
 	"bytes"
+	"io"
 	"log"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 )
@@ -138,3 +141,106 @@ func TestBroadcasterDrop(t *testing.T) {
 	// this should not block even though channel is full
 	Info("should not block")
 }
+
+func TestNewWithFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "osmanage.log")
+
+	if err := os.WriteFile(path, []byte("existing\n"), 0644); err != nil {
+		t.Fatalf("writing log file: %v", err)
+	}
+
+	l, err := NewWithFile("info", path, true)
+	if err != nil {
+		t.Fatalf("NewWithFile() error = %v", err)
+	}
+	l.Info("written to file")
+	l.Debug("below level")
+	if err := l.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("reading log file: %v", err)
+	}
+	content := string(data)
+	if !strings.HasPrefix(content, "existing\n") {
+		t.Errorf("Expected log file to be appended, got: %s", content)
+	}
+	if !strings.Contains(content, "[INFO] written to file") {
+		t.Errorf("Expected info message in log file, got: %s", content)
+	}
+	if strings.Contains(content, "below level") {
+		t.Errorf("Expected debug message to be filtered, got: %s", content)
+	}
+}
+
+func TestNewWithFile_InvalidPath(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "missing", "osmanage.log")
+	if _, err := NewWithFile("info", path, false); err == nil {
+		t.Error("Expected error for unwritable log file path")
+	}
+}
+
+func TestLoggerColor(t *testing.T) {
+	tests := []struct {
+		name     string
+		color    bool
+		level    Level
+		expected string
+	}{
+		{"error colored", true, LevelError, "\033[31m[ERROR]\033[0m boom\n"},
+		{"warn colored", true, LevelWarn, "\033[33m[WARN]\033[0m boom\n"},
+		{"no color", false, LevelError, "[ERROR] boom\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			l := &Logger{
+				level:  LevelDebug,
+				logger: log.New(&buf, "", 0),
+				color:  tt.color,
+			}
+
+			l.log(tt.level, "boom")
+
+			if buf.String() != tt.expected {
+				t.Errorf("Expected %q, got %q", tt.expected, buf.String())
+			}
+		})
+	}
+}
+
+func TestLoggerColor_FileCopy(t *testing.T) {
+	var stderr, file bytes.Buffer
+	l := &Logger{
+		level:  LevelDebug,
+		logger: log.New(io.MultiWriter(&stderr, noColorWriter{&file}), "", 0),
+		color:  true,
+	}
+
+	l.log(LevelWarn, "boom")
+
+	if want := "\033[33m[WARN]\033[0m boom\n"; stderr.String() != want {
+		t.Errorf("Expected colored stderr %q, got %q", want, stderr.String())
+	}
+	if want := "[WARN] boom\n"; file.String() != want {
+		t.Errorf("Expected uncolored file %q, got %q", want, file.String())
+	}
+}
+
+func TestColorSupported_NoColorEnv(t *testing.T) {
+	t.Setenv("NO_COLOR", "")
+	if colorSupported(os.Stderr) {
+		t.Error("Expected colors to be disabled when NO_COLOR is set")
+	}
+}
+
+func TestDisableColor(t *testing.T) {
+	l := &Logger{color: true}
+	l.DisableColor()
+	if l.color {
+		t.Error("Expected color to be disabled")
+	}
+}
@@ -1,8 +1,12 @@
 package action
 
 import (
+	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
+	"os"
+	"time"
 
 	"github.com/OpenSlides/openslides-cli/internal/constants"
 	"github.com/OpenSlides/openslides-cli/internal/logger"
@@ -18,18 +22,30 @@ const (
 formatted payload. Provide the payload directly or use the --file flag with a
 file or use this flag with - to read from stdin.
 
+Without an action name, --file must contain a JSON array of
+{"action": "...", "data": [...]} objects. The batch is sent as-is and the
+backend handles all actions in a single transaction.
+
+Use --pretty to indent the response and print a summary of the returned ids.
+Use "osmanage action list" to see common action names and their required fields.
+
 Examples:
   osmanage action meeting.create '[{"name": "Annual Meeting", "committee_id": 1, "language": "de", "admin_ids": [1]}]' \
     --address <myBackendManageIP>:9002 \
     --password-file ./my.instance.dir.org/secrets/internal_auth_password
 
-  osmanage action meeting.create \
+  osmanage action meeting.create --pretty \
     --file create_meeting.json \
     --address <myBackendManageIP>:9002 \
     --password-file ./my.instance.dir.org/secrets/internal_auth_password
 
   echo '[{"name": "Test Meeting", "committee_id": 1, "language": "de", "admin_ids": [1]}]' | osmanage action meeting.create \
     --file - \
+    --address <myBackendManageIP>:9002 \
+    --password-file ./my.instance.dir.org/secrets/internal_auth_password
+
+  # Send several actions atomically
+  osmanage action --file provisioning.json \
     --address <myBackendManageIP>:9002 \
     --password-file ./my.instance.dir.org/secrets/internal_auth_password
 	`
@@ -37,22 +53,46 @@ Examples:
 
 func Cmd() *cobra.Command {
 	cmd := &cobra.Command{
-		Use:   "action name [payload]",
+		Use:   "action [name [payload]]",
 		Short: ActionHelp,
 		Long:  ActionHelp + "\n\n" + ActionHelpExtra,
-		Args:  cobra.RangeArgs(1, 2),
+		Args:  cobra.RangeArgs(0, 2),
 	}
 
-	address := cmd.Flags().StringP("address", "a", "", "address of the OpenSlides backendManage service (default: "+constants.DefaultBackendManageAddress+")")
+	address := cmd.Flags().StringP("address", "a", "", constants.BackendManageAddressUsage)
 	passwordFile := cmd.Flags().String("password-file", "", "file with password for authorization (default: "+constants.DefaultPasswordFile+")")
-	payloadFile := cmd.Flags().StringP("file", "f", "", "JSON file with the payload, or - for stdin")
+	waitForBackend := cmd.Flags().Duration("wait-for-backend", 0, constants.WaitForBackendUsage)
+	caCert := cmd.Flags().String("cacert", "", "CA bundle (PEM) to verify the backendManage certificate (implies https)")
+	insecureSkipTLSVerify := cmd.Flags().Bool("insecure-skip-tls-verify", false, "skip verification of the backendManage certificate, for development only (implies https)")
+	payloadFile := cmd.Flags().StringP("file", "f", "", "JSON file with the payload (or an action batch without name), or - for stdin")
+	pretty := cmd.Flags().Bool("pretty", false, "indent the response and print a summary of the returned ids")
+	outputFile := cmd.Flags().String("output-file", "", "write the response to this file instead of stdout")
+
+	cmd.AddCommand(listCmd())
 
 	cmd.RunE = func(cmd *cobra.Command, args []string) error {
-		utils.KeepValueOrEnvOrDefault(address, constants.EnvOsmanageBackendAddress, constants.DefaultBackendManageAddress)
+		if err := utils.KeepValueOrBackendManageAddress(address); err != nil {
+			return err
+		}
 		utils.KeepValueOrEnvOrDefault(passwordFile, constants.EnvOsmanageBackendPasswordFile, constants.DefaultPasswordFile)
 
 		logger.Info("=== ACTION ===")
 
+		tlsOpts, err := client.TLSOptions(*caCert, *insecureSkipTLSVerify)
+		if err != nil {
+			return err
+		}
+		clientOpts := append([]client.Option{client.WithTimeout(utils.Timeout(cmd, 0))}, tlsOpts...)
+		ctx, cancel := utils.CommandContext(cmd)
+		defer cancel()
+
+		if len(args) == 0 {
+			if *payloadFile == "" {
+				return fmt.Errorf("either an action name or --file with an action batch must be provided")
+			}
+			return sendBatch(ctx, *address, *passwordFile, *payloadFile, *pretty, *outputFile, *waitForBackend, clientOpts)
+		}
+
 		actionName := args[0]
 		logger.Debug("Action name: %s", actionName)
 
@@ -77,21 +117,114 @@ func Cmd() *cobra.Command {
 			return fmt.Errorf("reading password: %w", err)
 		}
 
-		cl := client.New(*address, authPassword)
-		resp, err := cl.SendAction(actionName, payload)
+		cl := client.New(*address, authPassword, clientOpts...)
+		if err := cl.WaitForBackend(*waitForBackend); err != nil {
+			return err
+		}
+		resp, err := cl.SendActionContext(ctx, actionName, payload)
 		if err != nil {
 			return fmt.Errorf("sending request: %w", err)
 		}
 
 		body, err := client.CheckResponse(resp)
 		if err != nil {
-			return err
+			return responseError(body, err, *pretty)
 		}
 
 		logger.Info("Action completed successfully")
-		fmt.Printf("Request was successful with following response: %s\n", string(body))
-		return nil
+		return writeResponse(*outputFile, body, []string{actionName}, *pretty)
 	}
 
 	return cmd
 }
+
+// batchAction is one element of an action batch
+type batchAction struct {
+	Action string          `json:"action"`
+	Data   json.RawMessage `json:"data"`
+}
+
+// sendBatch reads an action batch from file and sends it unchanged.
+func sendBatch(ctx context.Context, address, passwordFile, file string, pretty bool, outputFile string, waitForBackend time.Duration, clientOpts []client.Option) error {
+	batch, err := utils.ReadFromFileOrStdin(file)
+	if err != nil {
+		return fmt.Errorf("reading action batch: %w", err)
+	}
+
+	names, err := validateBatch(batch)
+	if err != nil {
+		return err
+	}
+	logger.Debug("Action batch: %v", names)
+
+	authPassword, err := utils.ReadPassword(passwordFile)
+	if err != nil {
+		return fmt.Errorf("reading password: %w", err)
+	}
+
+	cl := client.New(address, authPassword, clientOpts...)
+	if err := cl.WaitForBackend(waitForBackend); err != nil {
+		return err
+	}
+	resp, err := cl.SendActionsContext(ctx, batch)
+	if err != nil {
+		return fmt.Errorf("sending request: %w", err)
+	}
+
+	body, err := client.CheckResponse(resp)
+	if err != nil {
+		return responseError(body, err, pretty)
+	}
+
+	logger.Info("%d actions completed successfully", len(names))
+	return writeResponse(outputFile, body, names, pretty)
+}
+
+// writeResponse prints the response to stdout or, if outputFile is given,
+// writes the response body to that file, indented with pretty, and reports
+// the path on stderr
+func writeResponse(outputFile string, body []byte, names []string, pretty bool) error {
+	if outputFile == "" {
+		return printResponse(os.Stdout, body, names, pretty)
+	}
+
+	data := body
+	if pretty {
+		var indented bytes.Buffer
+		if err := json.Indent(&indented, body, "", "  "); err == nil {
+			indented.WriteByte('\n')
+			data = indented.Bytes()
+		}
+	}
+	if err := utils.WriteOutputFile(outputFile, data); err != nil {
+		return fmt.Errorf("writing output file: %w", err)
+	}
+	fmt.Fprintf(os.Stderr, "Response written to %s\n", outputFile)
+	return nil
+}
+
+// validateBatch checks that batch is a non-empty JSON array of actions with a
+// name and a data array, and returns the action names.
+func validateBatch(batch []byte) ([]string, error) {
+	var actions []batchAction
+	if err := json.Unmarshal(batch, &actions); err != nil {
+		logger.Error("Invalid JSON in action batch")
+		return nil, fmt.Errorf("invalid action batch: %w", err)
+	}
+	if len(actions) == 0 {
+		return nil, fmt.Errorf("action batch is empty")
+	}
+
+	names := make([]string, 0, len(actions))
+	for i, action := range actions {
+		if action.Action == "" {
+			return nil, fmt.Errorf("action %d: missing action name", i+1)
+		}
+		var data []json.RawMessage
+		if err := json.Unmarshal(action.Data, &data); err != nil {
+			return nil, fmt.Errorf("action %d (%s): data must be a JSON array", i+1, action.Action)
+		}
+		names = append(names, action.Action)
+	}
+	return names, nil
+}
@@ -0,0 +1,168 @@
+package action
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestValidateBatch(t *testing.T) {
+	batch := `[
+		{"action": "committee.create", "data": [{"name": "c1", "organization_id": 1}]},
+		{"action": "meeting.create", "data": [{"name": "m1", "committee_id": 1, "language": "de", "admin_ids": [1]}]}
+	]`
+
+	names, err := validateBatch([]byte(batch))
+	if err != nil {
+		t.Fatalf("validateBatch() error = %v", err)
+	}
+	if !slices.Equal(names, []string{"committee.create", "meeting.create"}) {
+		t.Errorf("Unexpected action names: %v", names)
+	}
+}
+
+func TestValidateBatch_Invalid(t *testing.T) {
+	tests := []struct {
+		name  string
+		batch string
+	}{
+		{"invalid json", `[{"action":`},
+		{"single object", `{"action": "meeting.create", "data": []}`},
+		{"empty", `[]`},
+		{"missing action", `[{"data": [{"id": 1}]}]`},
+		{"missing data", `[{"action": "meeting.delete"}]`},
+		{"data not an array", `[{"action": "meeting.delete", "data": {"id": 1}}]`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := validateBatch([]byte(tt.batch)); err == nil {
+				t.Error("Expected error")
+			}
+		})
+	}
+}
+
+func TestPrintResponse(t *testing.T) {
+	body := []byte(`{"success":true,"message":"Actions handled successfully","results":[[{"id":5},{"id":6}],[null]]}`)
+
+	t.Run("raw", func(t *testing.T) {
+		var buf bytes.Buffer
+		if err := printResponse(&buf, body, []string{"meeting.create"}, false); err != nil {
+			t.Fatalf("printResponse() error = %v", err)
+		}
+		expected := "Request was successful with following response: " + string(body) + "\n"
+		if buf.String() != expected {
+			t.Errorf("printResponse() = %q, want %q", buf.String(), expected)
+		}
+	})
+
+	t.Run("pretty", func(t *testing.T) {
+		var buf bytes.Buffer
+		if err := printResponse(&buf, body, []string{"meeting.create", "meeting.update"}, true); err != nil {
+			t.Fatalf("printResponse() error = %v", err)
+		}
+		output := buf.String()
+		if !strings.Contains(output, "\n  \"success\": true,\n") {
+			t.Errorf("Expected indented JSON, got:\n%s", output)
+		}
+		if !strings.HasSuffix(output, "Summary:\n  meeting.create: ids 5, 6\n  meeting.update: 1 items, no ids returned\n") {
+			t.Errorf("Expected summary of ids, got:\n%s", output)
+		}
+	})
+
+	t.Run("pretty without results", func(t *testing.T) {
+		var buf bytes.Buffer
+		if err := printResponse(&buf, []byte(`{"success":true}`), nil, true); err != nil {
+			t.Fatalf("printResponse() error = %v", err)
+		}
+		if strings.Contains(buf.String(), "Summary:") {
+			t.Errorf("Expected no summary, got:\n%s", buf.String())
+		}
+	})
+}
+
+func TestResponseError(t *testing.T) {
+	checkErr := errors.New(`request failed [400]: {"success":false,"message":"Meeting 3 does not exist."}`)
+	body := []byte(`{"success":false,"message":"Meeting 3 does not exist."}`)
+
+	if err := responseError(body, checkErr, false); err != checkErr {
+		t.Errorf("Expected unchanged error without --pretty, got %v", err)
+	}
+	if err := responseError(body, checkErr, true); err == nil || err.Error() != "action failed: Meeting 3 does not exist." {
+		t.Errorf("Expected backend message with --pretty, got %v", err)
+	}
+	if err := responseError([]byte("Bad Gateway"), checkErr, true); err != checkErr {
+		t.Errorf("Expected unchanged error for non-JSON body, got %v", err)
+	}
+}
+
+func TestKnownActionsSorted(t *testing.T) {
+	for i, action := range knownActions {
+		if i > 0 && knownActions[i-1].Name >= action.Name {
+			t.Errorf("knownActions not sorted or duplicate at %s", action.Name)
+		}
+		if !strings.Contains(action.Name, ".") || len(action.Required) == 0 {
+			t.Errorf("invalid known action %+v", action)
+		}
+	}
+}
+
+func TestFilterActions(t *testing.T) {
+	if got := filterActions(""); len(got) != len(knownActions) {
+		t.Errorf("filterActions(\"\") returned %d actions, want %d", len(got), len(knownActions))
+	}
+
+	got := filterActions("user.set")
+	if len(got) != 1 || got[0].Name != "user.set_password" {
+		t.Errorf("filterActions(user.set) = %v", got)
+	}
+
+	if got := filterActions("unknown"); len(got) != 0 {
+		t.Errorf("filterActions(unknown) = %v, want none", got)
+	}
+}
+
+func TestWriteActions(t *testing.T) {
+	var buf bytes.Buffer
+	if err := writeActions(&buf, filterActions("organization.")); err != nil {
+		t.Fatalf("writeActions() error = %v", err)
+	}
+
+	want := "ACTION                       REQUIRED FIELDS\n" +
+		"organization.initial_import  data\n" +
+		"organization.update          id\n"
+	if buf.String() != want {
+		t.Errorf("writeActions() = %q, want %q", buf.String(), want)
+	}
+}
+
+func TestWriteResponse_OutputFile(t *testing.T) {
+	body := []byte(`{"success":true,"results":[[{"id":7}]]}`)
+
+	path := filepath.Join(t.TempDir(), "response.json")
+	if err := writeResponse(path, body, []string{"meeting.create"}, false); err != nil {
+		t.Fatalf("writeResponse() error = %v", err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(data, body) {
+		t.Errorf("Expected raw body in file, got %q", data)
+	}
+
+	if err := writeResponse(path, body, []string{"meeting.create"}, true); err != nil {
+		t.Fatalf("writeResponse() error = %v", err)
+	}
+	if data, err = os.ReadFile(path); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(string(data), "{\n  \"success\": true,") || !strings.HasSuffix(string(data), "}\n") {
+		t.Errorf("Expected indented body in file, got %q", data)
+	}
+}
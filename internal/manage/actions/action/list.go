@@ -0,0 +1,120 @@
+package action
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+)
+
+const (
+	ListHelp      = "Lists known OpenSlides actions and their required fields"
+	ListHelpExtra = `This command prints a curated list of common OpenSlides 4 backend actions
+with the fields each action data object needs. The backend has no endpoint to
+query its actions, so the list is embedded and may miss actions or fields of
+your backend version. Give a prefix to only list matching actions.
+
+Examples:
+  osmanage action list
+  osmanage action list user.`
+)
+
+// knownAction describes an action of the backend
+type knownAction struct {
+	Name     string
+	Required []string
+}
+
+// knownActions are common backend actions, sorted by name
+var knownActions = []knownAction{
+	{Name: "agenda_item.create", Required: []string{"content_object_id"}},
+	{Name: "agenda_item.delete", Required: []string{"id"}},
+	{Name: "agenda_item.update", Required: []string{"id"}},
+	{Name: "committee.create", Required: []string{"name", "organization_id"}},
+	{Name: "committee.delete", Required: []string{"id"}},
+	{Name: "committee.update", Required: []string{"id"}},
+	{Name: "group.create", Required: []string{"name", "meeting_id"}},
+	{Name: "group.delete", Required: []string{"id"}},
+	{Name: "group.update", Required: []string{"id"}},
+	{Name: "meeting.clone", Required: []string{"meeting_id"}},
+	{Name: "meeting.create", Required: []string{"committee_id", "name", "language"}},
+	{Name: "meeting.delete", Required: []string{"id"}},
+	{Name: "meeting.update", Required: []string{"id"}},
+	{Name: "motion.create", Required: []string{"meeting_id", "title"}},
+	{Name: "motion.delete", Required: []string{"id"}},
+	{Name: "motion.update", Required: []string{"id"}},
+	{Name: "organization.initial_import", Required: []string{"data"}},
+	{Name: "organization.update", Required: []string{"id"}},
+	{Name: "organization_tag.create", Required: []string{"name", "color"}},
+	{Name: "organization_tag.delete", Required: []string{"id"}},
+	{Name: "organization_tag.update", Required: []string{"id"}},
+	{Name: "poll.start", Required: []string{"id"}},
+	{Name: "poll.stop", Required: []string{"id"}},
+	{Name: "projector.create", Required: []string{"name", "meeting_id"}},
+	{Name: "projector.delete", Required: []string{"id"}},
+	{Name: "projector.update", Required: []string{"id"}},
+	{Name: "theme.create", Required: []string{"name", "primary_500", "accent_500", "warn_500"}},
+	{Name: "theme.delete", Required: []string{"id"}},
+	{Name: "theme.update", Required: []string{"id"}},
+	{Name: "topic.create", Required: []string{"meeting_id", "title"}},
+	{Name: "topic.delete", Required: []string{"id"}},
+	{Name: "topic.update", Required: []string{"id"}},
+	{Name: "user.create", Required: []string{"username", "default_password"}},
+	{Name: "user.delete", Required: []string{"id"}},
+	{Name: "user.generate_new_password", Required: []string{"id"}},
+	{Name: "user.reset_password_to_default", Required: []string{"id"}},
+	{Name: "user.set_password", Required: []string{"id", "password"}},
+	{Name: "user.update", Required: []string{"id"}},
+}
+
+func listCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "list [prefix]",
+		Short: ListHelp,
+		Long:  ListHelp + "\n\n" + ListHelpExtra,
+		Args:  cobra.MaximumNArgs(1),
+	}
+
+	cmd.RunE = func(cmd *cobra.Command, args []string) error {
+		var prefix string
+		if len(args) > 0 {
+			prefix = args[0]
+		}
+
+		actions := filterActions(prefix)
+		if len(actions) == 0 {
+			return fmt.Errorf("no known action starts with %q", prefix)
+		}
+		return writeActions(os.Stdout, actions)
+	}
+
+	return cmd
+}
+
+// filterActions returns the known actions whose name starts with prefix
+func filterActions(prefix string) []knownAction {
+	var actions []knownAction
+	for _, action := range knownActions {
+		if strings.HasPrefix(action.Name, prefix) {
+			actions = append(actions, action)
+		}
+	}
+	return actions
+}
+
+// writeActions prints the actions as a table of names and required fields
+func writeActions(w io.Writer, actions []knownAction) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	if _, err := fmt.Fprintln(tw, "ACTION\tREQUIRED FIELDS"); err != nil {
+		return fmt.Errorf("writing header: %w", err)
+	}
+	for _, action := range actions {
+		if _, err := fmt.Fprintf(tw, "%s\t%s\n", action.Name, strings.Join(action.Required, ", ")); err != nil {
+			return fmt.Errorf("writing action: %w", err)
+		}
+	}
+	return tw.Flush()
+}
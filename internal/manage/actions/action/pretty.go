@@ -0,0 +1,80 @@
+package action
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/OpenSlides/openslides-cli/internal/manage/client"
+)
+
+// printResponse writes a successful response body. With pretty the body is
+// indented and followed by the ids returned for each action in names.
+func printResponse(w io.Writer, body []byte, names []string, pretty bool) error {
+	if !pretty {
+		_, err := fmt.Fprintf(w, "Request was successful with following response: %s\n", string(body))
+		return err
+	}
+
+	var indented bytes.Buffer
+	if err := json.Indent(&indented, body, "", "  "); err != nil {
+		_, err := fmt.Fprintf(w, "%s\n", string(body))
+		return err
+	}
+	if _, err := fmt.Fprintf(w, "%s\n", indented.String()); err != nil {
+		return err
+	}
+
+	response, err := client.ParseActionResponse(body)
+	if err != nil || len(response.Results) == 0 {
+		return nil
+	}
+
+	if _, err := fmt.Fprintln(w, "\nSummary:"); err != nil {
+		return err
+	}
+	for i, results := range response.Results {
+		name := fmt.Sprintf("action %d", i+1)
+		if i < len(names) {
+			name = names[i]
+		}
+		if _, err := fmt.Fprintf(w, "  %s: %s\n", name, summarizeResults(results)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// summarizeResults lists the ids of the results of a single action
+func summarizeResults(results []map[string]any) string {
+	var ids []string
+	for _, result := range results {
+		if id, ok := result["id"]; ok {
+			ids = append(ids, fmt.Sprintf("%v", id))
+		}
+	}
+
+	switch {
+	case len(ids) == 0:
+		return fmt.Sprintf("%d items, no ids returned", len(results))
+	case len(ids) == 1:
+		return "id " + ids[0]
+	default:
+		return "ids " + strings.Join(ids, ", ")
+	}
+}
+
+// responseError returns the error for a failed request. With pretty it reports
+// only the backend's message instead of the raw response body.
+func responseError(body []byte, checkErr error, pretty bool) error {
+	if !pretty {
+		return checkErr
+	}
+	response, err := client.ParseActionResponse(body)
+	if err != nil || response.Message == "" {
+		return checkErr
+	}
+	return fmt.Errorf("action failed: %s", response.Message)
+}
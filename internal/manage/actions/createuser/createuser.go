@@ -1,8 +1,13 @@
 package createuser
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
 
 	"github.com/OpenSlides/openslides-cli/internal/constants"
 	"github.com/OpenSlides/openslides-cli/internal/logger"
@@ -18,6 +23,15 @@ const (
 Provide the user data as an argument, or use the --file flag with a file path,
 or use --file=- to read from stdin.
 
+The user data can be a single JSON object or a JSON array of objects to create
+several users with one backend request. Each object needs at least username and
+default_password.
+
+Use --organization-level to create organization admins, or --meeting-id together
+with --group-ids to add the users to a meeting. These flags apply to every user
+and take precedence over the same fields in the user data. The organization-level
+flag cannot be combined with the meeting flags.
+
 Examples:
   osmanage create-user '{"username": "myuser", "default_password": "mypwd"}' \
     --address <myBackendManageIP>:9002 \
@@ -32,9 +46,29 @@ Examples:
     --file - \
     --address <myBackendManageIP>:9002 \
     --password-file ./my.instance.dir.org/secrets/internal_auth_password
+
+  # Create several users at once
+  osmanage create-user '[{"username": "alice", "default_password": "pwd1"}, {"username": "bob", "default_password": "pwd2"}]' \
+    --address <myBackendManageIP>:9002 \
+    --password-file ./my.instance.dir.org/secrets/internal_auth_password
+
+  # Create an organization admin
+  osmanage create-user '{"username": "orgadmin", "default_password": "mypwd"}' \
+    --organization-level can_manage_organization \
+    --address <myBackendManageIP>:9002 \
+    --password-file ./my.instance.dir.org/secrets/internal_auth_password
+
+  # Create a user in meeting 1 with groups 2 and 3
+  osmanage create-user '{"username": "delegate", "default_password": "mypwd"}' \
+    --meeting-id 1 --group-ids 2,3 \
+    --address <myBackendManageIP>:9002 \
+    --password-file ./my.instance.dir.org/secrets/internal_auth_password
 `
 )
 
+// organizationLevels are the valid values of organization_management_level
+var organizationLevels = []string{"superadmin", "can_manage_organization", "can_manage_users"}
+
 func Cmd() *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "create-user [user-data]",
@@ -43,16 +77,26 @@ func Cmd() *cobra.Command {
 		Args:  cobra.RangeArgs(0, 1),
 	}
 
-	address := cmd.Flags().StringP("address", "a", "", "address of the OpenSlides backendManage service (default: "+constants.DefaultBackendManageAddress+")")
+	address := cmd.Flags().StringP("address", "a", "", constants.BackendManageAddressUsage)
 	passwordFile := cmd.Flags().String("password-file", "", "file with password for authorization (default: "+constants.DefaultPasswordFile+")")
-	userFile := cmd.Flags().StringP("file", "f", "", "JSON file with user data, or - for stdin")
+	waitForBackend := cmd.Flags().Duration("wait-for-backend", 0, constants.WaitForBackendUsage)
+	userFile := cmd.Flags().StringP("file", "f", "", "JSON file with user data (object or array of objects), or - for stdin")
+	orgLevel := cmd.Flags().String("organization-level", "", "organization management level ("+strings.Join(organizationLevels, ", ")+")")
+	meetingID := cmd.Flags().Int64("meeting-id", 0, "ID of the meeting to add the users to (requires --group-ids)")
+	groupIDs := cmd.Flags().Int64Slice("group-ids", nil, "IDs of the meeting groups of the users (requires --meeting-id)")
 
 	cmd.RunE = func(cmd *cobra.Command, args []string) error {
-		utils.KeepValueOrEnvOrDefault(address, constants.EnvOsmanageBackendAddress, constants.DefaultBackendManageAddress)
+		if err := utils.KeepValueOrBackendManageAddress(address); err != nil {
+			return err
+		}
 		utils.KeepValueOrEnvOrDefault(passwordFile, constants.EnvOsmanageBackendPasswordFile, constants.DefaultPasswordFile)
 
 		logger.Info("=== CREATE USER ===")
 
+		if err := validateScopeFlags(*orgLevel, *meetingID, *groupIDs); err != nil {
+			return err
+		}
+
 		var input string
 		if len(args) > 0 {
 			input = args[0]
@@ -63,44 +107,146 @@ func Cmd() *cobra.Command {
 			return fmt.Errorf("reading user data: %w", err)
 		}
 
-		var userPayload map[string]any
-		if err := json.Unmarshal(userData, &userPayload); err != nil {
-			logger.Error("Invalid JSON in user data")
-			return fmt.Errorf("invalid JSON: %w", err)
+		users, err := parseUsers(userData)
+		if err != nil {
+			return err
 		}
 
-		logger.Debug("Parsed user data: %v", userPayload)
-
-		if userPayload["username"] == nil || userPayload["default_password"] == nil {
-			return fmt.Errorf("missing required fields: username and default_password")
-		}
+		applyScopeFlags(users, *orgLevel, *meetingID, *groupIDs)
+		logger.Debug("Parsed %d users", len(users))
 
 		password, err := utils.ReadPassword(*passwordFile)
 		if err != nil {
 			return fmt.Errorf("reading password: %w", err)
 		}
 
-		userDataArray := []map[string]any{userPayload}
-		userDataJSON, err := json.Marshal(userDataArray)
+		userDataJSON, err := json.Marshal(users)
 		if err != nil {
 			return fmt.Errorf("marshalling user data: %w", err)
 		}
 
-		cl := client.New(*address, password)
-		resp, err := cl.SendAction("user.create", userDataJSON)
+		cl := client.New(*address, password, client.WithTimeout(utils.Timeout(cmd, 0)))
+		if err := cl.WaitForBackend(*waitForBackend); err != nil {
+			return err
+		}
+		ctx, cancel := utils.CommandContext(cmd)
+		defer cancel()
+		resp, err := cl.SendActionContext(ctx, "user.create", userDataJSON)
 		if err != nil {
 			return fmt.Errorf("sending request: %w", err)
 		}
 
-		body, err := client.CheckResponse(resp)
-		if err != nil {
+		body, checkErr := client.CheckResponse(resp)
+		if err := reportResults(os.Stdout, users, body, checkErr); err != nil {
 			return err
 		}
 
-		logger.Info("User created successfully")
-		fmt.Printf("Response: %s\n", string(body))
+		logger.Info("%d users created successfully", len(users))
 		return nil
 	}
 
 	return cmd
 }
+
+// parseUsers parses a single user object or an array of user objects and checks
+// that each has the required fields.
+func parseUsers(data []byte) ([]map[string]any, error) {
+	var users []map[string]any
+
+	trimmed := bytes.TrimSpace(data)
+	if len(trimmed) > 0 && trimmed[0] == '[' {
+		if err := json.Unmarshal(trimmed, &users); err != nil {
+			logger.Error("Invalid JSON in user data")
+			return nil, fmt.Errorf("invalid JSON: %w", err)
+		}
+	} else {
+		var user map[string]any
+		if err := json.Unmarshal(trimmed, &user); err != nil {
+			logger.Error("Invalid JSON in user data")
+			return nil, fmt.Errorf("invalid JSON: %w", err)
+		}
+		users = []map[string]any{user}
+	}
+
+	if len(users) == 0 {
+		return nil, fmt.Errorf("no users provided")
+	}
+
+	for i, user := range users {
+		if user["username"] == nil || user["default_password"] == nil {
+			return nil, fmt.Errorf("user %d: missing required fields: username and default_password", i+1)
+		}
+	}
+
+	return users, nil
+}
+
+// validateScopeFlags checks the organization-level and meeting flags. The
+// organization level cannot be combined with meeting-scoped flags, and a meeting
+// user needs both a meeting and its groups.
+func validateScopeFlags(orgLevel string, meetingID int64, groupIDs []int64) error {
+	if orgLevel != "" && !slices.Contains(organizationLevels, orgLevel) {
+		return fmt.Errorf("invalid --organization-level %q (available: %s)", orgLevel, strings.Join(organizationLevels, ", "))
+	}
+	if orgLevel != "" && (meetingID != 0 || len(groupIDs) > 0) {
+		return fmt.Errorf("--organization-level cannot be combined with --meeting-id or --group-ids")
+	}
+	if meetingID < 0 {
+		return fmt.Errorf("--meeting-id must be positive")
+	}
+	if meetingID != 0 && len(groupIDs) == 0 {
+		return fmt.Errorf("--meeting-id requires --group-ids")
+	}
+	if meetingID == 0 && len(groupIDs) > 0 {
+		return fmt.Errorf("--group-ids requires --meeting-id")
+	}
+	return nil
+}
+
+// applyScopeFlags sets the organization level or meeting fields on every user,
+// overriding values from the user data.
+func applyScopeFlags(users []map[string]any, orgLevel string, meetingID int64, groupIDs []int64) {
+	for _, user := range users {
+		if orgLevel != "" {
+			user["organization_management_level"] = orgLevel
+		}
+		if meetingID != 0 {
+			user["meeting_id"] = meetingID
+			user["group_ids"] = groupIDs
+		}
+	}
+}
+
+// reportResults prints one line per user with the created id or the failure
+// reason. The backend handles all users of a request in a single transaction,
+// so a failed request means no user was created.
+func reportResults(w io.Writer, users []map[string]any, body []byte, checkErr error) error {
+	response, parseErr := client.ParseActionResponse(body)
+
+	if checkErr != nil {
+		reason := checkErr.Error()
+		if parseErr == nil && response.Message != "" {
+			reason = response.Message
+		}
+		for _, user := range users {
+			fmt.Fprintf(w, "%v: failed: %s\n", user["username"], reason)
+		}
+		return fmt.Errorf("creating users: %w", checkErr)
+	}
+
+	if parseErr != nil {
+		logger.Warn("Could not read per-user results: %v", parseErr)
+		fmt.Fprintf(w, "Response: %s\n", string(body))
+		return nil
+	}
+
+	results := response.ActionResults()
+	for i, user := range users {
+		if i < len(results) && results[i] != nil {
+			fmt.Fprintf(w, "%v: created (id %v)\n", user["username"], results[i]["id"])
+		} else {
+			fmt.Fprintf(w, "%v: created\n", user["username"])
+		}
+	}
+	return nil
+}
@@ -0,0 +1,143 @@
+package createuser
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+func TestParseUsers(t *testing.T) {
+	tests := []struct {
+		name      string
+		input     string
+		wantCount int
+		wantErr   bool
+	}{
+		{"single object", `{"username": "alice", "default_password": "pwd"}`, 1, false},
+		{"array", `[{"username": "alice", "default_password": "pwd"}, {"username": "bob", "default_password": "pwd"}]`, 2, false},
+		{"array with whitespace", "\n  [{\"username\": \"alice\", \"default_password\": \"pwd\"}]\n", 1, false},
+		{"empty array", `[]`, 0, true},
+		{"missing password", `{"username": "alice"}`, 0, true},
+		{"missing username in array", `[{"username": "alice", "default_password": "pwd"}, {"default_password": "pwd"}]`, 0, true},
+		{"invalid json", `{"username":`, 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			users, err := parseUsers([]byte(tt.input))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseUsers() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if len(users) != tt.wantCount {
+				t.Errorf("parseUsers() returned %d users, want %d", len(users), tt.wantCount)
+			}
+		})
+	}
+}
+
+func TestValidateScopeFlags(t *testing.T) {
+	tests := []struct {
+		name      string
+		orgLevel  string
+		meetingID int64
+		groupIDs  []int64
+		wantErr   bool
+	}{
+		{"none", "", 0, nil, false},
+		{"organization level", "superadmin", 0, nil, false},
+		{"meeting user", "", 1, []int64{2, 3}, false},
+		{"unknown organization level", "admin", 0, nil, true},
+		{"organization level with meeting", "can_manage_users", 1, []int64{2}, true},
+		{"organization level with groups", "superadmin", 0, []int64{2}, true},
+		{"meeting without groups", "", 1, nil, true},
+		{"groups without meeting", "", 0, []int64{2}, true},
+		{"negative meeting", "", -1, []int64{2}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateScopeFlags(tt.orgLevel, tt.meetingID, tt.groupIDs)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("validateScopeFlags() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestApplyScopeFlags(t *testing.T) {
+	t.Run("organization level overrides user data", func(t *testing.T) {
+		users := []map[string]any{{"username": "alice", "organization_management_level": "can_manage_users"}}
+		applyScopeFlags(users, "superadmin", 0, nil)
+
+		if users[0]["organization_management_level"] != "superadmin" {
+			t.Errorf("Expected superadmin, got %v", users[0]["organization_management_level"])
+		}
+		if _, ok := users[0]["meeting_id"]; ok {
+			t.Error("Expected no meeting_id")
+		}
+	})
+
+	t.Run("meeting fields on every user", func(t *testing.T) {
+		users := []map[string]any{{"username": "alice"}, {"username": "bob"}}
+		applyScopeFlags(users, "", 1, []int64{2, 3})
+
+		for _, user := range users {
+			if user["meeting_id"] != int64(1) {
+				t.Errorf("Expected meeting_id 1 for %v, got %v", user["username"], user["meeting_id"])
+			}
+			if groups, ok := user["group_ids"].([]int64); !ok || len(groups) != 2 {
+				t.Errorf("Expected group_ids [2 3] for %v, got %v", user["username"], user["group_ids"])
+			}
+			if _, ok := user["organization_management_level"]; ok {
+				t.Errorf("Expected no organization_management_level for %v", user["username"])
+			}
+		}
+	})
+}
+
+func TestReportResults(t *testing.T) {
+	users := []map[string]any{
+		{"username": "alice"},
+		{"username": "bob"},
+	}
+
+	t.Run("success", func(t *testing.T) {
+		var buf bytes.Buffer
+		body := []byte(`{"success":true,"message":"Actions handled successfully","results":[[{"id":7},{"id":8}]]}`)
+
+		if err := reportResults(&buf, users, body, nil); err != nil {
+			t.Fatalf("reportResults() error = %v", err)
+		}
+
+		expected := "alice: created (id 7)\nbob: created (id 8)\n"
+		if buf.String() != expected {
+			t.Errorf("reportResults() output = %q, want %q", buf.String(), expected)
+		}
+	})
+
+	t.Run("failure", func(t *testing.T) {
+		var buf bytes.Buffer
+		body := []byte(`{"success":false,"message":"A user with the username bob already exists."}`)
+
+		err := reportResults(&buf, users, body, errors.New("request failed [400]"))
+		if err == nil {
+			t.Fatal("Expected error for failed request")
+		}
+
+		expected := "alice: failed: A user with the username bob already exists.\n" +
+			"bob: failed: A user with the username bob already exists.\n"
+		if buf.String() != expected {
+			t.Errorf("reportResults() output = %q, want %q", buf.String(), expected)
+		}
+	})
+
+	t.Run("unreadable response", func(t *testing.T) {
+		var buf bytes.Buffer
+		if err := reportResults(&buf, users, []byte(`ok`), nil); err != nil {
+			t.Fatalf("reportResults() error = %v", err)
+		}
+		if buf.String() != "Response: ok\n" {
+			t.Errorf("Expected raw response fallback, got %q", buf.String())
+		}
+	})
+}
@@ -1,22 +1,29 @@
 package get
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"fmt"
+	"io"
 	"os"
 	"reflect"
 	"regexp"
+	"slices"
 	"strconv"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/shopspring/decimal"
 	"github.com/spf13/cobra"
 	"golang.org/x/text/cases"
 	"golang.org/x/text/language"
+	"k8s.io/kube-openapi/pkg/validation/spec"
 
 	"github.com/OpenSlides/openslides-cli/internal/constants"
 	"github.com/OpenSlides/openslides-cli/internal/logger"
+	"github.com/OpenSlides/openslides-cli/internal/utils"
 	pb "github.com/OpenSlides/openslides-cli/proto/osmanage"
 	"github.com/OpenSlides/openslides-go/datastore"
 	"github.com/OpenSlides/openslides-go/datastore/dsfetch"
@@ -41,12 +48,111 @@ Examples:
     --postgres-user openslides --postgres-database openslides \
     --postgres-password-file ./secrets/postgres_password
 
+  # JSON array instead of an object keyed by id
+  osmanage get user --fields username,email --flatten \
+    --postgres-host localhost --postgres-port 5432 \
+    --postgres-user openslides --postgres-database openslides \
+    --postgres-password-file ./secrets/postgres_password
+
+  # Distinct first and last name combinations (id is ignored when comparing)
+  osmanage get user --fields first_name,last_name --distinct \
+    --postgres-host localhost --postgres-port 5432 \
+    --postgres-user openslides --postgres-database openslides \
+    --postgres-password-file ./secrets/postgres_password
+
+  # Number of users per organization management level
+  osmanage get user --group-by organization_management_level \
+    --postgres-host localhost --postgres-port 5432 \
+    --postgres-user openslides --postgres-database openslides \
+    --postgres-password-file ./secrets/postgres_password
+
+  # Rename fields in the output
+  osmanage get user --fields first_name:given_name,last_name:family_name \
+    --postgres-host localhost --postgres-port 5432 \
+    --postgres-user openslides --postgres-database openslides \
+    --postgres-password-file ./secrets/postgres_password
+
   # Complex filter with operators
   osmanage get meeting --filter-raw '{"field":"start_time","operator":">=","value":1609459200}' \
     --postgres-host localhost --postgres-port 5432 \
     --postgres-user openslides --postgres-database openslides \
     --postgres-password-file ./secrets/postgres_password
 
+  # Same filter with a date
+  osmanage get meeting --filter-raw '{"field":"start_time","operator":">=","value":"2021-01-01"}' \
+    --postgres-host localhost --postgres-port 5432 \
+    --postgres-user openslides --postgres-database openslides \
+    --postgres-password-file ./secrets/postgres_password
+
+  # Write a large export to a file instead of stdout
+  osmanage get user --fields username,email --output jsonl --stream --output-file users.jsonl \
+    --postgres-host localhost --postgres-port 5432 \
+    --postgres-user openslides --postgres-database openslides \
+    --postgres-password-file ./secrets/postgres_password
+
+  # Export as semicolon-separated CSV
+  osmanage get user --fields first_name,last_name,email --output csv --csv-delimiter ';' \
+    --postgres-host localhost --postgres-port 5432 \
+    --postgres-user openslides --postgres-database openslides \
+    --postgres-password-file ./secrets/postgres_password
+
+  # Stream a very large collection as JSON lines with bounded memory
+  osmanage get user --fields username,email --output jsonl --stream \
+    --postgres-host localhost --postgres-port 5432 \
+    --postgres-user openslides --postgres-database openslides \
+    --postgres-password-file ./secrets/postgres_password
+
+  # Stream JSON lines followed by a {"_summary":{"count":n}} line
+  osmanage get user --fields username --output ndjson-summary --stream \
+    --postgres-host localhost --postgres-port 5432 \
+    --postgres-user openslides --postgres-database openslides \
+    --postgres-password-file ./secrets/postgres_password
+
+  # Share an export without leaking personal data
+  osmanage get user --fields username,email,is_active --hash-fields username,email --hash-salt s3cr3t \
+    --postgres-host localhost --postgres-port 5432 \
+    --postgres-user openslides --postgres-database openslides \
+    --postgres-password-file ./secrets/postgres_password
+
+  # Show how long each query phase took
+  osmanage get user --filter is_active=true --timing \
+    --postgres-host localhost --postgres-port 5432 \
+    --postgres-user openslides --postgres-database openslides \
+    --postgres-password-file ./secrets/postgres_password
+
+  # Show which fields would be loaded for how many models
+  osmanage get user --fields username --filter is_active=true --explain \
+    --postgres-host localhost --postgres-port 5432 \
+    --postgres-user openslides --postgres-database openslides \
+    --postgres-password-file ./secrets/postgres_password
+
+  # Show labels instead of numeric codes
+  osmanage get meeting --fields name,state --enum-map state=0:inactive,1:active \
+    --postgres-host localhost --postgres-port 5432 \
+    --postgres-user openslides --postgres-database openslides \
+    --postgres-password-file ./secrets/postgres_password
+
+  # Export the votes of a poll for an audit
+  osmanage get vote --filter poll_id=42 --fields option_id,user_id,value,weight --output csv \
+    --postgres-host localhost --postgres-port 5432 \
+    --postgres-user openslides --postgres-database openslides \
+    --postgres-password-file ./secrets/postgres_password
+
+  # Inside a pod, connect using the DATABASE_* env vars
+  osmanage get user --fields username --from-env
+
+  # Fail if the export does not match the expected JSON Schema
+  osmanage get user --fields username,email --validate-output user_export.schema.json \
+    --postgres-host localhost --postgres-port 5432 \
+    --postgres-user openslides --postgres-database openslides \
+    --postgres-password-file ./secrets/postgres_password
+
+  # Fields and filter from files
+  osmanage get user --fields-file export_fields.txt --filter-raw-file active_admins.json \
+    --postgres-host localhost --postgres-port 5432 \
+    --postgres-user openslides --postgres-database openslides \
+    --postgres-password-file ./secrets/postgres_password
+
   # Combined AND filter
   osmanage get user --filter-raw '{"and_filter":[{"field":"first_name","operator":"~=","value":"^Ad"},{"field":"is_active","operator":"=","value":true}]}' \
     --postgres-host localhost --postgres-port 5432 \
@@ -61,11 +167,24 @@ Supported operators in filter-raw:
   >=  : Greater than or equal
   <=  : Less than or equal
   ~=  : Regex match (pattern matching)
+  between : Inclusive numeric range, value is [low, high]
+  contains     : List field contains value
+  not-contains : List field does not contain value
+  is-null : Field is null (no value needed)
+  is-set  : Field is not null, even if empty or zero (no value needed)
+  json-path : Value at "path" (e.g. "$.settings.theme" or "$.items[0]") in a JSON field equals value
+
+Numeric operators (>, <, >=, <=, between) also accept dates as values, which are
+compared as Unix timestamps in seconds:
+  RFC3339    : "2021-01-01T12:00:00Z" or "2021-01-01T12:00:00+01:00"
+  YYYY-MM-DD : "2021-01-01" (midnight UTC)
 
 Supported collections:
   - user
   - meeting
   - organization
+  - poll (requires --filter meeting_id=<id> or poll_id=<id>)
+  - vote (requires --filter meeting_id=<id> or poll_id=<id>)
 
 Note: Filtering is done in-memory after fetching. Field selection reduces memory usage by only loading requested fields.`
 )
@@ -75,9 +194,15 @@ type RawFilter struct {
 	Field     string      `json:"field,omitempty"`
 	Operator  string      `json:"operator,omitempty"`
 	Value     any         `json:"value,omitempty"`
+	Path      string      `json:"path,omitempty"`
 	AndFilter []RawFilter `json:"and_filter,omitempty"`
 	OrFilter  []RawFilter `json:"or_filter,omitempty"`
 	NotFilter *RawFilter  `json:"not_filter,omitempty"`
+
+	// regex is the compiled Value of a ~= condition, see compileRawFilter
+	regex *regexp.Regexp
+	// segments is the parsed Path of a json-path condition, see compileRawFilter
+	segments []any
 }
 
 func Cmd() *cobra.Command {
@@ -89,46 +214,73 @@ func Cmd() *cobra.Command {
 	}
 
 	// PostgreSQL connection flags
-	postgresHost := cmd.Flags().String("postgres-host", "", "PostgreSQL host (required)")
-	postgresPort := cmd.Flags().String("postgres-port", "", "PostgreSQL port (required)")
-	postgresUser := cmd.Flags().String("postgres-user", "", "PostgreSQL user (required)")
-	postgresDatabase := cmd.Flags().String("postgres-database", "", "PostgreSQL database (required)")
-	postgresPasswordFile := cmd.Flags().String("postgres-password-file", "", "PostgreSQL password file (required)")
+	postgresHost := cmd.Flags().String("postgres-host", "", "PostgreSQL host (required without --from-env)")
+	postgresPort := cmd.Flags().String("postgres-port", "", "PostgreSQL port (required without --from-env)")
+	postgresUser := cmd.Flags().String("postgres-user", "", "PostgreSQL user (required without --from-env)")
+	postgresDatabase := cmd.Flags().String("postgres-database", "", "PostgreSQL database (required without --from-env)")
+	postgresPasswordFile := cmd.Flags().String("postgres-password-file", "", "PostgreSQL password file (required without --from-env)")
 
+	fromEnv := cmd.Flags().Bool("from-env", false, "read all PostgreSQL connection parameters from the DATABASE_* env vars instead of --postgres-* flags")
+
+	// Without --from-env, the PostgreSQL flags are required unless set via OSMANAGE_POSTGRES_* env vars
+	var requiredFlags []string
 	if postgresHostEnv := os.Getenv("OSMANAGE_POSTGRES_HOST"); postgresHostEnv != "" {
 		postgresHost = &postgresHostEnv
 	} else {
-		_ = cmd.MarkFlagRequired("postgres-host")
+		requiredFlags = append(requiredFlags, "postgres-host")
 	}
 	if postgresPortEnv := os.Getenv("OSMANAGE_POSTGRES_PORT"); postgresPortEnv != "" {
 		postgresPort = &postgresPortEnv
 	} else {
-		_ = cmd.MarkFlagRequired("postgres-port")
+		requiredFlags = append(requiredFlags, "postgres-port")
 	}
 	if postgresUserEnv := os.Getenv("OSMANAGE_POSTGRES_USER"); postgresUserEnv != "" {
 		postgresUser = &postgresUserEnv
 	} else {
-		_ = cmd.MarkFlagRequired("postgres-user")
+		requiredFlags = append(requiredFlags, "postgres-user")
 	}
 	if postgresDatabaseEnv := os.Getenv("OSMANAGE_POSTGRES_DATABASE"); postgresDatabaseEnv != "" {
 		postgresDatabase = &postgresDatabaseEnv
 	} else {
-		_ = cmd.MarkFlagRequired("postgres-database")
+		requiredFlags = append(requiredFlags, "postgres-database")
 	}
 	if postgresPasswordFileEnv := os.Getenv("OSMANAGE_POSTGRES_PASSWORD_FILE"); postgresPasswordFileEnv != "" {
 		postgresPasswordFile = &postgresPasswordFileEnv
 	} else {
-		_ = cmd.MarkFlagRequired("postgres-password-file")
+		requiredFlags = append(requiredFlags, "postgres-password-file")
+	}
+	for _, name := range postgresFlags {
+		cmd.MarkFlagsMutuallyExclusive("from-env", name)
 	}
 
 	// Query flags
-	fields := cmd.Flags().StringSlice("fields", nil, "only include the provided fields in output")
+	fields := cmd.Flags().StringSlice("fields", nil, "only include the provided fields in output, rename with field:alias")
+	fieldsFile := cmd.Flags().String("fields-file", "", "read fields from file, one per line or comma-separated, '#' starts a comment (use '-' for stdin, merged with --fields)")
 	filter := cmd.Flags().StringToString("filter", nil, "simple filter using '=' operator, multiple filters are AND'ed")
-	rawFilter := cmd.Flags().String("filter-raw", "", "complex filter in JSON format with operators (=, !=, >, <, >=, <=, ~=)")
-	exists := cmd.Flags().Bool("exists", false, "check only for existence (requires --filter or --filter-raw)")
+	rawFilter := cmd.Flags().String("filter-raw", "", "complex filter in JSON format with operators (=, !=, >, <, >=, <=, ~=, between, contains, not-contains, is-null, is-set, json-path)")
+	rawFilterFile := cmd.Flags().String("filter-raw-file", "", "read the filter-raw JSON from file (use '-' for stdin)")
+	exists := cmd.Flags().Bool("exists", false, "check only for existence (requires --filter, --filter-raw or --filter-raw-file)")
+
+	// Output flags
+	output := cmd.Flags().StringP("output", "o", OutputJSON, "output format ("+strings.Join(outputFormats, ", ")+")")
+	csvDelimiter := cmd.Flags().String("csv-delimiter", ",", "field delimiter for csv output")
+	csvNoHeader := cmd.Flags().Bool("csv-no-header", false, "omit the header line in csv output")
+	hashFields := cmd.Flags().StringSlice("hash-fields", nil, "replace the values of these fields with a salted SHA-256 hex digest")
+	hashSalt := cmd.Flags().String("hash-salt", "", "salt for --hash-fields (random per run if empty)")
+	enumMapValues := cmd.Flags().StringArray("enum-map", nil, "translate values of a field to labels, e.g. 'field=0:inactive,1:active' (repeatable)")
+	stream := cmd.Flags().Bool("stream", false, "fetch and write models in chunks to bound memory usage (requires --output jsonl, ndjson-summary or csv)")
+	chunkSize := cmd.Flags().Int("chunk-size", constants.DefaultStreamChunkSize, "number of models fetched per chunk with --stream")
+	timing := cmd.Flags().Bool("timing", false, "print the duration of each query phase to stderr")
+	validateSchema := cmd.Flags().String("validate-output", "", "JSON Schema file to validate the output against (requires --output json)")
+	flatten := cmd.Flags().Bool("flatten", false, "write a JSON array sorted by id instead of an object keyed by id (requires --output json)")
+	distinct := cmd.Flags().Bool("distinct", false, "drop models whose selected fields, ignoring id, equal those of a model with a lower id")
+	groupBy := cmd.Flags().String("group-by", "", "write the number of models per value of this field instead of the models (requires --output json)")
+	groupValues := cmd.Flags().Bool("group-values", false, "with --group-by, write the ids of the models per value instead of their number")
+	outputFile := cmd.Flags().String("output-file", "", "write the output to this file instead of stdout")
+	explain := cmd.Flags().Bool("explain", false, "print the fetch plan (fields, number of models, filtering) instead of executing the query")
 
 	// Filter and raw filter flags are mutually exclusive
-	cmd.MarkFlagsMutuallyExclusive("filter", "filter-raw")
+	cmd.MarkFlagsMutuallyExclusive("filter", "filter-raw", "filter-raw-file")
 
 	cmd.RunE = func(cmd *cobra.Command, args []string) error {
 		logger.Info("=== GET COLLECTION ===")
@@ -137,17 +289,125 @@ func Cmd() *cobra.Command {
 		logger.Debug("Collection: %s", collection)
 
 		// Validate flags
+		if missing := missingFlags(cmd, requiredFlags); !*fromEnv && len(missing) > 0 {
+			return fmt.Errorf("required flag(s) \"%s\" not set (or use --from-env)", strings.Join(missing, `", "`))
+		}
+		if *fieldsFile == "-" && *rawFilterFile == "-" {
+			return fmt.Errorf("--fields-file and --filter-raw-file cannot both read from stdin")
+		}
+		if *fieldsFile != "" {
+			fileFields, err := readFieldsFile(*fieldsFile)
+			if err != nil {
+				return err
+			}
+			*fields = mergeFields(*fields, fileFields)
+		}
+		if err := validateFieldAliases(*fields); err != nil {
+			return err
+		}
+		if *rawFilterFile != "" {
+			data, err := readRawFilterFile(*rawFilterFile)
+			if err != nil {
+				return err
+			}
+			*rawFilter = data
+		}
 		if *exists && len(*filter) == 0 && *rawFilter == "" {
-			return fmt.Errorf("--exists requires --filter or --filter-raw")
+			return fmt.Errorf("--exists requires --filter, --filter-raw or --filter-raw-file")
+		}
+		if !slices.Contains(outputFormats, *output) {
+			return fmt.Errorf("invalid --output %q (available: %s)", *output, strings.Join(outputFormats, ", "))
+		}
+		delimiter, err := parseDelimiter(*csvDelimiter)
+		if err != nil {
+			return err
+		}
+		if *stream {
+			if *output == OutputJSON {
+				return fmt.Errorf("--stream requires --output %s, %s or %s", OutputJSONL, OutputNDJSONSummary, OutputCSV)
+			}
+			if *exists {
+				return fmt.Errorf("--stream cannot be used with --exists")
+			}
+			if *chunkSize <= 0 {
+				return fmt.Errorf("--chunk-size must be positive")
+			}
+			if *timing {
+				return fmt.Errorf("--stream cannot be used with --timing")
+			}
+			if *explain {
+				return fmt.Errorf("--stream cannot be used with --explain")
+			}
+		}
+		if *distinct && *exists {
+			return fmt.Errorf("--distinct cannot be used with --exists")
+		}
+		if *flatten {
+			if *output != OutputJSON {
+				return fmt.Errorf("--flatten requires --output %s", OutputJSON)
+			}
+			if *exists {
+				return fmt.Errorf("--flatten cannot be used with --exists")
+			}
+		}
+		if *groupValues && *groupBy == "" {
+			return fmt.Errorf("--group-values requires --group-by")
+		}
+		if *groupBy != "" {
+			if err := validateGroupBy(*groupBy, *fields); err != nil {
+				return err
+			}
+			if *output != OutputJSON {
+				return fmt.Errorf("--group-by requires --output %s", OutputJSON)
+			}
+			if *stream || *exists || *flatten {
+				return fmt.Errorf("--group-by cannot be used with --stream, --exists or --flatten")
+			}
+		}
+		if *validateSchema != "" {
+			if *output != OutputJSON {
+				return fmt.Errorf("--validate-output requires --output %s", OutputJSON)
+			}
+			if *stream || *exists {
+				return fmt.Errorf("--validate-output cannot be used with --stream or --exists")
+			}
+		}
+		*hashFields, err = hashOutputKeys(*fields, *hashFields)
+		if err != nil {
+			return err
+		}
+		enumMaps, err := parseEnumMaps(*enumMapValues)
+		if err != nil {
+			return err
+		}
+		enumMaps = enumMaps.withOutputKeys(*fields)
+		salt := *hashSalt
+		if len(*hashFields) > 0 && salt == "" {
+			if salt, err = randomSalt(); err != nil {
+				return err
+			}
+			logger.Info("Using random hash salt for this run")
+		}
+		var schema *spec.Schema
+		if *validateSchema != "" {
+			if schema, err = loadSchema(*validateSchema); err != nil {
+				return err
+			}
 		}
 
 		// Build database config
-		dbConfig := &pb.DatabaseConfig{
-			Host:         *postgresHost,
-			Port:         *postgresPort,
-			User:         *postgresUser,
-			Database:     *postgresDatabase,
-			PasswordFile: *postgresPasswordFile,
+		var dbConfig *pb.DatabaseConfig
+		if *fromEnv {
+			dbConfig = dbConfigFromEnv(os.Getenv)
+			logger.Debug("Using PostgreSQL connection from DATABASE_* env vars")
+		} else {
+			dbConfig = &pb.DatabaseConfig{
+				Host:         *postgresHost,
+				Port:         *postgresPort,
+				User:         *postgresUser,
+				Database:     *postgresDatabase,
+				PasswordFile: *postgresPasswordFile,
+			}
 		}
 
 		// Build query params
@@ -164,11 +424,66 @@ func Cmd() *cobra.Command {
 			queryParams.RawFilter = []byte(*rawFilter)
 		}
 
-		// Execute query using exported function
-		result, err := ExecuteGetCollection(context.Background(), dbConfig, queryParams)
+		csvOpts := CSVOptions{Delimiter: delimiter, NoHeader: *csvNoHeader}
+
+		ctx, cancel := utils.TimeoutContext(cmd, 0)
+		defer cancel()
+
+		if *stream {
+			// Streamed output is written to the file directly to keep memory bounded
+			var w io.Writer = os.Stdout
+			var f *os.File
+			if *outputFile != "" {
+				if f, err = utils.CreateOutputFile(*outputFile); err != nil {
+					return err
+				}
+				defer func() { _ = f.Close() }()
+				w = f
+			}
+			var dedup *distinctFilter
+			if *distinct {
+				dedup = newDistinctFilter(*fields)
+			}
+			if err := streamOutput(ctx, w, dbConfig, queryParams, *chunkSize, *output, csvOpts, *hashFields, salt, enumMaps, dedup); err != nil {
+				return fmt.Errorf("streaming query: %w", err)
+			}
+			if f != nil {
+				if err := f.Close(); err != nil {
+					return fmt.Errorf("closing output file: %w", err)
+				}
+				fmt.Fprintf(os.Stderr, "Output written to %s\n", *outputFile)
+			}
+			logger.Info("Query completed successfully")
+			return nil
+		}
+
+		// The output is buffered for --output-file, so no partial file is
+		// written when the query or validation fails
+		var out io.Writer = os.Stdout
+		var buf bytes.Buffer
+		if *outputFile != "" {
+			out = &buf
+		}
+
+		if *explain {
+			plan, err := explainGetCollection(ctx, dbConfig, queryParams)
+			if err != nil {
+				return fmt.Errorf("explaining query: %w", err)
+			}
+			plan.write(out)
+			return writeOutputFile(*outputFile, buf.Bytes())
+		}
+
+		var timings *queryTimings
+		if *timing {
+			timings = &queryTimings{}
+		}
+
+		result, err := executeGetCollection(ctx, dbConfig, queryParams, timings)
 		if err != nil {
 			return fmt.Errorf("executing query: %w", err)
 		}
+		timings.write(os.Stderr)
 
 		if !result.Success && result.Error != "" {
 			return fmt.Errorf("query failed: %s", result.Error)
@@ -177,13 +492,75 @@ func Cmd() *cobra.Command {
 		// Print result
 		switch r := result.Result.(type) {
 		case *pb.GetCollectionResponse_Exists:
-			fmt.Printf("%v\n", r.Exists)
+			fmt.Fprintf(out, "%v\n", r.Exists)
 		case *pb.GetCollectionResponse_JsonData:
-			fmt.Println(string(r.JsonData))
+			if *output == OutputJSON && len(*hashFields) == 0 && len(enumMaps) == 0 && !*flatten && !*distinct && *groupBy == "" {
+				if schema != nil {
+					if err := validateOutput(schema, r.JsonData); err != nil {
+						return err
+					}
+				}
+				fmt.Fprintln(out, string(r.JsonData))
+				break
+			}
+
+			records, err := decodeRecords(r.JsonData, collection)
+			if err != nil {
+				return err
+			}
+			if *distinct {
+				if records, err = distinctRecords(records, *fields); err != nil {
+					return err
+				}
+			}
+			applyEnumMaps(records, enumMaps)
+			if err := hashRecordFields(records, *hashFields, salt); err != nil {
+				return fmt.Errorf("hashing fields: %w", err)
+			}
+
+			switch *output {
+			case OutputCSV:
+				columns := csvColumns(records, *fields)
+				if err := writeCSV(out, records, columns, csvOpts); err != nil {
+					return fmt.Errorf("writing csv: %w", err)
+				}
+			case OutputJSONL:
+				if err := writeJSONL(out, records); err != nil {
+					return err
+				}
+			case OutputNDJSONSummary:
+				if err := writeNDJSONSummary(out, records); err != nil {
+					return err
+				}
+			default:
+				var data []byte
+				if *groupBy != "" {
+					var groups map[string]any
+					if groups, err = groupRecords(records, *groupBy, outputKey(*fields, "id"), *groupValues); err == nil {
+						data, err = encodeGroups(groups)
+					}
+				} else if *flatten {
+					data, err = encodeRecordArray(records)
+				} else {
+					data, err = encodeRecords(records, collection, outputKey(*fields, "id"))
+				}
+				if err != nil {
+					return err
+				}
+				if schema != nil {
+					if err := validateOutput(schema, data); err != nil {
+						return err
+					}
+				}
+				fmt.Fprintln(out, string(data))
+			}
 		default:
 			return fmt.Errorf("unexpected result type")
 		}
 
+		if err := writeOutputFile(*outputFile, buf.Bytes()); err != nil {
+			return err
+		}
 		logger.Info("Query completed successfully")
 		return nil
 	}
@@ -191,8 +568,84 @@ func Cmd() *cobra.Command {
 	return cmd
 }
 
+// writeOutputFile writes data to filename and reports the path on stderr. It
+// does nothing without a filename.
+func writeOutputFile(filename string, data []byte) error {
+	if filename == "" {
+		return nil
+	}
+	if err := utils.WriteOutputFile(filename, data); err != nil {
+		return fmt.Errorf("writing output file: %w", err)
+	}
+	fmt.Fprintf(os.Stderr, "Output written to %s\n", filename)
+	return nil
+}
+
+// streamOutput runs a streaming query and writes each model to w as soon as
+// its chunk has been fetched.
+func streamOutput(ctx context.Context, w io.Writer, dbConfig *pb.DatabaseConfig, params *pb.QueryParams, chunkSize int, output string, csvOpts CSVOptions, hashFields []string, salt string, enumMaps EnumMaps, dedup *distinctFilter) error {
+	var csvWriter *csvRecordWriter
+	encoder := json.NewEncoder(w)
+	count := 0
+
+	emit := func(record map[string]any) error {
+		normalized, err := normalizeRecord(record)
+		if err != nil {
+			return err
+		}
+		if keep, err := dedup.keep(normalized); err != nil || !keep {
+			return err
+		}
+		applyEnumMaps([]map[string]any{normalized}, enumMaps)
+		if err := hashRecordFields([]map[string]any{normalized}, hashFields, salt); err != nil {
+			return fmt.Errorf("hashing fields: %w", err)
+		}
+
+		count++
+		if output == OutputJSONL || output == OutputNDJSONSummary {
+			if err := encoder.Encode(normalized); err != nil {
+				return fmt.Errorf("writing json line: %w", err)
+			}
+			return nil
+		}
+
+		if csvWriter == nil {
+			// All records carry the same fields, so the first one defines the columns
+			columns := csvColumns([]map[string]any{normalized}, params.Fields)
+			if csvWriter, err = newCSVRecordWriter(w, columns, csvOpts); err != nil {
+				return err
+			}
+		}
+		return csvWriter.Write(normalized)
+	}
+
+	if err := StreamGetCollection(ctx, dbConfig, params, chunkSize, emit); err != nil {
+		return err
+	}
+
+	if output == OutputNDJSONSummary {
+		return writeSummary(w, count)
+	}
+	if output == OutputCSV {
+		if csvWriter == nil {
+			var err error
+			if csvWriter, err = newCSVRecordWriter(w, csvColumns(nil, params.Fields), csvOpts); err != nil {
+				return err
+			}
+		}
+		return csvWriter.Flush()
+	}
+	return nil
+}
+
 // ExecuteGetCollection executes a datastore query and returns the result.
 func ExecuteGetCollection(ctx context.Context, dbConfig *pb.DatabaseConfig, params *pb.QueryParams) (*pb.GetCollectionResponse, error) {
+	return executeGetCollection(ctx, dbConfig, params, nil)
+}
+
+// executeGetCollection works like ExecuteGetCollection and records the duration
+// of each query phase in timings, if not nil.
+func executeGetCollection(ctx context.Context, dbConfig *pb.DatabaseConfig, params *pb.QueryParams, timings *queryTimings) (*pb.GetCollectionResponse, error) {
 	logger.Debug("Executing get models query for collection: %s", params.Collection)
 
 	// Validate required fields
@@ -208,6 +661,12 @@ func ExecuteGetCollection(ctx context.Context, dbConfig *pb.DatabaseConfig, para
 			Error:   "query params are required",
 		}, nil
 	}
+	if err := validateFieldAliases(params.Fields); err != nil {
+		return &pb.GetCollectionResponse{
+			Success: false,
+			Error:   err.Error(),
+		}, nil
+	}
 
 	// Parse raw filter if provided
 	var parsedRawFilter *RawFilter
@@ -219,35 +678,24 @@ func ExecuteGetCollection(ctx context.Context, dbConfig *pb.DatabaseConfig, para
 				Error:   fmt.Sprintf("parsing filter-raw: %v", err),
 			}, nil
 		}
+		if err := compileRawFilter(parsedRawFilter); err != nil {
+			return &pb.GetCollectionResponse{
+				Success: false,
+				Error:   err.Error(),
+			}, nil
+		}
 	}
 
-	// Create environment map for database connection
-	envMap := map[string]string{
-		constants.EnvDatabaseHost:          dbConfig.Host,
-		constants.EnvDatabasePort:          dbConfig.Port,
-		constants.EnvDatabaseUser:          dbConfig.User,
-		constants.EnvDatabaseName:          dbConfig.Database,
-		constants.EnvDatabasePasswordFile:  dbConfig.PasswordFile,
-		constants.EnvOpenSlidesDevelopment: constants.DevelopmentModeDisabled,
-	}
-
-	// Initialize datastore flow
-	env := environment.ForTests(envMap)
-	dsFlow, err := datastore.NewFlowPostgres(env)
+	fetch, err := newFetch(dbConfig)
 	if err != nil {
 		return &pb.GetCollectionResponse{
 			Success: false,
-			Error:   fmt.Sprintf("creating datastore flow: %v", err),
+			Error:   err.Error(),
 		}, nil
 	}
 
-	logger.Info("Connected to database successfully")
-
-	// Create fetcher
-	fetch := dsfetch.New(dsFlow)
-
 	// Execute query
-	rawResult, err := executeQuery(ctx, fetch, params.Collection, params.SimpleFilter, parsedRawFilter, params.Fields, params.ExistsOnly)
+	rawResult, err := executeQuery(ctx, fetch, params.Collection, params.SimpleFilter, parsedRawFilter, params.Fields, params.ExistsOnly, timings)
 	if err != nil {
 		return &pb.GetCollectionResponse{
 			Success: false,
@@ -270,7 +718,9 @@ func ExecuteGetCollection(ctx context.Context, dbConfig *pb.DatabaseConfig, para
 		response.Result = &pb.GetCollectionResponse_Exists{Exists: exists}
 	} else {
 		// Result is data - marshal to JSON bytes
+		start := timings.clock()
 		jsonBytes, err := json.MarshalIndent(rawResult, "", "  ")
+		timings.record(phaseMarshalling, start)
 		if err != nil {
 			return &pb.GetCollectionResponse{
 				Success: false,
@@ -283,14 +733,111 @@ func ExecuteGetCollection(ctx context.Context, dbConfig *pb.DatabaseConfig, para
 	return response, nil
 }
 
-func executeQuery(ctx context.Context, fetch *dsfetch.Fetch, collection string, filter map[string]string, rawFilter *RawFilter, fields []string, existsOnly bool) (any, error) {
+// StreamGetCollection executes a datastore query like ExecuteGetCollection but
+// passes each matching model to emit instead of collecting the result. Models are
+// fetched chunkSize at a time to keep memory usage bounded.
+func StreamGetCollection(ctx context.Context, dbConfig *pb.DatabaseConfig, params *pb.QueryParams, chunkSize int, emit func(map[string]any) error) error {
+	if dbConfig == nil {
+		return fmt.Errorf("database config is required")
+	}
+	if params == nil {
+		return fmt.Errorf("query params are required")
+	}
+	if params.Collection == "organization" {
+		return fmt.Errorf("streaming is not supported for collection '%s'", params.Collection)
+	}
+
+	var parsedRawFilter *RawFilter
+	if len(params.RawFilter) > 0 {
+		parsedRawFilter = &RawFilter{}
+		if err := json.Unmarshal(params.RawFilter, parsedRawFilter); err != nil {
+			return fmt.Errorf("parsing filter-raw: %w", err)
+		}
+		if err := compileRawFilter(parsedRawFilter); err != nil {
+			return err
+		}
+	}
+
+	fetch, err := newFetch(dbConfig)
+	if err != nil {
+		return err
+	}
+
+	return streamCollection(ctx, fetch, params.Collection, params.SimpleFilter, parsedRawFilter, params.Fields, chunkSize, emit)
+}
+
+// postgresFlags are the flags for the PostgreSQL connection
+var postgresFlags = []string{"postgres-host", "postgres-port", "postgres-user", "postgres-database", "postgres-password-file"}
+
+// missingFlags returns the names of flags that were not given on the command line
+func missingFlags(cmd *cobra.Command, names []string) []string {
+	var missing []string
+	for _, name := range names {
+		if !utils.FlagGiven(cmd, name) {
+			missing = append(missing, name)
+		}
+	}
+	return missing
+}
+
+// dbConfigFromEnv builds the database config from the DATABASE_* env vars
+// read with getenv. Unset vars are left empty, so the datastore defaults apply.
+func dbConfigFromEnv(getenv func(string) string) *pb.DatabaseConfig {
+	return &pb.DatabaseConfig{
+		Host:         getenv(constants.EnvDatabaseHost),
+		Port:         getenv(constants.EnvDatabasePort),
+		User:         getenv(constants.EnvDatabaseUser),
+		Database:     getenv(constants.EnvDatabaseName),
+		PasswordFile: getenv(constants.EnvDatabasePasswordFile),
+	}
+}
+
+// connectionEnv returns the environment map for the datastore connection.
+// Empty values of dbConfig are omitted so the datastore defaults apply.
+func connectionEnv(dbConfig *pb.DatabaseConfig) map[string]string {
+	envMap := map[string]string{
+		constants.EnvOpenSlidesDevelopment: constants.DevelopmentModeDisabled,
+	}
+	for key, value := range map[string]string{
+		constants.EnvDatabaseHost:         dbConfig.Host,
+		constants.EnvDatabasePort:         dbConfig.Port,
+		constants.EnvDatabaseUser:         dbConfig.User,
+		constants.EnvDatabaseName:         dbConfig.Database,
+		constants.EnvDatabasePasswordFile: dbConfig.PasswordFile,
+	} {
+		if value != "" {
+			envMap[key] = value
+		}
+	}
+	return envMap
+}
+
+// newFetch connects to the database described by dbConfig
+func newFetch(dbConfig *pb.DatabaseConfig) (*dsfetch.Fetch, error) {
+	// Initialize datastore flow
+	env := environment.ForTests(connectionEnv(dbConfig))
+	dsFlow, err := datastore.NewFlowPostgres(env)
+	if err != nil {
+		return nil, fmt.Errorf("creating datastore flow: %w", err)
+	}
+
+	logger.Info("Connected to database successfully")
+
+	return dsfetch.New(dsFlow), nil
+}
+
+func executeQuery(ctx context.Context, fetch *dsfetch.Fetch, collection string, filter map[string]string, rawFilter *RawFilter, fields []string, existsOnly bool, timings *queryTimings) (any, error) {
 	logger.Debug("Executing query for collection: %s", collection)
 
 	switch collection {
 	case "user":
-		return queryUsers(ctx, fetch, filter, rawFilter, fields, existsOnly)
+		return queryUsers(ctx, fetch, filter, rawFilter, fields, existsOnly, timings)
 	case "meeting":
-		return queryMeetings(ctx, fetch, filter, rawFilter, fields, existsOnly)
+		return queryMeetings(ctx, fetch, filter, rawFilter, fields, existsOnly, timings)
+	case "poll":
+		return queryPolls(ctx, fetch, filter, rawFilter, fields, existsOnly, timings)
+	case "vote":
+		return queryVotes(ctx, fetch, filter, rawFilter, fields, existsOnly, timings)
 	case "organization":
 		return queryOrganization(ctx, fetch, fields, existsOnly)
 	default:
@@ -298,89 +845,194 @@ func executeQuery(ctx context.Context, fetch *dsfetch.Fetch, collection string,
 	}
 }
 
-func queryUsers(ctx context.Context, fetch *dsfetch.Fetch, filter map[string]string, rawFilter *RawFilter, fields []string, existsOnly bool) (any, error) {
+func queryUsers(ctx context.Context, fetch *dsfetch.Fetch, filter map[string]string, rawFilter *RawFilter, fields []string, existsOnly bool, timings *queryTimings) (any, error) {
 	logger.Debug("Querying users with fields: %v, filter: %v, rawFilter: %v", fields, filter, rawFilter)
+	return queryCollection(ctx, fetch, "user", filter, rawFilter, fields, existsOnly, timings)
+}
 
-	// Get user IDs from organization
-	var userIDs []int
-	fetch.Organization_UserIDs(constants.DefaultOrganizationID).Lazy(&userIDs)
-	if err := fetch.Execute(ctx); err != nil {
-		return nil, fmt.Errorf("fetching user IDs: %w", err)
-	}
+func queryMeetings(ctx context.Context, fetch *dsfetch.Fetch, filter map[string]string, rawFilter *RawFilter, fields []string, existsOnly bool, timings *queryTimings) (any, error) {
+	logger.Debug("Querying meetings with fields: %v, filter: %v, rawFilter: %v", fields, filter, rawFilter)
+	return queryCollection(ctx, fetch, "meeting", filter, rawFilter, fields, existsOnly, timings)
+}
 
-	logger.Debug("Found %d total users", len(userIDs))
+func queryPolls(ctx context.Context, fetch *dsfetch.Fetch, filter map[string]string, rawFilter *RawFilter, fields []string, existsOnly bool, timings *queryTimings) (any, error) {
+	logger.Debug("Querying polls with fields: %v, filter: %v, rawFilter: %v", fields, filter, rawFilter)
+	return queryCollection(ctx, fetch, "poll", filter, rawFilter, fields, existsOnly, timings)
+}
+
+func queryVotes(ctx context.Context, fetch *dsfetch.Fetch, filter map[string]string, rawFilter *RawFilter, fields []string, existsOnly bool, timings *queryTimings) (any, error) {
+	logger.Debug("Querying votes with fields: %v, filter: %v, rawFilter: %v", fields, filter, rawFilter)
+	return queryCollection(ctx, fetch, "vote", filter, rawFilter, fields, existsOnly, timings)
+}
+
+// queryCollection loads all models of a collection listed in the organization,
+// or for polls and votes in the meeting or poll selected by filter
+func queryCollection(ctx context.Context, fetch *dsfetch.Fetch, collection string, filter map[string]string, rawFilter *RawFilter, fields []string, existsOnly bool, timings *queryTimings) (any, error) {
+	start := timings.clock()
+	ids, filter, err := collectionIDs(ctx, fetch, collection, filter)
+	if err != nil {
+		return nil, err
+	}
+	timings.record(phaseIDDiscovery, start)
 
 	fieldsToFetch := determineFieldsToFetch(fields, filter, rawFilter)
 	logger.Debug("Fields to fetch: %v", fieldsToFetch)
 
-	// Fetch fields for each user
-	users := make([]map[string]any, 0, len(userIDs))
-	for _, userID := range userIDs {
-		user := map[string]any{"id": userID}
-		for _, field := range fieldsToFetch {
-			if field == "id" {
-				continue
-			}
-			value, err := fetchField(fetch, "user", userID, field)
-			if err != nil {
-				return nil, fmt.Errorf("fetching user %d field %s: %w", userID, field, err)
-			}
-			user[field] = value
-		}
-		users = append(users, user)
+	start = timings.clock()
+	records, err := fetchRecords(ctx, fetch, collection, ids, fieldsToFetch)
+	if err != nil {
+		return nil, err
 	}
+	timings.record(phaseFieldFetching, start)
 
-	// Execute all lazy fetches
-	if err := fetch.Execute(ctx); err != nil {
-		return nil, fmt.Errorf("executing batch fetch: %w", err)
+	start = timings.clock()
+	records, err = applyFilters(records, filter, rawFilter)
+	if err != nil {
+		return nil, err
 	}
-
-	users = applyFilters(users, filter, rawFilter)
+	timings.record(phaseFiltering, start)
 
 	if existsOnly {
-		return len(users) > 0, nil
+		return len(records) > 0, nil
 	}
 
 	if len(fields) > 0 {
-		users = selectFields(users, fields)
+		records = selectFields(records, fields)
 	}
 
-	return convertToMapFormat(users), nil
+	return convertToMapFormat(records, outputKey(fields, "id")), nil
 }
 
-func queryMeetings(ctx context.Context, fetch *dsfetch.Fetch, filter map[string]string, rawFilter *RawFilter, fields []string, existsOnly bool) (any, error) {
-	logger.Debug("Querying meetings with fields: %v, filter: %v, rawFilter: %v", fields, filter, rawFilter)
+// collectionIDs returns the ids of all users or meetings (active and archived)
+// of the organization. For polls and votes it returns the ids in the meeting or
+// poll selected by filter, see scopedIDs. The returned filter is the one to
+// apply to the fetched models.
+func collectionIDs(ctx context.Context, fetch *dsfetch.Fetch, collection string, filter map[string]string) ([]int, map[string]string, error) {
+	if collection == "poll" || collection == "vote" {
+		return scopedIDs(ctx, fetch, collection, filter)
+	}
+
+	var ids []int
+	switch collection {
+	case "user":
+		fetch.Organization_UserIDs(constants.DefaultOrganizationID).Lazy(&ids)
+		if err := fetch.Execute(ctx); err != nil {
+			return nil, nil, fmt.Errorf("fetching user IDs: %w", err)
+		}
+	case "meeting":
+		var activeMeetingIDs, archivedMeetingIDs []int
+		fetch.Organization_ActiveMeetingIDs(constants.DefaultOrganizationID).Lazy(&activeMeetingIDs)
+		fetch.Organization_ArchivedMeetingIDs(constants.DefaultOrganizationID).Lazy(&archivedMeetingIDs)
+		if err := fetch.Execute(ctx); err != nil {
+			return nil, nil, fmt.Errorf("fetching meeting IDs: %w", err)
+		}
+		ids = append(activeMeetingIDs, archivedMeetingIDs...)
+	default:
+		return nil, nil, fmt.Errorf("collection '%s' not yet supported", collection)
+	}
+
+	logger.Debug("Found %d total %ss", len(ids), collection)
+	return ids, filter, nil
+}
+
+// scopedIDs returns the ids of the polls or votes of the meeting_id or poll_id
+// given in filter. Polls and votes are only listed per meeting, so one of them is
+// required. poll_id is removed from the returned filter, as it is no field of
+// these collections; poll_id takes precedence if both are given.
+func scopedIDs(ctx context.Context, fetch *dsfetch.Fetch, collection string, filter map[string]string) ([]int, map[string]string, error) {
+	if pollValue, ok := filter["poll_id"]; ok {
+		pollID, err := strconv.Atoi(pollValue)
+		if err != nil || pollID <= 0 {
+			return nil, nil, fmt.Errorf("invalid poll_id filter %q", pollValue)
+		}
+
+		rest := make(map[string]string, len(filter))
+		for field, value := range filter {
+			if field != "poll_id" {
+				rest[field] = value
+			}
+		}
+
+		if collection == "poll" {
+			return []int{pollID}, rest, nil
+		}
+
+		ids, err := pollVoteIDs(ctx, fetch, pollID)
+		if err != nil {
+			return nil, nil, err
+		}
+		logger.Debug("Found %d votes in poll %d", len(ids), pollID)
+		return ids, rest, nil
+	}
+
+	meetingValue, ok := filter["meeting_id"]
+	if !ok {
+		return nil, nil, fmt.Errorf("collection '%s' requires a meeting_id or poll_id filter", collection)
+	}
+	meetingID, err := strconv.Atoi(meetingValue)
+	if err != nil || meetingID <= 0 {
+		return nil, nil, fmt.Errorf("invalid meeting_id filter %q", meetingValue)
+	}
+
+	var ids []int
+	if collection == "poll" {
+		fetch.Meeting_PollIDs(meetingID).Lazy(&ids)
+	} else {
+		fetch.Meeting_VoteIDs(meetingID).Lazy(&ids)
+	}
+	if err := fetch.Execute(ctx); err != nil {
+		return nil, nil, fmt.Errorf("fetching %s IDs of meeting %d: %w", collection, meetingID, err)
+	}
 
-	// Get active and archived meeting IDs
-	var activeMeetingIDs, archivedMeetingIDs []int
-	fetch.Organization_ActiveMeetingIDs(constants.DefaultOrganizationID).Lazy(&activeMeetingIDs)
-	fetch.Organization_ArchivedMeetingIDs(constants.DefaultOrganizationID).Lazy(&archivedMeetingIDs)
+	logger.Debug("Found %d %ss in meeting %d", len(ids), collection, meetingID)
+	return ids, filter, nil
+}
 
+// pollVoteIDs returns the ids of the votes on all options of a poll, including
+// its global option
+func pollVoteIDs(ctx context.Context, fetch *dsfetch.Fetch, pollID int) ([]int, error) {
+	var optionIDs []int
+	var globalOptionID dsfetch.Maybe[int]
+	fetch.Poll_OptionIDs(pollID).Lazy(&optionIDs)
+	fetch.Poll_GlobalOptionID(pollID).Lazy(&globalOptionID)
 	if err := fetch.Execute(ctx); err != nil {
-		return nil, fmt.Errorf("fetching meeting IDs: %w", err)
+		return nil, fmt.Errorf("fetching options of poll %d: %w", pollID, err)
+	}
+	if id, ok := globalOptionID.Value(); ok {
+		optionIDs = append(optionIDs, id)
 	}
 
-	meetingIDs := append(activeMeetingIDs, archivedMeetingIDs...)
-	logger.Debug("Found %d total meetings", len(meetingIDs))
+	voteIDs := make([][]int, len(optionIDs))
+	for i, optionID := range optionIDs {
+		fetch.Option_VoteIDs(optionID).Lazy(&voteIDs[i])
+	}
+	if err := fetch.Execute(ctx); err != nil {
+		return nil, fmt.Errorf("fetching votes of poll %d: %w", pollID, err)
+	}
 
-	fieldsToFetch := determineFieldsToFetch(fields, filter, rawFilter)
-	logger.Debug("Fields to fetch: %v", fieldsToFetch)
+	var ids []int
+	for _, optionVoteIDs := range voteIDs {
+		ids = append(ids, optionVoteIDs...)
+	}
+	return ids, nil
+}
 
-	// Fetch fields for each meeting
-	meetings := make([]map[string]any, 0, len(meetingIDs))
-	for _, meetingID := range meetingIDs {
-		meeting := map[string]any{"id": meetingID}
+// fetchRecords loads the given fields of the models with the given ids in one batch
+func fetchRecords(ctx context.Context, fetch *dsfetch.Fetch, collection string, ids []int, fieldsToFetch []string) ([]map[string]any, error) {
+	records := make([]map[string]any, 0, len(ids))
+	for _, id := range ids {
+		record := map[string]any{"id": id}
 		for _, field := range fieldsToFetch {
 			if field == "id" {
 				continue
 			}
-			value, err := fetchField(fetch, "meeting", meetingID, field)
+			value, err := fetchField(fetch, collection, id, field)
 			if err != nil {
-				return nil, fmt.Errorf("fetching meeting %d field %s: %w", meetingID, field, err)
+				return nil, fmt.Errorf("fetching %s %d field %s: %w", collection, id, field, err)
 			}
-			meeting[field] = value
+			record[field] = value
 		}
-		meetings = append(meetings, meeting)
+		records = append(records, record)
 	}
 
 	// Execute all lazy fetches
@@ -388,17 +1040,102 @@ func queryMeetings(ctx context.Context, fetch *dsfetch.Fetch, filter map[string]
 		return nil, fmt.Errorf("executing batch fetch: %w", err)
 	}
 
-	meetings = applyFilters(meetings, filter, rawFilter)
+	return records, nil
+}
 
-	if existsOnly {
-		return len(meetings) > 0, nil
+// streamCollection works like queryCollection but fetches, filters and emits
+// the models in chunks of chunkSize ids in ascending id order, so only one
+// chunk is held in memory at a time.
+func streamCollection(ctx context.Context, fetch *dsfetch.Fetch, collection string, filter map[string]string, rawFilter *RawFilter, fields []string, chunkSize int, emit func(map[string]any) error) error {
+	if chunkSize <= 0 {
+		return fmt.Errorf("chunk size must be positive, got %d", chunkSize)
 	}
 
-	if len(fields) > 0 {
-		meetings = selectFields(meetings, fields)
+	ids, filter, err := collectionIDs(ctx, fetch, collection, filter)
+	if err != nil {
+		return err
 	}
+	slices.Sort(ids)
+
+	fieldsToFetch := determineFieldsToFetch(fields, filter, rawFilter)
+	logger.Debug("Streaming %d %ss in chunks of %d, fields to fetch: %v", len(ids), collection, chunkSize, fieldsToFetch)
 
-	return convertToMapFormat(meetings), nil
+	for chunk := range slices.Chunk(ids, chunkSize) {
+		records, err := fetchRecords(ctx, fetch, collection, chunk, fieldsToFetch)
+		if err != nil {
+			return err
+		}
+
+		records, err = applyFilters(records, filter, rawFilter)
+		if err != nil {
+			return err
+		}
+		if len(fields) > 0 {
+			records = selectFields(records, fields)
+		}
+
+		for _, record := range records {
+			if err := emit(record); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// explainGetCollection resolves the fetch plan of a query without loading
+// any field values. Only the ids of the collection are fetched.
+func explainGetCollection(ctx context.Context, dbConfig *pb.DatabaseConfig, params *pb.QueryParams) (*queryPlan, error) {
+	var parsedRawFilter *RawFilter
+	if len(params.RawFilter) > 0 {
+		parsedRawFilter = &RawFilter{}
+		if err := json.Unmarshal(params.RawFilter, parsedRawFilter); err != nil {
+			return nil, fmt.Errorf("parsing filter-raw: %w", err)
+		}
+		if err := compileRawFilter(parsedRawFilter); err != nil {
+			return nil, err
+		}
+	}
+
+	fetch, err := newFetch(dbConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	return explainQuery(ctx, fetch, params.Collection, params.SimpleFilter, parsedRawFilter, params.Fields)
+}
+
+// explainQuery returns the plan queryCollection or queryOrganization would
+// follow for the given query
+func explainQuery(ctx context.Context, fetch *dsfetch.Fetch, collection string, filter map[string]string, rawFilter *RawFilter, fields []string) (*queryPlan, error) {
+	if collection == "organization" {
+		fieldsToFetch := make([]string, 0, len(fields))
+		for _, spec := range fields {
+			field, _ := splitFieldAlias(spec)
+			fieldsToFetch = append(fieldsToFetch, field)
+		}
+		if len(fieldsToFetch) == 0 {
+			fieldsToFetch = strings.Split(constants.DefaultOrganizationFields, ",")
+		}
+		slices.Sort(fieldsToFetch)
+		return &queryPlan{collection: collection, models: 1, fields: fieldsToFetch}, nil
+	}
+
+	ids, filter, err := collectionIDs(ctx, fetch, collection, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	fieldsToFetch := determineFieldsToFetch(fields, filter, rawFilter)
+	slices.Sort(fieldsToFetch)
+
+	return &queryPlan{
+		collection:       collection,
+		models:           len(ids),
+		fields:           fieldsToFetch,
+		clientSideFilter: len(filter) > 0 || rawFilter != nil,
+	}, nil
 }
 
 func queryOrganization(ctx context.Context, fetch *dsfetch.Fetch, fields []string, existsOnly bool) (any, error) {
@@ -418,12 +1155,13 @@ func queryOrganization(ctx context.Context, fetch *dsfetch.Fetch, fields []strin
 	}
 
 	org := make(map[string]any)
-	for _, field := range fieldsToFetch {
+	for _, spec := range fieldsToFetch {
+		field, key := splitFieldAlias(spec)
 		value, err := fetchField(fetch, "organization", constants.DefaultOrganizationID, field)
 		if err != nil {
 			return nil, fmt.Errorf("fetching organization field %s: %w", field, err)
 		}
-		org[field] = value
+		org[key] = value
 	}
 
 	if err := fetch.Execute(ctx); err != nil {
@@ -437,7 +1175,8 @@ func queryOrganization(ctx context.Context, fetch *dsfetch.Fetch, fields []strin
 func determineFieldsToFetch(requestedFields []string, filter map[string]string, rawFilter *RawFilter) []string {
 	fieldsSet := map[string]bool{"id": true}
 
-	for _, field := range requestedFields {
+	for _, spec := range requestedFields {
+		field, _ := splitFieldAlias(spec)
 		fieldsSet[field] = true
 	}
 
@@ -476,20 +1215,44 @@ func extractFieldsFromRawFilter(rf *RawFilter, fieldsSet map[string]bool) {
 	}
 }
 
-// fetchField dynamically fetches a single field using reflection
-func fetchField(fetch *dsfetch.Fetch, collection string, id int, field string) (any, error) {
-	methodName := snakeToPascal(collection) + "_" + snakeToPascal(field)
+// fetchMethod is the dsfetch.Fetch method fetching a field of a collection
+type fetchMethod struct {
+	name   string
+	method reflect.Method
+	found  bool
+}
 
-	fetchValue := reflect.ValueOf(fetch)
-	method := fetchValue.MethodByName(methodName)
+// fetchMethods caches the fetchMethod per "collection/field". Resolving the
+// method name and looking it up by reflection took most of the time of large
+// queries, with the cache it happens once per field instead of once per model
+// and field. This halves the time and allocations of fetching 5000 users with
+// three fields (BenchmarkStreamCollection/buffered).
+var fetchMethods sync.Map
+
+// lookupFetchMethod returns the cached fetchMethod of field in collection
+func lookupFetchMethod(collection, field string) fetchMethod {
+	key := collection + "/" + field
+	if cached, ok := fetchMethods.Load(key); ok {
+		return cached.(fetchMethod)
+	}
 
-	if !method.IsValid() {
-		return nil, fmt.Errorf("unsupported %s field: %s (method %s not found)", collection, field, methodName)
+	name := snakeToPascal(collection) + "_" + snakeToPascal(field)
+	method, found := reflect.TypeFor[*dsfetch.Fetch]().MethodByName(name)
+	fm := fetchMethod{name: name, method: method, found: found}
+	fetchMethods.Store(key, fm)
+	return fm
+}
+
+// fetchField dynamically fetches a single field using reflection
+func fetchField(fetch *dsfetch.Fetch, collection string, id int, field string) (any, error) {
+	fm := lookupFetchMethod(collection, field)
+	if !fm.found {
+		return nil, fmt.Errorf("unsupported %s field: %s (method %s not found)", collection, field, fm.name)
 	}
 
-	results := method.Call([]reflect.Value{reflect.ValueOf(id)})
+	results := fm.method.Func.Call([]reflect.Value{reflect.ValueOf(fetch), reflect.ValueOf(id)})
 	if len(results) == 0 {
-		return nil, fmt.Errorf("method %s returned no results", methodName)
+		return nil, fmt.Errorf("method %s returned no results", fm.name)
 	}
 
 	valueObj := results[0]
@@ -544,14 +1307,58 @@ func fetchField(fetch *dsfetch.Fetch, collection string, id int, field string) (
 }
 
 // applyFilters applies simple or raw filters to records
-func applyFilters(records []map[string]any, filter map[string]string, rawFilter *RawFilter) []map[string]any {
+func applyFilters(records []map[string]any, filter map[string]string, rawFilter *RawFilter) ([]map[string]any, error) {
 	if len(filter) > 0 {
-		return filterSimple(records, filter)
+		return filterSimple(records, filter), nil
 	}
 	if rawFilter != nil {
-		return filterRaw(records, rawFilter)
+		if err := compileRawFilter(rawFilter); err != nil {
+			return nil, err
+		}
+		return filterRaw(records, rawFilter), nil
 	}
-	return records
+	return records, nil
+}
+
+// compileRawFilter compiles the patterns of all ~= conditions and parses the
+// paths of all json-path conditions in rf, so they are not recompiled for every
+// record. Already compiled conditions are kept.
+func compileRawFilter(rf *RawFilter) error {
+	for i := range rf.AndFilter {
+		if err := compileRawFilter(&rf.AndFilter[i]); err != nil {
+			return err
+		}
+	}
+	for i := range rf.OrFilter {
+		if err := compileRawFilter(&rf.OrFilter[i]); err != nil {
+			return err
+		}
+	}
+	if rf.NotFilter != nil {
+		if err := compileRawFilter(rf.NotFilter); err != nil {
+			return err
+		}
+	}
+
+	if rf.Operator == "json-path" && rf.segments == nil {
+		segments, err := parseJSONPath(rf.Path)
+		if err != nil {
+			return fmt.Errorf("invalid json-path in filter for field %s: %w", rf.Field, err)
+		}
+		rf.segments = segments
+		return nil
+	}
+
+	if rf.Operator != "~=" || rf.regex != nil {
+		return nil
+	}
+	pattern := fmt.Sprintf("%v", rf.Value)
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return fmt.Errorf("invalid regex in filter for field %s: %w", rf.Field, err)
+	}
+	rf.regex = re
+	return nil
 }
 
 // filterSimple applies simple equality filters
@@ -619,12 +1426,67 @@ func matchesRawFilter(record map[string]any, rf *RawFilter) bool {
 
 	// Handle single condition
 	if rf.Field != "" {
+		if rf.Operator == "json-path" {
+			return matchesJSONPath(record, rf)
+		}
+		if rf.regex != nil {
+			return matchesCondition(record, rf.Field, rf.Operator, rf.regex)
+		}
 		return matchesCondition(record, rf.Field, rf.Operator, rf.Value)
 	}
 
 	return true
 }
 
+// readRawFilterFile reads a raw filter from file or stdin ("-") and checks
+// that it is a valid RawFilter
+func readRawFilterFile(filename string) (string, error) {
+	data, err := utils.ReadFromFileOrStdin(filename)
+	if err != nil {
+		return "", fmt.Errorf("reading filter-raw-file: %w", err)
+	}
+
+	var rf RawFilter
+	if err := json.Unmarshal(data, &rf); err != nil {
+		return "", fmt.Errorf("parsing filter-raw-file %s: %w", filename, err)
+	}
+	return string(data), nil
+}
+
+// readFieldsFile reads field names separated by newlines or commas from
+// filename. Blank lines and everything after '#' are ignored.
+func readFieldsFile(filename string) ([]string, error) {
+	data, err := utils.ReadFromFileOrStdin(filename)
+	if err != nil {
+		return nil, fmt.Errorf("reading fields-file: %w", err)
+	}
+
+	var fields []string
+	for line := range strings.Lines(string(data)) {
+		line, _, _ = strings.Cut(line, "#")
+		for field := range strings.SplitSeq(line, ",") {
+			if field = strings.TrimSpace(field); field != "" {
+				fields = append(fields, field)
+			}
+		}
+	}
+	if len(fields) == 0 {
+		return nil, fmt.Errorf("fields-file %s contains no fields", filename)
+	}
+	return fields, nil
+}
+
+// mergeFields appends the fields of extra missing in fields, keeping the order
+func mergeFields(fields, extra []string) []string {
+	merged := slices.Clone(fields)
+	for _, field := range extra {
+		if !slices.Contains(merged, field) {
+			merged = append(merged, field)
+		}
+	}
+	return merged
+}
+
 // matchesCondition checks if a record field matches a condition with the given operator
 func matchesCondition(record map[string]any, field, operator string, value any) bool {
 	recordValue, ok := record[field]
@@ -632,6 +1494,14 @@ func matchesCondition(record map[string]any, field, operator string, value any)
 		return false
 	}
 
+	// Null checks inspect the raw value, as dereferencing turns null into a zero value
+	switch operator {
+	case "is-null":
+		return isNull(recordValue)
+	case "is-set":
+		return !isNull(recordValue)
+	}
+
 	recordValue = dereferenceValue(recordValue)
 
 	// Special handling for JSON fields
@@ -654,6 +1524,12 @@ func matchesCondition(record map[string]any, field, operator string, value any)
 		return compareNumeric(recordValue, value, func(a, b float64) bool { return a <= b })
 	case "~=":
 		return matchesRegex(recordValue, value)
+	case "between":
+		return matchesBetween(recordValue, value)
+	case "contains":
+		return containsValue(recordValue, value)
+	case "not-contains":
+		return !containsValue(recordValue, value)
 	default:
 		logger.Debug("Unsupported operator: %s", operator)
 		return false
@@ -664,9 +1540,9 @@ func matchesCondition(record map[string]any, field, operator string, value any)
 func matchesJSONCondition(recordValue any, operator string, value any) bool {
 	switch operator {
 	case "=":
-		recordStr := string(recordValue.(json.RawMessage))
-		valueStr := fmt.Sprintf("%v", value)
-		return recordStr == valueStr
+		return jsonEqual(recordValue.(json.RawMessage), value)
+	case "!=":
+		return !jsonEqual(recordValue.(json.RawMessage), value)
 	case "~=":
 		// Regex match on JSON string
 		return matchesRegex(string(recordValue.(json.RawMessage)), value)
@@ -676,6 +1552,117 @@ func matchesJSONCondition(recordValue any, operator string, value any) bool {
 	}
 }
 
+// jsonEqual reports whether the JSON document raw is semantically equal to
+// value, ignoring whitespace and key order. A string value is parsed as JSON
+// if possible, other values are compared as they would be encoded.
+func jsonEqual(raw json.RawMessage, value any) bool {
+	var recordDoc any
+	if err := json.Unmarshal(raw, &recordDoc); err != nil {
+		logger.Debug("Invalid JSON field value: %v", err)
+		return false
+	}
+
+	var valueDoc any
+	if str, ok := value.(string); ok && json.Unmarshal([]byte(str), &valueDoc) == nil {
+		return reflect.DeepEqual(recordDoc, valueDoc)
+	}
+
+	encoded, err := json.Marshal(value)
+	if err != nil || json.Unmarshal(encoded, &valueDoc) != nil {
+		return false
+	}
+	return reflect.DeepEqual(recordDoc, valueDoc)
+}
+
+// parseJSONPath splits a path like $.settings.theme or $.items[0].name into
+// object keys (string) and array indexes (int)
+func parseJSONPath(path string) ([]any, error) {
+	rest, ok := strings.CutPrefix(path, "$")
+	if !ok {
+		return nil, fmt.Errorf("path %q must start with $", path)
+	}
+
+	segments := []any{}
+	for rest != "" {
+		switch rest[0] {
+		case '.':
+			end := strings.IndexAny(rest[1:], ".[")
+			if end < 0 {
+				end = len(rest) - 1
+			}
+			key := rest[1 : end+1]
+			if key == "" {
+				return nil, fmt.Errorf("path %q contains an empty key", path)
+			}
+			segments = append(segments, key)
+			rest = rest[end+1:]
+		case '[':
+			end := strings.IndexByte(rest, ']')
+			if end < 0 {
+				return nil, fmt.Errorf("path %q contains an unclosed [", path)
+			}
+			index, err := strconv.Atoi(rest[1:end])
+			if err != nil || index < 0 {
+				return nil, fmt.Errorf("path %q contains an invalid index %q", path, rest[1:end])
+			}
+			segments = append(segments, index)
+			rest = rest[end+1:]
+		default:
+			return nil, fmt.Errorf("path %q: expected . or [ at %q", path, rest)
+		}
+	}
+	return segments, nil
+}
+
+// matchesJSONPath checks if the value at the path of a json-path condition in
+// the JSON field of record equals the condition value, like = on JSON fields.
+// A missing field, path or non-JSON value never matches.
+func matchesJSONPath(record map[string]any, rf *RawFilter) bool {
+	segments := rf.segments
+	if segments == nil {
+		var err error
+		if segments, err = parseJSONPath(rf.Path); err != nil {
+			logger.Debug("JSON path error: %v", err)
+			return false
+		}
+	}
+
+	raw, ok := dereferenceValue(record[rf.Field]).(json.RawMessage)
+	if !ok {
+		return false
+	}
+	var doc any
+	if err := json.Unmarshal(raw, &doc); err != nil {
+		logger.Debug("Invalid JSON field value: %v", err)
+		return false
+	}
+
+	for _, segment := range segments {
+		switch segment := segment.(type) {
+		case string:
+			object, ok := doc.(map[string]any)
+			if !ok {
+				return false
+			}
+			if doc, ok = object[segment]; !ok {
+				return false
+			}
+		case int:
+			array, ok := doc.([]any)
+			if !ok || segment >= len(array) {
+				return false
+			}
+			doc = array[segment]
+		}
+	}
+
+	encoded, err := json.Marshal(doc)
+	if err != nil {
+		return false
+	}
+	return jsonEqual(encoded, rf.Value)
+}
+
 // compareNumeric converts values to numbers and applies comparison function
 func compareNumeric(recordValue, filterValue any, compareFn func(float64, float64) bool) bool {
 	rNum, rOk := toNumber(recordValue)
@@ -683,11 +1670,59 @@ func compareNumeric(recordValue, filterValue any, compareFn func(float64, float6
 	return rOk && fOk && compareFn(rNum, fNum)
 }
 
-// matchesRegex checks if recordValue matches the regex pattern in filterValue
+// matchesBetween checks if recordValue lies within the inclusive range given
+// by filterValue as [low, high]. Any other filterValue never matches.
+func matchesBetween(recordValue, filterValue any) bool {
+	bounds, ok := filterValue.([]any)
+	if !ok || len(bounds) != 2 {
+		logger.Debug("Operator between requires [low, high], got: %v", filterValue)
+		return false
+	}
+	return compareNumeric(recordValue, bounds[0], func(a, b float64) bool { return a >= b }) &&
+		compareNumeric(recordValue, bounds[1], func(a, b float64) bool { return a <= b })
+}
+
+// containsValue checks if recordValue is a []int or []string with an element
+// equal to filterValue. Numbers are compared numerically.
+func containsValue(recordValue, filterValue any) bool {
+	switch v := recordValue.(type) {
+	case []int:
+		fNum, ok := toNumber(filterValue)
+		if !ok {
+			return false
+		}
+		return slices.ContainsFunc(v, func(n int) bool { return float64(n) == fNum })
+	case []string:
+		return slices.Contains(v, fmt.Sprintf("%v", filterValue))
+	default:
+		logger.Debug("Operator contains requires a list field, got: %T", recordValue)
+		return false
+	}
+}
+
+// isNull reports whether value is a null Maybe, a nil pointer or nil
+func isNull(value any) bool {
+	switch v := value.(type) {
+	case nil:
+		return true
+	case *dsfetch.Maybe[int]:
+		return v == nil || v.Null()
+	case *dsfetch.Maybe[string]:
+		return v == nil || v.Null()
+	}
+	rv := reflect.ValueOf(value)
+	return rv.Kind() == reflect.Pointer && rv.IsNil()
+}
+
+// matchesRegex checks if recordValue matches the regex pattern in filterValue,
+// which is either a pattern or a *regexp.Regexp compiled by compileRawFilter
 func matchesRegex(recordValue, filterValue any) bool {
 	recordStr := fmt.Sprintf("%v", recordValue)
-	patternStr := fmt.Sprintf("%v", filterValue)
+	if re, ok := filterValue.(*regexp.Regexp); ok {
+		return re.MatchString(recordStr)
+	}
 
+	patternStr := fmt.Sprintf("%v", filterValue)
 	matched, err := regexp.MatchString(patternStr, recordStr)
 	if err != nil {
 		logger.Debug("Regex error: %v", err)
@@ -714,10 +1749,23 @@ func toNumber(value any) (float64, bool) {
 		if num, err := strconv.ParseFloat(v, 64); err == nil {
 			return num, true
 		}
+		if t, ok := parseDate(v); ok {
+			return float64(t.Unix()), true
+		}
 	}
 	return 0, false
 }
 
+// parseDate parses an RFC3339 timestamp or a YYYY-MM-DD date (midnight UTC)
+func parseDate(value string) (time.Time, bool) {
+	for _, layout := range []string{time.RFC3339, time.DateOnly} {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
+
 // dereferenceValue dereferences pointer values
 func dereferenceValue(value any) any {
 	switch v := value.(type) {
@@ -780,27 +1828,107 @@ func dereferenceValue(value any) any {
 
 // selectFields returns the requested fields (and id) from each record
 func selectFields(records []map[string]any, fields []string) []map[string]any {
+	idKey := outputKey(fields, "id")
 	filtered := make([]map[string]any, len(records))
 	for i, record := range records {
 		filtered[i] = make(map[string]any)
 		if id, ok := record["id"]; ok {
-			filtered[i]["id"] = id
+			filtered[i][idKey] = id
 		}
-		for _, field := range fields {
+		for _, spec := range fields {
+			field, key := splitFieldAlias(spec)
 			if value, ok := record[field]; ok {
-				filtered[i][field] = dereferenceValue(value)
+				filtered[i][key] = dereferenceValue(value)
 			}
 		}
 	}
 	return filtered
 }
 
-// convertToMapFormat converts an array of records to a map keyed by ID
-// This matches the old datastorereader output format for backward compatibility
-func convertToMapFormat(records []map[string]any) map[string]any {
+// splitFieldAlias splits a --fields entry of the form field:alias into the
+// field to fetch and the key it gets in the output. Without alias, the key is
+// the field itself.
+func splitFieldAlias(spec string) (field, key string) {
+	field, alias, found := strings.Cut(spec, ":")
+	if !found {
+		return spec, spec
+	}
+	return field, alias
+}
+
+// outputKey returns the output key of field given the --fields entries
+func outputKey(fields []string, field string) string {
+	for _, spec := range fields {
+		if name, key := splitFieldAlias(spec); name == field {
+			return key
+		}
+	}
+	return field
+}
+
+// outputKeys returns the output keys of names given the --fields entries, see
+// outputKey. Names that are already aliases are kept.
+func outputKeys(fields []string, names []string) []string {
+	keys := make([]string, len(names))
+	for i, name := range names {
+		keys[i] = outputKey(fields, name)
+	}
+	return keys
+}
+
+// hashOutputKeys returns the output keys of the --hash-fields given the
+// --fields entries, as hashing runs on the output records where aliased fields
+// are only found under their alias. The id, also under an alias, cannot be
+// hashed.
+func hashOutputKeys(fields []string, hashFields []string) ([]string, error) {
+	keys := outputKeys(fields, hashFields)
+	if slices.Contains(keys, "id") || slices.Contains(keys, outputKey(fields, "id")) {
+		return nil, fmt.Errorf("--hash-fields cannot include id")
+	}
+	return keys, nil
+}
+
+// validateGroupBy checks that the --group-by field is an output key of the
+// selected fields. Without --fields every field is fetched.
+func validateGroupBy(field string, fields []string) error {
+	if len(fields) == 0 || field == outputKey(fields, "id") {
+		return nil
+	}
+	for _, spec := range fields {
+		if _, key := splitFieldAlias(spec); key == field {
+			return nil
+		}
+	}
+	return fmt.Errorf("--group-by field %q is not in --fields", field)
+}
+
+// validateFieldAliases checks that every --fields entry names a field and an
+// alias if given, and that no two entries get the same output key
+func validateFieldAliases(fields []string) error {
+	keys := make(map[string]string, len(fields))
+	for _, spec := range fields {
+		field, key := splitFieldAlias(spec)
+		if field == "" || key == "" || strings.Contains(key, ":") {
+			return fmt.Errorf("invalid field %q: expected field or field:alias", spec)
+		}
+		if other, ok := keys[key]; ok && other != field {
+			return fmt.Errorf("fields %s and %s both map to output key %s", other, field, key)
+		}
+		keys[key] = field
+	}
+	if other, ok := keys["id"]; ok && other != "id" && outputKey(fields, "id") == "id" {
+		return fmt.Errorf("field %s cannot be aliased to id", other)
+	}
+	return nil
+}
+
+// convertToMapFormat converts an array of records to a map keyed by ID, read
+// from idKey. This matches the old datastorereader output format for backward
+// compatibility.
+func convertToMapFormat(records []map[string]any, idKey string) map[string]any {
 	result := make(map[string]any, len(records))
 	for _, record := range records {
-		id := dereferenceValue(record["id"])
+		id := dereferenceValue(record[idKey])
 		idStr := fmt.Sprintf("%v", id)
 		result[idStr] = record
 	}
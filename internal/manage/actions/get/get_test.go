@@ -1,12 +1,27 @@
 package get
 
 import (
+	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
 	"reflect"
+	"runtime"
 	"slices"
+	"strconv"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/OpenSlides/openslides-cli/internal/constants"
+	pb "github.com/OpenSlides/openslides-cli/proto/osmanage"
 	"github.com/OpenSlides/openslides-go/datastore/dsfetch"
+	"github.com/OpenSlides/openslides-go/datastore/dskey"
+	"github.com/OpenSlides/openslides-go/datastore/dsmock"
 	"github.com/shopspring/decimal"
 )
 
@@ -273,6 +288,9 @@ func TestToNumber(t *testing.T) {
 		{"decimal negative", decimal.NewFromFloat(-5.5), -5.5, true},
 		{"string number", "123.45", 123.45, true},
 		{"string not number", "abc", 0, false},
+		{"date", "2021-01-01", 1609459200, true},
+		{"rfc3339 date", "2021-01-01T01:00:00+01:00", 1609459200, true},
+		{"invalid date", "2021-13-01", 0, false},
 		{"bool", true, 0, false},
 		{"nil", nil, 0, false},
 	}
@@ -306,6 +324,8 @@ func TestCompareNumeric(t *testing.T) {
 		{"5 <= 5", 5, 5, func(a, b float64) bool { return a <= b }, true},
 		{"float > int", 10.5, 10, func(a, b float64) bool { return a > b }, true},
 		{"string numbers", "20", "10", func(a, b float64) bool { return a > b }, true},
+		{"timestamp >= date", 1609459200, "2021-01-01", func(a, b float64) bool { return a >= b }, true},
+		{"timestamp before date", 1609459199, "2021-01-01", func(a, b float64) bool { return a >= b }, false},
 		{"decimal > int", decimal.NewFromFloat(10.5), 10, func(a, b float64) bool { return a > b }, true},
 		{"int > decimal", 20, decimal.NewFromFloat(10.5), func(a, b float64) bool { return a > b }, true},
 		{"invalid comparison", "abc", 10, func(a, b float64) bool { return a > b }, false},
@@ -371,6 +391,48 @@ func TestMatchesJSONCondition(t *testing.T) {
 			filter:   `{"key":"other"}`,
 			expected: false,
 		},
+		{
+			name:     "json equality with reordered keys",
+			value:    json.RawMessage(`{"a":1,"b":[true,null]}`),
+			operator: "=",
+			filter:   `{"b":[true,null],"a":1}`,
+			expected: true,
+		},
+		{
+			name:     "json equality with whitespace",
+			value:    json.RawMessage(`{"key":"value"}`),
+			operator: "=",
+			filter:   "{ \"key\" :\n  \"value\" }",
+			expected: true,
+		},
+		{
+			name:     "json equality with decoded filter value",
+			value:    json.RawMessage(`{ "key": "value", "n": 1 }`),
+			operator: "=",
+			filter:   map[string]any{"n": float64(1), "key": "value"},
+			expected: true,
+		},
+		{
+			name:     "json equality with plain string",
+			value:    json.RawMessage(`"active"`),
+			operator: "=",
+			filter:   "active",
+			expected: true,
+		},
+		{
+			name:     "json inequality",
+			value:    json.RawMessage(`{"key":"value"}`),
+			operator: "!=",
+			filter:   `{"key":"other"}`,
+			expected: true,
+		},
+		{
+			name:     "json inequality with reordered keys",
+			value:    json.RawMessage(`{"a":1,"b":2}`),
+			operator: "!=",
+			filter:   `{"b":2,"a":1}`,
+			expected: false,
+		},
 		{
 			name:     "json regex match",
 			value:    json.RawMessage(`{"status":"active"}`),
@@ -421,6 +483,23 @@ func TestMatchesCondition(t *testing.T) {
 		{"greater or equal", map[string]any{"age": 20}, "age", ">=", 20, true},
 		{"less or equal", map[string]any{"age": 20}, "age", "<=", 20, true},
 		{"regex match", map[string]any{"name": "admin"}, "name", "~=", "^ad", true},
+		{"between match", map[string]any{"start_time": 1500}, "start_time", "between", []any{1000, 2000}, true},
+		{"between inclusive bounds", map[string]any{"start_time": 2000}, "start_time", "between", []any{float64(1000), float64(2000)}, true},
+		{"between no match", map[string]any{"start_time": 2500}, "start_time", "between", []any{1000, 2000}, false},
+		{"between single value", map[string]any{"start_time": 1500}, "start_time", "between", []any{1000}, false},
+		{"between not an array", map[string]any{"start_time": 1500}, "start_time", "between", 1000, false},
+		{"between non-numeric", map[string]any{"start_time": "soon"}, "start_time", "between", []any{1000, 2000}, false},
+		{"contains int", map[string]any{"meeting_ids": []int{1, 5}}, "meeting_ids", "contains", float64(5), true},
+		{"contains int no match", map[string]any{"meeting_ids": []int{1, 5}}, "meeting_ids", "contains", 2, false},
+		{"contains string", map[string]any{"tags": []string{"a", "b"}}, "tags", "contains", "b", true},
+		{"contains string no match", map[string]any{"tags": []string{"a", "b"}}, "tags", "contains", "c", false},
+		{"contains pointer slice", map[string]any{"meeting_ids": &[]int{3}}, "meeting_ids", "contains", 3, true},
+		{"contains scalar field", map[string]any{"name": "test"}, "name", "contains", "test", false},
+		{"not-contains int", map[string]any{"meeting_ids": []int{1, 5}}, "meeting_ids", "not-contains", 2, true},
+		{"not-contains string", map[string]any{"tags": []string{"a", "b"}}, "tags", "not-contains", "a", false},
+		{"is-null nil pointer", map[string]any{"name": (*string)(nil)}, "name", "is-null", nil, true},
+		{"is-null empty string", map[string]any{"name": ""}, "name", "is-null", nil, false},
+		{"is-set empty string", map[string]any{"name": ""}, "name", "is-set", nil, true},
 		{"field not exists", map[string]any{"name": "test"}, "missing", "=", "test", false},
 		{"unsupported operator", map[string]any{"name": "test"}, "name", "??", "test", false},
 		{"json field equality", map[string]any{"data": json.RawMessage(`{"x":1}`)}, "data", "=", `{"x":1}`, true},
@@ -578,6 +657,85 @@ func TestMatchesRawFilter(t *testing.T) {
 	}
 }
 
+func TestParseJSONPath(t *testing.T) {
+	tests := []struct {
+		path    string
+		want    []any
+		wantErr bool
+	}{
+		{path: "$", want: []any{}},
+		{path: "$.settings.theme", want: []any{"settings", "theme"}},
+		{path: "$.items[0].name", want: []any{"items", 0, "name"}},
+		{path: "$[2][1]", want: []any{2, 1}},
+		{path: "settings.theme", wantErr: true},
+		{path: "$.", wantErr: true},
+		{path: "$.a..b", wantErr: true},
+		{path: "$.items[0", wantErr: true},
+		{path: "$.items[-1]", wantErr: true},
+		{path: "$.items[x]", wantErr: true},
+		{path: "$settings", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			got, err := parseJSONPath(tt.path)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseJSONPath(%q) error = %v, wantErr %v", tt.path, err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseJSONPath(%q) = %#v, want %#v", tt.path, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMatchesJSONPath(t *testing.T) {
+	record := map[string]any{
+		"settings": json.RawMessage(`{"theme":"dark","font":{"size":12},"tags":["a","b"],"extra":{"x":1,"y":[true]}}`),
+		"name":     "not json",
+	}
+
+	tests := []struct {
+		name     string
+		field    string
+		path     string
+		value    any
+		expected bool
+	}{
+		{"path starting with field name", "settings", "$.settings.theme", "dark", false},
+		{"string", "settings", "$.theme", "dark", true},
+		{"string no match", "settings", "$.theme", "light", false},
+		{"number", "settings", "$.font.size", float64(12), true},
+		{"array index", "settings", "$.tags[1]", "b", true},
+		{"index out of range", "settings", "$.tags[2]", "b", false},
+		{"object with reordered keys", "settings", "$.extra", `{"y":[true],"x":1}`, true},
+		{"missing key", "settings", "$.missing", nil, false},
+		{"key on array", "settings", "$.tags.a", "a", false},
+		{"non-JSON field", "name", "$", "not json", false},
+		{"missing field", "other", "$.theme", "dark", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rf := &RawFilter{Field: tt.field, Operator: "json-path", Path: tt.path, Value: tt.value}
+			if got := matchesRawFilter(record, rf); got != tt.expected {
+				t.Errorf("matchesRawFilter(json-path %s) = %v, want %v", tt.path, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestCompileRawFilter_InvalidJSONPath(t *testing.T) {
+	var rf RawFilter
+	if err := json.Unmarshal([]byte(`{"field":"settings","operator":"json-path","path":"settings.theme","value":"dark"}`), &rf); err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+	err := compileRawFilter(&rf)
+	if err == nil || !strings.Contains(err.Error(), "invalid json-path in filter for field settings") {
+		t.Errorf("Expected invalid json-path error, got %v", err)
+	}
+}
+
 func TestMatchesRawFilter_Complex(t *testing.T) {
 	// Test: (age > 18 AND city = "NYC") OR (age > 65 AND city = "FL")
 	filter := &RawFilter{
@@ -652,6 +810,60 @@ func TestMatchesRawFilter_TripleNested(t *testing.T) {
 	}
 }
 
+func TestAliasedHashAndEnumFields(t *testing.T) {
+	fields := []string{"email:mail", "state:status"}
+	records := func() []map[string]any {
+		return selectFields([]map[string]any{
+			{"id": json.Number("1"), "email": "alice@example.com", "state": json.Number("1")},
+		}, fields)
+	}
+
+	t.Run("hashes aliased field by field name", func(t *testing.T) {
+		hashFields, err := hashOutputKeys(fields, []string{"email"})
+		if err != nil {
+			t.Fatalf("hashOutputKeys() error = %v", err)
+		}
+		result := records()
+		if err := hashRecordFields(result, hashFields, "salt"); err != nil {
+			t.Fatalf("hashRecordFields() error = %v", err)
+		}
+		if result[0]["mail"] == "alice@example.com" {
+			t.Error("Expected aliased email to be hashed, got plaintext")
+		}
+	})
+
+	t.Run("hashes aliased field by alias", func(t *testing.T) {
+		hashFields, err := hashOutputKeys(fields, []string{"mail"})
+		if err != nil {
+			t.Fatalf("hashOutputKeys() error = %v", err)
+		}
+		result := records()
+		if err := hashRecordFields(result, hashFields, "salt"); err != nil {
+			t.Fatalf("hashRecordFields() error = %v", err)
+		}
+		if result[0]["mail"] == "alice@example.com" {
+			t.Error("Expected aliased email to be hashed, got plaintext")
+		}
+	})
+
+	t.Run("rejects aliased id", func(t *testing.T) {
+		for _, name := range []string{"id", "uid"} {
+			if _, err := hashOutputKeys([]string{"id:uid", "email"}, []string{name}); err == nil {
+				t.Errorf("Expected error hashing %s aliased as uid", name)
+			}
+		}
+	})
+
+	t.Run("maps enum of aliased field", func(t *testing.T) {
+		enumMaps := EnumMaps{"state": {"1": "active"}}.withOutputKeys(fields)
+		result := records()
+		applyEnumMaps(result, enumMaps)
+		if result[0]["status"] != "active" {
+			t.Errorf("Expected aliased state to be mapped, got %v", result[0]["status"])
+		}
+	})
+}
+
 func TestSelectFields(t *testing.T) {
 	stringVal := "John"
 	intVal := 30
@@ -692,6 +904,26 @@ func TestSelectFields(t *testing.T) {
 				{"id": 1, "name": "John"},
 			},
 		},
+		{
+			name: "aliased and plain fields",
+			records: []map[string]any{
+				{"id": 1, "name": &stringVal, "age": &intVal, "city": "NYC"},
+			},
+			fields: []string{"name:given_name", "age"},
+			expected: []map[string]any{
+				{"id": 1, "given_name": "John", "age": 30},
+			},
+		},
+		{
+			name: "aliased id",
+			records: []map[string]any{
+				{"id": 1, "name": &stringVal},
+			},
+			fields: []string{"id:user_id", "name"},
+			expected: []map[string]any{
+				{"user_id": 1, "name": "John"},
+			},
+		},
 	}
 
 	for _, tt := range tests {
@@ -705,6 +937,51 @@ func TestSelectFields(t *testing.T) {
 	}
 }
 
+func TestSplitFieldAlias(t *testing.T) {
+	if field, key := splitFieldAlias("first_name:given_name"); field != "first_name" || key != "given_name" {
+		t.Errorf("splitFieldAlias() = %q, %q", field, key)
+	}
+	if field, key := splitFieldAlias("email"); field != "email" || key != "email" {
+		t.Errorf("splitFieldAlias() = %q, %q", field, key)
+	}
+}
+
+func TestValidateFieldAliases(t *testing.T) {
+	valid := [][]string{
+		nil,
+		{"id", "first_name:given_name", "last_name:family_name"},
+		{"id:user_id", "email"},
+		{"email", "email"},
+	}
+	for _, fields := range valid {
+		if err := validateFieldAliases(fields); err != nil {
+			t.Errorf("validateFieldAliases(%v) error = %v", fields, err)
+		}
+	}
+
+	invalid := [][]string{
+		{"first_name:"},
+		{":given_name"},
+		{"first_name:given:name"},
+		{"first_name:name", "last_name:name"},
+		{"username:id"},
+	}
+	for _, fields := range invalid {
+		if err := validateFieldAliases(fields); err == nil {
+			t.Errorf("validateFieldAliases(%v) expected error", fields)
+		}
+	}
+}
+
+func TestDetermineFieldsToFetchAliases(t *testing.T) {
+	result := determineFieldsToFetch([]string{"first_name:given_name", "email"}, nil, nil)
+	slices.Sort(result)
+	expected := []string{"email", "first_name", "id"}
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("determineFieldsToFetch() = %v, want %v", result, expected)
+	}
+}
+
 func TestConvertToMapFormat(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -740,7 +1017,7 @@ func TestConvertToMapFormat(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			result := convertToMapFormat(tt.records)
+			result := convertToMapFormat(tt.records, "id")
 
 			if !reflect.DeepEqual(result, tt.expected) {
 				t.Errorf("convertToMapFormat() = %v, want %v", result, tt.expected)
@@ -749,6 +1026,17 @@ func TestConvertToMapFormat(t *testing.T) {
 	}
 }
 
+// filterRecords runs applyFilters and fails the test on error
+func filterRecords(t *testing.T, records []map[string]any, filter map[string]string, rawFilter *RawFilter) []map[string]any {
+	t.Helper()
+
+	result, err := applyFilters(records, filter, rawFilter)
+	if err != nil {
+		t.Fatalf("applyFilters() error = %v", err)
+	}
+	return result
+}
+
 func TestApplyFilters(t *testing.T) {
 	records := []map[string]any{
 		{"id": 1, "name": "John", "age": 30},
@@ -758,7 +1046,7 @@ func TestApplyFilters(t *testing.T) {
 
 	t.Run("simple filter", func(t *testing.T) {
 		filter := map[string]string{"name": "John"}
-		result := applyFilters(records, filter, nil)
+		result := filterRecords(t, records, filter, nil)
 
 		if len(result) != 1 {
 			t.Errorf("applyFilters() returned %d records, want 1", len(result))
@@ -774,7 +1062,7 @@ func TestApplyFilters(t *testing.T) {
 			Operator: ">",
 			Value:    28,
 		}
-		result := applyFilters(records, nil, rawFilter)
+		result := filterRecords(t, records, nil, rawFilter)
 
 		if len(result) != 2 {
 			t.Errorf("applyFilters() returned %d records, want 2", len(result))
@@ -782,7 +1070,7 @@ func TestApplyFilters(t *testing.T) {
 	})
 
 	t.Run("no filter", func(t *testing.T) {
-		result := applyFilters(records, nil, nil)
+		result := filterRecords(t, records, nil, nil)
 
 		if len(result) != 3 {
 			t.Errorf("applyFilters() returned %d records, want 3", len(result))
@@ -790,6 +1078,57 @@ func TestApplyFilters(t *testing.T) {
 	})
 }
 
+func TestApplyFilters_InvalidRegex(t *testing.T) {
+	records := []map[string]any{{"id": 1, "username": "admin"}}
+	rawFilter := &RawFilter{AndFilter: []RawFilter{
+		{Field: "is_active", Operator: "=", Value: true},
+		{NotFilter: &RawFilter{Field: "username", Operator: "~=", Value: "[a-"}},
+	}}
+
+	_, err := applyFilters(records, nil, rawFilter)
+	if err == nil || !strings.Contains(err.Error(), "invalid regex in filter for field username") {
+		t.Errorf("Expected invalid regex error, got %v", err)
+	}
+}
+
+func TestCompileRawFilter(t *testing.T) {
+	rawFilter := &RawFilter{OrFilter: []RawFilter{
+		{Field: "username", Operator: "~=", Value: "^ad"},
+		{Field: "first_name", Operator: "=", Value: "Admin"},
+	}}
+	if err := compileRawFilter(rawFilter); err != nil {
+		t.Fatalf("compileRawFilter() error = %v", err)
+	}
+
+	re := rawFilter.OrFilter[0].regex
+	if re == nil || re.String() != "^ad" {
+		t.Fatalf("Expected compiled regex ^ad, got %v", re)
+	}
+	if rawFilter.OrFilter[1].regex != nil {
+		t.Error("Expected no regex for = condition")
+	}
+
+	// Compiling again keeps the existing regex
+	if err := compileRawFilter(rawFilter); err != nil {
+		t.Fatalf("compileRawFilter() error = %v", err)
+	}
+	if rawFilter.OrFilter[0].regex != re {
+		t.Error("Expected regex to be compiled only once")
+	}
+
+	if !matchesRawFilter(map[string]any{"username": "admin"}, rawFilter) {
+		t.Error("Expected compiled filter to match")
+	}
+}
+
+func TestQueryCollection_InvalidRegex(t *testing.T) {
+	rawFilter := &RawFilter{Field: "username", Operator: "~=", Value: "("}
+	_, err := queryCollection(context.Background(), dsfetch.New(dsmock.Stub(userTestData(3))), "user", nil, rawFilter, nil, false, nil)
+	if err == nil || !strings.Contains(err.Error(), "invalid regex in filter") {
+		t.Errorf("Expected invalid regex error instead of an empty result, got %v", err)
+	}
+}
+
 func TestMaybeTypeFiltering(t *testing.T) {
 	// Simulate records with Maybe fields (as they would appear after dereferencing)
 	maybeInt1 := dsfetch.MaybeValue(10)
@@ -812,7 +1151,7 @@ func TestMaybeTypeFiltering(t *testing.T) {
 			Operator: ">",
 			Value:    15,
 		}
-		result := applyFilters(records, nil, rawFilter)
+		result := filterRecords(t, records, nil, rawFilter)
 
 		if len(result) != 1 {
 			t.Errorf("Expected 1 record with maybe_field > 15, got %d", len(result))
@@ -824,7 +1163,7 @@ func TestMaybeTypeFiltering(t *testing.T) {
 
 	t.Run("filter maybe string field", func(t *testing.T) {
 		filter := map[string]string{"status": "active"}
-		result := applyFilters(records, filter, nil)
+		result := filterRecords(t, records, filter, nil)
 
 		if len(result) != 1 {
 			t.Errorf("Expected 1 record with status=active, got %d", len(result))
@@ -840,7 +1179,7 @@ func TestMaybeTypeFiltering(t *testing.T) {
 			Operator: "=",
 			Value:    0, // Null Maybe[int] dereferences to 0
 		}
-		result := applyFilters(records, nil, rawFilter)
+		result := filterRecords(t, records, nil, rawFilter)
 
 		if len(result) != 1 {
 			t.Errorf("Expected 1 record with null maybe_field, got %d", len(result))
@@ -850,16 +1189,752 @@ func TestMaybeTypeFiltering(t *testing.T) {
 		}
 	})
 
+	t.Run("is-null distinguishes null from zero", func(t *testing.T) {
+		maybeZero := dsfetch.MaybeValue(0)
+		maybeEmpty := dsfetch.MaybeValue("")
+		withZero := append(records, map[string]any{"id": 4, "maybe_field": &maybeZero, "status": &maybeEmpty})
+
+		for _, field := range []string{"maybe_field", "status"} {
+			result := filterRecords(t, withZero, nil, &RawFilter{Field: field, Operator: "is-null"})
+			if len(result) != 1 || result[0]["id"] != 3 {
+				t.Errorf("Expected only record id=3 with null %s, got %v", field, result)
+			}
+
+			result = filterRecords(t, withZero, nil, &RawFilter{Field: field, Operator: "is-set"})
+			if len(result) != 3 {
+				t.Errorf("Expected 3 records with set %s, got %d", field, len(result))
+			}
+		}
+	})
+
 	t.Run("regex on maybe string", func(t *testing.T) {
 		rawFilter := &RawFilter{
 			Field:    "status",
 			Operator: "~=",
 			Value:    "^act",
 		}
-		result := applyFilters(records, nil, rawFilter)
+		result := filterRecords(t, records, nil, rawFilter)
 
 		if len(result) != 1 {
 			t.Errorf("Expected 1 record matching regex, got %d", len(result))
 		}
 	})
 }
+
+// userTestData creates a datastore with n users, every third user is inactive
+func userTestData(n int) map[dskey.Key][]byte {
+	var sb strings.Builder
+	ids := make([]string, n)
+	sb.WriteString("user:\n")
+	// Add users in descending order to make sure output is sorted by id
+	for i := n; i >= 1; i-- {
+		ids[n-i] = strconv.Itoa(i)
+		fmt.Fprintf(&sb, "  %d:\n    username: user%d\n    email: user%d@example.com\n    is_active: %t\n", i, i, i, i%3 != 0)
+	}
+	fmt.Fprintf(&sb, "organization/1:\n  user_ids: [%s]\n", strings.Join(ids, ", "))
+	return dsmock.YAMLData(sb.String())
+}
+
+func TestStreamCollection_MatchesQuery(t *testing.T) {
+	ctx := context.Background()
+	data := userTestData(25)
+
+	tests := []struct {
+		name      string
+		fields    []string
+		filter    map[string]string
+		rawFilter *RawFilter
+	}{
+		{"all", nil, nil, nil},
+		{"fields", []string{"username", "email"}, nil, nil},
+		{"simple filter", []string{"username"}, map[string]string{"is_active": "true"}, nil},
+		{"raw filter", []string{"username", "is_active"}, nil, &RawFilter{Field: "username", Operator: "~=", Value: "^user1"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := queryCollection(ctx, dsfetch.New(dsmock.Stub(data)), "user", tt.filter, tt.rawFilter, tt.fields, false, nil)
+			if err != nil {
+				t.Fatalf("queryCollection() error = %v", err)
+			}
+			buffered, err := json.MarshalIndent(result, "", "  ")
+			if err != nil {
+				t.Fatalf("marshaling result: %v", err)
+			}
+			expected, err := decodeRecords(buffered, "user")
+			if err != nil {
+				t.Fatalf("decodeRecords() error = %v", err)
+			}
+
+			for _, chunkSize := range []int{1, 4, 25, 100} {
+				var streamed []map[string]any
+				err := streamCollection(ctx, dsfetch.New(dsmock.Stub(data)), "user", tt.filter, tt.rawFilter, tt.fields, chunkSize, func(record map[string]any) error {
+					normalized, err := normalizeRecord(record)
+					if err != nil {
+						return err
+					}
+					streamed = append(streamed, normalized)
+					return nil
+				})
+				if err != nil {
+					t.Fatalf("streamCollection(chunk %d) error = %v", chunkSize, err)
+				}
+
+				if len(expected) == 0 && len(streamed) == 0 {
+					continue
+				}
+				if !reflect.DeepEqual(streamed, expected) {
+					t.Errorf("streamCollection(chunk %d) = %v, want %v", chunkSize, streamed, expected)
+				}
+			}
+		})
+	}
+}
+
+func TestStreamCollection_BoundedRequests(t *testing.T) {
+	counter := dsmock.NewCounter(dsmock.Stub(userTestData(50)))
+	fields := []string{"username", "email"}
+	chunkSize := 10
+
+	count := 0
+	err := streamCollection(context.Background(), dsfetch.New(counter), "user", nil, nil, fields, chunkSize, func(map[string]any) error {
+		count++
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("streamCollection() error = %v", err)
+	}
+	if count != 50 {
+		t.Errorf("Expected 50 records, got %d", count)
+	}
+
+	// Each request loads at most chunkSize models with their fields and id fields
+	maxKeys := chunkSize * len(fields) * 2
+	for i, keys := range counter.Requests() {
+		if len(keys) > maxKeys {
+			t.Errorf("Request %d fetched %d keys, expected at most %d", i, len(keys), maxKeys)
+		}
+	}
+	// One request for the ids plus one per chunk
+	if counter.Count() != 1+50/chunkSize {
+		t.Errorf("Expected %d requests, got %d", 1+50/chunkSize, counter.Count())
+	}
+}
+
+func TestStreamCollection_EmitError(t *testing.T) {
+	emitErr := errors.New("write failed")
+	err := streamCollection(context.Background(), dsfetch.New(dsmock.Stub(userTestData(5))), "user", nil, nil, nil, 2, func(map[string]any) error {
+		return emitErr
+	})
+	if !errors.Is(err, emitErr) {
+		t.Errorf("Expected emit error, got %v", err)
+	}
+}
+
+func TestQueryCollection_Timing(t *testing.T) {
+	// Every clock reading advances by a millisecond, so each phase measures
+	// exactly the difference between its start and end reading.
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	timings := &queryTimings{now: func() time.Time {
+		now = now.Add(time.Millisecond)
+		return now
+	}}
+	_, err := queryCollection(context.Background(), dsfetch.New(dsmock.Stub(userTestData(20))), "user", map[string]string{"is_active": "true"}, nil, []string{"username"}, false, timings)
+	if err != nil {
+		t.Fatalf("queryCollection() error = %v", err)
+	}
+
+	var buf bytes.Buffer
+	timings.write(&buf)
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+
+	phases := []string{phaseIDDiscovery, phaseFieldFetching, phaseFiltering}
+	if len(lines) != len(phases) {
+		t.Fatalf("Expected %d timing lines, got %d: %q", len(phases), len(lines), buf.String())
+	}
+	for i, phase := range phases {
+		if !strings.HasPrefix(lines[i], "timing: "+phase) {
+			t.Errorf("Expected line %d for phase %q, got %q", i, phase, lines[i])
+		}
+		if timings.durations[phase] != time.Millisecond {
+			t.Errorf("Expected duration 1ms for phase %q, got %v", phase, timings.durations[phase])
+		}
+	}
+}
+
+func TestQueryCollection_NoTiming(t *testing.T) {
+	var timings *queryTimings
+	if _, err := queryCollection(context.Background(), dsfetch.New(dsmock.Stub(userTestData(5))), "user", nil, nil, nil, false, timings); err != nil {
+		t.Fatalf("queryCollection() error = %v", err)
+	}
+
+	var buf bytes.Buffer
+	timings.write(&buf)
+	if buf.Len() != 0 {
+		t.Errorf("Expected no timing output without --timing, got %q", buf.String())
+	}
+}
+
+func TestExplainQuery(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("user with filter", func(t *testing.T) {
+		plan, err := explainQuery(ctx, dsfetch.New(dsmock.Stub(userTestData(20))), "user", map[string]string{"is_active": "true"}, nil, []string{"username:login"})
+		if err != nil {
+			t.Fatalf("explainQuery() error = %v", err)
+		}
+		if plan.models != 20 {
+			t.Errorf("Expected 20 models, got %d", plan.models)
+		}
+		if want := []string{"id", "is_active", "username"}; !slices.Equal(plan.fields, want) {
+			t.Errorf("Expected fields %v, got %v", want, plan.fields)
+		}
+		if !plan.clientSideFilter {
+			t.Error("Expected client-side filter")
+		}
+
+		var buf bytes.Buffer
+		plan.write(&buf)
+		for _, want := range []string{"models:     20", "fields:     id, is_active, username", "warning: the filter is applied client-side after fetching all 20 users"} {
+			if !strings.Contains(buf.String(), want) {
+				t.Errorf("Expected output to contain %q, got %q", want, buf.String())
+			}
+		}
+	})
+
+	t.Run("user without filter", func(t *testing.T) {
+		plan, err := explainQuery(ctx, dsfetch.New(dsmock.Stub(userTestData(3))), "user", nil, nil, nil)
+		if err != nil {
+			t.Fatalf("explainQuery() error = %v", err)
+		}
+		if plan.clientSideFilter {
+			t.Error("Expected no client-side filter")
+		}
+		var buf bytes.Buffer
+		plan.write(&buf)
+		if strings.Contains(buf.String(), "warning") {
+			t.Errorf("Expected no warning, got %q", buf.String())
+		}
+	})
+
+	t.Run("vote scoped by poll", func(t *testing.T) {
+		plan, err := explainQuery(ctx, dsfetch.New(dsmock.Stub(pollTestData())), "vote", map[string]string{"poll_id": "1"}, nil, []string{"value"})
+		if err != nil {
+			t.Fatalf("explainQuery() error = %v", err)
+		}
+		if plan.clientSideFilter {
+			t.Error("Expected poll_id to select the votes, not filter them")
+		}
+		if want := []string{"id", "value"}; !slices.Equal(plan.fields, want) {
+			t.Errorf("Expected fields %v, got %v", want, plan.fields)
+		}
+	})
+
+	t.Run("organization", func(t *testing.T) {
+		plan, err := explainQuery(ctx, dsfetch.New(dsmock.Stub(nil)), "organization", nil, nil, []string{"name:title"})
+		if err != nil {
+			t.Fatalf("explainQuery() error = %v", err)
+		}
+		if plan.models != 1 || !slices.Equal(plan.fields, []string{"name"}) {
+			t.Errorf("Expected 1 model with fields [name], got %d %v", plan.models, plan.fields)
+		}
+	})
+}
+
+func TestExplainGetCollection_InvalidRegex(t *testing.T) {
+	params := &pb.QueryParams{
+		Collection: "user",
+		RawFilter:  []byte(`{"field":"username","operator":"~=","value":"[a-"}`),
+	}
+	// The filter is rejected before the database is contacted
+	_, err := explainGetCollection(context.Background(), nil, params)
+	if err == nil || !strings.Contains(err.Error(), "invalid regex") {
+		t.Errorf("Expected invalid regex error, got %v", err)
+	}
+}
+
+// heapSampler tracks the peak heap usage across samples
+type heapSampler struct {
+	peak uint64
+}
+
+func (h *heapSampler) sample() {
+	var m runtime.MemStats
+	runtime.ReadMemStats(&m)
+	h.peak = max(h.peak, m.HeapAlloc)
+}
+
+// BenchmarkStreamCollection reports the peak heap usage of streaming compared to
+// buffering the whole result. The streamed peak depends on the chunk size only.
+func BenchmarkStreamCollection(b *testing.B) {
+	data := userTestData(5000)
+	fields := []string{"username", "email", "is_active"}
+
+	for _, chunkSize := range []int{100, 1000} {
+		b.Run(fmt.Sprintf("chunk-%d", chunkSize), func(b *testing.B) {
+			b.ReportAllocs()
+			var heap heapSampler
+			for b.Loop() {
+				runtime.GC()
+				count := 0
+				err := streamCollection(context.Background(), dsfetch.New(dsmock.Stub(data)), "user", nil, nil, fields, chunkSize, func(record map[string]any) error {
+					if count++; count%chunkSize == 0 {
+						heap.sample()
+					}
+					_, err := normalizeRecord(record)
+					return err
+				})
+				if err != nil {
+					b.Fatal(err)
+				}
+			}
+			b.ReportMetric(float64(heap.peak), "peak-heap-B")
+		})
+	}
+
+	b.Run("buffered", func(b *testing.B) {
+		b.ReportAllocs()
+		var heap heapSampler
+		for b.Loop() {
+			runtime.GC()
+			result, err := queryCollection(context.Background(), dsfetch.New(dsmock.Stub(data)), "user", nil, nil, fields, false, nil)
+			if err != nil {
+				b.Fatal(err)
+			}
+			heap.sample()
+			_ = result
+		}
+		b.ReportMetric(float64(heap.peak), "peak-heap-B")
+	})
+}
+
+// pollTestData creates a meeting with two polls. Poll 1 has two options and a
+// global option with votes, poll 2 has one option with one vote.
+func pollTestData() map[dskey.Key][]byte {
+	return dsmock.YAMLData(`
+meeting/1:
+  poll_ids: [1, 2]
+  vote_ids: [1, 2, 3, 4, 5]
+poll:
+  1:
+    meeting_id: 1
+    title: Board election
+    state: finished
+    option_ids: [1, 2]
+    global_option_id: 3
+    votesvalid: "3.500000"
+    entitled_users_at_stop: [{"user_id": 7, "voted": true}]
+  2:
+    meeting_id: 1
+    title: Budget
+    state: created
+    option_ids: [4]
+option:
+  1:
+    poll_id: 1
+    vote_ids: [1, 2]
+  2:
+    poll_id: 1
+    vote_ids: [3]
+  3:
+    used_as_global_option_in_poll_id: 1
+    vote_ids: [4]
+  4:
+    poll_id: 2
+    vote_ids: [5]
+vote:
+  1:
+    meeting_id: 1
+    option_id: 1
+    value: "Y"
+    weight: "1.500000"
+  2:
+    meeting_id: 1
+    option_id: 1
+    value: "Y"
+    weight: "0.333333"
+  3:
+    meeting_id: 1
+    option_id: 2
+    value: "N"
+    weight: "1.000000"
+  4:
+    meeting_id: 1
+    option_id: 3
+    value: "A"
+    weight: "0.666667"
+  5:
+    meeting_id: 1
+    option_id: 4
+    value: "Y"
+    weight: "1.000000"
+`)
+}
+
+// queryRecords runs queryCollection and decodes the JSON output like the get command
+func queryRecords(t *testing.T, collection string, filter map[string]string, fields []string) []map[string]any {
+	t.Helper()
+
+	result, err := queryCollection(context.Background(), dsfetch.New(dsmock.Stub(pollTestData())), collection, filter, nil, fields, false, nil)
+	if err != nil {
+		t.Fatalf("queryCollection(%s) error = %v", collection, err)
+	}
+	data, err := json.Marshal(result)
+	if err != nil {
+		t.Fatalf("marshalling result: %v", err)
+	}
+	records, err := decodeRecords(data, collection)
+	if err != nil {
+		t.Fatalf("decodeRecords() error = %v", err)
+	}
+	return records
+}
+
+func recordIDs(records []map[string]any) []string {
+	ids := make([]string, len(records))
+	for i, record := range records {
+		ids[i] = fmt.Sprintf("%v", record["id"])
+	}
+	return ids
+}
+
+func TestQueryPolls(t *testing.T) {
+	t.Run("by meeting", func(t *testing.T) {
+		records := queryRecords(t, "poll", map[string]string{"meeting_id": "1"}, []string{"title", "state"})
+		if ids := recordIDs(records); !slices.Equal(ids, []string{"1", "2"}) {
+			t.Errorf("Expected polls [1 2], got %v", ids)
+		}
+	})
+
+	t.Run("by meeting with additional filter", func(t *testing.T) {
+		records := queryRecords(t, "poll", map[string]string{"meeting_id": "1", "state": "finished"}, []string{"title"})
+		if ids := recordIDs(records); !slices.Equal(ids, []string{"1"}) {
+			t.Errorf("Expected poll [1], got %v", ids)
+		}
+	})
+
+	t.Run("by poll with JSON and decimal fields", func(t *testing.T) {
+		records := queryRecords(t, "poll", map[string]string{"poll_id": "1"}, []string{"votesvalid", "entitled_users_at_stop"})
+		if len(records) != 1 {
+			t.Fatalf("Expected 1 poll, got %d", len(records))
+		}
+		if records[0]["votesvalid"] != "3.5" {
+			t.Errorf("Expected votesvalid \"3.5\", got %#v", records[0]["votesvalid"])
+		}
+		entitled, ok := records[0]["entitled_users_at_stop"].([]any)
+		if !ok || len(entitled) != 1 {
+			t.Errorf("Expected JSON list for entitled_users_at_stop, got %#v", records[0]["entitled_users_at_stop"])
+		}
+	})
+}
+
+func TestQueryVotes(t *testing.T) {
+	t.Run("by meeting", func(t *testing.T) {
+		records := queryRecords(t, "vote", map[string]string{"meeting_id": "1"}, []string{"value"})
+		if ids := recordIDs(records); !slices.Equal(ids, []string{"1", "2", "3", "4", "5"}) {
+			t.Errorf("Expected votes [1 2 3 4 5], got %v", ids)
+		}
+	})
+
+	t.Run("by poll includes global option", func(t *testing.T) {
+		records := queryRecords(t, "vote", map[string]string{"poll_id": "1"}, []string{"option_id", "weight"})
+		if ids := recordIDs(records); !slices.Equal(ids, []string{"1", "2", "3", "4"}) {
+			t.Errorf("Expected votes [1 2 3 4], got %v", ids)
+		}
+		if _, ok := records[0]["poll_id"]; ok {
+			t.Error("Expected poll_id to not be fetched as vote field")
+		}
+	})
+
+	t.Run("by poll with additional filter", func(t *testing.T) {
+		records := queryRecords(t, "vote", map[string]string{"poll_id": "1", "value": "Y"}, nil)
+		if ids := recordIDs(records); !slices.Equal(ids, []string{"1", "2"}) {
+			t.Errorf("Expected votes [1 2], got %v", ids)
+		}
+	})
+}
+
+func TestQueryVotes_DecimalWeights(t *testing.T) {
+	records := queryRecords(t, "vote", map[string]string{"poll_id": "1"}, []string{"weight"})
+
+	sum := decimal.Zero
+	for _, record := range records {
+		weight, ok := record["weight"].(string)
+		if !ok {
+			t.Fatalf("Expected weight to be serialized as exact decimal string, got %#v", record["weight"])
+		}
+		d, err := decimal.NewFromString(weight)
+		if err != nil {
+			t.Fatalf("parsing weight %q: %v", weight, err)
+		}
+		sum = sum.Add(d)
+	}
+
+	if !sum.Equal(decimal.RequireFromString("3.5")) {
+		t.Errorf("Expected exact weight sum 3.5, got %s", sum)
+	}
+}
+
+func TestQueryVotes_RequiresScope(t *testing.T) {
+	fetch := dsfetch.New(dsmock.Stub(pollTestData()))
+
+	for _, collection := range []string{"poll", "vote"} {
+		if _, err := queryCollection(context.Background(), fetch, collection, nil, nil, nil, false, nil); err == nil {
+			t.Errorf("Expected error for %s without meeting_id or poll_id", collection)
+		}
+		if _, err := queryCollection(context.Background(), fetch, collection, map[string]string{"meeting_id": "abc"}, nil, nil, false, nil); err == nil {
+			t.Errorf("Expected error for %s with invalid meeting_id", collection)
+		}
+	}
+}
+
+func TestDBConfigFromEnv(t *testing.T) {
+	t.Setenv(constants.EnvDatabaseHost, "postgres")
+	t.Setenv(constants.EnvDatabasePort, "5433")
+	t.Setenv(constants.EnvDatabaseUser, "osuser")
+	t.Setenv(constants.EnvDatabaseName, "osdb")
+	t.Setenv(constants.EnvDatabasePasswordFile, "/run/secrets/postgres_password")
+
+	envMap := connectionEnv(dbConfigFromEnv(os.Getenv))
+
+	expected := map[string]string{
+		constants.EnvDatabaseHost:          "postgres",
+		constants.EnvDatabasePort:          "5433",
+		constants.EnvDatabaseUser:          "osuser",
+		constants.EnvDatabaseName:          "osdb",
+		constants.EnvDatabasePasswordFile:  "/run/secrets/postgres_password",
+		constants.EnvOpenSlidesDevelopment: constants.DevelopmentModeDisabled,
+	}
+	if !reflect.DeepEqual(envMap, expected) {
+		t.Errorf("Expected connection env %v, got %v", expected, envMap)
+	}
+}
+
+func TestDBConfigFromEnv_Unset(t *testing.T) {
+	t.Setenv(constants.EnvDatabaseHost, "postgres")
+	t.Setenv(constants.EnvDatabasePort, "")
+	t.Setenv(constants.EnvDatabaseUser, "")
+	t.Setenv(constants.EnvDatabaseName, "")
+	t.Setenv(constants.EnvDatabasePasswordFile, "")
+
+	envMap := connectionEnv(dbConfigFromEnv(os.Getenv))
+
+	if envMap[constants.EnvDatabaseHost] != "postgres" {
+		t.Errorf("Expected host from env, got %q", envMap[constants.EnvDatabaseHost])
+	}
+	for _, key := range []string{constants.EnvDatabasePort, constants.EnvDatabaseUser, constants.EnvDatabaseName, constants.EnvDatabasePasswordFile} {
+		if _, ok := envMap[key]; ok {
+			t.Errorf("Expected unset %s to be omitted so the datastore default applies", key)
+		}
+	}
+}
+
+func TestCmd_PostgresFlags(t *testing.T) {
+	for _, name := range []string{"OSMANAGE_POSTGRES_HOST", "OSMANAGE_POSTGRES_PORT", "OSMANAGE_POSTGRES_USER", "OSMANAGE_POSTGRES_DATABASE", "OSMANAGE_POSTGRES_PASSWORD_FILE"} {
+		t.Setenv(name, "")
+	}
+
+	t.Run("required without from-env", func(t *testing.T) {
+		cmd := Cmd()
+		cmd.SetArgs([]string{"user"})
+		cmd.SetOut(io.Discard)
+		cmd.SetErr(io.Discard)
+		err := cmd.Execute()
+		if err == nil || !strings.Contains(err.Error(), "postgres-host") {
+			t.Errorf("Expected required flag error, got %v", err)
+		}
+	})
+
+	t.Run("from-env excludes postgres flags", func(t *testing.T) {
+		cmd := Cmd()
+		cmd.SetArgs([]string{"user", "--from-env", "--postgres-host", "localhost"})
+		cmd.SetOut(io.Discard)
+		cmd.SetErr(io.Discard)
+		if err := cmd.Execute(); err == nil {
+			t.Error("Expected error for --from-env with --postgres-host")
+		}
+	})
+}
+
+func TestReadRawFilterFile(t *testing.T) {
+	dir := t.TempDir()
+
+	t.Run("valid filter", func(t *testing.T) {
+		path := filepath.Join(dir, "filter.json")
+		content := `{"or_filter":[{"field":"username","operator":"~=","value":"^admin"},{"field":"is_active","operator":"=","value":true}]}`
+		if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+		got, err := readRawFilterFile(path)
+		if err != nil {
+			t.Fatalf("readRawFilterFile() error = %v", err)
+		}
+		if got != content {
+			t.Errorf("Expected %s, got %s", content, got)
+		}
+	})
+
+	t.Run("invalid json", func(t *testing.T) {
+		path := filepath.Join(dir, "invalid.json")
+		if err := os.WriteFile(path, []byte(`{"field":`), 0600); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := readRawFilterFile(path); err == nil {
+			t.Error("Expected error for invalid JSON")
+		}
+	})
+
+	t.Run("missing file", func(t *testing.T) {
+		if _, err := readRawFilterFile(filepath.Join(dir, "missing.json")); err == nil {
+			t.Error("Expected error for missing file")
+		}
+	})
+}
+
+func TestCmd_FilterRawFileExclusive(t *testing.T) {
+	for _, flag := range []string{"--filter=is_active=true", `--filter-raw={"field":"id","operator":"=","value":1}`} {
+		cmd := Cmd()
+		cmd.SetArgs([]string{"user", "--from-env", "--filter-raw-file", "filter.json", flag})
+		cmd.SetOut(io.Discard)
+		cmd.SetErr(io.Discard)
+		err := cmd.Execute()
+		if err == nil || !strings.Contains(err.Error(), "filter-raw-file") {
+			t.Errorf("Expected mutually exclusive error for %s, got %v", flag, err)
+		}
+	}
+}
+
+func TestReadFieldsFile(t *testing.T) {
+	dir := t.TempDir()
+
+	t.Run("lines, commas and comments", func(t *testing.T) {
+		path := filepath.Join(dir, "fields.txt")
+		content := "# export fields\nusername\n\nfirst_name, last_name # names\n  email\n"
+		if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+		got, err := readFieldsFile(path)
+		if err != nil {
+			t.Fatalf("readFieldsFile() error = %v", err)
+		}
+		want := []string{"username", "first_name", "last_name", "email"}
+		if !slices.Equal(got, want) {
+			t.Errorf("Expected %v, got %v", want, got)
+		}
+	})
+
+	t.Run("only comments", func(t *testing.T) {
+		path := filepath.Join(dir, "empty.txt")
+		if err := os.WriteFile(path, []byte("# nothing\n\n"), 0600); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := readFieldsFile(path); err == nil {
+			t.Error("Expected error for file without fields")
+		}
+	})
+
+	t.Run("missing file", func(t *testing.T) {
+		if _, err := readFieldsFile(filepath.Join(dir, "missing.txt")); err == nil {
+			t.Error("Expected error for missing file")
+		}
+	})
+}
+
+func TestMergeFields(t *testing.T) {
+	got := mergeFields([]string{"username", "email"}, []string{"email", "first_name"})
+	want := []string{"username", "email", "first_name"}
+	if !slices.Equal(got, want) {
+		t.Errorf("Expected %v, got %v", want, got)
+	}
+}
+
+func TestLookupFetchMethod(t *testing.T) {
+	fm := lookupFetchMethod("user", "first_name")
+	if !fm.found || fm.name != "User_FirstName" {
+		t.Errorf("lookupFetchMethod() = %+v, want found User_FirstName", fm)
+	}
+	if cached := lookupFetchMethod("user", "first_name"); cached.method.Index != fm.method.Index {
+		t.Errorf("Expected cached method, got %+v", cached)
+	}
+
+	if fm := lookupFetchMethod("user", "does_not_exist"); fm.found {
+		t.Errorf("Expected unknown field not to be found, got %+v", fm)
+	}
+	if _, err := fetchField(dsfetch.New(dsmock.Stub(userTestData(1))), "user", 1, "does_not_exist"); err == nil {
+		t.Error("Expected error for unknown field")
+	}
+}
+
+func TestWriteOutputFile(t *testing.T) {
+	if err := writeOutputFile("", []byte("ignored")); err != nil {
+		t.Errorf("writeOutputFile() without filename error = %v", err)
+	}
+
+	path := filepath.Join(t.TempDir(), "users.csv")
+	if err := writeOutputFile(path, []byte("id,username\n1,admin\n")); err != nil {
+		t.Fatalf("writeOutputFile() error = %v", err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "id,username\n1,admin\n" {
+		t.Errorf("Expected output in file, got %q", data)
+	}
+}
+
+func TestValidateGroupBy(t *testing.T) {
+	tests := []struct {
+		field   string
+		fields  []string
+		wantErr bool
+	}{
+		{"organization_management_level", nil, false},
+		{"organization_management_level", []string{"username", "organization_management_level"}, false},
+		{"level", []string{"organization_management_level:level"}, false},
+		{"id", []string{"username"}, false},
+		{"organization_management_level", []string{"organization_management_level:level"}, true},
+		{"email", []string{"username"}, true},
+	}
+
+	for _, tt := range tests {
+		err := validateGroupBy(tt.field, tt.fields)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("validateGroupBy(%q, %v) error = %v, wantErr %v", tt.field, tt.fields, err, tt.wantErr)
+		}
+	}
+}
+
+func TestCmd_GroupByFlags(t *testing.T) {
+	tests := []struct {
+		args []string
+		want string
+	}{
+		{[]string{"--group-values"}, "--group-values requires --group-by"},
+		{[]string{"--group-by", "is_active", "--output", "csv"}, "--group-by requires --output json"},
+		{[]string{"--group-by", "is_active", "--flatten"}, "--group-by cannot be used"},
+		{[]string{"--group-by", "is_active", "--fields", "username"}, "not in --fields"},
+	}
+
+	for _, tt := range tests {
+		cmd := Cmd()
+		cmd.SetArgs(append([]string{"user", "--from-env"}, tt.args...))
+		cmd.SetOut(io.Discard)
+		cmd.SetErr(io.Discard)
+		err := cmd.Execute()
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("args %v: expected error containing %q, got %v", tt.args, tt.want, err)
+		}
+	}
+}
+
+func TestCmd_ExplainWithStream(t *testing.T) {
+	cmd := Cmd()
+	cmd.SetArgs([]string{"user", "--from-env", "--explain", "--stream", "--output", "jsonl"})
+	cmd.SetOut(io.Discard)
+	cmd.SetErr(io.Discard)
+	err := cmd.Execute()
+	if err == nil || !strings.Contains(err.Error(), "--stream cannot be used with --explain") {
+		t.Errorf("Expected --stream/--explain error, got %v", err)
+	}
+}
@@ -0,0 +1,573 @@
+package get
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/csv"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// Output formats supported by the get command
+const (
+	OutputJSON  = "json"
+	OutputJSONL = "jsonl"
+	OutputCSV   = "csv"
+
+	// OutputNDJSONSummary is jsonl followed by a {"_summary":{"count":n}} line
+	OutputNDJSONSummary = "ndjson-summary"
+)
+
+// outputFormats lists all valid values for --output
+var outputFormats = []string{OutputJSON, OutputJSONL, OutputCSV, OutputNDJSONSummary}
+
+// outputSummary is the trailing line of the ndjson-summary output
+type outputSummary struct {
+	Summary struct {
+		Count int `json:"count"`
+	} `json:"_summary"`
+}
+
+// CSVOptions configures the CSV output
+type CSVOptions struct {
+	Delimiter rune
+	NoHeader  bool
+}
+
+// parseDelimiter validates that the delimiter is a single rune usable by csv.Writer
+func parseDelimiter(s string) (rune, error) {
+	if utf8.RuneCountInString(s) != 1 {
+		return 0, fmt.Errorf("csv delimiter must be a single character, got %q", s)
+	}
+	r, _ := utf8.DecodeRuneInString(s)
+	if r == utf8.RuneError || r == '"' || r == '\r' || r == '\n' {
+		return 0, fmt.Errorf("invalid csv delimiter %q", s)
+	}
+	return r, nil
+}
+
+// decodeRecords decodes the JSON result of a collection query into a list of
+// records sorted by id. The organization result is a single unkeyed object and
+// is returned as one record. Numbers are kept as json.Number to print them exactly.
+func decodeRecords(data []byte, collection string) ([]map[string]any, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+
+	if collection == "organization" {
+		var record map[string]any
+		if err := decoder.Decode(&record); err != nil {
+			return nil, fmt.Errorf("decoding result: %w", err)
+		}
+		return []map[string]any{record}, nil
+	}
+
+	var keyed map[string]map[string]any
+	if err := decoder.Decode(&keyed); err != nil {
+		return nil, fmt.Errorf("decoding result: %w", err)
+	}
+
+	// Sort by the keys, the id field may be renamed by an alias
+	ids := make([]string, 0, len(keyed))
+	for id := range keyed {
+		ids = append(ids, id)
+	}
+	sortIDs(ids)
+
+	records := make([]map[string]any, 0, len(keyed))
+	for _, id := range ids {
+		records = append(records, keyed[id])
+	}
+	return records, nil
+}
+
+// encodeRecords is the inverse of decodeRecords and produces the same JSON layout
+// as ExecuteGetCollection: a single object for organization, otherwise an object
+// keyed by the id stored under idKey.
+func encodeRecords(records []map[string]any, collection, idKey string) ([]byte, error) {
+	var result any
+	if collection == "organization" {
+		if len(records) > 0 {
+			result = records[0]
+		} else {
+			result = map[string]any{}
+		}
+	} else {
+		keyed := make(map[string]any, len(records))
+		for _, record := range records {
+			keyed[fmt.Sprintf("%v", record[idKey])] = record
+		}
+		result = keyed
+	}
+
+	data, err := json.MarshalIndent(result, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("encoding result: %w", err)
+	}
+	return data, nil
+}
+
+// encodeRecordArray encodes records as JSON array in their order, the layout of
+// --flatten
+func encodeRecordArray(records []map[string]any) ([]byte, error) {
+	data, err := json.MarshalIndent(records, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("encoding result: %w", err)
+	}
+	return data, nil
+}
+
+// nullGroupKey is the --group-by bucket of records with a missing or null value
+const nullGroupKey = "(null)"
+
+// groupRecords buckets records by the value of field and returns the number of
+// records per value, or with values the ids stored under idKey per value.
+// Values are rendered like CSV cells. Missing and null values are collected
+// separately under nullGroupKey, a value with the same rendering is an error.
+func groupRecords(records []map[string]any, field, idKey string, values bool) (map[string]any, error) {
+	counts := map[string]int{}
+	ids := map[string][]any{}
+	var nullIDs []any
+	for _, record := range records {
+		if dereferenceValue(record[field]) == nil {
+			nullIDs = append(nullIDs, record[idKey])
+			continue
+		}
+		key, err := csvValue(record[field])
+		if err != nil {
+			return nil, fmt.Errorf("grouping by %s: %w", field, err)
+		}
+		counts[key]++
+		ids[key] = append(ids[key], record[idKey])
+	}
+	if len(nullIDs) > 0 {
+		if _, ok := counts[nullGroupKey]; ok {
+			return nil, fmt.Errorf("grouping by %s: value %q collides with the bucket of null values", field, nullGroupKey)
+		}
+		counts[nullGroupKey] = len(nullIDs)
+		ids[nullGroupKey] = nullIDs
+	}
+
+	groups := make(map[string]any, len(counts))
+	for key, count := range counts {
+		if values {
+			groups[key] = ids[key]
+		} else {
+			groups[key] = count
+		}
+	}
+	return groups, nil
+}
+
+// encodeGroups encodes the result of groupRecords as JSON object
+func encodeGroups(groups map[string]any) ([]byte, error) {
+	data, err := json.MarshalIndent(groups, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("encoding groups: %w", err)
+	}
+	return data, nil
+}
+
+// sortIDs sorts ids numerically
+func sortIDs(ids []string) {
+	sort.SliceStable(ids, func(i, j int) bool {
+		a, _ := toNumber(ids[i])
+		b, _ := toNumber(ids[j])
+		return a < b
+	})
+}
+
+// csvColumns returns the column order: id first, then the output keys of the
+// requested fields (see splitFieldAlias), or all fields found in the records
+// in sorted order if none were requested.
+func csvColumns(records []map[string]any, fields []string) []string {
+	idKey := outputKey(fields, "id")
+	columns := []string{idKey}
+	seen := map[string]bool{idKey: true}
+
+	if len(fields) > 0 {
+		for _, spec := range fields {
+			if _, key := splitFieldAlias(spec); !seen[key] {
+				seen[key] = true
+				columns = append(columns, key)
+			}
+		}
+		return columns
+	}
+
+	var rest []string
+	for _, record := range records {
+		for field := range record {
+			if !seen[field] {
+				seen[field] = true
+				rest = append(rest, field)
+			}
+		}
+	}
+	sort.Strings(rest)
+	return append(columns, rest...)
+}
+
+// csvValue renders a single value as CSV cell. Lists and objects are JSON encoded.
+func csvValue(value any) (string, error) {
+	switch v := dereferenceValue(value).(type) {
+	case nil:
+		return "", nil
+	case string:
+		return v, nil
+	case json.Number:
+		return v.String(), nil
+	case json.RawMessage:
+		return string(v), nil
+	case bool, int, int64, float64:
+		return fmt.Sprintf("%v", v), nil
+	default:
+		data, err := json.Marshal(v)
+		if err != nil {
+			return "", fmt.Errorf("encoding value: %w", err)
+		}
+		return string(data), nil
+	}
+}
+
+// writeCSV writes records as CSV with the given columns
+func writeCSV(w io.Writer, records []map[string]any, columns []string, opts CSVOptions) error {
+	cw, err := newCSVRecordWriter(w, columns, opts)
+	if err != nil {
+		return err
+	}
+	for _, record := range records {
+		if err := cw.Write(record); err != nil {
+			return err
+		}
+	}
+	return cw.Flush()
+}
+
+// csvRecordWriter writes records one at a time as CSV rows
+type csvRecordWriter struct {
+	cw      *csv.Writer
+	columns []string
+	row     []string
+}
+
+// newCSVRecordWriter creates a csvRecordWriter and writes the header unless disabled
+func newCSVRecordWriter(w io.Writer, columns []string, opts CSVOptions) (*csvRecordWriter, error) {
+	cw := csv.NewWriter(w)
+	if opts.Delimiter != 0 {
+		cw.Comma = opts.Delimiter
+	}
+
+	if !opts.NoHeader {
+		if err := cw.Write(columns); err != nil {
+			return nil, fmt.Errorf("writing csv header: %w", err)
+		}
+	}
+
+	return &csvRecordWriter{cw: cw, columns: columns, row: make([]string, len(columns))}, nil
+}
+
+// Write writes a single record as CSV row
+func (w *csvRecordWriter) Write(record map[string]any) error {
+	for i, column := range w.columns {
+		cell, err := csvValue(record[column])
+		if err != nil {
+			return fmt.Errorf("column %s: %w", column, err)
+		}
+		w.row[i] = cell
+	}
+	if err := w.cw.Write(w.row); err != nil {
+		return fmt.Errorf("writing csv row: %w", err)
+	}
+	return nil
+}
+
+// Flush writes any buffered rows
+func (w *csvRecordWriter) Flush() error {
+	w.cw.Flush()
+	return w.cw.Error()
+}
+
+// writeJSONL writes each record as compact JSON object on its own line
+func writeJSONL(w io.Writer, records []map[string]any) error {
+	encoder := json.NewEncoder(w)
+	for _, record := range records {
+		if err := encoder.Encode(record); err != nil {
+			return fmt.Errorf("writing json line: %w", err)
+		}
+	}
+	return nil
+}
+
+// writeSummary writes the trailing summary line of the ndjson-summary output
+func writeSummary(w io.Writer, count int) error {
+	var summary outputSummary
+	summary.Summary.Count = count
+	if err := json.NewEncoder(w).Encode(summary); err != nil {
+		return fmt.Errorf("writing summary line: %w", err)
+	}
+	return nil
+}
+
+// writeNDJSONSummary writes records like writeJSONL followed by a summary line
+// with the number of records
+func writeNDJSONSummary(w io.Writer, records []map[string]any) error {
+	if err := writeJSONL(w, records); err != nil {
+		return err
+	}
+	return writeSummary(w, len(records))
+}
+
+// normalizeRecord converts a record as returned by the query functions into the
+// decoded form used by decodeRecords, so streamed and buffered output are identical.
+func normalizeRecord(record map[string]any) (map[string]any, error) {
+	data, err := json.Marshal(record)
+	if err != nil {
+		return nil, fmt.Errorf("encoding record: %w", err)
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+
+	var normalized map[string]any
+	if err := decoder.Decode(&normalized); err != nil {
+		return nil, fmt.Errorf("decoding record: %w", err)
+	}
+	return normalized, nil
+}
+
+// randomSalt returns a random hex encoded salt used when no --hash-salt is given
+func randomSalt() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("generating salt: %w", err)
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// hashValue returns the hex encoded SHA-256 digest of salt followed by value
+func hashValue(value, salt string) string {
+	sum := sha256.Sum256([]byte(salt + value))
+	return hex.EncodeToString(sum[:])
+}
+
+// hashRecordFields replaces the values of the given fields with their salted
+// hash. Missing and null values are left untouched.
+func hashRecordFields(records []map[string]any, fields []string, salt string) error {
+	for _, record := range records {
+		for _, field := range fields {
+			value, ok := record[field]
+			if !ok || value == nil {
+				continue
+			}
+			plain, err := csvValue(value)
+			if err != nil {
+				return fmt.Errorf("field %s: %w", field, err)
+			}
+			record[field] = hashValue(plain, salt)
+		}
+	}
+	return nil
+}
+
+// EnumMaps maps field names to tables translating stored values to labels
+type EnumMaps map[string]map[string]string
+
+// parseEnumMaps parses --enum-map values of the form field=0:inactive,1:active
+func parseEnumMaps(values []string) (EnumMaps, error) {
+	enumMaps := EnumMaps{}
+	for _, value := range values {
+		field, table, ok := strings.Cut(value, "=")
+		if !ok || field == "" || table == "" {
+			return nil, fmt.Errorf("invalid --enum-map %q: expected field=value:label,...", value)
+		}
+		if _, exists := enumMaps[field]; exists {
+			return nil, fmt.Errorf("invalid --enum-map %q: field %s mapped twice", value, field)
+		}
+
+		labels := map[string]string{}
+		for entry := range strings.SplitSeq(table, ",") {
+			code, label, ok := strings.Cut(entry, ":")
+			if !ok || code == "" {
+				return nil, fmt.Errorf("invalid --enum-map %q: expected value:label, got %q", value, entry)
+			}
+			labels[code] = label
+		}
+		enumMaps[field] = labels
+	}
+	return enumMaps, nil
+}
+
+// withOutputKeys returns the enum maps keyed by the output keys of their
+// fields given the --fields entries, see outputKey
+func (enumMaps EnumMaps) withOutputKeys(fields []string) EnumMaps {
+	keyed := make(EnumMaps, len(enumMaps))
+	for field, labels := range enumMaps {
+		keyed[outputKey(fields, field)] = labels
+	}
+	return keyed
+}
+
+// applyEnumMaps replaces the values of mapped fields with their labels. Elements
+// of list values are translated individually. Unmapped values are left unchanged.
+func applyEnumMaps(records []map[string]any, enumMaps EnumMaps) {
+	for _, record := range records {
+		for field, labels := range enumMaps {
+			value, ok := record[field]
+			if !ok {
+				continue
+			}
+			if list, ok := value.([]any); ok {
+				for i, element := range list {
+					list[i] = enumLabel(element, labels)
+				}
+				continue
+			}
+			record[field] = enumLabel(value, labels)
+		}
+	}
+}
+
+// enumLabel returns the label of a scalar value or the value itself if unmapped
+func enumLabel(value any, labels map[string]string) any {
+	switch value.(type) {
+	case nil, []any, map[string]any:
+		return value
+	}
+	plain, err := csvValue(value)
+	if err != nil {
+		return value
+	}
+	if label, ok := labels[plain]; ok {
+		return label
+	}
+	return value
+}
+
+// Query phases reported by get --timing
+const (
+	phaseIDDiscovery   = "id discovery"
+	phaseFieldFetching = "field fetching"
+	phaseFiltering     = "filtering"
+	phaseMarshalling   = "marshalling"
+)
+
+// queryTimings records how long each query phase took. All methods are no-ops
+// on a nil *queryTimings, so callers can pass nil to disable timing.
+type queryTimings struct {
+	phases    []string
+	durations map[string]time.Duration
+	now       func() time.Time // clock, time.Now if nil
+}
+
+// clock returns the current time to measure phases with
+func (t *queryTimings) clock() time.Time {
+	if t == nil || t.now == nil {
+		return time.Now()
+	}
+	return t.now()
+}
+
+// record adds the time elapsed since start to phase
+func (t *queryTimings) record(phase string, start time.Time) {
+	if t == nil {
+		return
+	}
+	if t.durations == nil {
+		t.durations = map[string]time.Duration{}
+	}
+	if _, ok := t.durations[phase]; !ok {
+		t.phases = append(t.phases, phase)
+	}
+	t.durations[phase] += t.clock().Sub(start)
+}
+
+// write prints one line per phase in the order the phases were first recorded
+func (t *queryTimings) write(w io.Writer) {
+	if t == nil {
+		return
+	}
+	for _, phase := range t.phases {
+		fmt.Fprintf(w, "timing: %-15s %v\n", phase, t.durations[phase])
+	}
+}
+
+// queryPlan describes how a query is executed, as printed by --explain
+type queryPlan struct {
+	collection       string
+	models           int
+	fields           []string
+	clientSideFilter bool
+}
+
+// write prints the plan in a human readable form
+func (p *queryPlan) write(w io.Writer) {
+	fmt.Fprintf(w, "collection: %s\n", p.collection)
+	fmt.Fprintf(w, "models:     %d\n", p.models)
+	fmt.Fprintf(w, "fields:     %s\n", strings.Join(p.fields, ", "))
+	if p.clientSideFilter {
+		fmt.Fprintf(w, "warning: the filter is applied client-side after fetching all %d %ss\n", p.models, p.collection)
+	}
+}
+
+// distinctFilter remembers the selected field values of the records seen so
+// far, ignoring the id. A nil *distinctFilter keeps all records.
+type distinctFilter struct {
+	idKey string
+	seen  map[string]bool
+}
+
+// newDistinctFilter returns a filter for records selected with fields
+func newDistinctFilter(fields []string) *distinctFilter {
+	return &distinctFilter{idKey: outputKey(fields, "id"), seen: map[string]bool{}}
+}
+
+// keep reports whether no record with the same values apart from the id was
+// seen before
+func (d *distinctFilter) keep(record map[string]any) (bool, error) {
+	if d == nil {
+		return true, nil
+	}
+
+	values := make(map[string]any, len(record))
+	for key, value := range record {
+		if key != d.idKey {
+			values[key] = value
+		}
+	}
+	// Maps are encoded with sorted keys, so equal values give equal keys
+	data, err := json.Marshal(values)
+	if err != nil {
+		return false, fmt.Errorf("encoding distinct key: %w", err)
+	}
+
+	key := string(data)
+	if d.seen[key] {
+		return false, nil
+	}
+	d.seen[key] = true
+	return true, nil
+}
+
+// distinctRecords returns the records whose values apart from the id differ
+// from all previous records, keeping the first occurrence
+func distinctRecords(records []map[string]any, fields []string) ([]map[string]any, error) {
+	dedup := newDistinctFilter(fields)
+	distinct := make([]map[string]any, 0, len(records))
+	for _, record := range records {
+		keep, err := dedup.keep(record)
+		if err != nil {
+			return nil, err
+		}
+		if keep {
+			distinct = append(distinct, record)
+		}
+	}
+	return distinct, nil
+}
@@ -0,0 +1,469 @@
+package get
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseDelimiter(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected rune
+		wantErr  bool
+	}{
+		{",", ',', false},
+		{";", ';', false},
+		{"\t", '\t', false},
+		{"|", '|', false},
+		{"", 0, true},
+		{";;", 0, true},
+		{"\"", 0, true},
+		{"\n", 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			result, err := parseDelimiter(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseDelimiter(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+			}
+			if result != tt.expected {
+				t.Errorf("parseDelimiter(%q) = %q, want %q", tt.input, result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestDecodeRecords(t *testing.T) {
+	t.Run("id keyed collection sorted by id", func(t *testing.T) {
+		data := []byte(`{"10": {"id": 10, "name": "b"}, "2": {"id": 2, "name": "a"}}`)
+
+		records, err := decodeRecords(data, "user")
+		if err != nil {
+			t.Fatalf("decodeRecords() error = %v", err)
+		}
+		if len(records) != 2 {
+			t.Fatalf("Expected 2 records, got %d", len(records))
+		}
+		if records[0]["id"] != json.Number("2") || records[1]["id"] != json.Number("10") {
+			t.Errorf("Expected records sorted by id, got %v", records)
+		}
+	})
+
+	t.Run("organization is a single record", func(t *testing.T) {
+		records, err := decodeRecords([]byte(`{"id": 1, "name": "Org"}`), "organization")
+		if err != nil {
+			t.Fatalf("decodeRecords() error = %v", err)
+		}
+		expected := []map[string]any{{"id": json.Number("1"), "name": "Org"}}
+		if !reflect.DeepEqual(records, expected) {
+			t.Errorf("decodeRecords() = %v, want %v", records, expected)
+		}
+	})
+
+	t.Run("invalid JSON", func(t *testing.T) {
+		if _, err := decodeRecords([]byte(`not json`), "user"); err == nil {
+			t.Error("Expected error for invalid JSON")
+		}
+	})
+}
+
+func TestCSVColumns(t *testing.T) {
+	records := []map[string]any{
+		{"id": 1, "last_name": "Doe", "first_name": "John"},
+		{"id": 2, "email": "jane@example.com"},
+	}
+
+	t.Run("requested fields keep order", func(t *testing.T) {
+		result := csvColumns(records, []string{"last_name", "id", "first_name"})
+		expected := []string{"id", "last_name", "first_name"}
+		if !reflect.DeepEqual(result, expected) {
+			t.Errorf("csvColumns() = %v, want %v", result, expected)
+		}
+	})
+
+	t.Run("aliased fields", func(t *testing.T) {
+		result := csvColumns(records, []string{"id:user_id", "first_name:given_name", "last_name"})
+		expected := []string{"user_id", "given_name", "last_name"}
+		if !reflect.DeepEqual(result, expected) {
+			t.Errorf("csvColumns() = %v, want %v", result, expected)
+		}
+	})
+
+	t.Run("all fields sorted", func(t *testing.T) {
+		result := csvColumns(records, nil)
+		expected := []string{"id", "email", "first_name", "last_name"}
+		if !reflect.DeepEqual(result, expected) {
+			t.Errorf("csvColumns() = %v, want %v", result, expected)
+		}
+	})
+}
+
+func TestWriteCSV(t *testing.T) {
+	records := []map[string]any{
+		{"id": json.Number("1"), "username": "admin", "is_active": true, "meeting_ids": []any{json.Number("1"), json.Number("2")}},
+		{"id": json.Number("2"), "username": "semi;colon", "is_active": false},
+	}
+	columns := []string{"id", "username", "is_active", "meeting_ids"}
+
+	t.Run("default comma delimiter", func(t *testing.T) {
+		var buf bytes.Buffer
+		if err := writeCSV(&buf, records, columns, CSVOptions{}); err != nil {
+			t.Fatalf("writeCSV() error = %v", err)
+		}
+		expected := "id,username,is_active,meeting_ids\n" +
+			"1,admin,true,\"[1,2]\"\n" +
+			"2,semi;colon,false,\n"
+		if buf.String() != expected {
+			t.Errorf("writeCSV() =\n%s\nwant\n%s", buf.String(), expected)
+		}
+	})
+
+	t.Run("semicolon delimiter", func(t *testing.T) {
+		var buf bytes.Buffer
+		if err := writeCSV(&buf, records, columns, CSVOptions{Delimiter: ';'}); err != nil {
+			t.Fatalf("writeCSV() error = %v", err)
+		}
+		expected := "id;username;is_active;meeting_ids\n" +
+			"1;admin;true;[1,2]\n" +
+			"2;\"semi;colon\";false;\n"
+		if buf.String() != expected {
+			t.Errorf("writeCSV() =\n%s\nwant\n%s", buf.String(), expected)
+		}
+	})
+
+	t.Run("no header", func(t *testing.T) {
+		var buf bytes.Buffer
+		if err := writeCSV(&buf, records, columns, CSVOptions{Delimiter: ';', NoHeader: true}); err != nil {
+			t.Fatalf("writeCSV() error = %v", err)
+		}
+		expected := "1;admin;true;[1,2]\n" +
+			"2;\"semi;colon\";false;\n"
+		if buf.String() != expected {
+			t.Errorf("writeCSV() =\n%s\nwant\n%s", buf.String(), expected)
+		}
+	})
+}
+
+func TestWriteNDJSONSummary(t *testing.T) {
+	records := []map[string]any{
+		{"id": json.Number("1"), "username": "admin"},
+		{"id": json.Number("2"), "username": "bob"},
+	}
+
+	var buf bytes.Buffer
+	if err := writeNDJSONSummary(&buf, records); err != nil {
+		t.Fatalf("writeNDJSONSummary() error = %v", err)
+	}
+
+	expected := `{"id":1,"username":"admin"}` + "\n" +
+		`{"id":2,"username":"bob"}` + "\n" +
+		`{"_summary":{"count":2}}` + "\n"
+	if buf.String() != expected {
+		t.Errorf("writeNDJSONSummary() =\n%s\nwant\n%s", buf.String(), expected)
+	}
+
+	t.Run("no records", func(t *testing.T) {
+		var buf bytes.Buffer
+		if err := writeNDJSONSummary(&buf, nil); err != nil {
+			t.Fatalf("writeNDJSONSummary() error = %v", err)
+		}
+		if buf.String() != `{"_summary":{"count":0}}`+"\n" {
+			t.Errorf("Expected only the summary line, got %q", buf.String())
+		}
+	})
+}
+
+func TestParseEnumMaps(t *testing.T) {
+	enumMaps, err := parseEnumMaps([]string{"state=0:inactive,1:active", "type=a:Type A"})
+	if err != nil {
+		t.Fatalf("parseEnumMaps() error = %v", err)
+	}
+
+	expected := EnumMaps{
+		"state": {"0": "inactive", "1": "active"},
+		"type":  {"a": "Type A"},
+	}
+	if !reflect.DeepEqual(enumMaps, expected) {
+		t.Errorf("parseEnumMaps() = %v, want %v", enumMaps, expected)
+	}
+
+	for _, value := range []string{"state", "=0:inactive", "state=", "state=0", "state=:inactive"} {
+		if _, err := parseEnumMaps([]string{value}); err == nil {
+			t.Errorf("Expected error for %q", value)
+		}
+	}
+	if _, err := parseEnumMaps([]string{"state=0:a", "state=1:b"}); err == nil {
+		t.Error("Expected error for field mapped twice")
+	}
+}
+
+func TestApplyEnumMaps(t *testing.T) {
+	records := []map[string]any{
+		{"id": json.Number("1"), "state": json.Number("0"), "codes": []any{json.Number("1"), json.Number("7")}},
+		{"id": json.Number("2"), "state": json.Number("1"), "codes": []any{}},
+		{"id": json.Number("3"), "state": json.Number("5"), "codes": nil},
+		{"id": json.Number("4"), "state": nil},
+	}
+	enumMaps := EnumMaps{
+		"state": {"0": "inactive", "1": "active"},
+		"codes": {"1": "one"},
+	}
+
+	applyEnumMaps(records, enumMaps)
+
+	if records[0]["state"] != "inactive" || records[1]["state"] != "active" {
+		t.Errorf("Expected mapped labels, got %v and %v", records[0]["state"], records[1]["state"])
+	}
+	if records[2]["state"] != json.Number("5") {
+		t.Errorf("Expected unmapped value unchanged, got %v", records[2]["state"])
+	}
+	if records[3]["state"] != nil {
+		t.Errorf("Expected null unchanged, got %v", records[3]["state"])
+	}
+	if !reflect.DeepEqual(records[0]["codes"], []any{"one", json.Number("7")}) {
+		t.Errorf("Expected list elements to be mapped individually, got %v", records[0]["codes"])
+	}
+	if records[0]["id"] != json.Number("1") {
+		t.Errorf("Expected unmapped field unchanged, got %v", records[0]["id"])
+	}
+}
+
+func TestHashRecordFields(t *testing.T) {
+	newRecords := func() []map[string]any {
+		return []map[string]any{
+			{"id": json.Number("1"), "email": "same@example.com", "username": "alice"},
+			{"id": json.Number("2"), "email": "same@example.com", "username": "bob"},
+			{"id": json.Number("3"), "email": nil, "username": "carol"},
+		}
+	}
+
+	t.Run("identical inputs hash identically", func(t *testing.T) {
+		records := newRecords()
+		if err := hashRecordFields(records, []string{"email"}, "salt"); err != nil {
+			t.Fatalf("hashRecordFields() error = %v", err)
+		}
+		if records[0]["email"] != records[1]["email"] {
+			t.Errorf("Expected identical hashes, got %v and %v", records[0]["email"], records[1]["email"])
+		}
+		if records[0]["email"] == "same@example.com" {
+			t.Error("Expected email to be hashed")
+		}
+		if len(records[0]["email"].(string)) != 64 {
+			t.Errorf("Expected 64 character hex digest, got %v", records[0]["email"])
+		}
+		if records[2]["email"] != nil {
+			t.Errorf("Expected null value to stay null, got %v", records[2]["email"])
+		}
+		if records[0]["username"] != "alice" {
+			t.Errorf("Expected username to be untouched, got %v", records[0]["username"])
+		}
+	})
+
+	t.Run("different salts produce different hashes", func(t *testing.T) {
+		a, b := newRecords(), newRecords()
+		if err := hashRecordFields(a, []string{"username"}, "salt-a"); err != nil {
+			t.Fatalf("hashRecordFields() error = %v", err)
+		}
+		if err := hashRecordFields(b, []string{"username"}, "salt-b"); err != nil {
+			t.Fatalf("hashRecordFields() error = %v", err)
+		}
+		if a[0]["username"] == b[0]["username"] {
+			t.Error("Expected different hashes for different salts")
+		}
+	})
+}
+
+func TestEncodeRecords(t *testing.T) {
+	for idKey, data := range map[string][]byte{
+		"id":      []byte(`{"1": {"id": 1, "name": "a"}, "2": {"id": 2, "name": "b"}}`),
+		"user_id": []byte(`{"10": {"user_id": 10, "name": "a"}, "9": {"user_id": 9, "name": "b"}}`),
+	} {
+		t.Run(idKey, func(t *testing.T) { testEncodeRecords(t, data, idKey) })
+	}
+}
+
+func testEncodeRecords(t *testing.T, data []byte, idKey string) {
+	t.Helper()
+	records, err := decodeRecords(data, "user")
+	if err != nil {
+		t.Fatalf("decodeRecords() error = %v", err)
+	}
+
+	encoded, err := encodeRecords(records, "user", idKey)
+	if err != nil {
+		t.Fatalf("encodeRecords() error = %v", err)
+	}
+
+	var got, want any
+	if err := json.Unmarshal(encoded, &got); err != nil {
+		t.Fatalf("invalid JSON: %v", err)
+	}
+	_ = json.Unmarshal(data, &want)
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("encodeRecords() = %s, want %s", encoded, data)
+	}
+}
+
+func TestEncodeRecordArray(t *testing.T) {
+	data := []byte(`{"10": {"id": 10, "name": "b"}, "2": {"id": 2, "name": "a"}}`)
+	records, err := decodeRecords(data, "user")
+	if err != nil {
+		t.Fatalf("decodeRecords() error = %v", err)
+	}
+
+	encoded, err := encodeRecordArray(records)
+	if err != nil {
+		t.Fatalf("encodeRecordArray() error = %v", err)
+	}
+
+	var got []map[string]any
+	if err := json.Unmarshal(encoded, &got); err != nil {
+		t.Fatalf("invalid JSON array: %v", err)
+	}
+	if len(got) != 2 || got[0]["id"] != float64(2) || got[1]["id"] != float64(10) {
+		t.Errorf("Expected array sorted by id, got %s", encoded)
+	}
+}
+
+func TestEncodeRecordArrayEmpty(t *testing.T) {
+	encoded, err := encodeRecordArray([]map[string]any{})
+	if err != nil {
+		t.Fatalf("encodeRecordArray() error = %v", err)
+	}
+	if string(encoded) != "[]" {
+		t.Errorf("Expected empty array, got %s", encoded)
+	}
+}
+
+func TestDistinctRecords(t *testing.T) {
+	data := []byte(`{
+		"1": {"id": 1, "first_name": "Ada", "last_name": "L"},
+		"2": {"id": 2, "first_name": "Bob", "last_name": "M"},
+		"3": {"id": 3, "last_name": "L", "first_name": "Ada"},
+		"4": {"id": 4, "first_name": "Ada", "last_name": null}
+	}`)
+	records, err := decodeRecords(data, "user")
+	if err != nil {
+		t.Fatalf("decodeRecords() error = %v", err)
+	}
+
+	distinct, err := distinctRecords(records, []string{"first_name", "last_name"})
+	if err != nil {
+		t.Fatalf("distinctRecords() error = %v", err)
+	}
+
+	var ids []string
+	for _, record := range distinct {
+		ids = append(ids, fmt.Sprint(record["id"]))
+	}
+	if got := strings.Join(ids, ","); got != "1,2,4" {
+		t.Errorf("Expected ids 1,2,4, got %s", got)
+	}
+}
+
+func TestDistinctRecordsAliasedID(t *testing.T) {
+	records := []map[string]any{
+		{"user_id": 1, "name": "a"},
+		{"user_id": 2, "name": "a"},
+	}
+
+	distinct, err := distinctRecords(records, []string{"id:user_id", "name"})
+	if err != nil {
+		t.Fatalf("distinctRecords() error = %v", err)
+	}
+	if len(distinct) != 1 || distinct[0]["user_id"] != 1 {
+		t.Errorf("Expected only the first record, got %v", distinct)
+	}
+}
+
+func TestDistinctFilterNil(t *testing.T) {
+	var dedup *distinctFilter
+	for range 2 {
+		keep, err := dedup.keep(map[string]any{"id": 1})
+		if err != nil || !keep {
+			t.Errorf("Expected nil filter to keep all records, got %v, %v", keep, err)
+		}
+	}
+}
+
+func TestGroupRecords(t *testing.T) {
+	data := []byte(`{
+		"1": {"id": 1, "organization_management_level": "superadmin"},
+		"2": {"id": 2, "organization_management_level": null},
+		"3": {"id": 3},
+		"4": {"id": 4, "organization_management_level": "superadmin"},
+		"5": {"id": 5, "organization_management_level": "can_manage_users"}
+	}`)
+	records, err := decodeRecords(data, "user")
+	if err != nil {
+		t.Fatalf("decodeRecords() error = %v", err)
+	}
+
+	groups, err := groupRecords(records, "organization_management_level", "id", false)
+	if err != nil {
+		t.Fatalf("groupRecords() error = %v", err)
+	}
+	encoded, err := json.Marshal(groups)
+	if err != nil {
+		t.Fatalf("json.Marshal() error = %v", err)
+	}
+	want := `{"(null)":2,"can_manage_users":1,"superadmin":2}`
+	if string(encoded) != want {
+		t.Errorf("Expected %s, got %s", want, encoded)
+	}
+
+	groups, err = groupRecords(records, "organization_management_level", "id", true)
+	if err != nil {
+		t.Fatalf("groupRecords() error = %v", err)
+	}
+	encoded, err = json.Marshal(groups)
+	if err != nil {
+		t.Fatalf("json.Marshal() error = %v", err)
+	}
+	want = `{"(null)":[2,3],"can_manage_users":[5],"superadmin":[1,4]}`
+	if string(encoded) != want {
+		t.Errorf("Expected %s, got %s", want, encoded)
+	}
+}
+
+func TestGroupRecordsNullString(t *testing.T) {
+	records := []map[string]any{
+		{"id": 1, "name": "null"},
+		{"id": 2, "name": nil},
+		{"id": 3},
+	}
+
+	groups, err := groupRecords(records, "name", "id", false)
+	if err != nil {
+		t.Fatalf("groupRecords() error = %v", err)
+	}
+	if groups["null"] != 1 || groups[nullGroupKey] != 2 {
+		t.Errorf("Expected the string \"null\" apart from null values, got %v", groups)
+	}
+
+	records = append(records, map[string]any{"id": 4, "name": nullGroupKey})
+	if _, err := groupRecords(records, "name", "id", false); err == nil {
+		t.Error("Expected error for a value colliding with the null bucket")
+	}
+}
+
+func TestGroupRecordsListValue(t *testing.T) {
+	records := []map[string]any{
+		{"id": 1, "group_ids": []any{1, 2}},
+		{"id": 2, "group_ids": []any{1, 2}},
+		{"id": 3, "group_ids": []any{}},
+	}
+
+	groups, err := groupRecords(records, "group_ids", "id", false)
+	if err != nil {
+		t.Fatalf("groupRecords() error = %v", err)
+	}
+	if groups["[1,2]"] != 2 || groups["[]"] != 1 {
+		t.Errorf("Expected lists to be grouped by their JSON encoding, got %v", groups)
+	}
+}
@@ -0,0 +1,39 @@
+package get
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"k8s.io/kube-openapi/pkg/validation/spec"
+	"k8s.io/kube-openapi/pkg/validation/strfmt"
+	"k8s.io/kube-openapi/pkg/validation/validate"
+)
+
+// loadSchema reads a JSON Schema from file. References ($ref) are not resolved.
+func loadSchema(file string) (*spec.Schema, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("reading schema: %w", err)
+	}
+
+	var schema spec.Schema
+	if err := json.Unmarshal(data, &schema); err != nil {
+		return nil, fmt.Errorf("parsing schema %s: %w", file, err)
+	}
+	return &schema, nil
+}
+
+// validateOutput validates the JSON document data against schema and returns
+// an error listing all violations.
+func validateOutput(schema *spec.Schema, data []byte) error {
+	var document any
+	if err := json.Unmarshal(data, &document); err != nil {
+		return fmt.Errorf("decoding output: %w", err)
+	}
+
+	if err := validate.AgainstSchema(schema, document, strfmt.Default); err != nil {
+		return fmt.Errorf("output does not match schema: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,66 @@
+package get
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const userOutputSchema = `{
+  "type": "object",
+  "additionalProperties": {
+    "type": "object",
+    "required": ["id", "username"],
+    "properties": {
+      "id": {"type": "integer"},
+      "username": {"type": "string", "minLength": 1},
+      "is_active": {"type": "boolean"}
+    }
+  }
+}`
+
+func writeSchema(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "schema.json")
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("writing schema: %v", err)
+	}
+	return path
+}
+
+func TestValidateOutput(t *testing.T) {
+	schema, err := loadSchema(writeSchema(t, userOutputSchema))
+	if err != nil {
+		t.Fatalf("loadSchema() error = %v", err)
+	}
+
+	t.Run("passing", func(t *testing.T) {
+		output := `{"1": {"id": 1, "username": "admin", "is_active": true}, "2": {"id": 2, "username": "jdoe"}}`
+		if err := validateOutput(schema, []byte(output)); err != nil {
+			t.Errorf("validateOutput() error = %v", err)
+		}
+	})
+
+	t.Run("failing", func(t *testing.T) {
+		output := `{"1": {"id": 1, "username": "admin", "is_active": "yes"}, "2": {"id": 2}}`
+		err := validateOutput(schema, []byte(output))
+		if err == nil {
+			t.Fatal("Expected validation error")
+		}
+		for _, want := range []string{"is_active", "username"} {
+			if !strings.Contains(err.Error(), want) {
+				t.Errorf("Expected error to mention %q, got %v", want, err)
+			}
+		}
+	})
+}
+
+func TestLoadSchema_Invalid(t *testing.T) {
+	if _, err := loadSchema(filepath.Join(t.TempDir(), "missing.json")); err == nil {
+		t.Error("Expected error for missing schema file")
+	}
+	if _, err := loadSchema(writeSchema(t, `{"type": `)); err == nil {
+		t.Error("Expected error for invalid schema JSON")
+	}
+}
@@ -2,10 +2,15 @@ package initialdata
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"net/http"
 	"os"
 	"strings"
+	"time"
 
 	"github.com/OpenSlides/openslides-cli/internal/constants"
 	"github.com/OpenSlides/openslides-cli/internal/logger"
@@ -19,6 +24,7 @@ const (
 	InitialDataHelp      = "Creates initial data if the database is empty"
 	InitialDataHelpExtra = `This command sets up initial data for a new OpenSlides instance.
 Provide initial data via --file flag with a JSON file path, or use --file=- to read from stdin.
+The file can also be an http:// or https:// URL to fetch the data from.
 If no file is provided, empty initialization data will be used.
 
 This command also sets the superadmin (user 1) password from the superadmin password file.
@@ -35,6 +41,12 @@ Examples:
     --address <myBackendManageIP>:9002 \
 	--password-file ./my.instance.dir.org/secrets/initial_auth_password \
 	--superadmin-password-file ./my.instance.dir.org/secrets/superadmin
+
+  osmanage initial-data \
+    --file https://provisioning.example.org/initial.json \
+    --address <myBackendManageIP>:9002 \
+	--password-file ./my.instance.dir.org/secrets/initial_auth_password \
+	--superadmin-password-file ./my.instance.dir.org/secrets/superadmin
 `
 )
 
@@ -46,10 +58,13 @@ func Cmd() *cobra.Command {
 		Args:  cobra.NoArgs,
 	}
 
-	address := cmd.Flags().StringP("address", "a", "", "address of the OpenSlides backendManage service (default: "+constants.DefaultBackendManageAddress+")")
+	address := cmd.Flags().StringP("address", "a", "", constants.BackendManageAddressUsage)
 	passwordFile := cmd.Flags().String("password-file", "", "file with password for authorization (default: "+constants.DefaultPasswordFile+")")
+	waitForBackend := cmd.Flags().Duration("wait-for-backend", 0, constants.WaitForBackendUsage)
+	caCert := cmd.Flags().String("cacert", "", "CA bundle (PEM) to verify the backendManage certificate (implies https)")
+	insecureSkipTLSVerify := cmd.Flags().Bool("insecure-skip-tls-verify", false, "skip verification of the backendManage certificate, for development only (implies https)")
 	superadminPasswordFile := cmd.Flags().String("superadmin-password-file", "", "file with superadmin password (required)")
-	dataFile := cmd.Flags().StringP("file", "f", "", "JSON file with initial data, or - for stdin")
+	dataFile := cmd.Flags().StringP("file", "f", "", "JSON file or http(s) URL with initial data, or - for stdin")
 
 	_ = cmd.MarkFlagRequired("superadmin-password-file")
 
@@ -58,7 +73,9 @@ func Cmd() *cobra.Command {
 			return fmt.Errorf("--superadmin-password-file cannot be empty")
 		}
 
-		utils.KeepValueOrEnvOrDefault(address, constants.EnvOsmanageBackendAddress, constants.DefaultBackendManageAddress)
+		if err := utils.KeepValueOrBackendManageAddress(address); err != nil {
+			return err
+		}
 		utils.KeepValueOrEnvOrDefault(passwordFile, constants.EnvOsmanageBackendPasswordFile, constants.DefaultPasswordFile)
 
 		logger.Info("=== INITIAL DATA ===")
@@ -67,16 +84,19 @@ func Cmd() *cobra.Command {
 		var err error
 
 		if *dataFile != "" {
-			data, err = utils.ReadFromFileOrStdin(*dataFile)
+			data, err = readInitialData(*dataFile, utils.Timeout(cmd, constants.InitialDataFetchTimeout))
 			if err != nil {
 				return fmt.Errorf("reading initial data: %w", err)
 			}
 		}
 
-		if len(data) == 0 {
+		if len(bytes.TrimSpace(data)) == 0 {
 			logger.Debug("No data provided, using empty object")
 			data = []byte("{}")
 		}
+		if err := validateInitialData(data); err != nil {
+			return fmt.Errorf("invalid initial data: %w", err)
+		}
 
 		password, err := utils.ReadPassword(*passwordFile)
 		if err != nil {
@@ -93,15 +113,25 @@ func Cmd() *cobra.Command {
 			return fmt.Errorf("marshalling payload: %w", err)
 		}
 
-		cl := client.New(*address, password)
-		resp, err := cl.SendAction("organization.initial_import", payloadJSON)
+		tlsOpts, err := client.TLSOptions(*caCert, *insecureSkipTLSVerify)
+		if err != nil {
+			return err
+		}
+
+		cl := client.New(*address, password, append([]client.Option{client.WithTimeout(utils.Timeout(cmd, 0))}, tlsOpts...)...)
+		if err := cl.WaitForBackend(*waitForBackend); err != nil {
+			return err
+		}
+		ctx, cancel := utils.CommandContext(cmd)
+		defer cancel()
+		resp, err := cl.SendActionContext(ctx, "organization.initial_import", payloadJSON)
 		if err != nil {
 			return fmt.Errorf("sending request: %w", err)
 		}
 
 		body, err := client.CheckResponse(resp)
 		if err != nil {
-			if bytes.Contains(body, []byte("is not empty")) {
+			if isNotEmptyError(body) {
 				logger.Warn("Database is not empty")
 				fmt.Fprintln(os.Stderr, "Database contains data, initial data were NOT set")
 				os.Exit(2)
@@ -112,7 +142,7 @@ func Cmd() *cobra.Command {
 		logger.Info("Initial data set successfully")
 		fmt.Println("Initial data set successfully.")
 
-		if err := setSuperadminPassword(*address, password, *superadminPasswordFile); err != nil {
+		if err := setSuperadminPassword(ctx, cl, *superadminPasswordFile); err != nil {
 			return fmt.Errorf("setting superadmin password: %w", err)
 		}
 
@@ -124,7 +154,88 @@ func Cmd() *cobra.Command {
 	return cmd
 }
 
-func setSuperadminPassword(address, authPassword, superadminPasswordFile string) error {
+// readInitialData reads the initial data from an http(s) URL, a file or stdin ("-")
+func readInitialData(source string, timeout time.Duration) ([]byte, error) {
+	if strings.HasPrefix(source, "http://") || strings.HasPrefix(source, "https://") {
+		return fetchInitialData(source, timeout)
+	}
+	return utils.ReadFromFileOrStdin(source)
+}
+
+// fetchInitialData downloads the initial data from url. Responses other than
+// 2xx are an error.
+func fetchInitialData(url string, timeout time.Duration) ([]byte, error) {
+	logger.Debug("Fetching initial data from %s (timeout %v)", url, timeout)
+
+	httpClient := &http.Client{Timeout: timeout}
+	resp, err := httpClient.Get(url)
+	if err != nil {
+		return nil, fmt.Errorf("fetching %s: %w", url, err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return nil, fmt.Errorf("fetching %s: unexpected status %s", url, resp.Status)
+	}
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("reading response from %s: %w", url, err)
+	}
+
+	logger.Debug("Fetched %d bytes", len(data))
+	return data, nil
+}
+
+// validateInitialData checks that data is a JSON object, reporting syntax
+// errors with their line and column
+func validateInitialData(data []byte) error {
+	var object map[string]json.RawMessage
+	err := json.Unmarshal(data, &object)
+
+	var syntaxErr *json.SyntaxError
+	var typeErr *json.UnmarshalTypeError
+	switch {
+	case err == nil && object == nil:
+		return fmt.Errorf("expected a JSON object, got null")
+	case err == nil:
+		return nil
+	case errors.As(err, &syntaxErr):
+		line, column := position(data, syntaxErr.Offset)
+		return fmt.Errorf("line %d, column %d: %w", line, column, err)
+	case errors.As(err, &typeErr):
+		return fmt.Errorf("expected a JSON object, got %s", typeErr.Value)
+	default:
+		return err
+	}
+}
+
+// position returns the 1-based line and column of the byte at offset in data
+func position(data []byte, offset int64) (line, column int) {
+	offset = min(max(offset, 1), int64(len(data)))
+	before := data[:offset-1]
+	line = bytes.Count(before, []byte("\n")) + 1
+	column = int(offset) - bytes.LastIndexByte(before, '\n') - 1
+	return line, column
+}
+
+// notEmptyMessage is part of the message the backend returns when
+// organization.initial_import is called on a database that already has data
+const notEmptyMessage = "is not empty"
+
+// isNotEmptyError reports whether body is the backend's response for an
+// already initialized database. The backend has no error codes for actions,
+// so the message field of the JSON response is checked. The raw body is only
+// searched if it is not a JSON action response.
+func isNotEmptyError(body []byte) bool {
+	response, err := client.ParseActionResponse(body)
+	if err != nil || response.Message == "" {
+		return bytes.Contains(bytes.ToLower(body), []byte(notEmptyMessage))
+	}
+	return !response.Success && strings.Contains(strings.ToLower(response.Message), notEmptyMessage)
+}
+
+func setSuperadminPassword(ctx context.Context, cl *client.Client, superadminPasswordFile string) error {
 	logger.Debug("Setting superadmin password")
 
 	superadminPW, err := utils.ReadPassword(superadminPasswordFile)
@@ -143,8 +254,7 @@ func setSuperadminPassword(address, authPassword, superadminPasswordFile string)
 		return fmt.Errorf("marshalling password payload: %w", err)
 	}
 
-	cl := client.New(address, authPassword)
-	resp, err := cl.SendAction("user.set_password", payloadJSON)
+	resp, err := cl.SendActionContext(ctx, "user.set_password", payloadJSON)
 	if err != nil {
 		return fmt.Errorf("sending password request: %w", err)
 	}
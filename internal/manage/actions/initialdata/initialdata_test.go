@@ -0,0 +1,113 @@
+package initialdata
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestReadInitialData_URL(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/initial.json":
+			_, _ = w.Write([]byte(`{"organization": {}}`))
+		case "/slow.json":
+			time.Sleep(200 * time.Millisecond)
+			_, _ = w.Write([]byte(`{}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	data, err := readInitialData(server.URL+"/initial.json", time.Second)
+	if err != nil {
+		t.Fatalf("readInitialData() error = %v", err)
+	}
+	if string(data) != `{"organization": {}}` {
+		t.Errorf("readInitialData() = %q", data)
+	}
+
+	_, err = readInitialData(server.URL+"/missing.json", time.Second)
+	if err == nil || !strings.Contains(err.Error(), "unexpected status 404 Not Found") {
+		t.Errorf("Expected status error, got %v", err)
+	}
+
+	if _, err := readInitialData(server.URL+"/slow.json", 50*time.Millisecond); err == nil {
+		t.Error("Expected timeout error")
+	}
+}
+
+func TestReadInitialData_File(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "initial.json")
+	if err := os.WriteFile(path, []byte(`{}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := readInitialData(path, time.Second)
+	if err != nil {
+		t.Fatalf("readInitialData() error = %v", err)
+	}
+	if string(data) != `{}` {
+		t.Errorf("readInitialData() = %q", data)
+	}
+}
+
+func TestValidateInitialData(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string
+		wantErr string
+	}{
+		{name: "object", data: "{\n  \"organization\": {\"1\": {\"name\": \"Test\"}}\n}"},
+		{name: "empty object", data: "{}"},
+		{name: "missing comma", data: "{\n  \"a\": 1\n  \"b\": 2\n}", wantErr: "line 3, column 3: invalid character '\"' after object key:value pair"},
+		{name: "trailing comma", data: `{"a": 1,}`, wantErr: "line 1, column 9: invalid character '}'"},
+		{name: "truncated", data: "{\n  \"a\": [1, 2", wantErr: "unexpected end of JSON input"},
+		{name: "array", data: `[{"a": 1}]`, wantErr: "expected a JSON object, got array"},
+		{name: "null", data: "null", wantErr: "expected a JSON object, got null"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateInitialData([]byte(tt.data))
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("validateInitialData() error = %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("validateInitialData() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestIsNotEmptyError(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		want bool
+	}{
+		{"action response", `{"success": false, "message": "Datastore is not empty."}`, true},
+		{"rephrased message", `{"success": false, "message": "Database is NOT EMPTY"}`, true},
+		{"unrelated error", `{"success": false, "message": "Action organization.initial_import failed"}`, false},
+		{"message in other field", `{"success": false, "message": "Invalid data", "hint": "is not empty"}`, false},
+		{"plain text", "Datastore is not empty", true},
+		{"plain text other error", "Internal Server Error", false},
+		{"empty", "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isNotEmptyError([]byte(tt.body)); got != tt.want {
+				t.Errorf("isNotEmptyError(%q) = %v, want %v", tt.body, got, tt.want)
+			}
+		})
+	}
+}
@@ -1,9 +1,11 @@
 package migrations
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"fmt"
+	"os"
 	"strings"
 	"time"
 
@@ -46,18 +48,49 @@ Examples:
     --address <myBackendManageIP>:9002 \
     --password-file my.instance.dir/secrets/internal_auth_password
 
+  # Block until no migration is running
+  osmanage migrations wait --interval 5s \
+    --address <myBackendManageIP>:9002 \
+    --password-file my.instance.dir/secrets/internal_auth_password
+
+  # Raw stats as JSON for automation
+  osmanage migrations stats --json \
+    --address <myBackendManageIP>:9002 \
+    --password-file my.instance.dir/secrets/internal_auth_password
+
   # Custom progress interval
   osmanage migrations finalize \
     --address <myBackendManageIP>:9002 \
     --password-file my.instance.dir/secrets/internal_auth_password \
     --interval 2s
 
+  # Allow a long running finalize up to 6 hours
+  osmanage migrations finalize --timeout 6h \
+    --address <myBackendManageIP>:9002 \
+    --password-file my.instance.dir/secrets/internal_auth_password
+
+  # More and slower retries on a congested network
+  osmanage migrations stats --max-retries 10 --retry-delay 30s --total-timeout 10m \
+    --address <myBackendManageIP>:9002 \
+    --password-file my.instance.dir/secrets/internal_auth_password
+
+The global --timeout bounds the whole command including progress tracking.
+Without it, each request may take up to --total-timeout (3m) including retries
+and progress is tracked until the migration is done. Ctrl-C aborts the command,
+a migration already started keeps running in the backend.
+
+Exit codes of stats:
+  0  no migrations required (or a migration is running)
+  1  error
+  3  migrations or finalization required
+
 Available commands:
   migrate                       Run migrations on auxiliary tables
   finalize                      Apply migrations to live tables
   reset                         Reset unapplied migrations
   stats                         Show migration statistics
-  progress                      Check running migration progress`
+  progress                      Check running migration progress
+  wait                          Wait until no migration is running`
 )
 
 func Cmd() *cobra.Command {
@@ -74,6 +107,7 @@ func Cmd() *cobra.Command {
 		clearCollectionfieldTablesCmd(),
 		statsCmd(),
 		progressCmd(),
+		waitCmd(),
 	)
 
 	return cmd
@@ -104,6 +138,150 @@ func progressCmd() *cobra.Command {
 	return createMigrationCmd("progress", "Query the progress of a currently running migration command", false)
 }
 
+func waitCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "wait",
+		Short: "Wait until no migration is running, fails if the migration fails",
+		Args:  cobra.NoArgs,
+	}
+
+	conn := addConnectionFlags(cmd)
+	retry := addRetryFlags(cmd)
+	interval := cmd.Flags().Duration("interval", constants.DefaultMigrationProgressInterval, "interval for progress checks")
+
+	cmd.RunE = func(cmd *cobra.Command, args []string) error {
+		if *interval <= 0 {
+			return fmt.Errorf("--interval must be positive")
+		}
+
+		policy, err := retry.policy(cmd)
+		if err != nil {
+			return err
+		}
+
+		logger.Info("=== MIGRATIONS: WAIT ===")
+
+		ctx, cancel := utils.CommandContext(cmd)
+		defer cancel()
+
+		cl, err := conn.newClient(policy.totalTimeout)
+		if err != nil {
+			return err
+		}
+
+		if err := waitForIdle(ctx, cl, *interval, policy); err != nil {
+			return err
+		}
+
+		fmt.Println("No migration running.")
+		return nil
+	}
+
+	return cmd
+}
+
+// connectionFlags are the flags to connect to the backendManage service
+type connectionFlags struct {
+	address               *string
+	passwordFile          *string
+	caCert                *string
+	insecureSkipTLSVerify *bool
+	waitForBackend        *time.Duration
+}
+
+// addConnectionFlags adds the backendManage connection flags to cmd
+func addConnectionFlags(cmd *cobra.Command) *connectionFlags {
+	return &connectionFlags{
+		address:               cmd.Flags().StringP("address", "a", "", constants.BackendManageAddressUsage),
+		passwordFile:          cmd.Flags().String("password-file", "", "file with password for authorization (default: "+constants.DefaultPasswordFile+")"),
+		caCert:                cmd.Flags().String("cacert", "", "CA bundle (PEM) to verify the backendManage certificate (implies https)"),
+		insecureSkipTLSVerify: cmd.Flags().Bool("insecure-skip-tls-verify", false, "skip verification of the backendManage certificate, for development only (implies https)"),
+		waitForBackend:        cmd.Flags().Duration("wait-for-backend", 0, constants.WaitForBackendUsage),
+	}
+}
+
+// newClient creates a backendManage client from the flags, falling back to
+// the env vars and defaults for address and password file. With
+// --wait-for-backend it returns once the backend accepts connections.
+func (f *connectionFlags) newClient(requestTimeout time.Duration) (*client.Client, error) {
+	if err := utils.KeepValueOrBackendManageAddress(f.address); err != nil {
+		return nil, err
+	}
+	utils.KeepValueOrEnvOrDefault(f.passwordFile, constants.EnvOsmanageBackendPasswordFile, constants.DefaultPasswordFile)
+
+	authPassword, err := utils.ReadPassword(*f.passwordFile)
+	if err != nil {
+		return nil, fmt.Errorf("reading password: %w", err)
+	}
+
+	tlsOpts, err := client.TLSOptions(*f.caCert, *f.insecureSkipTLSVerify)
+	if err != nil {
+		return nil, err
+	}
+
+	cl := client.New(*f.address, authPassword, append([]client.Option{client.WithTimeout(requestTimeout)}, tlsOpts...)...)
+	if err := cl.WaitForBackend(*f.waitForBackend); err != nil {
+		return nil, err
+	}
+	return cl, nil
+}
+
+// retryPolicy configures the retries of a migration request
+type retryPolicy struct {
+	maxRetries   int           // maximum number of attempts
+	delay        time.Duration // delay between attempts
+	totalTimeout time.Duration // maximum time for all attempts
+}
+
+// defaultRetryPolicy returns the retry policy of the migration constants
+func defaultRetryPolicy() retryPolicy {
+	return retryPolicy{
+		maxRetries:   constants.MigrationMaxRetries,
+		delay:        constants.MigrationRetryDelay,
+		totalTimeout: constants.MigrationTotalTimeout,
+	}
+}
+
+// retryFlags are the flags overriding the default retry policy
+type retryFlags struct {
+	maxRetries   *int
+	delay        *time.Duration
+	totalTimeout *time.Duration
+}
+
+// addRetryFlags adds the retry flags to cmd
+func addRetryFlags(cmd *cobra.Command) *retryFlags {
+	return &retryFlags{
+		maxRetries:   cmd.Flags().Int("max-retries", constants.MigrationMaxRetries, "maximum number of attempts for each request"),
+		delay:        cmd.Flags().Duration("retry-delay", constants.MigrationRetryDelay, "delay between attempts"),
+		totalTimeout: cmd.Flags().Duration("total-timeout", constants.MigrationTotalTimeout, "maximum time for all attempts of each request"),
+	}
+}
+
+// policy returns the retry policy given by the flags. Without --total-timeout,
+// an explicit global --timeout also bounds the attempts of each request.
+func (f *retryFlags) policy(cmd *cobra.Command) (retryPolicy, error) {
+	if *f.maxRetries < 1 {
+		return retryPolicy{}, fmt.Errorf("--max-retries must be at least 1")
+	}
+	if *f.delay < 0 {
+		return retryPolicy{}, fmt.Errorf("--retry-delay cannot be negative")
+	}
+	if *f.totalTimeout <= 0 {
+		return retryPolicy{}, fmt.Errorf("--total-timeout must be positive")
+	}
+
+	policy := retryPolicy{
+		maxRetries:   *f.maxRetries,
+		delay:        *f.delay,
+		totalTimeout: *f.totalTimeout,
+	}
+	if !utils.FlagGiven(cmd, "total-timeout") {
+		policy.totalTimeout = utils.Timeout(cmd, policy.totalTimeout)
+	}
+	return policy, nil
+}
+
 // createMigrationCmd creates a migration subcommand with standard flags
 func createMigrationCmd(name, description string, withProgressTracking bool) *cobra.Command {
 	cmd := &cobra.Command{
@@ -112,8 +290,18 @@ func createMigrationCmd(name, description string, withProgressTracking bool) *co
 		Args:  cobra.NoArgs,
 	}
 
-	address := cmd.Flags().StringP("address", "a", "", "address of the OpenSlides backendManage service (default: "+constants.DefaultBackendManageAddress+")")
-	passwordFile := cmd.Flags().String("password-file", "", "file with password for authorization (default: "+constants.DefaultPasswordFile+")")
+	conn := addConnectionFlags(cmd)
+	retry := addRetryFlags(cmd)
+
+	var force *bool
+	if name == "finalize" {
+		force = cmd.Flags().BoolP("force", "f", false, "Skip confirmation prompt")
+	}
+
+	var jsonOutput *bool
+	if name == "stats" || name == "progress" {
+		jsonOutput = cmd.Flags().Bool("json", false, "print the raw response as indented JSON instead of formatted text")
+	}
 
 	var progressInterval *time.Duration
 	if withProgressTracking {
@@ -122,29 +310,48 @@ func createMigrationCmd(name, description string, withProgressTracking bool) *co
 	}
 
 	cmd.RunE = func(cmd *cobra.Command, args []string) error {
-		utils.KeepValueOrEnvOrDefault(address, constants.EnvOsmanageBackendAddress, constants.DefaultBackendManageAddress)
-		utils.KeepValueOrEnvOrDefault(passwordFile, constants.EnvOsmanageBackendPasswordFile, constants.DefaultPasswordFile)
+		policy, err := retry.policy(cmd)
+		if err != nil {
+			return err
+		}
 
 		logger.Info("=== MIGRATIONS: %s ===", strings.ToUpper(name))
 
-		authPassword, err := utils.ReadPassword(*passwordFile)
-		if err != nil {
-			return fmt.Errorf("reading password: %w", err)
+		if force != nil && !*force && utils.IsInteractive(os.Stdin) {
+			confirmed, err := utils.Confirm(os.Stdout, os.Stdin, "Apply migrations to the live tables?", "")
+			if err != nil {
+				return fmt.Errorf("confirming %s: %w", name, err)
+			}
+			if !confirmed {
+				logger.Info("Migrations %s cancelled", name)
+				return nil
+			}
 		}
 
-		cl := client.New(*address, authPassword)
+		ctx, cancel := utils.CommandContext(cmd)
+		defer cancel()
 
-		response, err := ExecuteMigrationCommand(cl, name)
+		cl, err := conn.newClient(policy.totalTimeout)
+		if err != nil {
+			return err
+		}
+
+		response, err := executeMigrationCommand(ctx, cl, name, policy)
 		if err != nil {
 			return fmt.Errorf("executing migration command: %w", err)
 		}
 
-		output, err := GetOutput(response, name)
+		output, err := GetOutput(response, name, jsonOutput != nil && *jsonOutput)
 		if err != nil {
 			return fmt.Errorf("formatting output: %w", err)
 		}
 		fmt.Print(output)
 
+		if name == "stats" && Required(response) {
+			logger.Warn("Migrations are required")
+			return &utils.ExitError{Code: constants.ExitCodeMigrationsRequired}
+		}
+
 		if withProgressTracking && progressInterval != nil && *progressInterval > 0 && (Running(response) || Finalizing(response)) {
 			var stopCondition func(*pb.MigrationsResponse) bool
 			if name == "finalize" {
@@ -158,7 +365,7 @@ func createMigrationCmd(name, description string, withProgressTracking bool) *co
 				return nil
 			}
 
-			return TrackMigrationProgress(cl, *progressInterval, stopCondition, printCallback)
+			return trackMigrationProgress(ctx, cl, *progressInterval, policy, stopCondition, printCallback)
 		}
 
 		return nil
@@ -173,76 +380,53 @@ type migrationsHTTPResponse struct {
 	Status    string          `json:"status"`
 	Output    string          `json:"output"`
 	Exception string          `json:"exception"`
-	Stats     json.RawMessage `json:"stats"`
+	Stats     json.RawMessage `json:"stats,omitempty"`
 }
 
 // ExecuteMigrationCommand sends a migration command to the backend with retry logic.
 func ExecuteMigrationCommand(cl *client.Client, command string) (*pb.MigrationsResponse, error) {
+	return executeMigrationCommand(context.Background(), cl, command, defaultRetryPolicy())
+}
+
+// executeMigrationCommand works like ExecuteMigrationCommand with the retries
+// given by policy. It aborts when ctx is done.
+func executeMigrationCommand(ctx context.Context, cl *client.Client, command string, policy retryPolicy) (*pb.MigrationsResponse, error) {
 	logger.Debug("Executing migration command: %s", command)
 
-	ctx, cancel := context.WithTimeout(context.Background(), constants.MigrationTotalTimeout)
+	ctx, cancel := context.WithTimeout(ctx, policy.totalTimeout)
 	defer cancel()
 
-	var lastErr error
-
-	for attempt := range constants.MigrationMaxRetries {
-		if ctx.Err() != nil {
-			return nil, fmt.Errorf("migration command timed out after %v: %w", constants.MigrationTotalTimeout, ctx.Err())
-		}
-
-		if attempt > 0 {
-			logger.Warn("Retry attempt %d/%d after %v (previous error: %v)",
-				attempt, constants.MigrationMaxRetries, constants.MigrationRetryDelay, lastErr)
-
-			select {
-			case <-time.After(constants.MigrationRetryDelay):
-				// Continue to next attempt
-			case <-ctx.Done():
-				return nil, fmt.Errorf("migration command cancelled during retry: %w", ctx.Err())
-			}
-		}
-
-		resp, err := cl.SendMigrations(command)
+	var body []byte
+	err := utils.RetryHTTP(ctx, policy.maxRetries, policy.delay, func() error {
+		resp, err := cl.SendMigrationsContext(ctx, command)
 		if err != nil {
-			lastErr = fmt.Errorf("sending request: %w", err)
-			if isRetryableError(err) && attempt < constants.MigrationMaxRetries-1 {
-				logger.Debug("Retryable error: %v", err)
-				continue
-			}
-			return nil, lastErr
-		}
-
-		body, err := client.CheckResponse(resp)
-		if err != nil {
-			lastErr = err
-			if isRetryableError(err) && attempt < constants.MigrationMaxRetries-1 {
-				logger.Debug("Retryable error: %v", err)
-				continue
-			}
-			return nil, lastErr
-		}
-
-		var httpResp migrationsHTTPResponse
-		if err := json.Unmarshal(body, &httpResp); err != nil {
-			logger.Error("Failed to unmarshal migration response: %v", err)
-			return nil, fmt.Errorf("unmarshalling response: %w", err)
-		}
-
-		migrationResp := &pb.MigrationsResponse{
-			Success:   httpResp.Success,
-			Status:    httpResp.Status,
-			Output:    httpResp.Output,
-			Exception: httpResp.Exception,
-			Stats:     string(httpResp.Stats),
+			return fmt.Errorf("sending request: %w", err)
 		}
+		body, err = client.CheckResponse(resp)
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("migration command %s: %w", command, err)
+	}
 
-		logger.Debug("Migration response - Success: %v, Status: %s, Running: %v, Finalizing: %v",
-			migrationResp.Success, migrationResp.Status, Running(migrationResp), Finalizing(migrationResp))
+	var httpResp migrationsHTTPResponse
+	if err := json.Unmarshal(body, &httpResp); err != nil {
+		logger.Error("Failed to unmarshal migration response: %v", err)
+		return nil, fmt.Errorf("unmarshalling response: %w", err)
+	}
 
-		return migrationResp, nil
+	migrationResp := &pb.MigrationsResponse{
+		Success:   httpResp.Success,
+		Status:    httpResp.Status,
+		Output:    httpResp.Output,
+		Exception: httpResp.Exception,
+		Stats:     string(httpResp.Stats),
 	}
 
-	return nil, fmt.Errorf("migration command failed after %d retries: %w", constants.MigrationMaxRetries, lastErr)
+	logger.Debug("Migration response - Success: %v, Status: %s, Running: %v, Finalizing: %v",
+		migrationResp.Success, migrationResp.Status, Running(migrationResp), Finalizing(migrationResp))
+
+	return migrationResp, nil
 }
 
 // TrackMigrationProgress polls migration progress and sends updates to the callback.
@@ -251,13 +435,34 @@ func TrackMigrationProgress(
 	interval time.Duration,
 	stopCondition func(*pb.MigrationsResponse) bool,
 	callback func(*pb.MigrationsProgressResponse) error,
+) error {
+	return trackMigrationProgress(context.Background(), cl, interval, defaultRetryPolicy(), stopCondition, callback)
+}
+
+// trackMigrationProgress works like TrackMigrationProgress with the retries of
+// each progress request given by policy. It stops when ctx is done.
+func trackMigrationProgress(
+	ctx context.Context,
+	cl *client.Client,
+	interval time.Duration,
+	policy retryPolicy,
+	stopCondition func(*pb.MigrationsResponse) bool,
+	callback func(*pb.MigrationsProgressResponse) error,
 ) error {
 	logger.Debug("Starting progress tracking with interval: %v", interval)
 
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
 	for {
-		time.Sleep(interval)
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			logger.Warn("Progress tracking stopped, the migration may still be running in the backend")
+			return fmt.Errorf("tracking progress: %w", ctx.Err())
+		}
 
-		response, err := ExecuteMigrationCommand(cl, "progress")
+		response, err := executeMigrationCommand(ctx, cl, "progress", policy)
 		if err != nil {
 			return fmt.Errorf("checking progress: %w", err)
 		}
@@ -287,8 +492,36 @@ func TrackMigrationProgress(
 	return nil
 }
 
-// GetOutput returns the formatted output for the migration response
-func GetOutput(mr *pb.MigrationsResponse, command string) (string, error) {
+// waitForIdle blocks until no migration is running or finalizing. It returns
+// an error if the migration fails.
+func waitForIdle(ctx context.Context, cl *client.Client, interval time.Duration, policy retryPolicy) error {
+	response, err := executeMigrationCommand(ctx, cl, "progress", policy)
+	if err != nil {
+		return fmt.Errorf("checking progress: %w", err)
+	}
+	if Faulty(response) {
+		return fmt.Errorf("migration failed: %s", response.Exception)
+	}
+	if !Running(response) && !Finalizing(response) {
+		return nil
+	}
+
+	logger.Info("Waiting for running migration")
+	idle := func(r *pb.MigrationsResponse) bool { return !Running(r) && !Finalizing(r) }
+	logOutput := func(update *pb.MigrationsProgressResponse) error {
+		logger.Debug("Migration progress: %s", strings.TrimSpace(update.Output))
+		return nil
+	}
+	return trackMigrationProgress(ctx, cl, interval, policy, idle, logOutput)
+}
+
+// GetOutput returns the formatted output for the migration response. With
+// asJSON, the stats object (or the response itself if it has no stats) is
+// returned as indented JSON instead.
+func GetOutput(mr *pb.MigrationsResponse, command string, asJSON bool) (string, error) {
+	if asJSON {
+		return formatJSON(mr)
+	}
 	if Faulty(mr) {
 		return formatAll(mr)
 	}
@@ -319,6 +552,30 @@ func FormatStats(stats string) (string, error) {
 	return sb.String(), nil
 }
 
+// formatJSON returns the unmodified stats as indented JSON. Responses without
+// stats, like progress, are returned as a JSON object of their fields.
+func formatJSON(mr *pb.MigrationsResponse) (string, error) {
+	if mr.Stats != "" && mr.Stats != "null" {
+		var buf bytes.Buffer
+		if err := json.Indent(&buf, []byte(mr.Stats), "", "  "); err != nil {
+			return "", fmt.Errorf("indenting stats: %w", err)
+		}
+		buf.WriteByte('\n')
+		return buf.String(), nil
+	}
+
+	data, err := json.MarshalIndent(migrationsHTTPResponse{
+		Success:   mr.Success,
+		Status:    mr.Status,
+		Output:    mr.Output,
+		Exception: mr.Exception,
+	}, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("marshalling response: %w", err)
+	}
+	return string(data) + "\n", nil
+}
+
 // formatAll formats all response fields
 func formatAll(mr *pb.MigrationsResponse) (string, error) {
 	return fmt.Sprintf("Success: %v\nStatus: %s\nOutput: %s\nException: %s\n",
@@ -332,6 +589,22 @@ func Faulty(mr *pb.MigrationsResponse) bool {
 		mr.Status == constants.FinalizationStatusFailed
 }
 
+// Required returns true if the stats status says migrations or their
+// finalization are pending. The status of the stats object takes precedence
+// over the response status.
+func Required(mr *pb.MigrationsResponse) bool {
+	status := mr.Status
+	if mr.Stats != "" {
+		var stats struct {
+			Status string `json:"status"`
+		}
+		if err := json.Unmarshal([]byte(mr.Stats), &stats); err == nil && stats.Status != "" {
+			status = stats.Status
+		}
+	}
+	return status == constants.MigrationStatusRequired || status == constants.FinalizationStatusRequired
+}
+
 // Running returns true if the migration is currently in progress
 func Running(mr *pb.MigrationsResponse) bool {
 	return mr.Status == constants.MigrationStatusRunning
@@ -341,41 +614,3 @@ func Running(mr *pb.MigrationsResponse) bool {
 func Finalizing(mr *pb.MigrationsResponse) bool {
 	return mr.Status == constants.FinalizationStatusRunning
 }
-
-// isRetryableError determines if an error should trigger a retry
-func isRetryableError(err error) bool {
-	if err == nil {
-		return false
-	}
-
-	errStr := strings.ToLower(err.Error())
-
-	// Network-related errors
-	retryablePatterns := []string{
-		"connection refused",
-		"connection reset",
-		"timeout",
-		"temporary failure",
-		"no such host",
-		"network is unreachable",
-		"eof",
-		"broken pipe",
-		"i/o timeout",
-	}
-
-	for _, pattern := range retryablePatterns {
-		if strings.Contains(errStr, pattern) {
-			return true
-		}
-	}
-
-	// HTTP server errors (5xx)
-	serverErrors := []string{"server error", "503", "502", "504"}
-	for _, code := range serverErrors {
-		if strings.Contains(errStr, code) {
-			return true
-		}
-	}
-
-	return false
-}
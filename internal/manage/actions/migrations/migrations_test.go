@@ -1,11 +1,24 @@
 package migrations
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
 	"strings"
+	"sync/atomic"
 	"testing"
+	"time"
+
+	"github.com/spf13/cobra"
 
 	"github.com/OpenSlides/openslides-cli/internal/constants"
+	"github.com/OpenSlides/openslides-cli/internal/manage/client"
+	"github.com/OpenSlides/openslides-cli/internal/utils"
 	pb "github.com/OpenSlides/openslides-cli/proto/osmanage"
 )
 
@@ -67,7 +80,7 @@ func TestGetOutput(t *testing.T) {
 			Success: true,
 			Output:  "Migration completed",
 		}
-		output, err := GetOutput(resp, "migrate")
+		output, err := GetOutput(resp, "migrate", false)
 		if err != nil {
 			t.Errorf("GetOutput() error = %v", err)
 		}
@@ -91,7 +104,7 @@ func TestGetOutput(t *testing.T) {
 			Success: true,
 			Stats:   string(statsJSON),
 		}
-		output, err := GetOutput(resp, "stats")
+		output, err := GetOutput(resp, "stats", false)
 		if err != nil {
 			t.Errorf("GetOutput() error = %v", err)
 		}
@@ -116,7 +129,7 @@ func TestGetOutput(t *testing.T) {
 			Success:   false,
 			Exception: "Migration failed",
 		}
-		output, err := GetOutput(resp, "migrate")
+		output, err := GetOutput(resp, "migrate", false)
 		if err != nil {
 			t.Errorf("GetOutput() error = %v", err)
 		}
@@ -126,6 +139,54 @@ func TestGetOutput(t *testing.T) {
 	})
 }
 
+func TestGetOutput_JSON(t *testing.T) {
+	t.Run("stats unmodified", func(t *testing.T) {
+		resp := &pb.MigrationsResponse{
+			Success: true,
+			Stats:   `{"current_migration_index":68,"custom_field":"kept","status":"finalization_required"}`,
+		}
+		output, err := GetOutput(resp, "stats", true)
+		if err != nil {
+			t.Fatalf("GetOutput() error = %v", err)
+		}
+
+		want := "{\n  \"current_migration_index\": 68,\n  \"custom_field\": \"kept\",\n  \"status\": \"finalization_required\"\n}\n"
+		if output != want {
+			t.Errorf("Expected indented stats\n%s\ngot\n%s", want, output)
+		}
+	})
+
+	t.Run("progress without stats", func(t *testing.T) {
+		resp := &pb.MigrationsResponse{
+			Success: true,
+			Status:  constants.MigrationStatusRunning,
+			Output:  "50% done",
+		}
+		output, err := GetOutput(resp, "progress", true)
+		if err != nil {
+			t.Fatalf("GetOutput() error = %v", err)
+		}
+
+		var decoded map[string]any
+		if err := json.Unmarshal([]byte(output), &decoded); err != nil {
+			t.Fatalf("Expected JSON output, got %q: %v", output, err)
+		}
+		if decoded["status"] != constants.MigrationStatusRunning || decoded["output"] != "50% done" {
+			t.Errorf("Unexpected progress JSON: %v", decoded)
+		}
+		if _, ok := decoded["stats"]; ok {
+			t.Error("Expected no stats key in progress JSON")
+		}
+	})
+
+	t.Run("invalid stats", func(t *testing.T) {
+		resp := &pb.MigrationsResponse{Success: true, Stats: "{invalid"}
+		if _, err := GetOutput(resp, "stats", true); err == nil {
+			t.Error("Expected error for invalid stats JSON")
+		}
+	})
+}
+
 func TestFormatStats(t *testing.T) {
 	t.Run("ordered output", func(t *testing.T) {
 		stats := map[string]any{
@@ -200,44 +261,270 @@ func TestFormatStats(t *testing.T) {
 	})
 }
 
-func TestIsRetryableError(t *testing.T) {
+// testRetryPolicy retries quickly to keep tests fast
+var testRetryPolicy = retryPolicy{maxRetries: 3, delay: time.Millisecond, totalTimeout: time.Second}
+
+// progressServer returns a client for a backend answering every migrations
+// request with the status returned by status for the n-th request.
+func progressServer(t *testing.T, status func(n int32) string) (*client.Client, *atomic.Int32) {
+	t.Helper()
+
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := requests.Add(1)
+		if err := json.NewEncoder(w).Encode(map[string]any{"success": true, "status": status(n), "output": "progress\n"}); err != nil {
+			t.Errorf("failed to write response: %v", err)
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	return client.New(strings.TrimPrefix(server.URL, constants.BackendHTTPScheme), "test-password"), &requests
+}
+
+func stopWhenNotRunning(r *pb.MigrationsResponse) bool {
+	return !Running(r)
+}
+
+func TestTrackMigrationProgress_StopsWhenDone(t *testing.T) {
+	cl, requests := progressServer(t, func(n int32) string {
+		if n < 3 {
+			return constants.MigrationStatusRunning
+		}
+		return "migration_finished"
+	})
+
+	var updates int
+	err := trackMigrationProgress(context.Background(), cl, time.Millisecond, testRetryPolicy, stopWhenNotRunning,
+		func(*pb.MigrationsProgressResponse) error {
+			updates++
+			return nil
+		})
+	if err != nil {
+		t.Fatalf("trackMigrationProgress() error = %v", err)
+	}
+	if updates != 3 || requests.Load() != 3 {
+		t.Errorf("Expected 3 updates and requests, got %d updates and %d requests", updates, requests.Load())
+	}
+}
+
+func TestTrackMigrationProgress_Cancelled(t *testing.T) {
+	cl, _ := progressServer(t, func(int32) string { return constants.MigrationStatusRunning })
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(50*time.Millisecond, cancel)
+
+	err := trackMigrationProgress(ctx, cl, 5*time.Millisecond, testRetryPolicy, stopWhenNotRunning,
+		func(*pb.MigrationsProgressResponse) error { return nil })
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected context.Canceled, got %v", err)
+	}
+}
+
+func TestTrackMigrationProgress_Deadline(t *testing.T) {
+	cl, _ := progressServer(t, func(int32) string { return constants.MigrationStatusRunning })
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	err := trackMigrationProgress(ctx, cl, 5*time.Millisecond, testRetryPolicy, stopWhenNotRunning,
+		func(*pb.MigrationsProgressResponse) error { return nil })
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected context.DeadlineExceeded, got %v", err)
+	}
+}
+
+func TestExecuteMigrationCommand_Cancelled(t *testing.T) {
+	cl, requests := progressServer(t, func(int32) string { return "migration_finished" })
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if _, err := executeMigrationCommand(ctx, cl, "stats", testRetryPolicy); !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected context.Canceled, got %v", err)
+	}
+	if requests.Load() != 0 {
+		t.Errorf("Expected no request to be sent, got %d", requests.Load())
+	}
+}
+
+func TestRequired(t *testing.T) {
 	tests := []struct {
-		name      string
-		errMsg    string
-		retryable bool
+		name     string
+		resp     *pb.MigrationsResponse
+		required bool
 	}{
-		{"nil error", "", false},
-		{"connection refused", "connection refused", true},
-		{"connection reset", "connection reset by peer", true},
-		{"timeout", "i/o timeout", true},
-		{"eof", "unexpected EOF", true},
-		{"server error 503", "server returned 503", true},
-		{"server error 502", "bad gateway 502", true},
-		{"server error 504", "gateway timeout 504", true},
-		{"client error 404", "404 not found", false},
-		{"auth error", "unauthorized", false},
-		{"parse error", "invalid JSON", false},
+		{"finalization required in stats", &pb.MigrationsResponse{Success: true, Stats: `{"status":"finalization_required"}`}, true},
+		{"migration required in stats", &pb.MigrationsResponse{Success: true, Stats: `{"status":"migration_required"}`}, true},
+		{"no migration required", &pb.MigrationsResponse{Success: true, Stats: `{"status":"no_migration_required"}`}, false},
+		{"running", &pb.MigrationsResponse{Success: true, Stats: `{"status":"migration_running"}`}, false},
+		{"stats status takes precedence", &pb.MigrationsResponse{Status: constants.MigrationStatusRequired, Stats: `{"status":"no_migration_required"}`}, false},
+		{"response status without stats", &pb.MigrationsResponse{Status: constants.FinalizationStatusRequired}, true},
+		{"invalid stats", &pb.MigrationsResponse{Stats: `{invalid`}, false},
 	}
-
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			var err error
-			if tt.errMsg != "" {
-				err = &testError{msg: tt.errMsg}
+			if got := Required(tt.resp); got != tt.required {
+				t.Errorf("Required() = %v, want %v", got, tt.required)
 			}
+		})
+	}
+}
+
+func TestStatsCmd_MigrationsRequired(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := json.NewEncoder(w).Encode(map[string]any{"success": true, "stats": map[string]any{"status": "migration_required"}}); err != nil {
+			t.Errorf("failed to write response: %v", err)
+		}
+	}))
+	defer server.Close()
 
-			if got := isRetryableError(err); got != tt.retryable {
-				t.Errorf("isRetryableError() = %v, want %v for error: %s", got, tt.retryable, tt.errMsg)
+	passwordFile := filepath.Join(t.TempDir(), "password")
+	if err := os.WriteFile(passwordFile, []byte("test-password"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	cmd := statsCmd()
+	cmd.SetOut(io.Discard)
+	cmd.SetErr(io.Discard)
+	cmd.SetArgs([]string{"--address", strings.TrimPrefix(server.URL, constants.BackendHTTPScheme), "--password-file", passwordFile})
+	err := cmd.Execute()
+
+	var exitErr *utils.ExitError
+	if !errors.As(err, &exitErr) || exitErr.Code != constants.ExitCodeMigrationsRequired {
+		t.Errorf("Expected exit code %d, got %v", constants.ExitCodeMigrationsRequired, err)
+	}
+}
+
+func TestWaitForIdle(t *testing.T) {
+	t.Run("already idle", func(t *testing.T) {
+		cl, requests := progressServer(t, func(int32) string { return "no_migration_required" })
+
+		if err := waitForIdle(context.Background(), cl, time.Millisecond, testRetryPolicy); err != nil {
+			t.Fatalf("waitForIdle() error = %v", err)
+		}
+		if requests.Load() != 1 {
+			t.Errorf("Expected 1 request, got %d", requests.Load())
+		}
+	})
+
+	t.Run("waits while running and finalizing", func(t *testing.T) {
+		cl, requests := progressServer(t, func(n int32) string {
+			switch {
+			case n < 3:
+				return constants.MigrationStatusRunning
+			case n < 5:
+				return constants.FinalizationStatusRunning
+			default:
+				return "no_migration_required"
 			}
 		})
-	}
+
+		if err := waitForIdle(context.Background(), cl, time.Millisecond, testRetryPolicy); err != nil {
+			t.Fatalf("waitForIdle() error = %v", err)
+		}
+		if requests.Load() != 5 {
+			t.Errorf("Expected 5 requests, got %d", requests.Load())
+		}
+	})
+
+	t.Run("fails when migration fails", func(t *testing.T) {
+		cl, _ := progressServer(t, func(n int32) string {
+			if n < 3 {
+				return constants.MigrationStatusRunning
+			}
+			return constants.MigrationStatusFailed
+		})
+
+		if err := waitForIdle(context.Background(), cl, time.Millisecond, testRetryPolicy); err == nil {
+			t.Error("Expected error when migration fails")
+		}
+	})
+
+	t.Run("fails when already failed", func(t *testing.T) {
+		cl, requests := progressServer(t, func(int32) string { return constants.FinalizationStatusFailed })
+
+		if err := waitForIdle(context.Background(), cl, time.Millisecond, testRetryPolicy); err == nil {
+			t.Error("Expected error for failed migration")
+		}
+		if requests.Load() != 1 {
+			t.Errorf("Expected 1 request, got %d", requests.Load())
+		}
+	})
 }
 
-// Helper type for testing
-type testError struct {
-	msg string
+func TestExecuteMigrationCommand_RetryPolicy(t *testing.T) {
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if requests.Add(1) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		if _, err := w.Write([]byte(`{"success":true,"status":"no_migration_required"}`)); err != nil {
+			t.Errorf("failed to write response: %v", err)
+		}
+	}))
+	defer server.Close()
+	cl := client.New(strings.TrimPrefix(server.URL, constants.BackendHTTPScheme), "test-password")
+
+	t.Run("succeeds within max retries", func(t *testing.T) {
+		requests.Store(0)
+		if _, err := executeMigrationCommand(context.Background(), cl, "stats", testRetryPolicy); err != nil {
+			t.Fatalf("executeMigrationCommand() error = %v", err)
+		}
+		if requests.Load() != 3 {
+			t.Errorf("Expected 3 attempts, got %d", requests.Load())
+		}
+	})
+
+	t.Run("fails after max retries", func(t *testing.T) {
+		requests.Store(0)
+		policy := testRetryPolicy
+		policy.maxRetries = 2
+		if _, err := executeMigrationCommand(context.Background(), cl, "stats", policy); err == nil {
+			t.Error("Expected error after 2 attempts")
+		}
+		if requests.Load() != 2 {
+			t.Errorf("Expected 2 attempts, got %d", requests.Load())
+		}
+	})
 }
 
-func (e *testError) Error() string {
-	return e.msg
+func TestRetryFlags(t *testing.T) {
+	newCmd := func(args ...string) (*retryPolicy, error) {
+		cmd := &cobra.Command{Use: "test", RunE: func(*cobra.Command, []string) error { return nil }}
+		cmd.Flags().Duration("timeout", 0, "")
+		retry := addRetryFlags(cmd)
+		if err := cmd.ParseFlags(args); err != nil {
+			t.Fatalf("parsing flags: %v", err)
+		}
+		policy, err := retry.policy(cmd)
+		return &policy, err
+	}
+
+	policy, err := newCmd()
+	if err != nil || *policy != defaultRetryPolicy() {
+		t.Errorf("Expected default policy, got %+v (err %v)", policy, err)
+	}
+
+	policy, err = newCmd("--max-retries", "10", "--retry-delay", "30s", "--total-timeout", "1h")
+	expected := retryPolicy{maxRetries: 10, delay: 30 * time.Second, totalTimeout: time.Hour}
+	if err != nil || *policy != expected {
+		t.Errorf("Expected %+v, got %+v (err %v)", expected, policy, err)
+	}
+
+	policy, err = newCmd("--timeout", "6h")
+	if err != nil || policy.totalTimeout != 6*time.Hour {
+		t.Errorf("Expected --timeout to bound each request, got %+v (err %v)", policy, err)
+	}
+
+	policy, err = newCmd("--timeout", "6h", "--total-timeout", "10m")
+	if err != nil || policy.totalTimeout != 10*time.Minute {
+		t.Errorf("Expected --total-timeout to take precedence, got %+v (err %v)", policy, err)
+	}
+
+	for _, args := range [][]string{{"--max-retries", "0"}, {"--retry-delay", "-1s"}, {"--total-timeout", "0"}} {
+		if _, err := newCmd(args...); err == nil {
+			t.Errorf("Expected error for %v", args)
+		}
+	}
 }
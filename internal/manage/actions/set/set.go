@@ -1,9 +1,13 @@
 package set
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
+	"io"
+	"os"
 	"sort"
+	"strconv"
 	"strings"
 
 	"github.com/OpenSlides/openslides-cli/internal/constants"
@@ -21,6 +25,14 @@ formatted payload. Provide the payload directly or use the --file flag with a
 file or use this flag with - to read from stdin. Only the following update actions are
 supported: [agenda_item, committee, group, meeting, motion, organization_tag, organization, projector, theme, topic, user]
 
+Use --ids together with one or more --fields key=value to set the same fields on several objects with
+one backend request. Values true and false are sent as booleans, integers as
+numbers, null as null, JSON arrays and objects as given and everything else as
+string. A payload array given as argument or with --file is sent in the same
+request, so heterogeneous updates can be combined. One line per id is printed.
+Instead of or in addition to --ids, --ids-from reads the ids from the output of
+osmanage get (any JSON output format), e.g. piped to stdin with --ids-from -.
+
 Examples:
   osmanage set user '[{"id": 5, "first_name": "Jane", "last_name": "Smith"}]'
 	--address <myBackendManageIP>:9002 \
@@ -33,6 +45,17 @@ Examples:
 
   echo '[{"id": 5, "first_name": "Jane", "last_name": "Smith"}]' | osmanage set user \
     --file - \
+	--address <myBackendManageIP>:9002 \
+	--password-file ./my.instance.dir.org/secrets/internal_auth_password
+
+  # Deactivate several users at once
+  osmanage set user --ids 5,6,7 --fields is_active=false \
+	--address <myBackendManageIP>:9002 \
+	--password-file ./my.instance.dir.org/secrets/internal_auth_password
+
+  # Deactivate all demo users found by get
+  osmanage get user --filter is_demo_user=true --from-env | osmanage set user \
+    --ids-from - --fields is_active=false \
 	--address <myBackendManageIP>:9002 \
 	--password-file ./my.instance.dir.org/secrets/internal_auth_password`
 )
@@ -59,12 +82,18 @@ func Cmd() *cobra.Command {
 		Args:  cobra.RangeArgs(1, 2),
 	}
 
-	address := cmd.Flags().StringP("address", "a", "", "address of the OpenSlides backendManage service (default: "+constants.DefaultBackendManageAddress+")")
+	address := cmd.Flags().StringP("address", "a", "", constants.BackendManageAddressUsage)
 	passwordFile := cmd.Flags().String("password-file", "", "file with password for authorization (default: "+constants.DefaultPasswordFile+")")
+	waitForBackend := cmd.Flags().Duration("wait-for-backend", 0, constants.WaitForBackendUsage)
 	payloadFile := cmd.Flags().StringP("file", "f", "", "JSON file with the payload, or - for stdin")
+	ids := cmd.Flags().Int64Slice("ids", nil, "IDs of the objects to update with --fields in one request")
+	idsFrom := cmd.Flags().String("ids-from", "", "read the ids to update with --fields from the output of get, or - for stdin")
+	fields := cmd.Flags().StringArray("fields", nil, "field to set on every object of --ids, e.g. 'is_active=false' (repeatable)")
 
 	cmd.RunE = func(cmd *cobra.Command, args []string) error {
-		utils.KeepValueOrEnvOrDefault(address, constants.EnvOsmanageBackendAddress, constants.DefaultBackendManageAddress)
+		if err := utils.KeepValueOrBackendManageAddress(address); err != nil {
+			return err
+		}
 		utils.KeepValueOrEnvOrDefault(passwordFile, constants.EnvOsmanageBackendPasswordFile, constants.DefaultPasswordFile)
 
 		logger.Info("=== SET ACTION ===")
@@ -80,20 +109,60 @@ func Cmd() *cobra.Command {
 
 		logger.Debug("Mapped to backend action: %s", actionName)
 
+		if *idsFrom != "" {
+			if *idsFrom == "-" && *payloadFile == "-" {
+				return fmt.Errorf("--ids-from and --file cannot both read from stdin")
+			}
+			readIDs, err := utils.ReadIDsFrom(*idsFrom)
+			if err != nil {
+				return err
+			}
+			logger.Debug("Read %d ids from %s", len(readIDs), *idsFrom)
+			*ids = append(*ids, readIDs...)
+		}
+		if (len(*ids) > 0) != (len(*fields) > 0) {
+			return fmt.Errorf("--ids or --ids-from and --fields must be used together")
+		}
+		batch := len(*ids) > 0
+
 		var input string
 		if len(args) > 1 {
 			input = args[1]
 		}
 
-		payload, err := utils.ReadInputOrFileOrStdin(input, *payloadFile)
-		if err != nil {
-			return fmt.Errorf("reading payload: %w", err)
+		var payload []byte
+		var items []map[string]any
+		if input != "" || *payloadFile != "" || !batch {
+			data, err := utils.ReadInputOrFileOrStdin(input, *payloadFile)
+			if err != nil {
+				return fmt.Errorf("reading payload: %w", err)
+			}
+
+			var payloadData any
+			if err := json.Unmarshal(data, &payloadData); err != nil {
+				logger.Error("Invalid JSON in payload")
+				return fmt.Errorf("invalid JSON: %w", err)
+			}
+			payload = data
+
+			if batch {
+				if items, err = parsePayloadItems(data); err != nil {
+					return err
+				}
+			}
 		}
 
-		var payloadData any
-		if err := json.Unmarshal(payload, &payloadData); err != nil {
-			logger.Error("Invalid JSON in payload")
-			return fmt.Errorf("invalid JSON: %w", err)
+		if batch {
+			batchItems, err := buildBatchItems(*ids, *fields)
+			if err != nil {
+				return err
+			}
+			items = append(items, batchItems...)
+			logger.Debug("Updating %d objects", len(items))
+
+			if payload, err = json.Marshal(items); err != nil {
+				return fmt.Errorf("marshalling payload: %w", err)
+			}
 		}
 
 		authPassword, err := utils.ReadPassword(*passwordFile)
@@ -101,12 +170,26 @@ func Cmd() *cobra.Command {
 			return fmt.Errorf("reading password: %w", err)
 		}
 
-		cl := client.New(*address, authPassword)
-		resp, err := cl.SendAction(actionName, payload)
+		cl := client.New(*address, authPassword, client.WithTimeout(utils.Timeout(cmd, 0)))
+		if err := cl.WaitForBackend(*waitForBackend); err != nil {
+			return err
+		}
+		ctx, cancel := utils.CommandContext(cmd)
+		defer cancel()
+		resp, err := cl.SendActionContext(ctx, actionName, payload)
 		if err != nil {
 			return fmt.Errorf("sending request: %w", err)
 		}
 
+		if batch {
+			body, checkErr := client.CheckResponse(resp)
+			if err := reportResults(os.Stdout, items, body, checkErr); err != nil {
+				return err
+			}
+			logger.Info("%d objects updated successfully", len(items))
+			return nil
+		}
+
 		body, err := client.CheckResponse(resp)
 		if err != nil {
 			return err
@@ -128,3 +211,96 @@ func helpTextActionList() []string {
 	sort.Strings(actions)
 	return actions
 }
+
+// parsePayloadItems parses a payload array of objects for combining it with
+// --ids and --fields
+func parsePayloadItems(data []byte) ([]map[string]any, error) {
+	if trimmed := bytes.TrimSpace(data); len(trimmed) == 0 || trimmed[0] != '[' {
+		return nil, fmt.Errorf("payload must be a JSON array of objects to be combined with --ids")
+	}
+
+	var items []map[string]any
+	if err := json.Unmarshal(data, &items); err != nil {
+		return nil, fmt.Errorf("payload must be a JSON array of objects to be combined with --ids: %w", err)
+	}
+	return items, nil
+}
+
+// buildBatchItems returns one action data object per id with the fields given
+// as key=value pairs. Duplicate ids are skipped.
+func buildBatchItems(ids []int64, fields []string) ([]map[string]any, error) {
+	values := make(map[string]any, len(fields))
+	for _, field := range fields {
+		key, value, ok := strings.Cut(field, "=")
+		key = strings.TrimSpace(key)
+		if !ok || key == "" {
+			return nil, fmt.Errorf("invalid field %q (expected key=value)", field)
+		}
+		if key == "id" {
+			return nil, fmt.Errorf("--fields cannot set id, use --ids")
+		}
+		values[key] = parseFieldValue(value)
+	}
+
+	items := make([]map[string]any, 0, len(ids))
+	seen := make(map[int64]bool, len(ids))
+	for _, id := range ids {
+		if id <= 0 {
+			return nil, fmt.Errorf("invalid id %d in --ids", id)
+		}
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+		item := map[string]any{"id": id}
+		for key, value := range values {
+			item[key] = value
+		}
+		items = append(items, item)
+	}
+	return items, nil
+}
+
+// parseFieldValue converts a --fields value to a bool, integer, null, JSON
+// array or object, or keeps it as string
+func parseFieldValue(value string) any {
+	switch value {
+	case "true":
+		return true
+	case "false":
+		return false
+	case "null":
+		return nil
+	}
+	if n, err := strconv.ParseInt(value, 10, 64); err == nil {
+		return n
+	}
+	if strings.HasPrefix(value, "[") || strings.HasPrefix(value, "{") {
+		var decoded any
+		if err := json.Unmarshal([]byte(value), &decoded); err == nil {
+			return decoded
+		}
+	}
+	return value
+}
+
+// reportResults prints one line per updated object. The backend handles all
+// objects of a request in a single transaction, so a failed request means no
+// object was updated.
+func reportResults(w io.Writer, items []map[string]any, body []byte, checkErr error) error {
+	if checkErr != nil {
+		reason := checkErr.Error()
+		if response, err := client.ParseActionResponse(body); err == nil && response.Message != "" {
+			reason = response.Message
+		}
+		for _, item := range items {
+			fmt.Fprintf(w, "%v: failed: %s\n", item["id"], reason)
+		}
+		return fmt.Errorf("updating objects: %w", checkErr)
+	}
+
+	for _, item := range items {
+		fmt.Fprintf(w, "%v: updated\n", item["id"])
+	}
+	return nil
+}
@@ -1,6 +1,10 @@
 package set
 
 import (
+	"bytes"
+	"errors"
+	"reflect"
+	"strings"
 	"testing"
 )
 
@@ -53,3 +57,94 @@ func TestHelpTextActionList(t *testing.T) {
 		}
 	}
 }
+
+func TestParseFieldValue(t *testing.T) {
+	tests := []struct {
+		value string
+		want  any
+	}{
+		{"true", true},
+		{"false", false},
+		{"null", nil},
+		{"42", int64(42)},
+		{"-1", int64(-1)},
+		{"1.5", "1.5"},
+		{"Jane", "Jane"},
+		{"", ""},
+		{"[1,2]", []any{float64(1), float64(2)}},
+		{`{"a":"b"}`, map[string]any{"a": "b"}},
+		{"[not json", "[not json"},
+	}
+
+	for _, tt := range tests {
+		if got := parseFieldValue(tt.value); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("parseFieldValue(%q) = %#v, want %#v", tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestBuildBatchItems(t *testing.T) {
+	items, err := buildBatchItems([]int64{5, 6, 5}, []string{"is_active=false", "title=Dr. = Prof."})
+	if err != nil {
+		t.Fatalf("buildBatchItems() error = %v", err)
+	}
+	want := []map[string]any{
+		{"id": int64(5), "is_active": false, "title": "Dr. = Prof."},
+		{"id": int64(6), "is_active": false, "title": "Dr. = Prof."},
+	}
+	if !reflect.DeepEqual(items, want) {
+		t.Errorf("buildBatchItems() = %v, want %v", items, want)
+	}
+
+	for _, tt := range []struct {
+		name   string
+		ids    []int64
+		fields []string
+	}{
+		{"missing =", []int64{1}, []string{"is_active"}},
+		{"empty key", []int64{1}, []string{"=true"}},
+		{"id field", []int64{1}, []string{"id=2"}},
+		{"invalid id", []int64{0}, []string{"is_active=true"}},
+	} {
+		if _, err := buildBatchItems(tt.ids, tt.fields); err == nil {
+			t.Errorf("%s: expected error", tt.name)
+		}
+	}
+}
+
+func TestParsePayloadItems(t *testing.T) {
+	items, err := parsePayloadItems([]byte(` [{"id": 1, "first_name": "Jane"}]`))
+	if err != nil {
+		t.Fatalf("parsePayloadItems() error = %v", err)
+	}
+	if len(items) != 1 || items[0]["first_name"] != "Jane" {
+		t.Errorf("parsePayloadItems() = %v", items)
+	}
+
+	for _, payload := range []string{`{"id": 1}`, `[1, 2]`} {
+		if _, err := parsePayloadItems([]byte(payload)); err == nil {
+			t.Errorf("parsePayloadItems(%s): expected error", payload)
+		}
+	}
+}
+
+func TestReportResults(t *testing.T) {
+	items := []map[string]any{{"id": int64(5)}, {"id": float64(6)}}
+
+	var buf bytes.Buffer
+	if err := reportResults(&buf, items, []byte(`{"success":true,"results":[[null,null]]}`), nil); err != nil {
+		t.Fatalf("reportResults() error = %v", err)
+	}
+	if got, want := buf.String(), "5: updated\n6: updated\n"; got != want {
+		t.Errorf("reportResults() output = %q, want %q", got, want)
+	}
+
+	buf.Reset()
+	err := reportResults(&buf, items, []byte(`{"success":false,"message":"Model 'user/6' does not exist."}`), errors.New("request failed [400]"))
+	if err == nil {
+		t.Fatal("Expected error for failed request")
+	}
+	if !strings.Contains(buf.String(), "6: failed: Model 'user/6' does not exist.") {
+		t.Errorf("Expected failure reason per id, got %q", buf.String())
+	}
+}
@@ -15,9 +15,40 @@ import (
 
 const (
 	SetPasswordHelp      = "Sets the password of a user in OpenSlides"
-	SetPasswordHelpExtra = "This command sets the password of a user by a given user ID."
+	SetPasswordHelpExtra = `This command sets the password of a user by a given user ID.
+
+Use --file with a JSON array to set the passwords of several users with one
+backend request, or --file=- to read from stdin. Each entry needs an id and
+either a password or a password_file to read the password from.
+
+Use --ids-from with --password to set the same password for all users in the
+output of osmanage get (any JSON output format), or - to read it from stdin.
+
+Examples:
+  osmanage set-password --user_id 5 --password newpwd \
+    --address <myBackendManageIP>:9002 \
+    --password-file ./my.instance.dir.org/secrets/internal_auth_password
+
+  osmanage set-password --file passwords.json \
+    --address <myBackendManageIP>:9002 \
+    --password-file ./my.instance.dir.org/secrets/internal_auth_password
+
+  osmanage get user --filter is_demo_user=true --from-env | osmanage set-password \
+    --ids-from - --password newpwd \
+    --address <myBackendManageIP>:9002 \
+    --password-file ./my.instance.dir.org/secrets/internal_auth_password
+
+passwords.json:
+  [{"id": 5, "password": "newpwd"}, {"id": 6, "password_file": "./user6_password"}]`
 )
 
+// passwordEntry is one element of the --file JSON array
+type passwordEntry struct {
+	ID           int64  `json:"id"`
+	Password     string `json:"password"`
+	PasswordFile string `json:"password_file"`
+}
+
 func Cmd() *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "set-password",
@@ -26,46 +57,81 @@ func Cmd() *cobra.Command {
 		Args:  cobra.NoArgs,
 	}
 
-	address := cmd.Flags().StringP("address", "a", "", "address of the OpenSlides backendManage service (default: "+constants.DefaultBackendManageAddress+")")
+	address := cmd.Flags().StringP("address", "a", "", constants.BackendManageAddressUsage)
 	passwordFile := cmd.Flags().String("password-file", "", "file with password for authorization (default: "+constants.DefaultPasswordFile+")")
-	password := cmd.Flags().StringP("password", "p", "", "new password of the user (required)")
-	userID := cmd.Flags().Int64P("user_id", "u", 0, "ID of the user account (required)")
+	waitForBackend := cmd.Flags().Duration("wait-for-backend", 0, constants.WaitForBackendUsage)
+	password := cmd.Flags().StringP("password", "p", "", "new password of the user, or of all users of --ids-from (required without --file)")
+	userID := cmd.Flags().Int64P("user_id", "u", 0, "ID of the user account (required without --file)")
+	file := cmd.Flags().StringP("file", "f", "", "JSON file with an array of {id, password} or {id, password_file} objects, or - for stdin")
+	idsFrom := cmd.Flags().String("ids-from", "", "read the user ids from the output of get, or - for stdin (requires --password)")
 
-	_ = cmd.MarkFlagRequired("user_id")
-	_ = cmd.MarkFlagRequired("password")
+	cmd.MarkFlagsMutuallyExclusive("file", "user_id", "ids-from")
+	cmd.MarkFlagsMutuallyExclusive("file", "password")
 
 	cmd.RunE = func(cmd *cobra.Command, args []string) error {
-		if strings.TrimSpace(*password) == "" {
-			return fmt.Errorf("--password cannot be empty")
-		}
-		if *userID == 0 {
-			return fmt.Errorf("--user_id cannot be empty or less than 1")
+		if *file == "" {
+			if strings.TrimSpace(*password) == "" {
+				return fmt.Errorf("--password cannot be empty")
+			}
+			if *userID <= 0 && *idsFrom == "" {
+				return fmt.Errorf("--user_id cannot be empty or less than 1")
+			}
 		}
 
-		utils.KeepValueOrEnvOrDefault(address, constants.EnvOsmanageBackendAddress, constants.DefaultBackendManageAddress)
+		if err := utils.KeepValueOrBackendManageAddress(address); err != nil {
+			return err
+		}
 		utils.KeepValueOrEnvOrDefault(passwordFile, constants.EnvOsmanageBackendPasswordFile, constants.DefaultPasswordFile)
 
 		logger.Info("=== SET PASSWORD ===")
-		logger.Debug("Setting password for user ID: %d", *userID)
+
+		var payload []map[string]any
+		if *file != "" {
+			data, err := utils.ReadFromFileOrStdin(*file)
+			if err != nil {
+				return fmt.Errorf("reading password entries: %w", err)
+			}
+			if payload, err = parseEntries(data); err != nil {
+				return err
+			}
+			logger.Debug("Setting passwords for %d users", len(payload))
+		} else if *idsFrom != "" {
+			ids, err := utils.ReadIDsFrom(*idsFrom)
+			if err != nil {
+				return err
+			}
+			payload = make([]map[string]any, 0, len(ids))
+			for _, id := range ids {
+				payload = append(payload, map[string]any{"id": id, "password": *password})
+			}
+			logger.Debug("Setting the password for %d users", len(payload))
+		} else {
+			logger.Debug("Setting password for user ID: %d", *userID)
+			payload = []map[string]any{
+				{
+					"id":       *userID,
+					"password": *password,
+				},
+			}
+		}
 
 		authPassword, err := utils.ReadPassword(*passwordFile)
 		if err != nil {
 			return fmt.Errorf("reading password: %w", err)
 		}
 
-		payload := []map[string]any{
-			{
-				"id":       *userID,
-				"password": *password,
-			},
-		}
 		payloadJSON, err := json.Marshal(payload)
 		if err != nil {
 			return fmt.Errorf("marshalling payload: %w", err)
 		}
 
-		cl := client.New(*address, authPassword)
-		resp, err := cl.SendAction("user.set_password", payloadJSON)
+		cl := client.New(*address, authPassword, client.WithTimeout(utils.Timeout(cmd, 0)))
+		if err := cl.WaitForBackend(*waitForBackend); err != nil {
+			return err
+		}
+		ctx, cancel := utils.CommandContext(cmd)
+		defer cancel()
+		resp, err := cl.SendActionContext(ctx, "user.set_password", payloadJSON)
 		if err != nil {
 			return fmt.Errorf("sending request: %w", err)
 		}
@@ -75,6 +141,13 @@ func Cmd() *cobra.Command {
 			return err
 		}
 
+		if *file != "" || *idsFrom != "" {
+			logger.Info("Passwords set successfully for %d users", len(payload))
+			fmt.Printf("Response: %s\n", string(body))
+			fmt.Printf("Passwords for %d users set successfully.\n", len(payload))
+			return nil
+		}
+
 		logger.Info("Password set successfully for user %d", *userID)
 		fmt.Printf("Response: %s\n", string(body))
 		fmt.Printf("Password for user %d set successfully.\n", *userID)
@@ -83,3 +156,42 @@ func Cmd() *cobra.Command {
 
 	return cmd
 }
+
+// parseEntries parses the --file JSON array into the user.set_password payload,
+// reading passwords from password_file where given.
+func parseEntries(data []byte) ([]map[string]any, error) {
+	var entries []passwordEntry
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return nil, fmt.Errorf("invalid JSON: %w", err)
+	}
+	if len(entries) == 0 {
+		return nil, fmt.Errorf("no password entries provided")
+	}
+
+	payload := make([]map[string]any, 0, len(entries))
+	for i, entry := range entries {
+		if entry.ID <= 0 {
+			return nil, fmt.Errorf("entry %d: id must be at least 1", i+1)
+		}
+		if entry.Password != "" && entry.PasswordFile != "" {
+			return nil, fmt.Errorf("entry %d: cannot provide both password and password_file", i+1)
+		}
+
+		password := entry.Password
+		if entry.PasswordFile != "" {
+			var err error
+			if password, err = utils.ReadPassword(entry.PasswordFile); err != nil {
+				return nil, fmt.Errorf("entry %d: %w", i+1, err)
+			}
+		}
+		if strings.TrimSpace(password) == "" {
+			return nil, fmt.Errorf("entry %d: password cannot be empty", i+1)
+		}
+
+		payload = append(payload, map[string]any{
+			"id":       entry.ID,
+			"password": password,
+		})
+	}
+	return payload, nil
+}
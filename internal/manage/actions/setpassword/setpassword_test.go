@@ -0,0 +1,82 @@
+package setpassword
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestParseEntries(t *testing.T) {
+	passwordFile := filepath.Join(t.TempDir(), "password")
+	if err := os.WriteFile(passwordFile, []byte("frompwdfile"), 0600); err != nil {
+		t.Fatalf("writing password file: %v", err)
+	}
+
+	data := `[{"id": 5, "password": "newpwd"}, {"id": 6, "password_file": "` + passwordFile + `"}]`
+	payload, err := parseEntries([]byte(data))
+	if err != nil {
+		t.Fatalf("parseEntries() error = %v", err)
+	}
+
+	if len(payload) != 2 {
+		t.Fatalf("Expected 2 entries, got %d", len(payload))
+	}
+	if payload[0]["id"] != int64(5) || payload[0]["password"] != "newpwd" {
+		t.Errorf("Unexpected first entry: %v", payload[0])
+	}
+	if payload[1]["id"] != int64(6) || payload[1]["password"] != "frompwdfile" {
+		t.Errorf("Unexpected second entry: %v", payload[1])
+	}
+	if _, ok := payload[1]["password_file"]; ok {
+		t.Error("Expected password_file to not be sent to the backend")
+	}
+}
+
+func TestParseEntries_Invalid(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+	}{
+		{"invalid json", `[{"id": 5,`},
+		{"object instead of array", `{"id": 5, "password": "pwd"}`},
+		{"empty array", `[]`},
+		{"missing id", `[{"password": "pwd"}]`},
+		{"missing password", `[{"id": 5}]`},
+		{"both password and file", `[{"id": 5, "password": "pwd", "password_file": "pwd.txt"}]`},
+		{"missing password file", `[{"id": 5, "password_file": "/nonexistent/password"}]`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := parseEntries([]byte(tt.input)); err == nil {
+				t.Error("Expected error")
+			}
+		})
+	}
+}
+
+func TestCmd_IDsFromFlags(t *testing.T) {
+	tests := []struct {
+		name string
+		args []string
+		want string
+	}{
+		{"without password", []string{"--ids-from", "-"}, "--password cannot be empty"},
+		{"with user_id", []string{"--ids-from", "-", "--user_id", "5", "--password", "x"}, "if any flags in the group [file user_id ids-from] are set none of the others can be"},
+		{"with file", []string{"--ids-from", "-", "--file", "passwords.json"}, "if any flags in the group [file user_id ids-from] are set none of the others can be"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cmd := Cmd()
+			cmd.SetArgs(tt.args)
+			cmd.SilenceUsage = true
+			cmd.SilenceErrors = true
+			err := cmd.Execute()
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Expected error containing %q, got %v", tt.want, err)
+			}
+		})
+	}
+}
@@ -2,36 +2,160 @@ package client
 
 import (
 	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
 	"encoding/base64"
 	"encoding/json"
 	"fmt"
 	"io"
+	"net"
 	"net/http"
+	"os"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/OpenSlides/openslides-cli/internal/constants"
 	"github.com/OpenSlides/openslides-cli/internal/logger"
+	"github.com/OpenSlides/openslides-cli/internal/utils"
 )
 
 type Client struct {
-	address  string
-	password string
+	address    string
+	password   string
+	scheme     string
+	httpClient *http.Client
+}
+
+// Option configures a Client
+type Option func(*Client)
+
+// WithScheme sets the URL scheme, e.g. constants.BackendHTTPSScheme.
+// Defaults to constants.BackendHTTPScheme.
+func WithScheme(scheme string) Option {
+	return func(c *Client) {
+		c.scheme = scheme
+	}
+}
+
+// WithTimeout bounds the duration of every request. Zero means no timeout,
+// which is the default.
+func WithTimeout(timeout time.Duration) Option {
+	return func(c *Client) {
+		c.httpClient.Timeout = timeout
+	}
+}
+
+// WithTLSConfig sets the TLS configuration used for https connections.
+func WithTLSConfig(config *tls.Config) Option {
+	return func(c *Client) {
+		c.httpClient.Transport = &http.Transport{
+			Proxy:           http.ProxyFromEnvironment,
+			TLSClientConfig: config,
+		}
+	}
+}
+
+// TLSOptions returns the options for connecting via https with the CA bundle in
+// caCertFile and/or without certificate verification. Returns no options if
+// neither is set, so the client keeps using http.
+func TLSOptions(caCertFile string, insecureSkipVerify bool) ([]Option, error) {
+	if caCertFile == "" && !insecureSkipVerify {
+		return nil, nil
+	}
+
+	config := &tls.Config{MinVersion: tls.VersionTLS12}
+
+	if caCertFile != "" {
+		pem, err := os.ReadFile(caCertFile)
+		if err != nil {
+			return nil, fmt.Errorf("reading CA certificate %q: %w", caCertFile, err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no valid PEM certificates found in %q", caCertFile)
+		}
+		config.RootCAs = pool
+		logger.Debug("Using CA certificates from: %s", caCertFile)
+	}
+
+	if insecureSkipVerify {
+		logger.Warn("!!! TLS certificate verification is DISABLED (--insecure-skip-tls-verify). Use this only for development !!!")
+		config.InsecureSkipVerify = true
+	}
+
+	return []Option{WithScheme(constants.BackendHTTPSScheme), WithTLSConfig(config)}, nil
 }
 
 // New creates a new Client with the service address and password.
-// Address should be in the format "host:port" (e.g., "localhost:9002").
-func New(address, password string) *Client {
+// Address should be in the format "host:port" (e.g., "localhost:9002"). An
+// "https://" prefix selects https with the system root certificates, an
+// "http://" prefix is ignored.
+func New(address, password string, opts ...Option) *Client {
 	logger.Debug("Creating new client for address: %s", address)
-	return &Client{
-		address:  address,
-		password: password,
+	scheme := constants.BackendHTTPScheme
+	if rest, ok := strings.CutPrefix(address, constants.BackendHTTPSScheme); ok {
+		scheme, address = constants.BackendHTTPSScheme, rest
+	} else {
+		address = strings.TrimPrefix(address, constants.BackendHTTPScheme)
+	}
+	c := &Client{
+		address:    strings.TrimSuffix(address, "/"),
+		password:   password,
+		scheme:     scheme,
+		httpClient: &http.Client{},
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// dialAddress returns the host:port to open TCP connections to. An address
+// without port gets the default port of the scheme.
+func (c *Client) dialAddress() string {
+	if _, _, err := net.SplitHostPort(c.address); err == nil {
+		return c.address
+	}
+	if c.scheme == constants.BackendHTTPSScheme {
+		return net.JoinHostPort(c.address, "443")
 	}
+	return net.JoinHostPort(c.address, "80")
 }
 
-// buildURL constructs the full URL from the client's address and the given path.
+// buildURL constructs the full URL from the client's scheme and address and the given path.
 func (c *Client) buildURL(path string) string {
-	return constants.BackendHTTPScheme + c.address + path
+	return c.scheme + c.address + path
+}
+
+// redacted replaces secret header values in debug logs
+const redacted = "***"
+
+// secretHeaders are the headers whose values are redacted in debug logs
+var secretHeaders = []string{"Authorization", "Set-Cookie", "Cookie"}
+
+// logSecrets disables the redaction of secret header values
+var logSecrets = false
+
+// SetLogSecrets enables logging secret header values like the Authorization
+// header verbatim in the debug logs of requests and responses.
+func SetLogSecrets(enabled bool) {
+	logSecrets = enabled
+}
+
+// headerLogValue returns value or, if key is a secret header and logging
+// secrets is disabled, a placeholder.
+func headerLogValue(key, value string) string {
+	if logSecrets {
+		return value
+	}
+	for _, secret := range secretHeaders {
+		if strings.EqualFold(key, secret) {
+			return redacted
+		}
+	}
+	return value
 }
 
 // escapeForShell escapes single quotes in a string for safe use in shell commands.
@@ -40,12 +164,13 @@ func escapeForShell(s string) string {
 }
 
 // logCurlCommand logs a curl command that can be used to reproduce the request.
+// Secret header values are redacted unless enabled with SetLogSecrets.
 func logCurlCommand(method, url string, headers map[string]string, body []byte) {
 	var parts []string
 	parts = append(parts, fmt.Sprintf("curl -X %s '%s'", method, url))
 
 	for key, value := range headers {
-		parts = append(parts, fmt.Sprintf("-H '%s: %s'", key, value))
+		parts = append(parts, fmt.Sprintf("-H '%s: %s'", key, headerLogValue(key, value)))
 	}
 
 	if len(body) > 0 {
@@ -55,21 +180,61 @@ func logCurlCommand(method, url string, headers map[string]string, body []byte)
 	logger.Debug("Equivalent curl command:\n  %s", strings.Join(parts, " \\\n  "))
 }
 
-// logResponseDetails logs response headers and metadata.
+// logResponseDetails logs response headers and metadata, redacting secret
+// header values like logCurlCommand.
 func logResponseDetails(resp *http.Response, duration time.Duration) {
 	logger.Debug("Response status: %d %s", resp.StatusCode, resp.Status)
 	logger.Debug("Response headers:")
 	for key, values := range resp.Header {
 		for _, value := range values {
-			logger.Debug("  %s: %s", key, value)
+			logger.Debug("  %s: %s", key, headerLogValue(key, value))
 		}
 	}
 	logger.Debug("Request completed in %v", duration)
 }
 
+// WaitForBackend opens TCP connections to the backend address every
+// constants.BackendWaitInterval until one succeeds or wait has elapsed. A wait
+// of zero returns immediately.
+func (c *Client) WaitForBackend(wait time.Duration) error {
+	return c.waitForBackend(wait, constants.BackendWaitInterval)
+}
+
+// waitForBackend works like WaitForBackend with the given interval between attempts
+func (c *Client) waitForBackend(wait, interval time.Duration) error {
+	if wait <= 0 {
+		return nil
+	}
+
+	logger.Info("Waiting up to %v for backend at %s", wait, c.address)
+	ctx, cancel := context.WithTimeout(context.Background(), wait)
+	defer cancel()
+
+	dialer := &net.Dialer{}
+	maxRetries := int(wait/interval) + 2
+	err := utils.RetryHTTP(ctx, maxRetries, interval, func() error {
+		conn, err := dialer.DialContext(ctx, "tcp", c.dialAddress())
+		if err != nil {
+			return err
+		}
+		return conn.Close()
+	})
+	if err != nil {
+		return fmt.Errorf("backend at %s not reachable within %v: %w", c.address, wait, err)
+	}
+
+	logger.Debug("Backend at %s is reachable", c.address)
+	return nil
+}
+
 // SendAction sends an action request to the backend service.
 // rawData should be a JSON array of action data objects.
 func (c *Client) SendAction(action string, rawData []byte) (*http.Response, error) {
+	return c.SendActionContext(context.Background(), action, rawData)
+}
+
+// SendActionContext works like SendAction but aborts the request when ctx is done.
+func (c *Client) SendActionContext(ctx context.Context, action string, rawData []byte) (*http.Response, error) {
 	logger.Info("Sending action: %s", action)
 
 	payload := []map[string]any{
@@ -85,9 +250,27 @@ func (c *Client) SendAction(action string, rawData []byte) (*http.Response, erro
 		return nil, fmt.Errorf("marshalling payload: %w", err)
 	}
 
+	return c.postHandleRequest(ctx, body)
+}
+
+// SendActions sends a raw batch of actions to the backend service as-is.
+// rawActions should be a JSON array of {"action": ..., "data": [...]} objects,
+// which the backend handles in a single transaction.
+func (c *Client) SendActions(rawActions []byte) (*http.Response, error) {
+	return c.SendActionsContext(context.Background(), rawActions)
+}
+
+// SendActionsContext works like SendActions but aborts the request when ctx is done.
+func (c *Client) SendActionsContext(ctx context.Context, rawActions []byte) (*http.Response, error) {
+	logger.Info("Sending action batch")
+	return c.postHandleRequest(ctx, rawActions)
+}
+
+// postHandleRequest posts an action request body to the handle_request endpoint.
+func (c *Client) postHandleRequest(ctx context.Context, body []byte) (*http.Response, error) {
 	url := c.buildURL(constants.BackendHandleRequestPath)
 
-	req, err := http.NewRequest("POST", url, bytes.NewReader(body))
+	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewReader(body))
 	if err != nil {
 		logger.Error("Failed to create request: %v", err)
 		return nil, fmt.Errorf("creating request: %w", err)
@@ -103,7 +286,7 @@ func (c *Client) SendAction(action string, rawData []byte) (*http.Response, erro
 	}, body)
 
 	start := time.Now()
-	resp, err := http.DefaultClient.Do(req)
+	resp, err := c.httpClient.Do(req)
 	duration := time.Since(start)
 
 	if err != nil {
@@ -118,6 +301,11 @@ func (c *Client) SendAction(action string, rawData []byte) (*http.Response, erro
 
 // SendMigrations sends a migrations command to the backend
 func (c *Client) SendMigrations(command string) (*http.Response, error) {
+	return c.SendMigrationsContext(context.Background(), command)
+}
+
+// SendMigrationsContext works like SendMigrations but aborts the request when ctx is done.
+func (c *Client) SendMigrationsContext(ctx context.Context, command string) (*http.Response, error) {
 	logger.Info("Sending migrations command: %s", command)
 
 	payload := map[string]string{
@@ -132,7 +320,7 @@ func (c *Client) SendMigrations(command string) (*http.Response, error) {
 
 	url := c.buildURL(constants.BackendMigrationsPath)
 
-	req, err := http.NewRequest("POST", url, bytes.NewReader(body))
+	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewReader(body))
 	if err != nil {
 		logger.Error("Failed to create request: %v", err)
 		return nil, fmt.Errorf("creating request: %w", err)
@@ -148,7 +336,7 @@ func (c *Client) SendMigrations(command string) (*http.Response, error) {
 	}, body)
 
 	start := time.Now()
-	resp, err := http.DefaultClient.Do(req)
+	resp, err := c.httpClient.Do(req)
 	duration := time.Since(start)
 
 	if err != nil {
@@ -175,9 +363,72 @@ func CheckResponse(resp *http.Response) ([]byte, error) {
 
 	if resp.StatusCode != http.StatusOK {
 		logger.Error("Request failed with status %d: %s", resp.StatusCode, string(body))
-		return body, fmt.Errorf("request failed [%d]: %s", resp.StatusCode, string(body))
+		return body, &StatusError{
+			StatusCode: resp.StatusCode,
+			Body:       string(body),
+			RetryAfter: parseRetryAfter(resp.Header.Get("Retry-After"), time.Now()),
+		}
 	}
 
 	logger.Debug("Response successful")
 	return body, nil
 }
+
+// StatusError is returned by CheckResponse for responses other than 200 OK
+type StatusError struct {
+	StatusCode int
+	Body       string
+	RetryAfter time.Duration // delay requested by a Retry-After header, zero if absent
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("request failed [%d]: %s", e.StatusCode, e.Body)
+}
+
+// RetryAfterDelay returns the delay the server asked for before the next
+// request, see utils.RetryHTTP
+func (e *StatusError) RetryAfterDelay() time.Duration {
+	return e.RetryAfter
+}
+
+// parseRetryAfter returns the delay of a Retry-After header value, given as
+// seconds or HTTP date. Missing, invalid or past values give zero.
+func parseRetryAfter(value string, now time.Time) time.Duration {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		return max(time.Duration(seconds)*time.Second, 0)
+	}
+	if date, err := http.ParseTime(value); err == nil {
+		return max(date.Sub(now), 0)
+	}
+	return 0
+}
+
+// ActionResponse is the body the backend returns for action requests.
+// Results holds one list per sent action with one entry per action data object.
+type ActionResponse struct {
+	Success bool               `json:"success"`
+	Message string             `json:"message"`
+	Results [][]map[string]any `json:"results"`
+}
+
+// ParseActionResponse decodes an action response body
+func ParseActionResponse(body []byte) (*ActionResponse, error) {
+	var response ActionResponse
+	if err := json.Unmarshal(body, &response); err != nil {
+		return nil, fmt.Errorf("decoding action response: %w", err)
+	}
+	return &response, nil
+}
+
+// ActionResults returns the per item results of the first action in the response.
+// The returned slice has one entry per sent action data object, entries may be nil.
+func (r *ActionResponse) ActionResults() []map[string]any {
+	if len(r.Results) == 0 {
+		return nil
+	}
+	return r.Results[0]
+}
@@ -1,11 +1,19 @@
 package client
 
 import (
+	"context"
+	"crypto/tls"
+	"encoding/pem"
+	"errors"
 	"io"
+	"net"
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/OpenSlides/openslides-cli/internal/constants"
 )
@@ -22,6 +30,71 @@ func TestNew(t *testing.T) {
 	if client.password != password {
 		t.Errorf("Expected password %s, got %s", password, client.password)
 	}
+	if client.scheme != constants.BackendHTTPScheme {
+		t.Errorf("Expected default scheme %s, got %s", constants.BackendHTTPScheme, client.scheme)
+	}
+	if client.httpClient.Timeout != 0 {
+		t.Errorf("Expected no default timeout, got %v", client.httpClient.Timeout)
+	}
+}
+
+func TestNewWithOptions(t *testing.T) {
+	client := New("backend.example.org:443", "password", WithScheme(constants.BackendHTTPSScheme), WithTimeout(10*time.Second))
+
+	if got := client.buildURL(constants.BackendHandleRequestPath); got != "https://backend.example.org:443/internal/handle_request" {
+		t.Errorf("buildURL() = %s, want https URL", got)
+	}
+	if client.httpClient.Timeout != 10*time.Second {
+		t.Errorf("Expected timeout 10s, got %v", client.httpClient.Timeout)
+	}
+	if client.httpClient == http.DefaultClient {
+		t.Error("Expected a dedicated http client")
+	}
+}
+
+func TestNewAddressScheme(t *testing.T) {
+	tests := []struct {
+		address                 string
+		wantScheme, wantAddress string
+		wantDial                string
+	}{
+		{"localhost:9002", constants.BackendHTTPScheme, "localhost:9002", "localhost:9002"},
+		{"http://localhost:9002", constants.BackendHTTPScheme, "localhost:9002", "localhost:9002"},
+		{"https://openslides.example.org", constants.BackendHTTPSScheme, "openslides.example.org", "openslides.example.org:443"},
+		{"https://openslides.example.org:8443/", constants.BackendHTTPSScheme, "openslides.example.org:8443", "openslides.example.org:8443"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.address, func(t *testing.T) {
+			client := New(tt.address, "password")
+			if client.scheme != tt.wantScheme || client.address != tt.wantAddress {
+				t.Errorf("Expected %s%s, got %s%s", tt.wantScheme, tt.wantAddress, client.scheme, client.address)
+			}
+			if got := client.dialAddress(); got != tt.wantDial {
+				t.Errorf("dialAddress() = %s, want %s", got, tt.wantDial)
+			}
+		})
+	}
+}
+
+func TestSendActionTimeout(t *testing.T) {
+	done := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-done:
+		case <-time.After(5 * time.Second):
+		}
+	}))
+	defer server.Close()
+	defer close(done)
+
+	address := strings.TrimPrefix(server.URL, constants.BackendHTTPScheme)
+	cl := New(address, "test-password", WithTimeout(50*time.Millisecond))
+
+	resp, err := cl.SendAction("test.action", []byte(`[{"id":1}]`))
+	if err == nil {
+		_ = resp.Body.Close()
+		t.Fatal("Expected timeout error")
+	}
 }
 
 func TestBuildURL(t *testing.T) {
@@ -150,6 +223,139 @@ func TestSendAction(t *testing.T) {
 	})
 }
 
+func TestSendActions(t *testing.T) {
+	batch := `[{"action":"committee.create","data":[{"name":"c1","organization_id":1}]},{"action":"meeting.create","data":[{"name":"m1","committee_id":1}]}]`
+
+	var receivedBody string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != constants.BackendHandleRequestPath {
+			t.Errorf("Expected path %s, got %s", constants.BackendHandleRequestPath, r.URL.Path)
+		}
+		if r.Header.Get("Authorization") == "" {
+			t.Error("Expected Authorization header")
+		}
+		body, _ := io.ReadAll(r.Body)
+		receivedBody = string(body)
+
+		w.WriteHeader(http.StatusOK)
+		if _, err := w.Write([]byte(`{"success":true}`)); err != nil {
+			t.Fatalf("failed to write response: %v", err)
+		}
+	}))
+	defer server.Close()
+
+	address := strings.TrimPrefix(server.URL, constants.BackendHTTPScheme)
+	cl := New(address, "test-password")
+
+	resp, err := cl.SendActions([]byte(batch))
+	if err != nil {
+		t.Fatalf("SendActions() error = %v", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if receivedBody != batch {
+		t.Errorf("Expected batch to be sent as-is, got %s", receivedBody)
+	}
+}
+
+func TestSendActionContext_Cancelled(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+	}))
+	defer server.Close()
+	defer close(release)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	address := strings.TrimPrefix(server.URL, constants.BackendHTTPScheme)
+	_, err := New(address, "test-password").SendActionContext(ctx, "test.action", []byte(`[{"id":1}]`))
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected context.DeadlineExceeded, got %v", err)
+	}
+}
+
+func TestTLSOptions(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	address := strings.TrimPrefix(server.URL, constants.BackendHTTPSScheme)
+	caFile := filepath.Join(t.TempDir(), "ca.pem")
+	caPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+	if err := os.WriteFile(caFile, caPEM, 0600); err != nil {
+		t.Fatalf("writing CA file: %v", err)
+	}
+
+	send := func(opts []Option) error {
+		resp, err := New(address, "test-password", opts...).SendAction("test.action", []byte(`[{"id":1}]`))
+		if err == nil {
+			_ = resp.Body.Close()
+		}
+		return err
+	}
+
+	t.Run("no options", func(t *testing.T) {
+		opts, err := TLSOptions("", false)
+		if err != nil {
+			t.Fatalf("TLSOptions() error = %v", err)
+		}
+		if len(opts) != 0 {
+			t.Errorf("Expected no options, got %d", len(opts))
+		}
+	})
+
+	t.Run("custom CA", func(t *testing.T) {
+		opts, err := TLSOptions(caFile, false)
+		if err != nil {
+			t.Fatalf("TLSOptions() error = %v", err)
+		}
+		if err := send(opts); err != nil {
+			t.Errorf("Expected request to succeed with custom CA, got %v", err)
+		}
+	})
+
+	t.Run("https address", func(t *testing.T) {
+		// Without --cacert the system roots are used, which do not trust the test server
+		_, err := New(server.URL, "test-password").SendAction("test.action", []byte(`[{"id":1}]`))
+		var certErr *tls.CertificateVerificationError
+		if !errors.As(err, &certErr) {
+			t.Errorf("Expected certificate verification error, got %v", err)
+		}
+	})
+
+	t.Run("https without CA", func(t *testing.T) {
+		if err := send([]Option{WithScheme(constants.BackendHTTPSScheme)}); err == nil {
+			t.Error("Expected certificate verification error")
+		}
+	})
+
+	t.Run("insecure skip verify", func(t *testing.T) {
+		opts, err := TLSOptions("", true)
+		if err != nil {
+			t.Fatalf("TLSOptions() error = %v", err)
+		}
+		if err := send(opts); err != nil {
+			t.Errorf("Expected request to succeed without verification, got %v", err)
+		}
+	})
+
+	t.Run("invalid CA file", func(t *testing.T) {
+		invalid := filepath.Join(t.TempDir(), "invalid.pem")
+		if err := os.WriteFile(invalid, []byte("not a certificate"), 0600); err != nil {
+			t.Fatalf("writing file: %v", err)
+		}
+		if _, err := TLSOptions(invalid, false); err == nil {
+			t.Error("Expected error for invalid CA file")
+		}
+		if _, err := TLSOptions(filepath.Join(t.TempDir(), "missing.pem"), false); err == nil {
+			t.Error("Expected error for missing CA file")
+		}
+	})
+}
+
 func TestSendMigrations(t *testing.T) {
 	t.Run("successful migrations request", func(t *testing.T) {
 		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -307,3 +513,167 @@ func TestCheckResponse(t *testing.T) {
 		}
 	})
 }
+
+func TestCheckResponseRetryAfter(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Retry-After", "7")
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	resp, err := http.Get(server.URL)
+	if err != nil {
+		t.Fatalf("GET error = %v", err)
+	}
+	_, err = CheckResponse(resp)
+
+	var statusErr *StatusError
+	if !errors.As(err, &statusErr) {
+		t.Fatalf("Expected *StatusError, got %v", err)
+	}
+	if statusErr.StatusCode != http.StatusServiceUnavailable {
+		t.Errorf("StatusCode = %d, want %d", statusErr.StatusCode, http.StatusServiceUnavailable)
+	}
+	if statusErr.RetryAfterDelay() != 7*time.Second {
+		t.Errorf("RetryAfterDelay() = %v, want 7s", statusErr.RetryAfterDelay())
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2026, 1, 2, 15, 4, 5, 0, time.UTC)
+	tests := []struct {
+		value string
+		want  time.Duration
+	}{
+		{"", 0},
+		{"120", 2 * time.Minute},
+		{" 3 ", 3 * time.Second},
+		{"-5", 0},
+		{now.Add(30 * time.Second).Format(http.TimeFormat), 30 * time.Second},
+		{now.Add(-time.Minute).Format(http.TimeFormat), 0},
+		{"soon", 0},
+	}
+
+	for _, tt := range tests {
+		if got := parseRetryAfter(tt.value, now); got != tt.want {
+			t.Errorf("parseRetryAfter(%q) = %v, want %v", tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestParseActionResponse(t *testing.T) {
+	t.Run("results", func(t *testing.T) {
+		body := []byte(`{"success":true,"message":"Actions handled successfully","results":[[{"id":5},{"id":6},null]]}`)
+
+		response, err := ParseActionResponse(body)
+		if err != nil {
+			t.Fatalf("ParseActionResponse() error = %v", err)
+		}
+		if !response.Success {
+			t.Error("Expected success")
+		}
+
+		results := response.ActionResults()
+		if len(results) != 3 {
+			t.Fatalf("Expected 3 results, got %d", len(results))
+		}
+		if results[0]["id"] != float64(5) || results[1]["id"] != float64(6) {
+			t.Errorf("Unexpected results: %v", results)
+		}
+		if results[2] != nil {
+			t.Errorf("Expected nil result for null entry, got %v", results[2])
+		}
+	})
+
+	t.Run("error message", func(t *testing.T) {
+		response, err := ParseActionResponse([]byte(`{"success":false,"message":"A user with the username admin already exists."}`))
+		if err != nil {
+			t.Fatalf("ParseActionResponse() error = %v", err)
+		}
+		if response.Success || response.Message != "A user with the username admin already exists." {
+			t.Errorf("Unexpected response: %+v", response)
+		}
+		if response.ActionResults() != nil {
+			t.Error("Expected no results")
+		}
+	})
+
+	t.Run("invalid json", func(t *testing.T) {
+		if _, err := ParseActionResponse([]byte(`not json`)); err == nil {
+			t.Error("Expected error for invalid JSON")
+		}
+	})
+}
+
+func TestHeaderLogValue(t *testing.T) {
+	t.Cleanup(func() { SetLogSecrets(false) })
+
+	tests := []struct {
+		key   string
+		value string
+		want  string
+	}{
+		{"Authorization", "Basic c2VjcmV0", redacted},
+		{"authorization", "Basic c2VjcmV0", redacted},
+		{"Set-Cookie", "session=abc", redacted},
+		{"Cookie", "session=abc", redacted},
+		{"Content-Type", "application/json", "application/json"},
+	}
+
+	for _, tt := range tests {
+		if got := headerLogValue(tt.key, tt.value); got != tt.want {
+			t.Errorf("headerLogValue(%q) = %q, want %q", tt.key, got, tt.want)
+		}
+	}
+
+	SetLogSecrets(true)
+	if got := headerLogValue("Authorization", "Basic c2VjcmV0"); got != "Basic c2VjcmV0" {
+		t.Errorf("Expected verbatim value with SetLogSecrets(true), got %q", got)
+	}
+}
+
+func TestWaitForBackend(t *testing.T) {
+	// Reserve a free port and release it, so the first attempts are refused
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	address := listener.Addr().String()
+	_ = listener.Close()
+
+	t.Run("no wait", func(t *testing.T) {
+		if err := New(address, "pw").waitForBackend(0, 10*time.Millisecond); err != nil {
+			t.Errorf("waitForBackend(0) error = %v", err)
+		}
+	})
+
+	t.Run("unreachable", func(t *testing.T) {
+		err := New(address, "pw").waitForBackend(150*time.Millisecond, 20*time.Millisecond)
+		if err == nil || !strings.Contains(err.Error(), "not reachable within 150ms") {
+			t.Errorf("Expected not reachable error, got %v", err)
+		}
+	})
+
+	t.Run("becomes reachable", func(t *testing.T) {
+		ready := make(chan net.Listener, 1)
+		go func() {
+			time.Sleep(100 * time.Millisecond)
+			l, err := net.Listen("tcp", address)
+			if err != nil {
+				ready <- nil
+				return
+			}
+			ready <- l
+		}()
+
+		err := New(address, "pw").waitForBackend(5*time.Second, 20*time.Millisecond)
+		l := <-ready
+		if l == nil {
+			t.Skip("port was taken by another process")
+		}
+		defer func() { _ = l.Close() }()
+		if err != nil {
+			t.Errorf("waitForBackend() error = %v", err)
+		}
+	})
+}
@@ -0,0 +1,137 @@
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"sigs.k8s.io/yaml"
+)
+
+// ConfigFileFlag is the name of the global config file flag defined on the root command
+const ConfigFileFlag = "config-file"
+
+// configFileAnnotation marks flags whose default was set from the config file
+const configFileAnnotation = "osmanage_config_file"
+
+// fileRefusedFlags are destructive flags that must be given on the command
+// line, so a shared config file cannot silently turn them on
+var fileRefusedFlags = []string{"force", "force-secrets", "force-certs", "force-files", "force-finalize", "clean", "prune", "unsafe"}
+
+// DefaultConfigFile returns the path of the config file used without
+// --config-file, osmanage.yaml in the user config directory (e.g. ~/.config).
+// Returns an empty string if the directory cannot be determined.
+func DefaultConfigFile() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "osmanage.yaml")
+}
+
+// ApplyConfigFile sets the default of all flags of cmd that were not given on
+// the command line to the values in the YAML file at path. The file maps flag
+// names to values, lists are joined with commas. Keys that are not flags of cmd
+// are ignored, so one file can hold defaults for all commands. Destructive
+// flags like force are refused.
+// The flags are not marked as changed, so flag groups like mutually exclusive
+// flags only consider the command line; use FlagGiven to also consider the
+// file. A value from the file satisfies a required flag.
+// If optional is true, a missing file is not an error. Returns the names of the
+// flags set from the file.
+func ApplyConfigFile(cmd *cobra.Command, path string, optional bool) ([]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if optional && errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("reading config file: %w", err)
+	}
+
+	var values map[string]any
+	if err := yaml.Unmarshal(data, &values); err != nil {
+		return nil, fmt.Errorf("parsing config file %s: %w", path, err)
+	}
+
+	names := make([]string, 0, len(values))
+	for name := range values {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var applied []string
+	for _, name := range names {
+		if slices.Contains(fileRefusedFlags, name) {
+			return nil, fmt.Errorf("config file %s: %s cannot be set in a config file, give --%s on the command line", path, name, name)
+		}
+		flag := cmd.Flags().Lookup(name)
+		if flag == nil || flag.Changed || name == ConfigFileFlag {
+			continue
+		}
+		value, err := configValue(values[name])
+		if err != nil {
+			return nil, fmt.Errorf("config file %s: %s: %w", path, name, err)
+		}
+		if err := setDefault(flag, value, path); err != nil {
+			return nil, fmt.Errorf("config file %s: %s: %w", path, name, err)
+		}
+		applied = append(applied, name)
+	}
+	return applied, nil
+}
+
+// setDefault sets value as the default of flag without marking it as changed
+// and records path as its origin
+func setDefault(flag *pflag.Flag, value, path string) error {
+	if err := flag.Value.Set(value); err != nil {
+		return err
+	}
+	flag.DefValue = flag.Value.String()
+	if flag.Annotations == nil {
+		flag.Annotations = map[string][]string{}
+	}
+	flag.Annotations[configFileAnnotation] = []string{path}
+	if _, ok := flag.Annotations[cobra.BashCompOneRequiredFlag]; ok {
+		flag.Annotations[cobra.BashCompOneRequiredFlag] = []string{"false"}
+	}
+	return nil
+}
+
+// FlagGiven reports whether the flag name of cmd was given on the command line
+// or set from the config file
+func FlagGiven(cmd *cobra.Command, name string) bool {
+	flag := cmd.Flags().Lookup(name)
+	if flag == nil {
+		return false
+	}
+	_, fromFile := flag.Annotations[configFileAnnotation]
+	return flag.Changed || fromFile
+}
+
+// configValue converts a YAML value to its flag string representation
+func configValue(value any) (string, error) {
+	switch v := value.(type) {
+	case nil:
+		return "", fmt.Errorf("no value")
+	case map[string]any:
+		return "", fmt.Errorf("nested values are not supported")
+	case []any:
+		parts := make([]string, 0, len(v))
+		for _, item := range v {
+			part, err := configValue(item)
+			if err != nil {
+				return "", err
+			}
+			parts = append(parts, part)
+		}
+		return strings.Join(parts, ","), nil
+	default:
+		return fmt.Sprint(v), nil
+	}
+}
@@ -0,0 +1,160 @@
+package utils
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// configTestCmd returns a command with flags of the kinds used by manage commands
+func configTestCmd() *cobra.Command {
+	cmd := &cobra.Command{Use: "test"}
+	cmd.Flags().StringP("address", "a", "", "address")
+	cmd.Flags().String("password-file", "", "password file")
+	cmd.Flags().Int("max-retries", 5, "retries")
+	cmd.Flags().Duration(TimeoutFlag, 0, "timeout")
+	cmd.Flags().StringSlice("fields", nil, "fields")
+	return cmd
+}
+
+func writeConfigFile(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "osmanage.yaml")
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestApplyConfigFile(t *testing.T) {
+	path := writeConfigFile(t, `address: backend:9002
+password-file: ./secrets/superadmin
+max-retries: 10
+timeout: 30s
+fields: [username, email]
+postgres-host: db
+`)
+
+	t.Run("sets flags not given", func(t *testing.T) {
+		cmd := configTestCmd()
+		if err := cmd.ParseFlags([]string{"--address", "localhost:9002"}); err != nil {
+			t.Fatal(err)
+		}
+
+		applied, err := ApplyConfigFile(cmd, path, false)
+		if err != nil {
+			t.Fatalf("ApplyConfigFile() error = %v", err)
+		}
+
+		if want := []string{"fields", "max-retries", "password-file", "timeout"}; !slices.Equal(applied, want) {
+			t.Errorf("Expected applied %v, got %v", want, applied)
+		}
+		if address, _ := cmd.Flags().GetString("address"); address != "localhost:9002" {
+			t.Errorf("Expected explicit address to win, got %s", address)
+		}
+		if passwordFile, _ := cmd.Flags().GetString("password-file"); passwordFile != "./secrets/superadmin" {
+			t.Errorf("Expected password-file from config, got %s", passwordFile)
+		}
+		if retries, _ := cmd.Flags().GetInt("max-retries"); retries != 10 {
+			t.Errorf("Expected max-retries 10, got %d", retries)
+		}
+		if got := Timeout(cmd, time.Minute); got != 30*time.Second {
+			t.Errorf("Expected timeout 30s, got %v", got)
+		}
+		if fields, _ := cmd.Flags().GetStringSlice("fields"); !slices.Equal(fields, []string{"username", "email"}) {
+			t.Errorf("Expected fields [username email], got %v", fields)
+		}
+	})
+
+	t.Run("invalid value", func(t *testing.T) {
+		cmd := configTestCmd()
+		if _, err := ApplyConfigFile(cmd, writeConfigFile(t, "max-retries: many\n"), false); err == nil {
+			t.Error("Expected error for invalid int value")
+		}
+	})
+
+	t.Run("nested value", func(t *testing.T) {
+		cmd := configTestCmd()
+		if _, err := ApplyConfigFile(cmd, writeConfigFile(t, "address:\n  host: backend\n"), false); err == nil {
+			t.Error("Expected error for nested value")
+		}
+	})
+
+	t.Run("not marked as changed", func(t *testing.T) {
+		cmd := configTestCmd()
+		cmd.Flags().String("unset", "", "not in the config file")
+		if _, err := ApplyConfigFile(cmd, path, false); err != nil {
+			t.Fatalf("ApplyConfigFile() error = %v", err)
+		}
+		if cmd.Flags().Changed("password-file") {
+			t.Error("Expected flag from config file not to be marked as changed")
+		}
+		if !FlagGiven(cmd, "password-file") {
+			t.Error("Expected FlagGiven to report the flag from the config file")
+		}
+		if FlagGiven(cmd, "unset") {
+			t.Error("Expected FlagGiven to be false for a flag set nowhere")
+		}
+	})
+
+	t.Run("refuses destructive flags", func(t *testing.T) {
+		cmd := configTestCmd()
+		force := cmd.Flags().Bool("force", false, "force")
+		_, err := ApplyConfigFile(cmd, writeConfigFile(t, "force: true\n"), false)
+		if err == nil || !strings.Contains(err.Error(), "force cannot be set in a config file") {
+			t.Errorf("Expected error for force in config file, got %v", err)
+		}
+		if *force {
+			t.Error("Expected force to stay off")
+		}
+	})
+
+	t.Run("satisfies required flag", func(t *testing.T) {
+		cmd := configTestCmd()
+		cmd.RunE = func(cmd *cobra.Command, args []string) error { return nil }
+		_ = cmd.MarkFlagRequired("password-file")
+		cmd.PersistentPreRunE = func(cmd *cobra.Command, args []string) error {
+			_, err := ApplyConfigFile(cmd, path, false)
+			return err
+		}
+		cmd.SetArgs(nil)
+		if err := cmd.Execute(); err != nil {
+			t.Errorf("Execute() error = %v", err)
+		}
+	})
+
+	t.Run("no conflict with mutually exclusive flag", func(t *testing.T) {
+		// Like get, where --from-env excludes the --postgres-* flags
+		cmd := &cobra.Command{Use: "get", RunE: func(cmd *cobra.Command, args []string) error { return nil }}
+		fromEnv := cmd.Flags().Bool("from-env", false, "from env")
+		host := cmd.Flags().String("postgres-host", "", "host")
+		cmd.MarkFlagsMutuallyExclusive("from-env", "postgres-host")
+		cmd.PersistentPreRunE = func(cmd *cobra.Command, args []string) error {
+			_, err := ApplyConfigFile(cmd, path, false)
+			return err
+		}
+
+		cmd.SetArgs([]string{"--from-env"})
+		if err := cmd.Execute(); err != nil {
+			t.Fatalf("Execute() error = %v", err)
+		}
+		if !*fromEnv || *host != "db" {
+			t.Errorf("Expected from-env with postgres-host default db, got %v, %q", *fromEnv, *host)
+		}
+	})
+
+	t.Run("missing file", func(t *testing.T) {
+		missing := filepath.Join(t.TempDir(), "missing.yaml")
+		if _, err := ApplyConfigFile(configTestCmd(), missing, false); err == nil {
+			t.Error("Expected error for missing file")
+		}
+		if _, err := ApplyConfigFile(configTestCmd(), missing, true); err != nil {
+			t.Errorf("Expected no error for missing optional file, got %v", err)
+		}
+	})
+}
@@ -0,0 +1,51 @@
+package utils
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"golang.org/x/term"
+)
+
+// Confirm writes prompt to w and reads a single line answer from r.
+//
+// If requireExact is empty, the prompt is a yes/no question defaulting to no and
+// only "y" or "yes" (case-insensitive) confirm. Otherwise the answer must match
+// requireExact exactly, e.g. to make the user type the name of the resource to delete.
+// An empty input (EOF) does not confirm.
+func Confirm(w io.Writer, r io.Reader, prompt string, requireExact string) (bool, error) {
+	if requireExact == "" {
+		prompt += " [y/N]: "
+	} else {
+		prompt += " "
+	}
+	if _, err := fmt.Fprint(w, prompt); err != nil {
+		return false, fmt.Errorf("writing prompt: %w", err)
+	}
+
+	answer, err := bufio.NewReader(r).ReadString('\n')
+	if err != nil && !errors.Is(err, io.EOF) {
+		return false, fmt.Errorf("reading answer: %w", err)
+	}
+	answer = strings.TrimRight(answer, "\r\n")
+
+	if requireExact != "" {
+		return answer == requireExact, nil
+	}
+
+	switch strings.ToLower(strings.TrimSpace(answer)) {
+	case "y", "yes":
+		return true, nil
+	default:
+		return false, nil
+	}
+}
+
+// IsInteractive reports whether f is connected to a terminal
+func IsInteractive(f *os.File) bool {
+	return term.IsTerminal(int(f.Fd()))
+}
@@ -0,0 +1,73 @@
+package utils
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestConfirm_YesNo(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected bool
+	}{
+		{"y", "y\n", true},
+		{"yes", "yes\n", true},
+		{"uppercase YES", "YES\n", true},
+		{"with whitespace", "  y  \n", true},
+		{"windows line ending", "y\r\n", true},
+		{"no trailing newline", "y", true},
+		{"n", "n\n", false},
+		{"empty line", "\n", false},
+		{"eof", "", false},
+		{"other", "sure\n", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out bytes.Buffer
+			result, err := Confirm(&out, strings.NewReader(tt.input), "Continue?", "")
+			if err != nil {
+				t.Fatalf("Confirm() error = %v", err)
+			}
+			if result != tt.expected {
+				t.Errorf("Confirm(%q) = %v, want %v", tt.input, result, tt.expected)
+			}
+			if out.String() != "Continue? [y/N]: " {
+				t.Errorf("Unexpected prompt: %q", out.String())
+			}
+		})
+	}
+}
+
+func TestConfirm_ExactMatch(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected bool
+	}{
+		{"exact", "myinstance\n", true},
+		{"no trailing newline", "myinstance", true},
+		{"yes is not enough", "yes\n", false},
+		{"different case", "MyInstance\n", false},
+		{"prefix", "myinst\n", false},
+		{"eof", "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out bytes.Buffer
+			result, err := Confirm(&out, strings.NewReader(tt.input), "Type the instance name to confirm:", "myinstance")
+			if err != nil {
+				t.Fatalf("Confirm() error = %v", err)
+			}
+			if result != tt.expected {
+				t.Errorf("Confirm(%q) = %v, want %v", tt.input, result, tt.expected)
+			}
+			if out.String() != "Type the instance name to confirm: " {
+				t.Errorf("Unexpected prompt: %q", out.String())
+			}
+		})
+	}
+}
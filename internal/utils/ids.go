@@ -0,0 +1,127 @@
+package utils
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// ReadIDsFrom reads the output of the get command from a file or stdin ("-")
+// and returns the ids of the models in it, see ParseGetOutputIDs.
+func ReadIDsFrom(filename string) ([]int64, error) {
+	data, err := ReadFromFileOrStdin(filename)
+	if err != nil {
+		return nil, fmt.Errorf("reading ids: %w", err)
+	}
+	return ParseGetOutputIDs(data)
+}
+
+// ParseGetOutputIDs extracts the model ids from the output of the get command:
+// a JSON object keyed by id (--output json), a JSON array of objects with an
+// id field (--flatten) or one such object per line (--output jsonl and
+// ndjson-summary, whose summary line is skipped). The ids are returned sorted
+// and without duplicates.
+func ParseGetOutputIDs(data []byte) ([]int64, error) {
+	trimmed := bytes.TrimSpace(data)
+	if len(trimmed) == 0 {
+		return nil, fmt.Errorf("no ids found in empty input")
+	}
+
+	var ids []int64
+	switch trimmed[0] {
+	case '[':
+		var records []map[string]any
+		if err := json.Unmarshal(trimmed, &records); err != nil {
+			return nil, fmt.Errorf("parsing JSON array: %w", err)
+		}
+		for i, record := range records {
+			id, err := recordID(record)
+			if err != nil {
+				return nil, fmt.Errorf("element %d: %w", i+1, err)
+			}
+			ids = append(ids, id)
+		}
+	case '{':
+		var err error
+		if ids, err = objectOrLinesIDs(trimmed); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("expected a JSON object, array or JSON lines")
+	}
+
+	if len(ids) == 0 {
+		return nil, fmt.Errorf("no ids found in input")
+	}
+	slices.Sort(ids)
+	return slices.Compact(ids), nil
+}
+
+// objectOrLinesIDs returns the ids of a JSON object keyed by id, or of JSON
+// lines with one model per line
+func objectOrLinesIDs(data []byte) ([]int64, error) {
+	var models map[string]json.RawMessage
+	if err := json.Unmarshal(data, &models); err == nil {
+		if _, ok := models["id"]; !ok {
+			ids := make([]int64, 0, len(models))
+			for key := range models {
+				id, err := parseID(key)
+				if err != nil {
+					return nil, fmt.Errorf("key %q: %w", key, err)
+				}
+				ids = append(ids, id)
+			}
+			return ids, nil
+		}
+	}
+
+	var ids []int64
+	for i, line := range strings.Split(string(data), "\n") {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		var record map[string]any
+		if err := json.Unmarshal([]byte(line), &record); err != nil {
+			return nil, fmt.Errorf("line %d: %w", i+1, err)
+		}
+		if _, ok := record["_summary"]; ok {
+			continue
+		}
+		id, err := recordID(record)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", i+1, err)
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
+
+// recordID returns the id field of a decoded model
+func recordID(record map[string]any) (int64, error) {
+	switch value := record["id"].(type) {
+	case float64:
+		if value != math.Trunc(value) || value <= 0 {
+			return 0, fmt.Errorf("invalid id %v", value)
+		}
+		return int64(value), nil
+	case string:
+		return parseID(value)
+	case nil:
+		return 0, fmt.Errorf("missing id field")
+	default:
+		return 0, fmt.Errorf("invalid id %v", value)
+	}
+}
+
+// parseID parses a positive model id
+func parseID(value string) (int64, error) {
+	id, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || id <= 0 {
+		return 0, fmt.Errorf("invalid id %q", value)
+	}
+	return id, nil
+}
@@ -0,0 +1,84 @@
+package utils
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestParseGetOutputIDs(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    []int64
+		wantErr bool
+	}{
+		{
+			name: "object keyed by id",
+			input: `{
+  "7": {"id": 7, "username": "bob"},
+  "3": {"id": 3, "username": "alice"}
+}`,
+			want: []int64{3, 7},
+		},
+		{
+			name:  "object with aliased id",
+			input: `{"5": {"user_id": 5}}`,
+			want:  []int64{5},
+		},
+		{
+			name:  "flattened array",
+			input: `[{"id": 2}, {"id": 1000000}, {"id": 2}]`,
+			want:  []int64{2, 1000000},
+		},
+		{
+			name:  "json lines with summary",
+			input: "{\"id\":4,\"username\":\"a\"}\n{\"id\":1}\n{\"_summary\":{\"count\":2}}\n",
+			want:  []int64{1, 4},
+		},
+		{
+			name:  "single json line",
+			input: `{"id": 9, "username": "x"}`,
+			want:  []int64{9},
+		},
+		{name: "empty", input: "  \n", wantErr: true},
+		{name: "empty object", input: `{}`, wantErr: true},
+		{name: "non-numeric key", input: `{"abc": {}}`, wantErr: true},
+		{name: "array without id", input: `[{"username": "x"}]`, wantErr: true},
+		{name: "fractional id", input: `[{"id": 1.5}]`, wantErr: true},
+		{name: "line without id", input: "{\"id\":1}\n{\"name\":\"x\"}", wantErr: true},
+		{name: "plain list", input: `5,6`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseGetOutputIDs([]byte(tt.input))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseGetOutputIDs() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("ParseGetOutputIDs() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReadIDsFrom(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "users.json")
+	if err := os.WriteFile(path, []byte(`{"1": {"id": 1}, "2": {"id": 2}}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	ids, err := ReadIDsFrom(path)
+	if err != nil {
+		t.Fatalf("ReadIDsFrom() error = %v", err)
+	}
+	if !slices.Equal(ids, []int64{1, 2}) {
+		t.Errorf("ReadIDsFrom() = %v, want [1 2]", ids)
+	}
+
+	if _, err := ReadIDsFrom(filepath.Join(t.TempDir(), "missing.json")); err == nil {
+		t.Error("Expected error for missing file")
+	}
+}
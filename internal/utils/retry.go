@@ -0,0 +1,116 @@
+package utils
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/OpenSlides/openslides-cli/internal/logger"
+)
+
+// retryablePatterns are substrings of network errors worth retrying
+var retryablePatterns = []string{
+	"connection refused",
+	"connection reset",
+	"timeout",
+	"temporary failure",
+	"no such host",
+	"network is unreachable",
+	"eof",
+	"broken pipe",
+	"i/o timeout",
+}
+
+// retryableServerErrors are substrings of HTTP server errors (5xx) worth retrying
+var retryableServerErrors = []string{"server error", "503", "502", "504"}
+
+// IsRetryableError determines if an error should trigger a retry
+func IsRetryableError(err error) bool {
+	if err == nil {
+		return false
+	}
+
+	errStr := strings.ToLower(err.Error())
+
+	for _, pattern := range retryablePatterns {
+		if strings.Contains(errStr, pattern) {
+			return true
+		}
+	}
+
+	for _, code := range retryableServerErrors {
+		if strings.Contains(errStr, code) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// retryAfterError is implemented by errors carrying the delay a server asked
+// for before the next attempt, e.g. by a Retry-After header
+type retryAfterError interface {
+	RetryAfterDelay() time.Duration
+}
+
+// RetryHTTP calls fn up to maxRetries times, waiting delay between attempts,
+// as long as it fails with an error accepted by IsRetryableError. If the error
+// carries a server requested delay, that is waited instead, but not beyond the
+// deadline of ctx. The error of the last attempt is returned. It aborts when
+// ctx is done.
+func RetryHTTP(ctx context.Context, maxRetries int, delay time.Duration, fn func() error) error {
+	var lastErr error
+	wait := delay
+
+	for attempt := range maxRetries {
+		if ctx.Err() != nil {
+			return fmt.Errorf("aborted: %w", ctx.Err())
+		}
+
+		if attempt > 0 {
+			logger.Warn("Retry attempt %d/%d after %v (previous error: %v)",
+				attempt, maxRetries, wait, lastErr)
+
+			select {
+			case <-time.After(wait):
+				// Continue to next attempt
+			case <-ctx.Done():
+				return fmt.Errorf("cancelled during retry: %w", ctx.Err())
+			}
+		}
+
+		err := fn()
+		if err == nil {
+			return nil
+		}
+		if ctx.Err() != nil {
+			return fmt.Errorf("aborted: %w", ctx.Err())
+		}
+		lastErr = err
+		if !IsRetryableError(err) {
+			return err
+		}
+		logger.Debug("Retryable error: %v", err)
+		wait = retryDelay(ctx, err, delay)
+	}
+
+	return fmt.Errorf("failed after %d attempts: %w", maxRetries, lastErr)
+}
+
+// retryDelay returns the delay requested by err if it has one, capped to the
+// time left until the deadline of ctx, otherwise delay
+func retryDelay(ctx context.Context, err error, delay time.Duration) time.Duration {
+	var rae retryAfterError
+	if !errors.As(err, &rae) || rae.RetryAfterDelay() <= 0 {
+		return delay
+	}
+
+	wait := rae.RetryAfterDelay()
+	if deadline, ok := ctx.Deadline(); ok {
+		wait = min(wait, time.Until(deadline))
+	}
+	logger.Debug("Server asked to retry after %v", wait)
+	return wait
+}
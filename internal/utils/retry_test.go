@@ -0,0 +1,160 @@
+package utils
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestIsRetryableError(t *testing.T) {
+	tests := []struct {
+		name      string
+		errMsg    string
+		retryable bool
+	}{
+		{"nil error", "", false},
+		{"connection refused", "connection refused", true},
+		{"connection reset", "connection reset by peer", true},
+		{"timeout", "i/o timeout", true},
+		{"eof", "unexpected EOF", true},
+		{"server error 503", "server returned 503", true},
+		{"server error 502", "bad gateway 502", true},
+		{"server error 504", "gateway timeout 504", true},
+		{"client error 404", "404 not found", false},
+		{"auth error", "unauthorized", false},
+		{"parse error", "invalid JSON", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var err error
+			if tt.errMsg != "" {
+				err = &testError{msg: tt.errMsg}
+			}
+
+			if got := IsRetryableError(err); got != tt.retryable {
+				t.Errorf("IsRetryableError() = %v, want %v for error: %s", got, tt.retryable, tt.errMsg)
+			}
+		})
+	}
+}
+
+// Helper type for testing
+type testError struct {
+	msg string
+}
+
+func (e *testError) Error() string {
+	return e.msg
+}
+
+func TestRetryHTTP(t *testing.T) {
+	t.Run("retries retryable errors", func(t *testing.T) {
+		calls := 0
+		err := RetryHTTP(context.Background(), 3, time.Millisecond, func() error {
+			calls++
+			if calls < 3 {
+				return errors.New("connection refused")
+			}
+			return nil
+		})
+		if err != nil {
+			t.Errorf("RetryHTTP() error = %v", err)
+		}
+		if calls != 3 {
+			t.Errorf("Expected 3 calls, got %d", calls)
+		}
+	})
+
+	t.Run("stops on other errors", func(t *testing.T) {
+		calls := 0
+		wantErr := errors.New("unauthorized")
+		err := RetryHTTP(context.Background(), 3, time.Millisecond, func() error {
+			calls++
+			return wantErr
+		})
+		if !errors.Is(err, wantErr) {
+			t.Errorf("RetryHTTP() error = %v, want %v", err, wantErr)
+		}
+		if calls != 1 {
+			t.Errorf("Expected 1 call, got %d", calls)
+		}
+	})
+
+	t.Run("gives up after max retries", func(t *testing.T) {
+		calls := 0
+		err := RetryHTTP(context.Background(), 2, time.Millisecond, func() error {
+			calls++
+			return errors.New("server returned 503")
+		})
+		if err == nil {
+			t.Error("Expected error after max retries")
+		}
+		if calls != 2 {
+			t.Errorf("Expected 2 calls, got %d", calls)
+		}
+	})
+
+	t.Run("aborts when context is done", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		err := RetryHTTP(ctx, 3, time.Hour, func() error {
+			cancel()
+			return errors.New("connection reset")
+		})
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("RetryHTTP() error = %v, want context.Canceled", err)
+		}
+	})
+}
+
+// retryAfterTestError is a retryable error asking for a delay
+type retryAfterTestError struct {
+	after time.Duration
+}
+
+func (e *retryAfterTestError) Error() string                  { return "server returned 503" }
+func (e *retryAfterTestError) RetryAfterDelay() time.Duration { return e.after }
+
+func TestRetryDelay(t *testing.T) {
+	t.Run("plain error uses delay", func(t *testing.T) {
+		if got := retryDelay(context.Background(), errors.New("eof"), time.Second); got != time.Second {
+			t.Errorf("retryDelay() = %v, want 1s", got)
+		}
+	})
+
+	t.Run("server delay replaces delay", func(t *testing.T) {
+		err := fmt.Errorf("wrapped: %w", &retryAfterTestError{after: time.Minute})
+		if got := retryDelay(context.Background(), err, time.Second); got != time.Minute {
+			t.Errorf("retryDelay() = %v, want 1m", got)
+		}
+	})
+
+	t.Run("server delay capped to deadline", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		got := retryDelay(ctx, &retryAfterTestError{after: time.Hour}, time.Second)
+		if got > 10*time.Second || got < 9*time.Second {
+			t.Errorf("retryDelay() = %v, want about 10s", got)
+		}
+	})
+
+	t.Run("RetryHTTP waits the server delay", func(t *testing.T) {
+		calls := 0
+		start := time.Now()
+		err := RetryHTTP(context.Background(), 2, time.Minute, func() error {
+			calls++
+			if calls == 1 {
+				return &retryAfterTestError{after: time.Millisecond}
+			}
+			return nil
+		})
+		if err != nil {
+			t.Errorf("RetryHTTP() error = %v", err)
+		}
+		if time.Since(start) > 30*time.Second {
+			t.Error("Expected server delay instead of the default delay")
+		}
+	})
+}
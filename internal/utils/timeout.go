@@ -0,0 +1,54 @@
+package utils
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// TimeoutFlag is the name of the global timeout flag defined on the root command
+const TimeoutFlag = "timeout"
+
+// Timeout returns the value of the global --timeout flag if it was given on
+// the command line or set from the config file, otherwise fallback, the
+// command's own default.
+func Timeout(cmd *cobra.Command, fallback time.Duration) time.Duration {
+	if !FlagGiven(cmd, TimeoutFlag) {
+		return fallback
+	}
+	timeout, err := cmd.Flags().GetDuration(TimeoutFlag)
+	if err != nil {
+		return fallback
+	}
+	return timeout
+}
+
+// TimeoutContext returns a context bounded by Timeout(cmd, fallback). If that
+// is zero, the context is only cancelled by calling cancel.
+func TimeoutContext(cmd *cobra.Command, fallback time.Duration) (context.Context, context.CancelFunc) {
+	if timeout := Timeout(cmd, fallback); timeout > 0 {
+		return context.WithTimeout(context.Background(), timeout)
+	}
+	return context.WithCancel(context.Background())
+}
+
+// CommandContext returns a context that is cancelled on Ctrl-C and, if the
+// global --timeout is given, after that timeout.
+func CommandContext(cmd *cobra.Command) (context.Context, context.CancelFunc) {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+
+	timeout := Timeout(cmd, 0)
+	if timeout <= 0 {
+		return ctx, stop
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	return ctx, func() {
+		cancel()
+		stop()
+	}
+}
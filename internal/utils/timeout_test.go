@@ -0,0 +1,82 @@
+package utils
+
+import (
+	"testing"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// runWithTimeout executes a child command of a root command with the global
+// timeout flag and returns the timeout the child resolved.
+func runWithTimeout(t *testing.T, fallback time.Duration, args ...string) time.Duration {
+	t.Helper()
+
+	var got time.Duration
+	root := &cobra.Command{Use: "root"}
+	root.PersistentFlags().Duration(TimeoutFlag, 0, "global timeout")
+	child := &cobra.Command{
+		Use: "child",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			got = Timeout(cmd, fallback)
+			return nil
+		},
+	}
+	root.AddCommand(child)
+
+	root.SetArgs(append([]string{"child"}, args...))
+	if err := root.Execute(); err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	return got
+}
+
+func TestTimeout(t *testing.T) {
+	t.Run("command default without flag", func(t *testing.T) {
+		if got := runWithTimeout(t, 3*time.Minute); got != 3*time.Minute {
+			t.Errorf("Timeout() = %v, want 3m", got)
+		}
+	})
+
+	t.Run("root flag overrides command default", func(t *testing.T) {
+		if got := runWithTimeout(t, 3*time.Minute, "--timeout", "30s"); got != 30*time.Second {
+			t.Errorf("Timeout() = %v, want 30s", got)
+		}
+	})
+
+	t.Run("root flag applies to commands without default", func(t *testing.T) {
+		if got := runWithTimeout(t, 0, "--timeout", "10s"); got != 10*time.Second {
+			t.Errorf("Timeout() = %v, want 10s", got)
+		}
+	})
+
+	t.Run("no flag and no default", func(t *testing.T) {
+		if got := runWithTimeout(t, 0); got != 0 {
+			t.Errorf("Timeout() = %v, want 0", got)
+		}
+	})
+}
+
+func TestTimeoutContext(t *testing.T) {
+	cmd := &cobra.Command{Use: "cmd"}
+	cmd.Flags().Duration(TimeoutFlag, 0, "timeout")
+
+	ctx, cancel := TimeoutContext(cmd, 0)
+	defer cancel()
+	if _, ok := ctx.Deadline(); ok {
+		t.Error("Expected no deadline without timeout")
+	}
+
+	if err := cmd.Flags().Set(TimeoutFlag, "1m"); err != nil {
+		t.Fatalf("setting flag: %v", err)
+	}
+	ctx, cancel = TimeoutContext(cmd, 0)
+	defer cancel()
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		t.Fatal("Expected deadline with timeout")
+	}
+	if remaining := time.Until(deadline); remaining <= 0 || remaining > time.Minute {
+		t.Errorf("Expected deadline within 1m, got %v", remaining)
+	}
+}
@@ -10,7 +10,10 @@ import (
 	"path/filepath"
 	"strings"
 
+	"github.com/OpenSlides/openslides-cli/internal/constants"
 	"github.com/OpenSlides/openslides-cli/internal/logger"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"sigs.k8s.io/yaml"
 )
 
 // CreateFile creates a file in the given directory with the given content.
@@ -46,6 +49,24 @@ func FileExists(p string) (bool, error) {
 	return false, fmt.Errorf("checking existance of file %s: %w", p, err)
 }
 
+// WriteOutputFile writes the output of a command to filename with
+// constants.OutputFilePerm, replacing an existing file. Reporting the written
+// file is left to the caller.
+func WriteOutputFile(filename string, data []byte) error {
+	return CreateFile(filepath.Dir(filename), true, filepath.Base(filename), data, constants.OutputFilePerm)
+}
+
+// CreateOutputFile creates or truncates filename with constants.OutputFilePerm
+// for writing output incrementally
+func CreateOutputFile(filename string) (*os.File, error) {
+	f, err := os.OpenFile(filename, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, constants.OutputFilePerm)
+	if err != nil {
+		return nil, fmt.Errorf("creating output file %q: %w", filename, err)
+	}
+	logger.Info("Writing output to %s", filename)
+	return f, nil
+}
+
 // ReadInputOrFileOrStdin reads data from either a positional argument, a file, or stdin
 func ReadInputOrFileOrStdin(input, filename string) ([]byte, error) {
 	logger.Debug("Reading input: direct=%v, file=%s", input != "", filename)
@@ -92,7 +113,9 @@ func ReadFromFileOrStdin(filename string) ([]byte, error) {
 	return data, nil
 }
 
-// ReadPassword reads password from a file
+// ReadPassword reads password from a file. A single trailing newline (\n or
+// \r\n), as written by e.g. `echo pass > file`, is removed. Other whitespace is
+// kept, since passwords may start or end with spaces.
 func ReadPassword(passwordFile string) (string, error) {
 	logger.Debug("Reading password from: %s", passwordFile)
 
@@ -103,16 +126,95 @@ func ReadPassword(passwordFile string) (string, error) {
 	}
 
 	password := string(data)
+	if trimmed, ok := strings.CutSuffix(password, "\r\n"); ok {
+		password = trimmed
+	} else {
+		password = strings.TrimSuffix(password, "\n")
+	}
 	logger.Debug("Password read successfully (%d bytes)", len(password))
 	return password, nil
 }
 
-// extractNamespace gets the namespace from instance directory path
-// Example: "/real/path/to/my.instance.dir.url" -> "myinstancedirurl"
+// ExtractNamespace gets the namespace from instance directory path. Dots are
+// removed, letters lowercased and other characters invalid in a namespace
+// replaced by "-". The result may still be invalid, see ValidateNamespace.
+// Example: "/real/path/to/My.Instance.dir_url" -> "myinstancedir-url"
 func ExtractNamespace(instanceDir string) string {
-	dirName := filepath.Base(instanceDir)
-	namespace := strings.ReplaceAll(dirName, ".", "")
-	return namespace
+	dirName := strings.ToLower(filepath.Base(instanceDir))
+	namespace := strings.Map(func(r rune) rune {
+		switch {
+		case r == '.':
+			return -1
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9', r == '-':
+			return r
+		default:
+			return '-'
+		}
+	}, dirName)
+	return strings.Trim(namespace, "-")
+}
+
+// ValidateNamespace checks that namespace is a valid Kubernetes namespace
+// name, i.e. an RFC 1123 label
+func ValidateNamespace(namespace string) error {
+	if errs := validation.IsDNS1123Label(namespace); len(errs) > 0 {
+		return fmt.Errorf("invalid namespace %q: %s", namespace, strings.Join(errs, "; "))
+	}
+	return nil
+}
+
+// ResolveNamespace returns the Kubernetes namespace of the instance in
+// projectDir. The name in the namespace.yaml of projectDir is used and
+// ExtractNamespace only if there is none. Disagreeing sources are logged as
+// warning, so commands do not silently act on another instance than the one in
+// projectDir.
+func ResolveNamespace(projectDir string) (string, error) {
+	fromDir := ExtractNamespace(projectDir)
+
+	fromManifest, err := manifestNamespace(filepath.Join(projectDir, constants.NamespaceYAML))
+	if err != nil {
+		return "", err
+	}
+
+	namespace := fromManifest
+	if namespace == "" {
+		logger.Debug("No %s in %s, deriving namespace from directory name", constants.NamespaceYAML, projectDir)
+		if err := ValidateNamespace(fromDir); err != nil {
+			return "", fmt.Errorf("%w: derived from directory %s, use a directory name like my.instance.org with at most 63 characters besides dots, or add a %s",
+				err, filepath.Base(projectDir), constants.NamespaceYAML)
+		}
+		namespace = fromDir
+	} else if namespace != fromDir {
+		logger.Warn("Namespace %s in %s differs from %s derived from the directory name, using %s",
+			namespace, constants.NamespaceYAML, fromDir, namespace)
+	}
+
+	return namespace, nil
+}
+
+// manifestNamespace returns the metadata.name of the namespace manifest at
+// path, or an empty string if the file does not exist
+func manifestNamespace(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("reading namespace manifest: %w", err)
+	}
+
+	var manifest struct {
+		Metadata struct {
+			Name string `json:"name"`
+		} `json:"metadata"`
+	}
+	if err := yaml.Unmarshal(data, &manifest); err != nil {
+		return "", fmt.Errorf("parsing namespace manifest %s: %w", path, err)
+	}
+	if manifest.Metadata.Name == "" {
+		return "", fmt.Errorf("namespace manifest %s has no metadata.name", path)
+	}
+	return manifest.Metadata.Name, nil
 }
 
 // isYAMLFile checks if filename has YAML extension
@@ -134,3 +236,29 @@ func KeepValueOrEnvOrDefault(value *string, envVarName string, defaultValue stri
 
 	*value = defaultValue
 }
+
+// KeepValueOrBackendManageAddress sets an empty address to the backendManage
+// address from the OSMANAGE_BACKEND_ADDRESS env var. It returns an error if
+// neither is set.
+func KeepValueOrBackendManageAddress(address *string) error {
+	if *address != "" {
+		return nil
+	}
+
+	if *address = os.Getenv(constants.EnvOsmanageBackendAddress); *address != "" {
+		logger.Debug("Using backendManage address from %s: %s", constants.EnvOsmanageBackendAddress, *address)
+		return nil
+	}
+
+	return fmt.Errorf("no backendManage address: use --address or set $%s", constants.EnvOsmanageBackendAddress)
+}
+
+// ExitError makes the command exit with Code without printing an error, for
+// results that scripts check by exit status
+type ExitError struct {
+	Code int
+}
+
+func (e *ExitError) Error() string {
+	return fmt.Sprintf("exit status %d", e.Code)
+}
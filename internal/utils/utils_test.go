@@ -3,6 +3,7 @@ package utils
 import (
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/OpenSlides/openslides-cli/internal/constants"
@@ -107,6 +108,38 @@ func TestReadPassword(t *testing.T) {
 		}
 	})
 
+	t.Run("trailing newline", func(t *testing.T) {
+		tests := []struct {
+			name    string
+			content string
+			want    string
+		}{
+			{"without newline", "secret123", "secret123"},
+			{"with newline", "secret123\n", "secret123"},
+			{"with CRLF", "secret123\r\n", "secret123"},
+			{"only one newline removed", "secret123\n\n", "secret123\n"},
+			{"spaces kept", " secret 123 \n", " secret 123 "},
+			{"trailing carriage return kept", "secret123\r", "secret123\r"},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				path := filepath.Join(t.TempDir(), "password")
+				if err := os.WriteFile(path, []byte(tt.content), 0600); err != nil {
+					t.Fatal(err)
+				}
+
+				result, err := ReadPassword(path)
+				if err != nil {
+					t.Fatalf("ReadPassword() error = %v", err)
+				}
+				if result != tt.want {
+					t.Errorf("ReadPassword() = %q, want %q", result, tt.want)
+				}
+			})
+		}
+	})
+
 	t.Run("file not found", func(t *testing.T) {
 		_, err := ReadPassword("nonexistent-password.txt")
 		if err == nil {
@@ -262,6 +295,16 @@ func TestExtractNamespace(t *testing.T) {
 			input:    "/var/lib/test/prod-instance",
 			expected: "prod-instance",
 		},
+		{
+			name:     "uppercase letters",
+			input:    "My.Instance.Dir.org",
+			expected: "myinstancedirorg",
+		},
+		{
+			name:     "invalid characters",
+			input:    "_my instance_",
+			expected: "my-instance",
+		},
 	}
 
 	for _, tt := range tests {
@@ -274,6 +317,73 @@ func TestExtractNamespace(t *testing.T) {
 	}
 }
 
+func TestValidateNamespace(t *testing.T) {
+	valid := []string{"myinstanceorg", "prod-instance", "a1", strings.Repeat("a", 63)}
+	for _, namespace := range valid {
+		if err := ValidateNamespace(namespace); err != nil {
+			t.Errorf("ValidateNamespace(%q) error = %v", namespace, err)
+		}
+	}
+
+	invalid := []string{"", "My-Instance", "-leading", "with_underscore", strings.Repeat("a", 64)}
+	for _, namespace := range invalid {
+		if err := ValidateNamespace(namespace); err == nil {
+			t.Errorf("ValidateNamespace(%q) expected error", namespace)
+		}
+	}
+}
+
+func TestResolveNamespace(t *testing.T) {
+	writeNamespace := func(t *testing.T, dir, content string) {
+		t.Helper()
+		if err := os.WriteFile(filepath.Join(dir, constants.NamespaceYAML), []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to write namespace manifest: %v", err)
+		}
+	}
+
+	t.Run("without manifest", func(t *testing.T) {
+		dir := filepath.Join(t.TempDir(), "my.instance.org")
+		got, err := ResolveNamespace(dir)
+		if err != nil {
+			t.Fatalf("ResolveNamespace() error = %v", err)
+		}
+		if got != "myinstanceorg" {
+			t.Errorf("ResolveNamespace() = %q, want %q", got, "myinstanceorg")
+		}
+	})
+
+	t.Run("manifest wins over directory name", func(t *testing.T) {
+		dir := filepath.Join(t.TempDir(), "my.instance.org")
+		if err := os.Mkdir(dir, 0755); err != nil {
+			t.Fatal(err)
+		}
+		writeNamespace(t, dir, "apiVersion: v1\nkind: Namespace\nmetadata:\n  name: renamed\n")
+
+		got, err := ResolveNamespace(dir)
+		if err != nil {
+			t.Fatalf("ResolveNamespace() error = %v", err)
+		}
+		if got != "renamed" {
+			t.Errorf("ResolveNamespace() = %q, want %q", got, "renamed")
+		}
+	})
+
+	t.Run("directory name too long", func(t *testing.T) {
+		dir := filepath.Join(t.TempDir(), strings.Repeat("a", 64)+".org")
+		if _, err := ResolveNamespace(dir); err == nil {
+			t.Error("Expected error for too long namespace")
+		}
+	})
+
+	t.Run("manifest without name", func(t *testing.T) {
+		dir := t.TempDir()
+		writeNamespace(t, dir, "apiVersion: v1\nkind: Namespace\n")
+		if _, err := ResolveNamespace(dir); err == nil {
+			t.Error("Expected error for manifest without name")
+		}
+	})
+}
+
 func TestFileExists(t *testing.T) {
 	// Create a temporary file
 	tmpFile, err := os.CreateTemp("", "test-file-*")
@@ -357,3 +467,92 @@ func TestIsYAMLFile(t *testing.T) {
 		})
 	}
 }
+
+func TestKeepValueOrBackendManageAddress(t *testing.T) {
+	tests := []struct {
+		name       string
+		flag       string
+		envAddress string
+		want       string
+		wantErr    bool
+	}{
+		{"flag", "backend:9002", "env:9002", "backend:9002", false},
+		{"env", "", "env:9002", "env:9002", false},
+		{"none", "", "", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv(constants.EnvOsmanageBackendAddress, tt.envAddress)
+
+			address := tt.flag
+			err := KeepValueOrBackendManageAddress(&address)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("KeepValueOrBackendManageAddress() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if address != tt.want {
+				t.Errorf("Expected address %q, got %q", tt.want, address)
+			}
+		})
+	}
+}
+
+func TestWriteOutputFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "export.json")
+	if err := os.WriteFile(path, []byte("old content that is longer"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := WriteOutputFile(path, []byte(`{"1":{}}`)); err != nil {
+		t.Fatalf("WriteOutputFile() error = %v", err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != `{"1":{}}` {
+		t.Errorf("Expected file to be replaced, got %q", data)
+	}
+
+	newPath := filepath.Join(t.TempDir(), "new.json")
+	if err := WriteOutputFile(newPath, []byte("{}")); err != nil {
+		t.Fatalf("WriteOutputFile() error = %v", err)
+	}
+	info, err := os.Stat(newPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode().Perm() != constants.OutputFilePerm {
+		t.Errorf("Expected permissions %v, got %v", constants.OutputFilePerm, info.Mode().Perm())
+	}
+
+	if err := WriteOutputFile(filepath.Join(t.TempDir(), "missing", "out.json"), []byte("{}")); err == nil {
+		t.Error("Expected error for missing directory")
+	}
+}
+
+func TestCreateOutputFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "export.jsonl")
+	if err := os.WriteFile(path, []byte("old content that is longer"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	f, err := CreateOutputFile(path)
+	if err != nil {
+		t.Fatalf("CreateOutputFile() error = %v", err)
+	}
+	if _, err := f.WriteString("{\"id\":1}\n"); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "{\"id\":1}\n" {
+		t.Errorf("Expected truncated file, got %q", data)
+	}
+}